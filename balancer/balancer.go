@@ -12,11 +12,11 @@ import (
 type Balancer interface {
 	io.Closer
 	// Put puts a new client.
-	Put(client rsocket.Client) error
+	Put(client rsocket.CloseableRSocket) error
 	// PutLabel puts a new client with a label.
-	PutLabel(label string, client rsocket.Client) error
+	PutLabel(label string, client rsocket.CloseableRSocket) error
 	// Next returns next balanced RSocket client.
-	Next(context.Context) (rsocket.Client, bool)
+	Next(context.Context) (rsocket.CloseableRSocket, bool)
 	// OnLeave handle events when a client exit.
 	OnLeave(fn func(label string))
 }
@@ -0,0 +1,136 @@
+package balancer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rsocket/rsocket-go"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx/mono"
+)
+
+var errNoClient = errors.New("no client is available")
+
+// HedgingPolicy controls how a request is hedged.
+type HedgingPolicy struct {
+	// Delay is the duration to wait for a response before sending the same request to the next client.
+	Delay time.Duration
+	// MaxAttempts is the max number of clients which receive the request, including the first one.
+	MaxAttempts int
+}
+
+// Hedging sends the same request to multiple clients of a Balancer to reduce tail latency.
+// The first success wins, and all other requests in flight will be cancelled.
+type Hedging struct {
+	b      Balancer
+	policy HedgingPolicy
+}
+
+type hedgeResult struct {
+	res payload.Payload
+	err error
+}
+
+// NewHedging returns a new Hedging which picks clients from the Balancer.
+func NewHedging(b Balancer, policy HedgingPolicy) *Hedging {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	if policy.Delay < 0 {
+		policy.Delay = 0
+	}
+	return &Hedging{
+		b:      b,
+		policy: policy,
+	}
+}
+
+// RequestResponse sends the request to a client, then fans out to another client every time the delay
+// elapses without any response, until MaxAttempts is reached. An attempt is also started immediately
+// when all attempts in flight failed. It returns the first response, or the last error if all attempts failed.
+func (h *Hedging) RequestResponse(msg payload.Payload) mono.Mono {
+	req := payload.Clone(msg)
+	return mono.Create(func(ctx context.Context, sink mono.Sink) {
+		ctx, cancel := context.WithCancel(ctx)
+		// cancel all requests which are still in flight.
+		defer cancel()
+
+		results := make(chan hedgeResult, h.policy.MaxAttempts)
+		var (
+			mu   sync.Mutex
+			used []rsocket.CloseableRSocket
+		)
+		attempt := func() {
+			go func() {
+				mu.Lock()
+				client, ok := h.next(ctx, used)
+				if ok {
+					used = append(used, client)
+				}
+				mu.Unlock()
+				if !ok {
+					results <- hedgeResult{err: errNoClient}
+					return
+				}
+				res, err := client.RequestResponseSync(ctx, req)
+				results <- hedgeResult{res: res, err: err}
+			}()
+		}
+
+		sent, failed := 1, 0
+		attempt()
+		timer := time.NewTimer(h.policy.Delay)
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				sink.Error(ctx.Err())
+				return
+			case <-timer.C:
+				if sent < h.policy.MaxAttempts {
+					sent++
+					attempt()
+					timer.Reset(h.policy.Delay)
+				}
+			case it := <-results:
+				if it.err == nil {
+					sink.Success(it.res)
+					return
+				}
+				failed++
+				if failed < sent {
+					continue
+				}
+				if sent >= h.policy.MaxAttempts {
+					sink.Error(it.err)
+					return
+				}
+				// all attempts failed, don't wait for the delay.
+				sent++
+				attempt()
+			}
+		}
+	})
+}
+
+// next prefers a client which has not been used by the request.
+func (h *Hedging) next(ctx context.Context, used []rsocket.CloseableRSocket) (client rsocket.CloseableRSocket, ok bool) {
+	for i := 0; i < h.policy.MaxAttempts; i++ {
+		client, ok = h.b.Next(ctx)
+		if !ok || !containsClient(used, client) {
+			return
+		}
+	}
+	return
+}
+
+func containsClient(clients []rsocket.CloseableRSocket, client rsocket.CloseableRSocket) bool {
+	for _, it := range clients {
+		if it == client {
+			return true
+		}
+	}
+	return false
+}
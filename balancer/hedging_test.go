@@ -0,0 +1,92 @@
+package balancer_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rsocket/rsocket-go"
+	. "github.com/rsocket/rsocket-go/balancer"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx/mono"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+)
+
+func startDelayServer(ctx context.Context, port int, delay time.Duration, requests, cancelled *atomic.Int32) {
+	_ = rsocket.Receive().
+		Acceptor(func(setup payload.SetupPayload, sendingSocket rsocket.CloseableRSocket) (rsocket.RSocket, error) {
+			return rsocket.NewAbstractSocket(
+				rsocket.RequestResponse(func(msg payload.Payload) mono.Mono {
+					requests.Inc()
+					data := fmt.Sprintf("%d", port)
+					return mono.Create(func(ctx context.Context, sink mono.Sink) {
+						time.AfterFunc(delay, func() {
+							sink.Success(payload.NewString(data, ""))
+						})
+					}).DoOnCancel(func() {
+						cancelled.Inc()
+					})
+				}),
+			), nil
+		}).
+		Transport(rsocket.TCPServer().SetHostAndPort("127.0.0.1", port).Build()).
+		Serve(ctx)
+}
+
+func TestHedging_RequestResponse(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const fastPort, slowPort = 7010, 7011
+	fastRequests, fastCancelled := atomic.NewInt32(0), atomic.NewInt32(0)
+	slowRequests, slowCancelled := atomic.NewInt32(0), atomic.NewInt32(0)
+	go startDelayServer(ctx, fastPort, 10*time.Millisecond, fastRequests, fastCancelled)
+	go startDelayServer(ctx, slowPort, 10*time.Second, slowRequests, slowCancelled)
+	time.Sleep(500 * time.Millisecond)
+
+	b := NewRoundRobinBalancer()
+	defer b.Close()
+	// the round-robin balancer picks index 1 at first, so requests go to the slow one first.
+	for _, port := range []int{fastPort, slowPort} {
+		client, err := rsocket.Connect().
+			Transport(rsocket.TCPClient().SetHostAndPort("127.0.0.1", port).Build()).
+			Start(context.Background())
+		assert.NoError(t, err)
+		assert.NoError(t, b.Put(client))
+	}
+
+	h := NewHedging(b, HedgingPolicy{
+		Delay:       100 * time.Millisecond,
+		MaxAttempts: 2,
+	})
+	start := time.Now()
+	res, err := h.RequestResponse(payload.NewString("hello", "")).Block(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%d", fastPort), res.DataUTF8())
+	assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+	assert.Equal(t, int32(1), slowRequests.Load())
+	assert.Equal(t, int32(1), fastRequests.Load())
+	assert.Eventually(t, func() bool {
+		return slowCancelled.Load() == 1
+	}, 3*time.Second, 10*time.Millisecond, "slow request should be cancelled")
+
+	// no hedging when the first response arrives within the delay.
+	b2 := NewRoundRobinBalancer()
+	defer b2.Close()
+	client, err := rsocket.Connect().
+		Transport(rsocket.TCPClient().SetHostAndPort("127.0.0.1", fastPort).Build()).
+		Start(context.Background())
+	assert.NoError(t, err)
+	assert.NoError(t, b2.Put(client))
+	h = NewHedging(b2, HedgingPolicy{
+		Delay:       5 * time.Second,
+		MaxAttempts: 2,
+	})
+	res, err = h.RequestResponse(payload.NewString("hello", "")).Block(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%d", fastPort), res.DataUTF8())
+	assert.Equal(t, int32(2), fastRequests.Load())
+	assert.Equal(t, int32(0), fastCancelled.Load())
+}
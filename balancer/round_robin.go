@@ -18,7 +18,7 @@ var errConflictSocket = errors.New("socket exists already")
 type balancerRoundRobin struct {
 	seq     *atomic.Uint32
 	keys    []string
-	sockets []rsocket.Client
+	sockets []rsocket.CloseableRSocket
 	done    chan struct{}
 	once    sync.Once
 	onLeave []func(string)
@@ -31,11 +31,11 @@ func (b *balancerRoundRobin) OnLeave(fn func(label string)) {
 	}
 }
 
-func (b *balancerRoundRobin) Put(client rsocket.Client) error {
+func (b *balancerRoundRobin) Put(client rsocket.CloseableRSocket) error {
 	return b.PutLabel(uuid.New().String(), client)
 }
 
-func (b *balancerRoundRobin) PutLabel(label string, client rsocket.Client) error {
+func (b *balancerRoundRobin) PutLabel(label string, client rsocket.CloseableRSocket) error {
 	b.c.L.Lock()
 	defer b.c.L.Unlock()
 	for _, k := range b.keys {
@@ -54,7 +54,7 @@ func (b *balancerRoundRobin) PutLabel(label string, client rsocket.Client) error
 	return nil
 }
 
-func (b *balancerRoundRobin) Next(ctx context.Context) (client rsocket.Client, ok bool) {
+func (b *balancerRoundRobin) Next(ctx context.Context) (client rsocket.CloseableRSocket, ok bool) {
 	b.c.L.Lock()
 	for {
 		n := len(b.keys)
@@ -80,12 +80,12 @@ func (b *balancerRoundRobin) Close() (err error) {
 		if len(b.sockets) < 1 {
 			return
 		}
-		clone := append([]rsocket.Client(nil), b.sockets...)
+		clone := append([]rsocket.CloseableRSocket(nil), b.sockets...)
 		close(b.done)
 		wg := &sync.WaitGroup{}
 		wg.Add(len(clone))
 		for i := 0; i < len(clone); i++ {
-			go func(c rsocket.Client, wg *sync.WaitGroup) {
+			go func(c rsocket.CloseableRSocket, wg *sync.WaitGroup) {
 				defer wg.Done()
 				if err := c.Close(); err != nil {
 					logger.Warnf("close client failed: %s\n", err)
@@ -97,7 +97,7 @@ func (b *balancerRoundRobin) Close() (err error) {
 	return
 }
 
-func (b *balancerRoundRobin) remove(client rsocket.Client) (label string, ok bool) {
+func (b *balancerRoundRobin) remove(client rsocket.CloseableRSocket) (label string, ok bool) {
 	b.c.L.Lock()
 	j := -1
 	for i, l := 0, len(b.sockets); i < l; i++ {
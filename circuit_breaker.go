@@ -0,0 +1,220 @@
+package rsocket
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/clock"
+	"github.com/rsocket/rsocket-go/logger"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx"
+	"github.com/rsocket/rsocket-go/rx/flux"
+	"github.com/rsocket/rsocket-go/rx/mono"
+)
+
+// ErrCircuitOpen is returned by requests of a circuit breaker client while the circuit is open.
+var ErrCircuitOpen = errors.New("rsocket: circuit breaker is open")
+
+var _defaultTripCodes = []ErrorCode{ErrorCodeRejected, ErrorCodeConnectionError, ErrorCodeConnectionClose}
+
+// CircuitBreakerPolicy controls when the circuit of a client is opened.
+type CircuitBreakerPolicy struct {
+	// ErrorRate is the rate of failed requests in the Window which opens the circuit, eg: 0.5.
+	ErrorRate float64
+	// MinRequests is the min number of completed requests in the Window before the error rate is evaluated.
+	MinRequests int
+	// Window is the duration in which requests are counted. Default is 10 seconds.
+	Window time.Duration
+	// OpenDuration is the duration the circuit stays open, after which one request is allowed to probe the responder
+	// (the half-open state), another probe is allowed every OpenDuration until one of them succeeds. Default is 5 seconds.
+	OpenDuration time.Duration
+	// ErrorCodes are the codes of ERROR frames which count as failures, errors without a code always count,
+	// eg: a closed connection or a timeout. Default is REJECTED, CONNECTION_ERROR and CONNECTION_CLOSE,
+	// so an APPLICATION_ERROR of the business logic never trips the circuit.
+	ErrorCodes []ErrorCode
+	// Clock measures the windows, default is the real one.
+	Clock clock.Clock
+}
+
+// NewCircuitBreakerClient returns a Client which counts the outcome of requests of the client, and short-circuits
+// requests with ErrCircuitOpen while the circuit is open. FireAndForget requests are dropped while the circuit is open,
+// METADATA_PUSH is sent as is.
+func NewCircuitBreakerClient(client Client, policy CircuitBreakerPolicy) Client {
+	if policy.Window <= 0 {
+		policy.Window = 10 * time.Second
+	}
+	if policy.OpenDuration <= 0 {
+		policy.OpenDuration = 5 * time.Second
+	}
+	if policy.MinRequests < 1 {
+		policy.MinRequests = 1
+	}
+	if policy.ErrorCodes == nil {
+		policy.ErrorCodes = _defaultTripCodes
+	}
+	b := &circuitBreaker{
+		policy: policy,
+		clock:  clock.OrReal(policy.Clock),
+		codes:  make(map[ErrorCode]struct{}, len(policy.ErrorCodes)),
+	}
+	for _, code := range policy.ErrorCodes {
+		b.codes[code] = struct{}{}
+	}
+	b.windowStart = b.clock.Now()
+	return circuitBreakerClient{
+		Client:  client,
+		breaker: b,
+	}
+}
+
+type circuitBreaker struct {
+	mu          sync.Mutex
+	policy      CircuitBreakerPolicy
+	clock       clock.Clock
+	codes       map[ErrorCode]struct{}
+	open        bool
+	nextProbe   time.Time
+	windowStart time.Time
+	total       int
+	failures    int
+}
+
+// acquire returns ErrCircuitOpen if the request is short-circuited, probe is true if the request probes an open circuit.
+func (b *circuitBreaker) acquire() (probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.open {
+		return false, nil
+	}
+	now := b.clock.Now()
+	if now.Before(b.nextProbe) {
+		return false, ErrCircuitOpen
+	}
+	// a probe which never completes, eg: it has not been subscribed, doesn't block the next one.
+	b.nextProbe = now.Add(b.policy.OpenDuration)
+	return true, nil
+}
+
+func (b *circuitBreaker) isOpen() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.open
+}
+
+// record counts the outcome of a request, err is nil if it succeeded.
+func (b *circuitBreaker) record(probe bool, err error) {
+	failed := err != nil && b.trips(err)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.clock.Now()
+	if b.open {
+		// outcomes of requests which have been sent before the circuit was opened are ignored.
+		if !probe {
+			return
+		}
+		if failed {
+			b.nextProbe = now.Add(b.policy.OpenDuration)
+			return
+		}
+		b.open = false
+		b.reset(now)
+		return
+	}
+	if now.Sub(b.windowStart) >= b.policy.Window {
+		b.reset(now)
+	}
+	b.total++
+	if failed {
+		b.failures++
+	}
+	if b.failures > 0 && b.total >= b.policy.MinRequests && float64(b.failures) >= b.policy.ErrorRate*float64(b.total) {
+		b.open = true
+		b.nextProbe = now.Add(b.policy.OpenDuration)
+	}
+}
+
+func (b *circuitBreaker) reset(now time.Time) {
+	b.windowStart = now
+	b.total = 0
+	b.failures = 0
+}
+
+func (b *circuitBreaker) trips(err error) bool {
+	if err == context.Canceled {
+		return false
+	}
+	e, ok := err.(core.CustomError)
+	if !ok {
+		return true
+	}
+	_, ok = b.codes[e.ErrorCode()]
+	return ok
+}
+
+type circuitBreakerClient struct {
+	Client
+	breaker *circuitBreaker
+}
+
+func (c circuitBreakerClient) FireAndForget(request payload.Payload) {
+	if c.breaker.isOpen() {
+		logger.Warnf("request FireAndForget failed: %v\n", ErrCircuitOpen)
+		return
+	}
+	c.Client.FireAndForget(request)
+}
+
+func (c circuitBreakerClient) RequestResponse(request payload.Payload) mono.Mono {
+	probe, err := c.breaker.acquire()
+	if err != nil {
+		return mono.Error(err)
+	}
+	return c.Client.RequestResponse(request).
+		DoOnError(func(e error) {
+			c.breaker.record(probe, e)
+		}).
+		DoFinally(func(s rx.SignalType) {
+			if s == rx.SignalComplete {
+				c.breaker.record(probe, nil)
+			}
+		})
+}
+
+func (c circuitBreakerClient) RequestResponseSync(ctx context.Context, request payload.Payload) (payload.Payload, error) {
+	probe, err := c.breaker.acquire()
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.Client.RequestResponseSync(ctx, request)
+	c.breaker.record(probe, err)
+	return res, err
+}
+
+func (c circuitBreakerClient) RequestStream(request payload.Payload) flux.Flux {
+	probe, err := c.breaker.acquire()
+	if err != nil {
+		return flux.Error(err)
+	}
+	return c.observe(c.Client.RequestStream(request), probe)
+}
+
+func (c circuitBreakerClient) RequestChannel(requests flux.Flux) flux.Flux {
+	probe, err := c.breaker.acquire()
+	if err != nil {
+		return flux.Error(err)
+	}
+	return c.observe(c.Client.RequestChannel(requests), probe)
+}
+
+func (c circuitBreakerClient) observe(responses flux.Flux, probe bool) flux.Flux {
+	return responses.
+		DoOnError(func(e error) {
+			c.breaker.record(probe, e)
+		}).
+		DoOnComplete(func() {
+			c.breaker.record(probe, nil)
+		})
+}
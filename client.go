@@ -2,14 +2,19 @@ package rsocket
 
 import (
 	"context"
+	"math"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jjeffcaii/reactor-go/scheduler"
 	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/clock"
+	"github.com/rsocket/rsocket-go/core/framing"
 	"github.com/rsocket/rsocket-go/core/transport"
 	"github.com/rsocket/rsocket-go/internal/common"
 	"github.com/rsocket/rsocket-go/internal/fragmentation"
 	"github.com/rsocket/rsocket-go/internal/socket"
+	"github.com/rsocket/rsocket-go/lease"
 	"github.com/rsocket/rsocket-go/payload"
 )
 
@@ -26,6 +31,13 @@ type (
 // Client is Client Side of a RSocket socket. Sends Frames to a RSocket Server.
 type Client interface {
 	CloseableRSocket
+	// Reauthenticate replaces the connection by a new one which is set up with the payload, eg: a refreshed auth token.
+	// A nil payload sets up without data and metadata, the same as SetupPayload(nil).
+	// New requests are sent by the new connection once it has been set up, and requests in flight are drained:
+	// the previous connection is closed once all its streams have completed, or the context is done which terminates them.
+	// The previous connection keeps serving if the new one fails to set up. Other settings of the builder are kept,
+	// and handlers of OnClose are only invoked when the current connection is closed.
+	Reauthenticate(ctx context.Context, setup payload.Payload) error
 }
 
 // ClientSocketAcceptor is alias for RSocket handler function.
@@ -49,22 +61,133 @@ type ClientBuilder interface {
 	Resume(opts ...ClientResumeOptions) ClientBuilder
 	// Lease enable the functionality of lease.
 	Lease() ClientBuilder
+	// OnLease register a handler which is invoked with every LEASE frame received, it implies Lease.
+	// The metadata of the lease can describe it, eg: which service tier granted it, and it can be retained safely.
+	OnLease(handler func(lease lease.Lease)) ClientBuilder
 	// DataMimeType is used to set payload data MIME type.
 	// Default MIME type is `application/binary`.
 	DataMimeType(mime string) ClientBuilder
 	// MetadataMimeType is used to set payload metadata MIME type.
 	// Default MIME type is `application/binary`.
 	MetadataMimeType(mime string) ClientBuilder
-	// SetupPayload set the setup payload.
+	// SetupPayload set the setup payload, a nil payload sends SETUP without data and metadata which is the default.
 	SetupPayload(setup payload.Payload) ClientBuilder
+	// SetupPayloadFactory set a generator of the setup payload, eg: refresh a rotating auth token.
+	// It is invoked every time a SETUP frame is sent: once in every Start, so each client started by
+	// the builder gets a fresh payload. A resumed connection sends RESUME instead of SETUP and never invokes it.
+	// It replaces the payload set by SetupPayload, and SetupPayload replaces it too.
+	SetupPayloadFactory(factory func() payload.Payload) ClientBuilder
+	// InterceptSetup set a hook which can modify every SETUP frame just before it is sent, eg: sign the
+	// metadata over other fields computed at connect time. It is invoked after SetupPayloadFactory.
+	// Only data and metadata are safe to modify by SetData and SetMetadata, other fields such as the
+	// version, keepalive, resume token, lease and MIME types have been applied to the client already.
+	InterceptSetup(interceptor func(setup *framing.WriteableSetupFrame)) ClientBuilder
+	// InterceptRequest add a hook which replaces the request payload of every request sent by the client,
+	// eg: inject a rotating token scoped to the request by extension.InjectAuthentication. Interceptors are invoked
+	// in order of registration, and the request fails if any of them returns an error. Only the first payload of
+	// RequestChannel is intercepted, and requests sent by the socket passed to the Acceptor are never intercepted.
+	InterceptRequest(interceptor RequestInterceptor) ClientBuilder
 	// ConnectTimeout set connect timeout.
 	ConnectTimeout(timeout time.Duration) ClientBuilder
+	// SetupTimeout set the max time to wait for the server to confirm the SETUP frame, since a server may accept the
+	// connection but never complete the handshake. The client sends a KEEPALIVE frame which requires a response
+	// right after the SETUP frame, Start fails with core.ErrSetupTimeout if the response doesn't return in time.
+	// Default is 10 seconds, zero means not confirming the SETUP frame.
+	SetupTimeout(timeout time.Duration) ClientBuilder
+	// MaxResponsePayloadSize set the max bytes of a response payload after reassembling fragments.
+	// The request will fail with core.ErrResponseTooLarge and a CANCEL frame will be sent once the limit is exceeded.
+	// It is different from the fragmentation size which limits a single frame. Default is zero which means unlimited.
+	MaxResponsePayloadSize(size int) ClientBuilder
+	// StreamListener set a listener of stream lifecycle events.
+	StreamListener(listener StreamListener) ClientBuilder
+	// StreamStallThreshold set the duration without REQUEST_N after which a REQUEST_STREAM responded by the client
+	// is considered stalled: all requested payloads have been sent but the stream is not completed.
+	// The stall is reported if the StreamListener implements StreamStallListener. Default is zero which means disabled.
+	StreamStallThreshold(threshold time.Duration) ClientBuilder
+	// RequestNCoalescing set the window in which REQUEST_N signals of a receiving stream are accumulated into
+	// a single REQUEST_N frame, it reduces frames sent by chatty consumers, eg: request(1) per item after a prefetch.
+	// Demand is only held back while the peer has credits left, and it is sent at once when all granted payloads
+	// have been received, so the consumer never waits for the window. Default is zero which means disabled.
+	RequestNCoalescing(window time.Duration) ClientBuilder
+	// RequestMetrics set a receiver of rejected/cancelled request counters.
+	RequestMetrics(metrics RequestMetrics) ClientBuilder
+	// DispatchScheduler set the scheduler which dispatches responses to requests sent by the server, default is a new
+	// goroutine for every response. With rx.NewBoundedScheduler, the policy decides the behavior under overload:
+	// rx.BlockOnSaturation holds the response until a worker is free while frames are still read, and
+	// rx.RejectOnSaturation rejects the request with ERROR[REJECTED] at once, which is counted by RequestMetrics as
+	// RejectedBySaturation.
+	DispatchScheduler(sc scheduler.Scheduler) ClientBuilder
+	// ConnectionIDGenerator set the generator of IDs of connections, it is invoked when a connection is set up.
+	// Default is a random UUID, see Client.ConnectionID.
+	ConnectionIDGenerator(gen func() string) ClientBuilder
+	// OnFrameDrop register handler of frames which are dropped without being handled, with the reason,
+	// eg: METADATA_PUSH with non-zero stream id, ignorable frames of unknown types, frames of unmatched streams
+	// and requests rejected by lease. It is invoked in the read loop, so it should return quickly.
+	OnFrameDrop(handler FrameDropHandler) ClientBuilder
+	// FragmentationMetrics set a receiver of fragmentation events: fragmented payloads sent and reassembling in progress.
+	FragmentationMetrics(metrics FragmentationMetrics) ClientBuilder
+	// MaxOutboundBufferBytes set the max bytes of outbound frames queued but not written yet.
+	// Once the limit is exceeded, eg: the peer does not read, requests and responses will block until queued frames are written.
+	// Default is zero which means unlimited.
+	MaxOutboundBufferBytes(n int) ClientBuilder
+	// MaxConnectionMemory set the max bytes held by the connection across all streams: payloads being reassembled
+	// from fragments plus outbound frames queued but not written yet. Once a received fragment exceeds the budget,
+	// the payload is dropped and its stream is terminated: a request fails with core.ErrMemoryBudgetExceeded and a CANCEL
+	// frame is sent, an incoming request is rejected. If closeConn is true, the connection is closed instead.
+	// Default is zero which means unlimited.
+	MaxConnectionMemory(n int, closeConn bool) ClientBuilder
+	// MaxReassemblies set the max amount of received payloads being reassembled from fragments at the same time.
+	// Once it is reached, a response starting another fragmented payload is dropped: the request fails with
+	// core.ErrTooManyReassemblies and a CANCEL frame is sent, an incoming request is rejected with ERROR[REJECTED].
+	// Default is zero which means unlimited.
+	MaxReassemblies(n int) ClientBuilder
+	// ChannelOutboundWindow set the max amount of outbound payloads of a RequestChannel which are in flight:
+	// requested from the source Flux but not written yet. The source is paused when the window is full,
+	// and it is resumed once payloads are written and the peer grants more by REQUEST_N.
+	// Default is zero which means the demand of the peer is forwarded to the source directly.
+	ChannelOutboundWindow(size int) ClientBuilder
+	// ControlFrameRetry set the max times a KEEPALIVE, CANCEL or REQUEST_N frame is written again after the Conn of the
+	// transport reports a clean temporary failure, waiting for the backoff between retries. They are safe to be
+	// duplicated, while frames carrying data are never retried since they may have been partially written.
+	// Only custom Conns report clean failures, see transport.Conn: the TCP transport retries temporary errors itself
+	// before the bytes are committed and closes the connection after a failed write.
+	// Default is zero which means disabled.
+	ControlFrameRetry(maxRetries int, backoff time.Duration) ClientBuilder
+	// Ordering set the order in which outbound frames of different streams are written, default is StrictOrdering.
+	// StrictOrdering writes frames in the order they are emitted, so fragments of a large payload delay all streams behind it.
+	// PerStreamOrdering only keeps the order inside every stream: frames of concurrent streams are interleaved
+	// in each coalesced write batch, which improves latency of small streams while the number of flushes stays the same.
+	// FairOrdering writes pending frames of all streams in round-robin, so a flooding stream cannot starve sparse ones.
+	Ordering(ordering FrameOrdering) ClientBuilder
+	// QueueDuringReconnect makes requests issued during a disconnect wait for the reconnection instead of failing.
+	// At most maxItems requests can wait at the same time, others fail with core.ErrReconnectQueueFull.
+	// A request which waits longer than maxWait fails with core.ErrReconnectTimeout.
+	// Requests wait once they are subscribed without blocking the caller, and stop waiting once the subscribe context
+	// is done. It only takes effect when Resume is enabled.
+	QueueDuringReconnect(maxItems int, maxWait time.Duration) ClientBuilder
+	// OnMetadataPush register handler of METADATA_PUSH frames sent by the server.
+	// It is registered before SETUP, so metadata which is pushed by the server acceptor will never be missed.
+	OnMetadataPush(handler func(metadata []byte)) ClientBuilder
+	// StreamIDAllocator replace the default allocator of stream ids: 1,3,5...
+	// The generator is called once for every connection, it is mainly used by interop tests.
+	StreamIDAllocator(gen func() StreamIDAllocator) ClientBuilder
+	// Clock set the clock of keepalive, lease expiration, read deadline and other time-based features,
+	// eg: a clock.Fake for deterministic tests. Default is the real clock.
+	Clock(c clock.Clock) ClientBuilder
 	// OnClose register handler when client socket closed.
 	OnClose(func(error)) ClientBuilder
 	// OnConnect register handler when client socket connected.
 	OnConnect(func(Client, error)) ClientBuilder
+	// OnConnectionError register a handler of ERROR frames with stream id 0 sent by the server, eg: CONNECTION_ERROR,
+	// unlike errors of a single stream they always close the connection. By default the error is logged and the client
+	// is closed. If the handler returns true, the client reconnects with a new SETUP instead: requests in flight fail,
+	// but the Client keeps working and OnClose handlers are only invoked if reconnecting fails.
+	OnConnectionError(handler func(err error) (reconnect bool)) ClientBuilder
 	// Acceptor set acceptor for RSocket client.
 	Acceptor(acceptor ClientSocketAcceptor) ToClientStarter
+	// Validate returns all configuration errors at once as ConfigErrors, it returns nil if the configuration is valid.
+	// Start will validate the configuration before any network activity.
+	Validate() error
 }
 
 // ToClientStarter is used to build a RSocket client with custom Transport.
@@ -80,7 +203,7 @@ type ToClientStarter interface {
 }
 
 type setupClientSocket interface {
-	Client
+	CloseableRSocket
 	Setup(ctx context.Context, connectTimeout time.Duration, setup *socket.SetupInfo) error
 }
 
@@ -93,6 +216,30 @@ type clientBuilder struct {
 	onCloses       []func(error)
 	onConnects     []func(Client, error)
 	connectTimeout time.Duration
+	maxResponse    int
+	listener       StreamListener
+	stallAfter     time.Duration
+	coalesceN      time.Duration
+	metrics        RequestMetrics
+	dispatcher     scheduler.Scheduler
+	connIDGen      func() string
+	fragMetrics    FragmentationMetrics
+	onMetadataPush func(metadata []byte)
+	streamIDs      func() StreamIDAllocator
+	maxOutbound    int
+	maxMemory      int
+	memoryClose    bool
+	maxReassembly  int
+	ordering       FrameOrdering
+	channelWindow  int
+	ctrlRetries    int
+	ctrlBackoff    time.Duration
+	queueItems     int
+	queueWait      time.Duration
+	clock          clock.Clock
+	onDrop         FrameDropHandler
+	interceptors   []RequestInterceptor
+	onConnErr      func(err error) bool
 }
 
 func (cb *clientBuilder) Lease() ClientBuilder {
@@ -100,6 +247,12 @@ func (cb *clientBuilder) Lease() ClientBuilder {
 	return cb
 }
 
+func (cb *clientBuilder) OnLease(handler func(lease lease.Lease)) ClientBuilder {
+	cb.setup.Lease = true
+	cb.setup.OnLease = handler
+	return cb
+}
+
 func (cb *clientBuilder) Resume(opts ...ClientResumeOptions) ClientBuilder {
 	if cb.resume == nil {
 		cb.resume = newResumeOpts()
@@ -124,6 +277,26 @@ func (cb *clientBuilder) OnConnect(fn func(Client, error)) ClientBuilder {
 	return cb
 }
 
+func (cb *clientBuilder) OnConnectionError(handler func(err error) (reconnect bool)) ClientBuilder {
+	cb.onConnErr = handler
+	return cb
+}
+
+func (cb *clientBuilder) OnMetadataPush(handler func(metadata []byte)) ClientBuilder {
+	cb.onMetadataPush = handler
+	return cb
+}
+
+func (cb *clientBuilder) StreamIDAllocator(gen func() StreamIDAllocator) ClientBuilder {
+	cb.streamIDs = gen
+	return cb
+}
+
+func (cb *clientBuilder) Clock(c clock.Clock) ClientBuilder {
+	cb.clock = c
+	return cb
+}
+
 func (cb *clientBuilder) OnClose(fn func(error)) ClientBuilder {
 	cb.onCloses = append(cb.onCloses, fn)
 	return cb
@@ -148,7 +321,11 @@ func (cb *clientBuilder) MetadataMimeType(mime string) ClientBuilder {
 func (cb *clientBuilder) SetupPayload(setup payload.Payload) ClientBuilder {
 	cb.setup.Data = nil
 	cb.setup.Metadata = nil
+	cb.setup.PayloadFactory = nil
 
+	if setup == nil {
+		return cb
+	}
 	if data := setup.Data(); len(data) > 0 {
 		cb.setup.Data = make([]byte, len(data))
 		copy(cb.setup.Data, data)
@@ -160,11 +337,116 @@ func (cb *clientBuilder) SetupPayload(setup payload.Payload) ClientBuilder {
 	return cb
 }
 
+func (cb *clientBuilder) SetupPayloadFactory(factory func() payload.Payload) ClientBuilder {
+	cb.setup.Data = nil
+	cb.setup.Metadata = nil
+	cb.setup.PayloadFactory = factory
+	return cb
+}
+
+func (cb *clientBuilder) InterceptSetup(interceptor func(setup *framing.WriteableSetupFrame)) ClientBuilder {
+	cb.setup.Interceptor = interceptor
+	return cb
+}
+
+func (cb *clientBuilder) InterceptRequest(interceptor RequestInterceptor) ClientBuilder {
+	cb.interceptors = append(cb.interceptors, interceptor)
+	return cb
+}
+
 func (cb *clientBuilder) ConnectTimeout(timeout time.Duration) ClientBuilder {
 	cb.connectTimeout = timeout
 	return cb
 }
 
+func (cb *clientBuilder) SetupTimeout(timeout time.Duration) ClientBuilder {
+	cb.setup.Timeout = timeout
+	return cb
+}
+
+func (cb *clientBuilder) MaxResponsePayloadSize(size int) ClientBuilder {
+	cb.maxResponse = size
+	return cb
+}
+
+func (cb *clientBuilder) StreamStallThreshold(threshold time.Duration) ClientBuilder {
+	cb.stallAfter = threshold
+	return cb
+}
+
+func (cb *clientBuilder) RequestNCoalescing(window time.Duration) ClientBuilder {
+	cb.coalesceN = window
+	return cb
+}
+
+func (cb *clientBuilder) StreamListener(listener StreamListener) ClientBuilder {
+	cb.listener = listener
+	return cb
+}
+
+func (cb *clientBuilder) DispatchScheduler(sc scheduler.Scheduler) ClientBuilder {
+	cb.dispatcher = sc
+	return cb
+}
+
+func (cb *clientBuilder) ConnectionIDGenerator(gen func() string) ClientBuilder {
+	cb.connIDGen = gen
+	return cb
+}
+
+func (cb *clientBuilder) RequestMetrics(metrics RequestMetrics) ClientBuilder {
+	cb.metrics = metrics
+	return cb
+}
+
+func (cb *clientBuilder) OnFrameDrop(handler FrameDropHandler) ClientBuilder {
+	cb.onDrop = handler
+	return cb
+}
+
+func (cb *clientBuilder) FragmentationMetrics(metrics FragmentationMetrics) ClientBuilder {
+	cb.fragMetrics = metrics
+	return cb
+}
+
+func (cb *clientBuilder) MaxOutboundBufferBytes(n int) ClientBuilder {
+	cb.maxOutbound = n
+	return cb
+}
+
+func (cb *clientBuilder) MaxConnectionMemory(n int, closeConn bool) ClientBuilder {
+	cb.maxMemory = n
+	cb.memoryClose = closeConn
+	return cb
+}
+
+func (cb *clientBuilder) MaxReassemblies(n int) ClientBuilder {
+	cb.maxReassembly = n
+	return cb
+}
+
+func (cb *clientBuilder) ChannelOutboundWindow(size int) ClientBuilder {
+	cb.channelWindow = size
+	return cb
+}
+
+func (cb *clientBuilder) ControlFrameRetry(maxRetries int, backoff time.Duration) ClientBuilder {
+	cb.ctrlRetries = maxRetries
+	cb.ctrlBackoff = backoff
+	return cb
+}
+
+func (cb *clientBuilder) Ordering(ordering FrameOrdering) ClientBuilder {
+	cb.ordering = ordering
+	return cb
+}
+
+func (cb *clientBuilder) QueueDuringReconnect(maxItems int, maxWait time.Duration) ClientBuilder {
+	cb.queueItems = maxItems
+	cb.queueWait = maxWait
+	return cb
+}
+
 func (cb *clientBuilder) Acceptor(acceptor ClientSocketAcceptor) ToClientStarter {
 	cb.acceptor = acceptor
 	return cb
@@ -175,21 +457,94 @@ func (cb *clientBuilder) Transport(t transport.ClientTransporter) ClientStarter
 	return cb
 }
 
+func (cb *clientBuilder) Validate() error {
+	v := &configValidator{}
+	v.add(fragmentation.IsValidFragment(cb.fragment))
+	v.check(cb.setup.KeepaliveInterval > 0, "keepalive interval must be positive: %s", cb.setup.KeepaliveInterval)
+	v.check(cb.setup.KeepaliveLifetime > 0, "keepalive lifetime must be positive: %s", cb.setup.KeepaliveLifetime)
+	v.check(cb.setup.KeepaliveInterval < cb.setup.KeepaliveLifetime,
+		"keepalive interval %s must be less than lifetime %s", cb.setup.KeepaliveInterval, cb.setup.KeepaliveLifetime)
+	v.check(len(cb.setup.DataMimeType) > 0 && len(cb.setup.DataMimeType) <= math.MaxUint8,
+		"length of data MIME type must be between 1 and %d: %d", math.MaxUint8, len(cb.setup.DataMimeType))
+	v.check(len(cb.setup.MetadataMimeType) > 0 && len(cb.setup.MetadataMimeType) <= math.MaxUint8,
+		"length of metadata MIME type must be between 1 and %d: %d", math.MaxUint8, len(cb.setup.MetadataMimeType))
+	v.check(cb.connectTimeout >= 0, "connect timeout cannot be negative: %s", cb.connectTimeout)
+	v.check(cb.setup.Timeout >= 0, "setup timeout cannot be negative: %s", cb.setup.Timeout)
+	v.check(cb.maxResponse >= 0, "max response payload size cannot be negative: %d", cb.maxResponse)
+	v.check(cb.maxOutbound >= 0, "max outbound buffer bytes cannot be negative: %d", cb.maxOutbound)
+	v.check(cb.maxMemory >= 0, "max connection memory cannot be negative: %d", cb.maxMemory)
+	v.check(cb.maxReassembly >= 0, "max reassemblies cannot be negative: %d", cb.maxReassembly)
+	v.check(cb.channelWindow >= 0, "channel outbound window cannot be negative: %d", cb.channelWindow)
+	v.check(cb.ctrlRetries >= 0, "control frame retries cannot be negative: %d", cb.ctrlRetries)
+	v.check(cb.ctrlBackoff >= 0, "control frame retry backoff cannot be negative: %s", cb.ctrlBackoff)
+	v.check(cb.stallAfter >= 0, "stream stall threshold cannot be negative: %s", cb.stallAfter)
+	v.check(cb.coalesceN >= 0, "request n coalescing window cannot be negative: %s", cb.coalesceN)
+	v.check(cb.ordering >= StrictOrdering && cb.ordering <= FairOrdering, "invalid frame ordering: %d", cb.ordering)
+	if cb.queueItems > 0 {
+		v.check(cb.queueWait > 0, "max wait of reconnect queue must be positive: %s", cb.queueWait)
+		v.check(cb.resume != nil, "reconnect queue requires resume")
+	}
+	return v.result()
+}
+
 func (cb *clientBuilder) Start(ctx context.Context) (client Client, err error) {
-	// create a blank socket.
-	err = fragmentation.IsValidFragment(cb.fragment)
+	err = cb.Validate()
 	if err != nil {
 		return
 	}
 
+	sc := newSessionClient(ctx, cb)
+	if err = sc.start(); err == nil {
+		client = sc
+	}
+
+	// trigger OnConnect
+	if len(cb.onConnects) > 0 {
+		var onConnects []func(Client, error)
+		onConnects, cb.onConnects = cb.onConnects, nil
+		go func() {
+			for _, onConnect := range onConnects {
+				onConnect(client, err)
+			}
+		}()
+	}
+
+	return
+}
+
+// connect starts a new connection which is set up by the SetupInfo, onClose is invoked when it is closed,
+// and onConnErr is invoked with an ERROR frame of stream id 0 before the connection is closed.
+func (cb *clientBuilder) connect(ctx context.Context, setup *socket.SetupInfo, onClose, onConnErr func(error)) (CloseableRSocket, error) {
 	conn := socket.NewClientDuplexConnection(
 		cb.fragment,
-		cb.setup.KeepaliveInterval,
+		setup.KeepaliveInterval,
 	)
+	conn.SetConnectionID(newConnectionID(cb.connIDGen))
+	conn.SetClock(cb.clock)
+	conn.SetMaxResponsePayloadSize(cb.maxResponse)
+	conn.SetStreamListener(cb.listener)
+	conn.SetStreamStallThreshold(cb.stallAfter)
+	conn.SetRequestNCoalescing(cb.coalesceN)
+	conn.SetRequestMetrics(cb.metrics)
+	conn.SetDispatchScheduler(cb.dispatcher)
+	conn.SetFrameDropHandler(cb.onDrop)
+	conn.SetFragmentationMetrics(cb.fragMetrics)
+	conn.SetMaxOutboundBufferBytes(cb.maxOutbound)
+	conn.SetMaxConnectionMemory(cb.maxMemory, cb.memoryClose)
+	conn.SetMaxReassemblies(cb.maxReassembly)
+	conn.SetFrameOrdering(cb.ordering)
+	conn.SetChannelOutboundWindow(cb.channelWindow)
+	conn.SetControlFrameRetry(cb.ctrlRetries, cb.ctrlBackoff)
+	conn.OnMetadataPush(cb.onMetadataPush)
+	conn.OnConnectionError(onConnErr)
+	if cb.streamIDs != nil {
+		conn.SetStreamIDs(cb.streamIDs())
+	}
 	// create a client.
 	var cs setupClientSocket
 	if cb.resume != nil {
-		cb.setup.Token = cb.resume.tokenGen()
+		setup.Token = cb.resume.tokenGen()
+		conn.SetReconnectQueue(cb.queueItems, cb.queueWait)
 		cs = socket.NewResumableClientSocket(cb.tpGen, conn)
 	} else {
 		cs = socket.NewClient(cb.tpGen, conn)
@@ -201,30 +556,13 @@ func (cb *clientBuilder) Start(ctx context.Context) (client Client, err error) {
 	}
 
 	// bind closers.
-	if len(cb.onCloses) > 0 {
-		for _, closer := range cb.onCloses {
-			cs.OnClose(closer)
-		}
-	}
+	cs.OnClose(onClose)
 
 	// setup client.
-	err = cs.Setup(ctx, cb.connectTimeout, cb.setup)
-	if err == nil {
-		client = cs
-	}
-
-	// trigger OnConnect
-	if len(cb.onConnects) > 0 {
-		var onConnects []func(Client, error)
-		onConnects, cb.onConnects = cb.onConnects, nil
-		go func() {
-			for _, onConnect := range onConnects {
-				onConnect(client, err)
-			}
-		}()
+	if err := cs.Setup(ctx, cb.connectTimeout, setup); err != nil {
+		return nil, err
 	}
-
-	return
+	return newInterceptedClient(cs, cb.interceptors), nil
 }
 
 type resumeOpts struct {
@@ -259,6 +597,7 @@ func Connect() ClientBuilder {
 			KeepaliveLifetime: common.DefaultKeepaliveMaxLifetime,
 			DataMimeType:      _defaultMimeType,
 			MetadataMimeType:  _defaultMimeType,
+			Timeout:           common.DefaultSetupTimeout,
 		},
 	}
 }
@@ -0,0 +1,300 @@
+package rsocket
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	reactorFlux "github.com/jjeffcaii/reactor-go/flux"
+
+	"github.com/rsocket/rsocket-go/core/clock"
+	"github.com/rsocket/rsocket-go/internal/socket"
+	"github.com/rsocket/rsocket-go/logger"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx/flux"
+	"github.com/rsocket/rsocket-go/rx/mono"
+)
+
+const _drainPollInterval = 10 * time.Millisecond
+
+var errClientClosed = errors.New("rsocket: client has been closed")
+
+// sessionClient is the Client started by a ClientBuilder, it forwards everything to the connection of the current
+// session, which can be replaced by Reauthenticate. Handlers of OnClose are only invoked when the current session
+// is closed, a session which has been replaced is closed silently.
+type sessionClient struct {
+	mu         sync.RWMutex
+	reauth     sync.Mutex // serializes Reauthenticate
+	ctx        context.Context
+	builder    *clientBuilder
+	current    CloseableRSocket
+	setup      *socket.SetupInfo // SETUP of the current session, it is used to reconnect
+	gen        uint64            // generation of the current session
+	nextGen    uint64
+	closing    bool
+	recovering uint64 // generation of the session which will be replaced after it is closed by a connection error
+	closed     bool
+	onCloses   []func(error)
+	onMetaPush func(metadata []byte)
+}
+
+func newSessionClient(ctx context.Context, builder *clientBuilder) *sessionClient {
+	return &sessionClient{
+		ctx:      ctx,
+		builder:  builder,
+		setup:    builder.setup,
+		onCloses: append([]func(error){}, builder.onCloses...),
+	}
+}
+
+// start sets up the first session.
+func (c *sessionClient) start() error {
+	c.mu.Lock()
+	c.nextGen++
+	c.gen = c.nextGen
+	gen := c.gen
+	c.mu.Unlock()
+	current, err := c.builder.connect(c.ctx, c.builder.setup, c.closeHook(gen), c.connectionErrorHook(gen))
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.current = current
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *sessionClient) closeHook(gen uint64) func(error) {
+	return func(err error) {
+		c.mu.Lock()
+		if gen != c.gen || c.closed {
+			c.mu.Unlock()
+			return
+		}
+		if c.recovering == gen && !c.closing {
+			c.mu.Unlock()
+			go c.reconnect(err)
+			return
+		}
+		c.closed = true
+		onCloses := c.onCloses
+		c.mu.Unlock()
+		for _, fn := range onCloses {
+			fn(err)
+		}
+	}
+}
+
+// connectionErrorHook asks the handler of connection errors whether the session should be replaced once closed.
+func (c *sessionClient) connectionErrorHook(gen uint64) func(error) {
+	handler := c.builder.onConnErr
+	return func(err error) {
+		if handler == nil || !handler(err) {
+			return
+		}
+		c.mu.Lock()
+		if gen == c.gen {
+			c.recovering = gen
+		}
+		c.mu.Unlock()
+	}
+}
+
+// reconnect replaces the session which has been closed by a connection error with a new one.
+// The client is closed with the connection error if the new session cannot be set up.
+func (c *sessionClient) reconnect(cause error) {
+	c.mu.Lock()
+	c.nextGen++
+	gen := c.nextGen
+	setup := c.setup
+	c.mu.Unlock()
+	next, err := c.builder.connect(c.ctx, setup, c.closeHook(gen), c.connectionErrorHook(gen))
+
+	c.mu.Lock()
+	if err == nil && !c.closing {
+		c.current = next
+		c.gen = gen
+		onMetaPush := c.onMetaPush
+		c.mu.Unlock()
+		if onMetaPush != nil {
+			next.OnMetadataPush(onMetaPush)
+		}
+		return
+	}
+	c.closed = true
+	onCloses := c.onCloses
+	c.mu.Unlock()
+	if err == nil {
+		_ = next.Close()
+	} else {
+		logger.Warnf("rsocket: reconnect after connection error failed: %s\n", err)
+	}
+	for _, fn := range onCloses {
+		fn(cause)
+	}
+}
+
+func (c *sessionClient) session() CloseableRSocket {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.current
+}
+
+func (c *sessionClient) Reauthenticate(ctx context.Context, setup payload.Payload) error {
+	c.reauth.Lock()
+	defer c.reauth.Unlock()
+
+	c.mu.Lock()
+	if c.closing || c.closed {
+		c.mu.Unlock()
+		return errClientClosed
+	}
+	c.nextGen++
+	gen := c.nextGen
+	c.mu.Unlock()
+
+	info := *c.builder.setup
+	info.Data = nil
+	info.Metadata = nil
+	info.PayloadFactory = nil
+	if setup != nil {
+		if data := setup.Data(); data != nil {
+			info.Data = append([]byte{}, data...)
+		}
+		if metadata, ok := setup.Metadata(); ok {
+			info.Metadata = append([]byte{}, metadata...)
+		}
+	}
+	next, err := c.builder.connect(c.ctx, &info, c.closeHook(gen), c.connectionErrorHook(gen))
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	if c.closing || c.closed {
+		c.mu.Unlock()
+		_ = next.Close()
+		return errClientClosed
+	}
+	prev := c.current
+	c.current = next
+	c.setup = &info
+	c.gen = gen
+	onMetaPush := c.onMetaPush
+	c.mu.Unlock()
+	if onMetaPush != nil {
+		next.OnMetadataPush(onMetaPush)
+	}
+
+	err = c.drain(ctx, prev)
+	_ = prev.Close()
+	return err
+}
+
+// drain waits until all streams of the previous session have completed.
+func (c *sessionClient) drain(ctx context.Context, prev CloseableRSocket) error {
+	if prev.ActiveStreams() < 1 {
+		return nil
+	}
+	ticker := clock.OrReal(c.builder.clock).NewTicker(_drainPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C():
+			if prev.ActiveStreams() < 1 {
+				return nil
+			}
+		}
+	}
+}
+
+func (c *sessionClient) Close() error {
+	c.mu.Lock()
+	c.closing = true
+	current := c.current
+	c.mu.Unlock()
+	if current == nil {
+		return nil
+	}
+	return current.Close()
+}
+
+func (c *sessionClient) OnClose(fn func(error)) {
+	if fn == nil {
+		return
+	}
+	c.mu.Lock()
+	c.onCloses = append(c.onCloses, fn)
+	c.mu.Unlock()
+}
+
+func (c *sessionClient) OnMetadataPush(handler func(metadata []byte)) {
+	c.mu.Lock()
+	c.onMetaPush = handler
+	current := c.current
+	c.mu.Unlock()
+	current.OnMetadataPush(handler)
+}
+
+func (c *sessionClient) HealthEvents() reactorFlux.Flux {
+	return c.session().HealthEvents()
+}
+
+func (c *sessionClient) FireAndForget(message payload.Payload) {
+	c.session().FireAndForget(message)
+}
+
+func (c *sessionClient) MetadataPush(message payload.Payload) {
+	c.session().MetadataPush(message)
+}
+
+func (c *sessionClient) RequestResponse(message payload.Payload) mono.Mono {
+	return c.session().RequestResponse(message)
+}
+
+func (c *sessionClient) RequestResponseSync(ctx context.Context, message payload.Payload) (payload.Payload, error) {
+	return c.session().RequestResponseSync(ctx, message)
+}
+
+func (c *sessionClient) RequestStream(message payload.Payload) flux.Flux {
+	return c.session().RequestStream(message)
+}
+
+func (c *sessionClient) RequestChannel(messages flux.Flux) flux.Flux {
+	return c.session().RequestChannel(messages)
+}
+
+func (c *sessionClient) KeepaliveSettings() KeepaliveSettings {
+	return c.session().KeepaliveSettings()
+}
+
+func (c *sessionClient) WaitReady(ctx context.Context) error {
+	return c.session().WaitReady(ctx)
+}
+
+func (c *sessionClient) CancelAll() int {
+	return c.session().CancelAll()
+}
+
+func (c *sessionClient) ConnectTiming() ConnectTiming {
+	return c.session().ConnectTiming()
+}
+
+func (c *sessionClient) ActiveStreams() int {
+	return c.session().ActiveStreams()
+}
+
+func (c *sessionClient) OutboundQueueDepth() int {
+	return c.session().OutboundQueueDepth()
+}
+
+func (c *sessionClient) Reassemblies() int {
+	return c.session().Reassemblies()
+}
+
+func (c *sessionClient) ConnectionID() string {
+	return c.session().ConnectionID()
+}
@@ -0,0 +1,24 @@
+package rsocket
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rsocket/rsocket-go/internal/socket"
+)
+
+// newConnectionID returns an ID generated by gen for a connection being set up, default is a random UUID.
+func newConnectionID(gen func() string) string {
+	if gen != nil {
+		return gen()
+	}
+	return uuid.New().String()
+}
+
+// ConnectionIDFromContext returns the ID of the connection which current request is received from, it is generated
+// when the connection is set up and appears in logs of the connection as "conn=<id>". It can be propagated as metadata
+// to downstream calls, so logs of a request can be correlated across services.
+// The context is the one which the responding Mono or Flux is subscribed with, see StreamIDFromContext.
+func ConnectionIDFromContext(ctx context.Context) (string, bool) {
+	return socket.ConnectionIDFromContext(ctx)
+}
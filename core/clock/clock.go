@@ -0,0 +1,84 @@
+// Package clock abstracts time for time-based features, eg: keepalive, deadline of transports and timeout operators.
+// Inject a Fake to test them fast and deterministically.
+package clock
+
+import "time"
+
+// Clock tells the time and creates timers.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTimer creates a Timer which sends the time on its channel after the duration.
+	NewTimer(d time.Duration) Timer
+	// NewTicker creates a Ticker which sends the time on its channel every period, ticks are dropped for slow receivers.
+	NewTicker(period time.Duration) Ticker
+	// AfterFunc calls fn after the duration, the returned Timer can be used to cancel the call.
+	AfterFunc(d time.Duration, fn func()) Timer
+}
+
+// Timer is a single event, like time.Timer.
+type Timer interface {
+	// C returns the channel on which the time is delivered, it is nil for timers created by AfterFunc.
+	C() <-chan time.Time
+	// Stop prevents the Timer from firing, it returns false if the timer has already expired or been stopped.
+	Stop() bool
+	// Reset changes the timer to expire after the duration, it returns true if the timer had been active.
+	Reset(d time.Duration) bool
+}
+
+// Ticker delivers ticks at intervals, like time.Ticker.
+type Ticker interface {
+	// C returns the channel on which the ticks are delivered.
+	C() <-chan time.Time
+	// Stop turns off the Ticker, no more ticks will be sent.
+	Stop()
+}
+
+var system Clock = realClock{}
+
+// Real returns the Clock of the system time, it is the default Clock.
+func Real() Clock {
+	return system
+}
+
+// OrReal returns the Clock, or the real one if it is nil.
+func OrReal(c Clock) Clock {
+	if c == nil {
+		return system
+	}
+	return c
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (realClock) NewTicker(period time.Duration) Ticker {
+	return realTicker{time.NewTicker(period)}
+}
+
+func (realClock) AfterFunc(d time.Duration, fn func()) Timer {
+	return realTimer{time.AfterFunc(d, fn)}
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
@@ -0,0 +1,157 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is a Clock which only moves forward when it is advanced manually.
+// Timers, tickers and functions of AfterFunc fire synchronously in Advance, in order of their expiration.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeTimer
+}
+
+// NewFake creates a Fake which starts at the time.
+func NewFake(now time.Time) *Fake {
+	return &Fake{
+		now: now,
+	}
+}
+
+// Now returns the current time of the Fake.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// NewTimer creates a Timer which fires once the Fake has been advanced for the duration.
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	return f.add(&fakeTimer{c: make(chan time.Time, 1)}, d)
+}
+
+// NewTicker creates a Ticker which fires every time the Fake has been advanced for the period.
+func (f *Fake) NewTicker(period time.Duration) Ticker {
+	if period <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	return fakeTicker{f.add(&fakeTimer{c: make(chan time.Time, 1), period: period}, period)}
+}
+
+// AfterFunc calls fn in Advance once the Fake has been advanced for the duration.
+func (f *Fake) AfterFunc(d time.Duration, fn func()) Timer {
+	return f.add(&fakeTimer{fn: fn}, d)
+}
+
+// Waiters returns the number of active timers and tickers, it helps to wait until a timer has been created.
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+// Advance moves the Fake forward by the duration, and fires all expired timers and tickers.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	end := f.now.Add(d)
+	for {
+		w := f.next(end)
+		if w == nil {
+			break
+		}
+		f.now = w.at
+		if w.period > 0 {
+			w.at = w.at.Add(w.period)
+		} else {
+			f.remove(w)
+		}
+		if w.fn == nil {
+			// drop the tick if the receiver is slow, like time.Ticker.
+			select {
+			case w.c <- f.now:
+			default:
+			}
+			continue
+		}
+		f.mu.Unlock()
+		w.fn()
+		f.mu.Lock()
+	}
+	f.now = end
+	f.mu.Unlock()
+}
+
+func (f *Fake) add(w *fakeTimer, d time.Duration) *fakeTimer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.f = f
+	w.at = f.now.Add(d)
+	f.waiters = append(f.waiters, w)
+	return w
+}
+
+// next returns the earliest waiter which expires before the end.
+func (f *Fake) next(end time.Time) *fakeTimer {
+	if len(f.waiters) < 1 {
+		return nil
+	}
+	sort.SliceStable(f.waiters, func(i, j int) bool {
+		return f.waiters[i].at.Before(f.waiters[j].at)
+	})
+	if w := f.waiters[0]; !w.at.After(end) {
+		return w
+	}
+	return nil
+}
+
+func (f *Fake) remove(w *fakeTimer) bool {
+	for i, it := range f.waiters {
+		if it == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type fakeTimer struct {
+	f      *Fake
+	c      chan time.Time
+	fn     func()
+	at     time.Time
+	period time.Duration
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.f.mu.Lock()
+	defer t.f.mu.Unlock()
+	return t.f.remove(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.f.mu.Lock()
+	defer t.f.mu.Unlock()
+	active := t.f.remove(t)
+	t.at = t.f.now.Add(d)
+	t.f.waiters = append(t.f.waiters, t)
+	return active
+}
+
+type fakeTicker struct {
+	t *fakeTimer
+}
+
+func (t fakeTicker) C() <-chan time.Time {
+	return t.t.c
+}
+
+func (t fakeTicker) Stop() {
+	t.t.Stop()
+}
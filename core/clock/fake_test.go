@@ -0,0 +1,95 @@
+package clock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+var epoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFake_Timer(t *testing.T) {
+	c := clock.NewFake(epoch)
+	timer := c.NewTimer(time.Second)
+	assert.Equal(t, 1, c.Waiters())
+
+	c.Advance(999 * time.Millisecond)
+	select {
+	case <-timer.C():
+		assert.Fail(t, "timer should not fire yet")
+	default:
+	}
+	c.Advance(time.Millisecond)
+	assert.Equal(t, epoch.Add(time.Second), <-timer.C())
+	assert.Equal(t, 0, c.Waiters())
+	assert.False(t, timer.Stop(), "timer has expired")
+
+	assert.False(t, timer.Reset(time.Second))
+	assert.True(t, timer.Stop())
+	c.Advance(time.Hour)
+	select {
+	case <-timer.C():
+		assert.Fail(t, "stopped timer should not fire")
+	default:
+	}
+	assert.Equal(t, epoch.Add(time.Hour+time.Second), c.Now())
+}
+
+func TestFake_Ticker(t *testing.T) {
+	c := clock.NewFake(epoch)
+	ticker := c.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for i := 1; i <= 3; i++ {
+		c.Advance(time.Second)
+		assert.Equal(t, epoch.Add(time.Duration(i)*time.Second), <-ticker.C())
+	}
+	// ticks are dropped for slow receivers.
+	c.Advance(10 * time.Second)
+	assert.Equal(t, epoch.Add(4*time.Second), <-ticker.C())
+	select {
+	case <-ticker.C():
+		assert.Fail(t, "ticks should be dropped")
+	default:
+	}
+	ticker.Stop()
+	assert.Equal(t, 0, c.Waiters())
+}
+
+func TestFake_AfterFunc(t *testing.T) {
+	c := clock.NewFake(epoch)
+	var fired []time.Duration
+	c.AfterFunc(2*time.Second, func() {
+		fired = append(fired, c.Now().Sub(epoch))
+	})
+	c.AfterFunc(time.Second, func() {
+		fired = append(fired, c.Now().Sub(epoch))
+		// timers created in callbacks fire in the same Advance if they expire.
+		c.AfterFunc(500*time.Millisecond, func() {
+			fired = append(fired, c.Now().Sub(epoch))
+		})
+	})
+	stopped := c.AfterFunc(time.Second, func() {
+		assert.Fail(t, "stopped func should not be called")
+	})
+	assert.True(t, stopped.Stop())
+	assert.Nil(t, stopped.C())
+
+	c.Advance(3 * time.Second)
+	assert.Equal(t, []time.Duration{time.Second, 1500 * time.Millisecond, 2 * time.Second}, fired)
+}
+
+func TestReal(t *testing.T) {
+	c := clock.Real()
+	assert.Equal(t, c, clock.OrReal(nil))
+	fake := clock.NewFake(epoch)
+	assert.Equal(t, fake, clock.OrReal(fake))
+	assert.WithinDuration(t, time.Now(), c.Now(), time.Second)
+	timer := c.NewTimer(time.Millisecond)
+	<-timer.C()
+	ticker := c.NewTicker(time.Millisecond)
+	<-ticker.C()
+	ticker.Stop()
+}
@@ -66,14 +66,23 @@ type CustomError interface {
 
 // Error defines.
 var (
-	ErrFrameLengthExceed  = errors.New("rsocket: frame length is greater than 24bits")
-	ErrInvalidTransport   = errors.New("rsocket: invalid Transport")
-	ErrInvalidFrame       = errors.New("rsocket: invalid frame")
-	ErrInvalidContext     = errors.New("rsocket: invalid context")
-	ErrInvalidFrameLength = errors.New("rsocket: invalid frame length")
-	ErrReleasedResource   = errors.New("rsocket: resource has been released")
-	ErrInvalidEmitter     = errors.New("rsocket: invalid emitter")
-	ErrHandlerNil         = errors.New("rsocket: handler cannot be nil")
-	ErrHandlerExist       = errors.New("rsocket: handler exists already")
-	ErrSendFull           = errors.New("rsocket: frame send channel is full")
+	ErrFrameLengthExceed    = errors.New("rsocket: frame length is greater than 24bits")
+	ErrInvalidTransport     = errors.New("rsocket: invalid Transport")
+	ErrInvalidFrame         = errors.New("rsocket: invalid frame")
+	ErrInvalidContext       = errors.New("rsocket: invalid context")
+	ErrInvalidFrameLength   = errors.New("rsocket: invalid frame length")
+	ErrReleasedResource     = errors.New("rsocket: resource has been released")
+	ErrInvalidEmitter       = errors.New("rsocket: invalid emitter")
+	ErrHandlerNil           = errors.New("rsocket: handler cannot be nil")
+	ErrHandlerExist         = errors.New("rsocket: handler exists already")
+	ErrSendFull             = errors.New("rsocket: frame send channel is full")
+	ErrResponseTooLarge     = errors.New("rsocket: response payload exceeds max size")
+	ErrReconnectQueueFull   = errors.New("rsocket: too many requests waiting for reconnect")
+	ErrReconnectTimeout     = errors.New("rsocket: wait for reconnect timeout")
+	ErrSetupTimeout         = errors.New("rsocket: setup timeout")
+	ErrRequestCancelled     = errors.New("rsocket: request has been cancelled")
+	ErrMemoryBudgetExceeded = errors.New("rsocket: connection memory budget exceeded")
+	ErrTooManyReassemblies  = errors.New("rsocket: too many payloads being reassembled")
+	ErrSchedulerSaturated   = errors.New("rsocket: all workers of the scheduler are busy")
+	ErrSchedulerClosed      = errors.New("rsocket: scheduler has been closed")
 )
@@ -0,0 +1,30 @@
+package core_test
+
+import (
+	"fmt"
+
+	"github.com/rsocket/rsocket-go/core"
+)
+
+func ExampleFrameHeader() {
+	h := core.NewFrameHeader(1, core.FrameTypePayload, core.FlagNext|core.FlagComplete)
+	fmt.Println("stream:", h.StreamID())
+	fmt.Println("type:", h.Type())
+	fmt.Println("flag:", h.Flag())
+	fmt.Println("complete:", h.Flag().Check(core.FlagComplete))
+	fmt.Println("follow:", h.HasFlag(core.FlagFollow))
+	// Output:
+	// stream: 1
+	// type: PAYLOAD
+	// flag: N|CL
+	// complete: true
+	// follow: false
+}
+
+func ExampleParseFrameHeader() {
+	raw := []byte{0x00, 0x00, 0x00, 0x05, 0x18, 0x00}
+	h := core.ParseFrameHeader(raw)
+	fmt.Println(h)
+	// Output:
+	// FrameHeader{id=5,type=REQUEST_STREAM,flag=}
+}
@@ -13,7 +13,7 @@ type FireAndForgetFrame struct {
 
 // NewFireAndForgetFrame returns a new FireAndForgetFrame.
 func NewFireAndForgetFrame(sid uint32, data, metadata []byte, flag core.FrameFlag) *FireAndForgetFrame {
-	if len(metadata) > 0 {
+	if metadata != nil {
 		flag |= core.FlagMetadata
 	}
 
@@ -24,7 +24,7 @@ func NewFireAndForgetFrame(sid uint32, data, metadata []byte, flag core.FrameFla
 		panic(err)
 	}
 
-	if len(metadata) > 0 {
+	if metadata != nil {
 		if err := bb.WriteUint24(len(metadata)); err != nil {
 			common.ReturnByteBuff(bb)
 			panic(err)
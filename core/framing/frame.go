@@ -28,11 +28,11 @@ func FromBytes(b []byte) (f core.BufferedFrame, err error) {
 	return
 }
 
-// CalcPayloadFrameSize returns payload frame size.
+// CalcPayloadFrameSize returns payload frame size, non-nil metadata is counted even if it is empty.
 func CalcPayloadFrameSize(data, metadata []byte) int {
 	size := core.FrameHeaderLen + len(data)
-	if n := len(metadata); n > 0 {
-		size += 3 + n
+	if metadata != nil {
+		size += 3 + len(metadata)
 	}
 	return size
 }
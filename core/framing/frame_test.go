@@ -110,6 +110,27 @@ func TestFrameLease(t *testing.T) {
 	assert.Equal(t, metadata, f.Metadata())
 	f2 := NewWriteableLeaseFrame(time.Second, n, metadata)
 	checkBytes(t, f, f2)
+
+	// decode the metadata of a writeable lease frame.
+	b := &bytes.Buffer{}
+	_, err := f2.WriteTo(b)
+	assert.NoError(t, err)
+	bf := common.BorrowByteBuff()
+	defer common.ReturnByteBuff(bf)
+	_, _ = bf.Write(b.Bytes())
+	decoded, err := convert(newBufferedFrame(bf))
+	assert.NoError(t, err)
+	assert.True(t, decoded.HasFlag(core.FlagMetadata))
+	assert.Equal(t, metadata, decoded.(*LeaseFrame).Metadata())
+	assert.Equal(t, n, decoded.(*LeaseFrame).NumberOfRequests())
+
+	// without metadata
+	f3 := NewLeaseFrame(time.Second, n, nil)
+	defer f3.Release()
+	checkBasic(t, f3, core.FrameTypeLease)
+	assert.False(t, f3.HasFlag(core.FlagMetadata))
+	assert.Nil(t, f3.Metadata())
+	checkBytes(t, f3, NewWriteableLeaseFrame(time.Second, n, nil))
 }
 
 func TestFrameMetadataPush(t *testing.T) {
@@ -151,6 +172,44 @@ func TestPayloadFrame(t *testing.T) {
 	_, _ = f2.MetadataUTF8()
 }
 
+func TestPayloadFrame_Empty(t *testing.T) {
+	// empty metadata is present, and empty data is always present.
+	f := NewPayloadFrame(_sid, nil, []byte{}, core.FlagNext)
+	defer f.Release()
+	assert.Equal(t, core.FlagNext|core.FlagMetadata, f.Header().Flag())
+	m, ok := f.Metadata()
+	assert.True(t, ok)
+	assert.Empty(t, m)
+	assert.Empty(t, f.Data())
+	f2 := NewWriteablePayloadFrame(_sid, nil, []byte{}, core.FlagNext)
+	assert.Equal(t, core.FrameHeaderLen+3, f2.Len())
+	checkBytes(t, f, f2)
+	m, ok = f2.Metadata()
+	assert.True(t, ok)
+	assert.NotNil(t, m)
+
+	// absent metadata.
+	f3 := NewPayloadFrame(_sid, []byte{}, nil, core.FlagNext)
+	defer f3.Release()
+	assert.Equal(t, core.FlagNext, f3.Header().Flag())
+	_, ok = f3.Metadata()
+	assert.False(t, ok)
+	f4 := NewWriteablePayloadFrame(_sid, []byte{}, nil, core.FlagNext)
+	assert.Equal(t, core.FrameHeaderLen, f4.Len())
+	checkBytes(t, f3, f4)
+	_, ok = f4.Metadata()
+	assert.False(t, ok)
+
+	for _, fn := range []func() core.WriteableFrame{
+		func() core.WriteableFrame { return NewWriteableRequestResponseFrame(_sid, nil, []byte{}, 0) },
+		func() core.WriteableFrame { return NewWriteableRequestStreamFrame(_sid, 1, nil, []byte{}, 0) },
+		func() core.WriteableFrame { return NewWriteableRequestChannelFrame(_sid, 1, nil, []byte{}, 0) },
+		func() core.WriteableFrame { return NewWriteableFireAndForgetFrame(_sid, nil, []byte{}, 0) },
+	} {
+		assert.True(t, fn().Header().Flag().Check(core.FlagMetadata))
+	}
+}
+
 func TestFrameRequestChannel(t *testing.T) {
 	b := []byte("foobar")
 	n := uint32(1)
@@ -257,6 +316,8 @@ func TestFrameSetup(t *testing.T) {
 	assert.Equal(t, timeKeepalive, f.TimeBetweenKeepalive())
 	assert.Equal(t, maxLifetime, f.MaxLifetime())
 	assert.Equal(t, token, f.Token())
+	assert.False(t, f.LeaseRequested())
+	assert.False(t, f.WillResume())
 	assert.Equal(t, string(mimeData), f.DataMimeType())
 	assert.Equal(t, string(mimeMetadata), f.MetadataMimeType())
 	assert.Equal(t, d, f.Data())
@@ -270,6 +331,40 @@ func TestFrameSetup(t *testing.T) {
 	fs := NewWriteableSetupFrame(v, timeKeepalive, maxLifetime, token, mimeMetadata, mimeData, d, m, false)
 
 	checkBytes(t, f, fs)
+	assert.Equal(t, timeKeepalive, fs.TimeBetweenKeepalive())
+	assert.Equal(t, maxLifetime, fs.MaxLifetime())
+	assert.Equal(t, string(mimeData), fs.DataMimeType())
+
+	fs.SetData([]byte("foo"))
+	fs.SetMetadata(nil)
+	_, ok = fs.Metadata()
+	assert.False(t, ok)
+	f3 := NewSetupFrame(v, timeKeepalive, maxLifetime, token, mimeMetadata, mimeData, []byte("foo"), nil, false)
+	defer f3.Release()
+	checkBytes(t, f3, fs)
+	fs.SetMetadata([]byte("bar"))
+	m3, ok := fs.Metadata()
+	assert.True(t, ok)
+	assert.Equal(t, []byte("bar"), m3)
+
+	// with lease and resume flags
+	token = []byte("token")
+	f4 := NewSetupFrame(v, timeKeepalive, maxLifetime, token, mimeMetadata, mimeData, d, m, true)
+	defer f4.Release()
+	checkBasic(t, f4, core.FrameTypeSetup)
+	assert.True(t, f4.LeaseRequested())
+	assert.True(t, f4.WillResume())
+	assert.Equal(t, token, f4.Token())
+	assert.Equal(t, d, f4.Data())
+
+	// without data and metadata
+	f5 := NewSetupFrame(v, timeKeepalive, maxLifetime, nil, mimeMetadata, mimeData, nil, nil, false)
+	defer f5.Release()
+	checkBasic(t, f5, core.FrameTypeSetup)
+	assert.Nil(t, f5.Data())
+	assert.Empty(t, f5.DataUTF8())
+	_, ok = f5.Metadata()
+	assert.False(t, ok)
 }
 
 func checkBasic(t *testing.T, f core.BufferedFrame, typ core.FrameType) {
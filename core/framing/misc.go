@@ -48,7 +48,11 @@ func convert(f *bufferedFrame) (frame core.BufferedFrame, err error) {
 	case core.FrameTypeResumeOK:
 		frame = &ResumeOKFrame{bufferedFrame: f}
 	default:
-		err = core.ErrInvalidFrame
+		if f.Header().Flag().Check(core.FlagIgnore) {
+			frame = &UnknownFrame{bufferedFrame: f}
+		} else {
+			err = core.ErrInvalidFrame
+		}
 	}
 	return
 }
@@ -136,9 +140,10 @@ func PrintFrame(f core.Frame) string {
 }
 
 func writePayload(w io.Writer, data []byte, metadata []byte) (n int64, err error) {
-	if l := len(metadata); l > 0 {
+	// non-nil metadata is present even if it is empty.
+	if metadata != nil {
 		var wrote int64
-		u := common.MustNewUint24(l)
+		u := common.MustNewUint24(len(metadata))
 		wrote, err = u.WriteTo(w)
 		if err != nil {
 			return
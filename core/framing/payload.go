@@ -13,7 +13,7 @@ type PayloadFrame struct {
 
 // NewPayloadFrame returns a new PayloadFrame.
 func NewPayloadFrame(id uint32, data, metadata []byte, flag core.FrameFlag) *PayloadFrame {
-	if len(metadata) > 0 {
+	if metadata != nil {
 		flag |= core.FlagMetadata
 	}
 
@@ -24,7 +24,7 @@ func NewPayloadFrame(id uint32, data, metadata []byte, flag core.FrameFlag) *Pay
 		panic(err)
 	}
 
-	if len(metadata) > 0 {
+	if metadata != nil {
 		if err := bb.WriteUint24(len(metadata)); err != nil {
 			common.ReturnByteBuff(bb)
 			panic(err)
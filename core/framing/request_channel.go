@@ -20,7 +20,7 @@ type RequestChannelFrame struct {
 
 // NewRequestChannelFrame creates a new RequestChannelFrame.
 func NewRequestChannelFrame(sid uint32, n uint32, data, metadata []byte, flag core.FrameFlag) *RequestChannelFrame {
-	if len(metadata) > 0 {
+	if metadata != nil {
 		flag |= core.FlagMetadata
 	}
 	bb := common.BorrowByteBuff()
@@ -35,7 +35,7 @@ func NewRequestChannelFrame(sid uint32, n uint32, data, metadata []byte, flag co
 		panic(err)
 	}
 
-	if len(metadata) > 0 {
+	if metadata != nil {
 		if err := bb.WriteUint24(len(metadata)); err != nil {
 			common.ReturnByteBuff(bb)
 			panic(err)
@@ -13,7 +13,7 @@ type RequestResponseFrame struct {
 
 // NewRequestResponseFrame returns a new RequestResponseFrame.
 func NewRequestResponseFrame(id uint32, data, metadata []byte, fg core.FrameFlag) *RequestResponseFrame {
-	if len(metadata) > 0 {
+	if metadata != nil {
 		fg |= core.FlagMetadata
 	}
 
@@ -24,7 +24,7 @@ func NewRequestResponseFrame(id uint32, data, metadata []byte, fg core.FrameFlag
 		panic(err)
 	}
 
-	if len(metadata) > 0 {
+	if metadata != nil {
 		if err := b.WriteUint24(len(metadata)); err != nil {
 			common.ReturnByteBuff(b)
 			panic(err)
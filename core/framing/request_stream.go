@@ -19,7 +19,7 @@ type RequestStreamFrame struct {
 
 // NewRequestStreamFrame returns a new RequestStreamFrame.
 func NewRequestStreamFrame(id uint32, n uint32, data, metadata []byte, flag core.FrameFlag) *RequestStreamFrame {
-	if len(metadata) > 0 {
+	if metadata != nil {
 		flag |= core.FlagMetadata
 	}
 
@@ -34,7 +34,7 @@ func NewRequestStreamFrame(id uint32, n uint32, data, metadata []byte, flag core
 		common.ReturnByteBuff(bb)
 		panic(err)
 	}
-	if len(metadata) > 0 {
+	if metadata != nil {
 		if err := bb.WriteUint24(len(metadata)); err != nil {
 			common.ReturnByteBuff(bb)
 			panic(err)
@@ -41,7 +41,7 @@ func NewSetupFrame(
 	if len(token) > 0 {
 		fg |= core.FlagResume
 	}
-	if len(metadata) > 0 {
+	if metadata != nil {
 		fg |= core.FlagMetadata
 	}
 
@@ -92,7 +92,7 @@ func NewSetupFrame(
 		common.ReturnByteBuff(b)
 		panic(err)
 	}
-	if len(metadata) > 0 {
+	if metadata != nil {
 		if err := b.WriteUint24(len(metadata)); err != nil {
 			common.ReturnByteBuff(b)
 			panic(err)
@@ -139,6 +139,16 @@ func (p *SetupFrame) MaxLifetime() time.Duration {
 	return time.Millisecond * time.Duration(binary.BigEndian.Uint32(p.Body()[8:]))
 }
 
+// LeaseRequested returns true if the LEASE flag is set.
+func (p *SetupFrame) LeaseRequested() bool {
+	return p.HasFlag(core.FlagLease)
+}
+
+// WillResume returns true if the RESUME flag is set, the frame carries a resume token.
+func (p *SetupFrame) WillResume() bool {
+	return p.HasFlag(core.FlagResume)
+}
+
 // Token returns token of setup.
 func (p *SetupFrame) Token() []byte {
 	if !p.HasFlag(core.FlagResume) {
@@ -172,12 +182,15 @@ func (p *SetupFrame) Metadata() ([]byte, bool) {
 	return p.trySliceMetadata(offset)
 }
 
-// Data returns data bytes.
+// Data returns data bytes, it is nil if the SETUP frame carries no data.
 func (p *SetupFrame) Data() []byte {
 	offset := p.seekMIME()
 	m1, m2 := p.mime()
 	offset += 2 + len(m1) + len(m2)
 	if !p.HasFlag(core.FlagMetadata) {
+		if offset >= len(p.Body()) {
+			return nil
+		}
 		return p.Body()[offset:]
 	}
 	return p.trySliceData(offset)
@@ -0,0 +1,12 @@
+package framing
+
+// UnknownFrame is frame of an unknown type which has the IGNORE flag, it can be dropped safely.
+type UnknownFrame struct {
+	*bufferedFrame
+}
+
+// Validate returns error if frame is invalid.
+func (f *UnknownFrame) Validate() error {
+	// the body of an unknown frame is never parsed.
+	return nil
+}
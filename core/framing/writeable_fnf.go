@@ -15,7 +15,7 @@ type WriteableFireAndForgetFrame struct {
 
 // NewWriteableFireAndForgetFrame creates a new WriteableFireAndForgetFrame.
 func NewWriteableFireAndForgetFrame(sid uint32, data, metadata []byte, flag core.FrameFlag) *WriteableFireAndForgetFrame {
-	if len(metadata) > 0 {
+	if metadata != nil {
 		flag |= core.FlagMetadata
 	}
 	h := core.NewFrameHeader(sid, core.FrameTypeRequestFNF, flag)
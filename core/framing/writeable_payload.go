@@ -15,7 +15,7 @@ type WriteablePayloadFrame struct {
 
 // NewWriteablePayloadFrame returns a new WriteablePayloadFrame.
 func NewWriteablePayloadFrame(id uint32, data, metadata []byte, flag core.FrameFlag) *WriteablePayloadFrame {
-	if len(metadata) > 0 {
+	if metadata != nil {
 		flag |= core.FlagMetadata
 	}
 	h := core.NewFrameHeader(id, core.FrameTypePayload, flag)
@@ -19,7 +19,7 @@ type WriteableRequestChannelFrame struct {
 func NewWriteableRequestChannelFrame(sid uint32, n uint32, data, metadata []byte, flag core.FrameFlag) *WriteableRequestChannelFrame {
 	var b [4]byte
 	binary.BigEndian.PutUint32(b[:], n)
-	if len(metadata) > 0 {
+	if metadata != nil {
 		flag |= core.FlagMetadata
 	}
 	h := core.NewFrameHeader(sid, core.FrameTypeRequestChannel, flag)
@@ -15,7 +15,7 @@ type WriteableRequestResponseFrame struct {
 
 // NewWriteableRequestResponseFrame returns a new WriteableRequestResponseFrame.
 func NewWriteableRequestResponseFrame(id uint32, data, metadata []byte, fg core.FrameFlag) *WriteableRequestResponseFrame {
-	if len(metadata) > 0 {
+	if metadata != nil {
 		fg |= core.FlagMetadata
 	}
 	return &WriteableRequestResponseFrame{
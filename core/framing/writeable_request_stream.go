@@ -17,7 +17,7 @@ type WriteableRequestStreamFrame struct {
 
 // NewWriteableRequestStreamFrame creates a new WriteableRequestStreamFrame.
 func NewWriteableRequestStreamFrame(id uint32, n uint32, data, metadata []byte, flag core.FrameFlag) *WriteableRequestStreamFrame {
-	if len(metadata) > 0 {
+	if metadata != nil {
 		flag |= core.FlagMetadata
 	}
 	var b [4]byte
@@ -41,7 +41,7 @@ func NewWriteableSetupFrame(
 	if lease {
 		flag |= core.FlagLease
 	}
-	if l := len(metadata); l > 0 {
+	if metadata != nil {
 		flag |= core.FlagMetadata
 	}
 	h := core.NewFrameHeader(0, core.FrameTypeSetup, flag)
@@ -63,6 +63,61 @@ func NewWriteableSetupFrame(
 	}
 }
 
+// Version returns version.
+func (s WriteableSetupFrame) Version() core.Version {
+	return s.version
+}
+
+// TimeBetweenKeepalive returns keepalive interval duration.
+func (s WriteableSetupFrame) TimeBetweenKeepalive() time.Duration {
+	return time.Millisecond * time.Duration(binary.BigEndian.Uint32(s.keepalive[:]))
+}
+
+// MaxLifetime returns keepalive max lifetime.
+func (s WriteableSetupFrame) MaxLifetime() time.Duration {
+	return time.Millisecond * time.Duration(binary.BigEndian.Uint32(s.lifetime[:]))
+}
+
+// Token returns token of setup.
+func (s WriteableSetupFrame) Token() []byte {
+	return s.token
+}
+
+// DataMimeType returns MIME of data.
+func (s WriteableSetupFrame) DataMimeType() string {
+	return string(s.mimeData)
+}
+
+// MetadataMimeType returns MIME of metadata.
+func (s WriteableSetupFrame) MetadataMimeType() string {
+	return string(s.mimeMetadata)
+}
+
+// Data returns data bytes.
+func (s WriteableSetupFrame) Data() []byte {
+	return s.data
+}
+
+// Metadata returns metadata bytes.
+func (s WriteableSetupFrame) Metadata() (metadata []byte, ok bool) {
+	return s.metadata, s.header.Flag().Check(core.FlagMetadata)
+}
+
+// SetData replaces the data.
+func (s *WriteableSetupFrame) SetData(data []byte) {
+	s.data = data
+}
+
+// SetMetadata replaces the metadata, nil metadata removes the METADATA flag.
+func (s *WriteableSetupFrame) SetMetadata(metadata []byte) {
+	flag := s.header.Flag() &^ core.FlagMetadata
+	if metadata != nil {
+		flag |= core.FlagMetadata
+	}
+	s.header = core.NewFrameHeader(0, core.FrameTypeSetup, flag)
+	s.metadata = metadata
+}
+
 // WriteTo writes frame to writer.
 func (s WriteableSetupFrame) WriteTo(w io.Writer) (n int64, err error) {
 	var wrote int64
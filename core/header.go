@@ -17,6 +17,7 @@ const (
 // It includes StreamID, FrameType and Flags.
 type FrameHeader [FrameHeaderLen]byte
 
+// String returns a readable description of frame header.
 func (h FrameHeader) String() string {
 	bu := strings.Builder{}
 	bu.WriteString("FrameHeader{id=")
@@ -60,6 +61,11 @@ func (h FrameHeader) Flag() FrameFlag {
 	return FrameFlag(h.n() & 0x03FF)
 }
 
+// HasFlag returns true if target frame flag is enabled.
+func (h FrameHeader) HasFlag(flag FrameFlag) bool {
+	return h.Flag().Check(flag)
+}
+
 // Bytes returns raw frame header bytes.
 func (h FrameHeader) Bytes() []byte {
 	return h[:]
@@ -22,6 +22,8 @@ func TestHeader_All(t *testing.T) {
 	assert.Equal(t, h1.Flag(), h2.Flag())
 	assert.Equal(t, FrameTypePayload, h1.Type())
 	assert.Equal(t, FlagMetadata|FlagComplete|FlagNext, h1.Flag())
+	assert.True(t, h1.HasFlag(FlagComplete|FlagNext))
+	assert.False(t, h1.HasFlag(FlagFollow))
 	bf := &bytes.Buffer{}
 	n, err := h2.WriteTo(bf)
 	assert.NoError(t, err)
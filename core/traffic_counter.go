@@ -29,6 +29,12 @@ func (p TrafficCounter) IncReadBytes(n int) {
 	p.r.Add(uint64(n))
 }
 
+// Reset resets both read and written bytes to zero, eg: when a new session starts.
+func (p TrafficCounter) Reset() {
+	p.r.Store(0)
+	p.w.Store(0)
+}
+
 // NewTrafficCounter returns a new counter.
 func NewTrafficCounter() *TrafficCounter {
 	return &TrafficCounter{
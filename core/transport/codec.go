@@ -0,0 +1,45 @@
+package transport
+
+import (
+	"io"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/internal/common"
+)
+
+// DefaultFrameCodec is the standard codec of RSocket over byte stream transports,
+// every frame is prefixed with an unsigned 24-bit big-endian length.
+var DefaultFrameCodec FrameCodec = lengthBasedFrameCodec{}
+
+// FrameCodec defines how frames are delimited on a byte stream connection, eg: TCP or UDS.
+// It only changes the framing around frames, the frames themselves are always encoded in RSocket format.
+// Message based transports like websocket do not use it.
+type FrameCodec interface {
+	// NewDecoder creates a decoder which reads raw frames from the reader.
+	NewDecoder(r io.Reader) FrameDecoder
+	// Encode writes a frame to the writer with its framing.
+	Encode(w io.Writer, frame core.WriteableFrame) error
+}
+
+// FrameDecoder reads raw frames, a raw frame starts with the frame header.
+// It should return io.EOF when the underlying connection is closed.
+type FrameDecoder interface {
+	// Read reads next raw frame in bytes, the bytes are valid until next read.
+	Read() ([]byte, error)
+}
+
+type lengthBasedFrameCodec struct {
+}
+
+func (lengthBasedFrameCodec) NewDecoder(r io.Reader) FrameDecoder {
+	return NewLengthBasedFrameDecoder(r)
+}
+
+func (lengthBasedFrameCodec) Encode(w io.Writer, frame core.WriteableFrame) (err error) {
+	_, err = common.MustNewUint24(frame.Len()).WriteTo(w)
+	if err != nil {
+		return
+	}
+	_, err = frame.WriteTo(w)
+	return
+}
@@ -0,0 +1,91 @@
+package transport_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/framing"
+	"github.com/rsocket/rsocket-go/core/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// varintCodec prefixes every frame with a varint length.
+type varintCodec struct {
+}
+
+type varintDecoder struct {
+	r   *bufio.Reader
+	buf []byte
+}
+
+func (d *varintDecoder) Read() ([]byte, error) {
+	n, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return nil, err
+	}
+	if cap(d.buf) < int(n) {
+		d.buf = make([]byte, n)
+	}
+	d.buf = d.buf[:n]
+	if _, err := io.ReadFull(d.r, d.buf); err != nil {
+		return nil, err
+	}
+	return d.buf, nil
+}
+
+func (varintCodec) NewDecoder(r io.Reader) transport.FrameDecoder {
+	return &varintDecoder{r: bufio.NewReader(r)}
+}
+
+func (varintCodec) Encode(w io.Writer, frame core.WriteableFrame) error {
+	var prefix [binary.MaxVarintLen64]byte
+	if _, err := w.Write(prefix[:binary.PutUvarint(prefix[:], uint64(frame.Len()))]); err != nil {
+		return err
+	}
+	_, err := frame.WriteTo(w)
+	return err
+}
+
+func TestDefaultFrameCodec(t *testing.T) {
+	frame := framing.NewWriteablePayloadFrame(1, fakeData, fakeMetadata, core.FlagNext)
+	var bf bytes.Buffer
+	require.NoError(t, transport.DefaultFrameCodec.Encode(&bf, frame))
+	assert.Equal(t, frame.Len()+3, bf.Len())
+
+	raw, err := transport.DefaultFrameCodec.NewDecoder(&bf).Read()
+	require.NoError(t, err)
+	decoded, err := framing.FromBytes(raw)
+	require.NoError(t, err)
+	assert.Equal(t, fakeData, decoded.(*framing.PayloadFrame).Data())
+}
+
+func TestTCPConn_CustomFrameCodec(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	writer := transport.NewTCPConnWithCodec(client, varintCodec{})
+	reader := transport.NewTCPConnWithCodec(server, varintCodec{})
+
+	go func() {
+		_ = writer.Write(framing.NewWriteablePayloadFrame(1, fakeData, fakeMetadata, core.FlagNext))
+		_ = writer.Write(framing.NewWriteableRequestResponseFrame(3, fakeData, nil, 0))
+		_ = writer.Flush()
+	}()
+
+	first, err := reader.Read()
+	require.NoError(t, err)
+	assert.Equal(t, core.FrameTypePayload, first.Header().Type())
+	m, _ := first.(*framing.PayloadFrame).Metadata()
+	assert.Equal(t, fakeMetadata, m)
+	second, err := reader.Read()
+	require.NoError(t, err)
+	assert.Equal(t, core.FrameTypeRequestResponse, second.Header().Type())
+	assert.Equal(t, uint32(3), second.Header().StreamID())
+}
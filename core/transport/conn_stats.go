@@ -0,0 +1,51 @@
+package transport
+
+import (
+	"net"
+)
+
+// AddrConn is implemented by connections which know the address of the peer.
+type AddrConn interface {
+	// RemoteAddr returns the address of the peer, it returns nil if the address is unknown.
+	RemoteAddr() net.Addr
+}
+
+// RemoteAddr returns the address of the peer.
+func (p *TCPConn) RemoteAddr() net.Addr {
+	return p.conn.RemoteAddr()
+}
+
+// RemoteAddr returns the address of the peer, it returns nil if the raw connection doesn't know it.
+func (p *WebsocketConn) RemoteAddr() net.Addr {
+	if c, ok := p.c.(AddrConn); ok {
+		return c.RemoteAddr()
+	}
+	return nil
+}
+
+// RemoteAddr returns the address of the peer of the wrapped connection.
+func (h hexdumpConn) RemoteAddr() net.Addr {
+	if c, ok := h.Conn.(AddrConn); ok {
+		return c.RemoteAddr()
+	}
+	return nil
+}
+
+// RemoteAddr returns the address of the peer, it returns nil if the connection doesn't implement AddrConn.
+func (p *Transport) RemoteAddr() net.Addr {
+	if c, ok := p.conn.(AddrConn); ok {
+		return c.RemoteAddr()
+	}
+	return nil
+}
+
+// BytesRead returns the bytes of all frames read by the transport, excluding the frame length prefix.
+// Unlike core.TrafficCounter, which only counts resumable frames, it counts every frame.
+func (p *Transport) BytesRead() uint64 {
+	return p.bytesRead.Load()
+}
+
+// BytesWritten returns the bytes of all frames written by the transport, excluding the frame length prefix.
+func (p *Transport) BytesWritten() uint64 {
+	return p.bytesWritten.Load()
+}
@@ -0,0 +1,73 @@
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ConnectTiming is the time spent in every phase of establishing a client connection.
+type ConnectTiming struct {
+	// Dial is the time spent in the TCP connect. For websocket transports it includes the TLS
+	// and HTTP upgrade handshakes, which cannot be measured separately.
+	Dial time.Duration
+	// TLSHandshake is the time spent in the TLS handshake, it is zero without TLS.
+	TLSHandshake time.Duration
+	// Setup is the time spent in sending the SETUP (or RESUME) frame until it is flushed.
+	Setup time.Duration
+}
+
+func (t ConnectTiming) String() string {
+	return fmt.Sprintf("ConnectTiming{dial=%s,tls=%s,setup=%s}", t.Dial, t.TLSHandshake, t.Setup)
+}
+
+// ConnectTiming returns the time spent in dialing the transport, it is zero if the transport is not dialed by rsocket-go.
+func (p *Transport) ConnectTiming() ConnectTiming {
+	return p.timing
+}
+
+// SetConnectTiming sets the time spent in dialing the transport, eg: the timing returned by DialTCPWithTiming.
+// It should be set before the transport starts.
+func (p *Transport) SetConnectTiming(timing ConnectTiming) {
+	p.timing = timing
+}
+
+// DialTCPWithTiming dials the address like DialTCP, and returns the time spent in the TCP connect and TLS handshake.
+// The TLS handshake is completed before it returns, so it is bound to the context.
+func DialTCPWithTiming(ctx context.Context, network, addr string, tlsConfig *tls.Config, opts ...TCPConnOption) (conn net.Conn, timing ConnectTiming, err error) {
+	var dial net.Dialer
+	start := time.Now()
+	conn, err = dial.DialContext(ctx, network, addr)
+	if err != nil {
+		return
+	}
+	timing.Dial = time.Since(start)
+	if err = applyTCPConnOptions(conn, opts); err != nil {
+		_ = conn.Close()
+		conn = nil
+		err = errors.Wrap(err, "apply tcp options failed")
+		return
+	}
+	if tlsConfig == nil {
+		return
+	}
+	start = time.Now()
+	tlsConn := tls.Client(conn, tlsConfig)
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if err = tlsConn.Handshake(); err != nil {
+		_ = conn.Close()
+		conn = nil
+		err = errors.Wrap(err, "tls handshake failed")
+		return
+	}
+	_ = conn.SetDeadline(time.Time{})
+	timing.TLSHandshake = time.Since(start)
+	conn = tlsConn
+	return
+}
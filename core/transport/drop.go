@@ -0,0 +1,56 @@
+package transport
+
+import (
+	"github.com/rsocket/rsocket-go/core"
+)
+
+// DropReason is the reason why a frame is dropped without being handled.
+type DropReason int8
+
+// All drop reasons
+const (
+	// DroppedByInvalidStreamID means the stream id is invalid for the frame type, eg: METADATA_PUSH with non-zero stream id.
+	DroppedByInvalidStreamID DropReason = iota
+	// DroppedByUnknownType means the frame type is not understood, and the frame can be ignored because of the IGNORE flag.
+	DroppedByUnknownType
+	// DroppedByUnmatchedStream means the stream of the frame doesn't exist, maybe it has been cancelled or completed.
+	DroppedByUnmatchedStream
+	// DroppedByLease means the request frame is not sent because no lease is available.
+	DroppedByLease
+	// DroppedByTerminatedStream means the receiving side of a channel has been completed or cancelled, but the stream
+	// is still open for sending, eg: a PAYLOAD after COMPLETE.
+	DroppedByTerminatedStream
+)
+
+func (r DropReason) String() string {
+	switch r {
+	case DroppedByInvalidStreamID:
+		return "INVALID_STREAM_ID"
+	case DroppedByUnknownType:
+		return "UNKNOWN_TYPE"
+	case DroppedByUnmatchedStream:
+		return "UNMATCHED_STREAM"
+	case DroppedByLease:
+		return "LEASE"
+	case DroppedByTerminatedStream:
+		return "TERMINATED_STREAM"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// FrameDropHandler is an alias of func which is invoked when a frame is dropped, it should return quickly.
+type FrameDropHandler = func(frameType core.FrameType, streamID uint32, reason DropReason)
+
+// OnFrameDrop sets the handler of frames which are dropped by the transport.
+// It should be set before the transport starts.
+func (p *Transport) OnFrameDrop(handler FrameDropHandler) {
+	p.onDrop = handler
+}
+
+func (p *Transport) frameDropped(frame core.BufferedFrame, reason DropReason) {
+	if p.onDrop != nil {
+		h := frame.Header()
+		p.onDrop(h.Type(), h.StreamID(), reason)
+	}
+}
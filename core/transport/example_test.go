@@ -0,0 +1,38 @@
+package transport_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/framing"
+	"github.com/rsocket/rsocket-go/core/transport"
+)
+
+func ExampleTransport_Handle() {
+	c, _ := net.Pipe()
+	tp := transport.NewTCPClientTransport(c)
+	defer tp.Close()
+	// Register a custom handler, it only depends on the public frame header accessors.
+	tp.Handle(transport.OnPayload, func(frame core.BufferedFrame) error {
+		defer frame.Release()
+		h := frame.Header()
+		switch {
+		case h.HasFlag(core.FlagNext | core.FlagComplete):
+			fmt.Printf("stream %d: last %s\n", h.StreamID(), h.Type())
+		case h.Flag().Check(core.FlagNext):
+			fmt.Printf("stream %d: next %s\n", h.StreamID(), h.Type())
+		default:
+			fmt.Printf("stream %d: complete\n", h.StreamID())
+		}
+		return nil
+	})
+	_ = tp.DispatchFrame(context.Background(), framing.NewPayloadFrame(1, []byte("foo"), nil, core.FlagNext))
+	_ = tp.DispatchFrame(context.Background(), framing.NewPayloadFrame(1, []byte("bar"), nil, core.FlagNext|core.FlagComplete))
+	_ = tp.DispatchFrame(context.Background(), framing.NewPayloadFrame(3, nil, nil, core.FlagComplete))
+	// Output:
+	// stream 1: next PAYLOAD
+	// stream 1: last PAYLOAD
+	// stream 3: complete
+}
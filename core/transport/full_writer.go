@@ -0,0 +1,39 @@
+package transport
+
+import (
+	"io"
+	"time"
+)
+
+// maxWriteStalls is the max times in a row a write can make no progress before it fails.
+const maxWriteStalls = 3
+
+// fullWriter writes all bytes or fails, so that a frame is never half-sent by a writer that returns short writes,
+// eg: a custom net.Conn. The remainder of a short write is retried, and so is a write failed by a temporary error.
+type fullWriter struct {
+	w io.Writer
+}
+
+func (f fullWriter) Write(b []byte) (n int, err error) {
+	var stalls int
+	for n < len(b) {
+		var wrote int
+		wrote, err = f.w.Write(b[n:])
+		n += wrote
+		if err != nil && err != io.ErrShortWrite && !IsTemporaryError(err) {
+			return
+		}
+		if wrote > 0 {
+			stalls = 0
+			continue
+		}
+		if stalls++; stalls > maxWriteStalls {
+			if err == nil {
+				err = io.ErrShortWrite
+			}
+			return
+		}
+		time.Sleep(time.Duration(stalls) * time.Millisecond)
+	}
+	return n, nil
+}
@@ -0,0 +1,55 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/logger"
+)
+
+type hexdumpConn struct {
+	Conn
+}
+
+// NewHexdumpConn wraps a Conn and prints raw bytes of every frame read or written in hex, like `xxd`.
+// Dumping only happens when trace level of logger is enabled, otherwise it costs a level check only.
+func NewHexdumpConn(c Conn) Conn {
+	return hexdumpConn{Conn: c}
+}
+
+// EnableHexdump wraps the connection by NewHexdumpConn, it must be called before the transport is started.
+func (p *Transport) EnableHexdump() {
+	p.conn = NewHexdumpConn(p.conn)
+}
+
+// Read reads next frame from Conn.
+func (h hexdumpConn) Read() (f core.BufferedFrame, err error) {
+	f, err = h.Conn.Read()
+	if err == nil && logger.IsTraceEnabled() {
+		hexdump("read", f)
+	}
+	return
+}
+
+// Write writes a frame to Conn.
+func (h hexdumpConn) Write(f core.WriteableFrame) error {
+	if logger.IsTraceEnabled() {
+		hexdump("write", f)
+	}
+	return h.Conn.Write(f)
+}
+
+func hexdump(action string, f interface {
+	core.Frame
+	io.WriterTo
+}) {
+	var b bytes.Buffer
+	b.Grow(f.Len())
+	if _, err := f.WriteTo(&b); err != nil {
+		logger.Tracef("%s %s: dump failed: %s\n", action, f.Header(), err)
+		return
+	}
+	logger.Tracef("%s %s, %d bytes:\n%s", action, f.Header(), b.Len(), hex.Dump(b.Bytes()))
+}
@@ -0,0 +1,68 @@
+package transport_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/framing"
+	"github.com/rsocket/rsocket-go/core/transport"
+	"github.com/rsocket/rsocket-go/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+type captureLogger struct {
+	lines []string
+}
+
+func (c *captureLogger) Debugf(format string, args ...interface{}) {
+	c.lines = append(c.lines, fmt.Sprintf(format, args...))
+}
+
+func (c *captureLogger) Infof(format string, args ...interface{}) {
+}
+
+func (c *captureLogger) Warnf(format string, args ...interface{}) {
+}
+
+func (c *captureLogger) Errorf(format string, args ...interface{}) {
+}
+
+func TestHexdumpConn(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c := NewMockConn(ctrl)
+	c.EXPECT().Write(gomock.Any()).Return(nil).Times(2)
+	c.EXPECT().Read().Return(framing.NewPayloadFrame(1, []byte("hello"), nil, core.FlagNext), nil).Times(2)
+
+	l := &captureLogger{}
+	level := logger.GetLevel()
+	logger.SetLogger(l)
+	defer func() {
+		logger.SetLevel(level)
+		logger.SetLogger(nil)
+	}()
+
+	conn := transport.NewHexdumpConn(c)
+
+	// trace disabled
+	logger.SetLevel(logger.LevelDebug)
+	assert.NoError(t, conn.Write(framing.NewWriteablePayloadFrame(1, []byte("hello"), nil, core.FlagNext)))
+	_, err := conn.Read()
+	assert.NoError(t, err)
+	assert.Empty(t, l.lines)
+
+	// trace enabled
+	logger.SetLevel(logger.LevelTrace)
+	assert.NoError(t, conn.Write(framing.NewWriteablePayloadFrame(1, []byte("hello"), nil, core.FlagNext)))
+	_, err = conn.Read()
+	assert.NoError(t, err)
+	assert.Len(t, l.lines, 2)
+	for _, line := range l.lines {
+		assert.True(t, strings.Contains(line, "00 00 00 01 28 20 68 65  6c 6c 6f"), "should contain hex")
+		assert.True(t, strings.Contains(line, "|....( hello|"), "should contain ascii")
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package transport
+
+import "net"
+
+// setListenBacklog is not supported on this platform, the listener keeps the default backlog of Go.
+func setListenBacklog(l net.Listener, backlog int) error {
+	return nil
+}
@@ -1,10 +1,31 @@
 package transport
 
 import (
+	"net"
 	"net/http"
 	"strings"
+	"time"
 )
 
+const maxAcceptDelay = time.Second
+
+// IsTemporaryError returns true if the error is temporary, eg: a timeout or running out of file descriptors.
+// It is the default AcceptErrorHandler, so a server transport keeps accepting connections after temporary errors.
+func IsTemporaryError(err error) bool {
+	ne, ok := err.(net.Error)
+	return ok && ne.Temporary()
+}
+
+func nextAcceptDelay(delay time.Duration) time.Duration {
+	if delay == 0 {
+		return 5 * time.Millisecond
+	}
+	if delay *= 2; delay > maxAcceptDelay {
+		delay = maxAcceptDelay
+	}
+	return delay
+}
+
 func isClosedErr(err error) bool {
 	if err == nil {
 		return false
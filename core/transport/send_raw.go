@@ -0,0 +1,66 @@
+package transport
+
+import (
+	"io"
+
+	"github.com/rsocket/rsocket-go/core"
+)
+
+// rawFrame is a pre-encoded frame which is written as is, the header is parsed from the bytes if they are long enough.
+type rawFrame struct {
+	header core.FrameHeader
+	raw    []byte
+	done   []func()
+}
+
+func newRawFrame(raw []byte) *rawFrame {
+	f := &rawFrame{
+		raw: raw,
+	}
+	if len(raw) >= core.FrameHeaderLen {
+		f.header = core.ParseFrameHeader(raw)
+	}
+	return f
+}
+
+func (f *rawFrame) Header() core.FrameHeader {
+	return f.header
+}
+
+func (f *rawFrame) Len() int {
+	return len(f.raw)
+}
+
+func (f *rawFrame) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(f.raw)
+	return int64(n), err
+}
+
+func (f *rawFrame) Done() {
+	for _, fn := range f.done {
+		fn()
+	}
+}
+
+func (f *rawFrame) HandleDone(fn func()) {
+	f.done = append(f.done, fn)
+}
+
+// SendRaw writes the bytes as a frame and flushes, it is used to test the robustness of peers, eg: conformance and fuzz tests.
+// The bytes are the frame without the length prefix, which is added by the connection if required, so they can be malformed.
+// It bypasses the outbound interceptor and the validation of frames.
+func (p *Transport) SendRaw(raw []byte) (err error) {
+	if p.isClosed() {
+		return ErrClosed
+	}
+	p.writeMu.Lock()
+	err = p.conn.Write(newRawFrame(raw))
+	if err == nil {
+		err = p.conn.Flush()
+	}
+	p.writeMu.Unlock()
+	if err != nil && p.isClosed() {
+		err = ErrClosed
+	}
+	return
+}
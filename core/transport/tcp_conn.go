@@ -9,7 +9,6 @@ import (
 	"github.com/pkg/errors"
 	"github.com/rsocket/rsocket-go/core"
 	"github.com/rsocket/rsocket-go/core/framing"
-	"github.com/rsocket/rsocket-go/internal/common"
 	"github.com/rsocket/rsocket-go/logger"
 )
 
@@ -17,10 +16,14 @@ import (
 type TCPConn struct {
 	conn    net.Conn
 	writer  *bufio.Writer
-	decoder *LengthBasedFrameDecoder
+	codec   FrameCodec
+	decoder FrameDecoder
 	counter *core.TrafficCounter
+	broken  bool
 }
 
+var errBrokenConn = errors.New("connection is broken by a failed write")
+
 // SetCounter bind a counter which can count r/w bytes.
 func (p *TCPConn) SetCounter(c *core.TrafficCounter) {
 	p.counter = c
@@ -63,8 +66,12 @@ func (p *TCPConn) Read() (f core.BufferedFrame, err error) {
 
 // Flush flush data.
 func (p *TCPConn) Flush() (err error) {
+	if p.broken {
+		return errBrokenConn
+	}
 	err = p.writer.Flush()
 	if err != nil {
+		p.abort()
 		err = errors.Wrap(err, "flush failed")
 	}
 	return
@@ -72,30 +79,40 @@ func (p *TCPConn) Flush() (err error) {
 
 // Write writes a frame.
 func (p *TCPConn) Write(frame core.WriteableFrame) (err error) {
+	if p.broken {
+		return errBrokenConn
+	}
 	size := frame.Len()
 	if p.counter != nil && frame.Header().Resumable() {
 		p.counter.IncWriteBytes(size)
 	}
-	_, err = common.MustNewUint24(size).WriteTo(p.writer)
-	if err != nil {
-		err = errors.Wrap(err, "write frame failed")
-		return
-	}
+	// the level may be changed while writing, check it once.
+	debug := logger.IsDebugEnabled()
 	var debugStr string
-	if logger.IsDebugEnabled() {
+	if debug {
 		debugStr = framing.PrintFrame(frame)
 	}
-	_, err = frame.WriteTo(p.writer)
+	err = p.codec.Encode(p.writer, frame)
 	if err != nil {
+		p.abort()
 		err = errors.Wrap(err, "write frame failed")
 		return
 	}
-	if logger.IsDebugEnabled() {
+	if debug {
 		logger.Debugf("%s\n", debugStr)
 	}
 	return
 }
 
+// abort closes the connection after a failed write, since a part of the frame may have been written and the peer
+// could not find the boundary of next frames. Nothing is written after it.
+func (p *TCPConn) abort() {
+	if !p.broken {
+		p.broken = true
+		_ = p.conn.Close()
+	}
+}
+
 // Close close current connection.
 func (p *TCPConn) Close() error {
 	return p.conn.Close()
@@ -103,9 +120,19 @@ func (p *TCPConn) Close() error {
 
 // NewTCPConn creates a new TCP RSocket connection.
 func NewTCPConn(conn net.Conn) *TCPConn {
+	return NewTCPConnWithCodec(conn, DefaultFrameCodec)
+}
+
+// NewTCPConnWithCodec creates a new TCP RSocket connection which uses a custom frame codec.
+// DefaultFrameCodec will be used if codec is nil.
+func NewTCPConnWithCodec(conn net.Conn, codec FrameCodec) *TCPConn {
+	if codec == nil {
+		codec = DefaultFrameCodec
+	}
 	return &TCPConn{
 		conn:    conn,
-		writer:  bufio.NewWriter(conn),
-		decoder: NewLengthBasedFrameDecoder(conn),
+		writer:  bufio.NewWriter(fullWriter{w: conn}),
+		codec:   codec,
+		decoder: codec.NewDecoder(conn),
 	}
 }
@@ -110,11 +110,76 @@ func TestTcpConn_WriteWithBrokenConn(t *testing.T) {
 		Write(gomock.Any()).
 		Return(0, fakeErr).
 		AnyTimes()
+	// the connection is closed since the frame may have been written partially.
+	nc.EXPECT().Close().Times(1)
 	_ = tc.Write(framing.NewWriteablePayloadFrame(1, fakeData, fakeMetadata, 0))
 	err := tc.Flush()
 	assert.Equal(t, fakeErr, errors.Cause(err), "should be fake error")
 }
 
+func TestTcpConn_WriteWithShortWrites(t *testing.T) {
+	ctrl, nc, tc := InitMockTcpConn(t)
+	defer ctrl.Finish()
+
+	bf := &bytes.Buffer{}
+	var calls int
+	nc.EXPECT().
+		Write(gomock.Any()).
+		DoAndReturn(func(b []byte) (int, error) {
+			calls++
+			// fail by a temporary error every 3 writes, otherwise write at most 3 bytes.
+			if calls%3 == 0 {
+				return 0, temporaryErr{}
+			}
+			if len(b) > 3 {
+				b = b[:3]
+			}
+			return bf.Write(b)
+		}).
+		AnyTimes()
+
+	toBeWritten := []core.WriteableFrame{
+		framing.NewWriteablePayloadFrame(1, fakeData, fakeMetadata, 0),
+		framing.NewWriteableKeepaliveFrame(0, fakeData, true),
+		framing.NewWriteablePayloadFrame(3, make([]byte, 8192), fakeMetadata, 0),
+	}
+	for _, frame := range toBeWritten {
+		assert.NoError(t, tc.Write(frame), "write failed")
+	}
+	assert.NoError(t, tc.Flush(), "flush failed")
+
+	// all frames are intact.
+	decoder := transport.NewLengthBasedFrameDecoder(bf)
+	for _, frame := range toBeWritten {
+		raw, err := decoder.Read()
+		assert.NoError(t, err)
+		f, err := framing.FromBytes(raw)
+		assert.NoError(t, err)
+		assert.Equal(t, frame.Header(), f.Header())
+		assert.Equal(t, frame.Len(), f.Len())
+	}
+	_, err := decoder.Read()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestTcpConn_WriteWithStalledConn(t *testing.T) {
+	ctrl, nc, tc := InitMockTcpConn(t)
+	defer ctrl.Finish()
+	nc.EXPECT().
+		Write(gomock.Any()).
+		DoAndReturn(func(b []byte) (int, error) {
+			return 0, nil
+		}).
+		AnyTimes()
+	nc.EXPECT().Close().Times(1)
+	assert.NoError(t, tc.Write(framing.NewWriteablePayloadFrame(1, fakeData, fakeMetadata, 0)))
+	err := tc.Flush()
+	assert.Equal(t, io.ErrShortWrite, errors.Cause(err))
+	// nothing is written after a failed write.
+	assert.Error(t, tc.Write(framing.NewWriteablePayloadFrame(3, fakeData, fakeMetadata, 0)))
+	assert.Error(t, tc.Flush())
+}
+
 func TestTcpConn_WriteAndFlush(t *testing.T) {
 	ctrl, nc, tc := InitMockTcpConn(t)
 	defer ctrl.Finish()
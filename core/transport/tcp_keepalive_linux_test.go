@@ -0,0 +1,30 @@
+//go:build linux
+// +build linux
+
+package transport_test
+
+import (
+	"net"
+	"syscall"
+	"time"
+)
+
+// socketKeepAlive returns whether SO_KEEPALIVE is enabled on the socket and the idle time before the first probe.
+func socketKeepAlive(c net.Conn) (enabled bool, idle time.Duration, err error) {
+	raw, err := c.(*net.TCPConn).SyscallConn()
+	if err != nil {
+		return
+	}
+	var on, secs int
+	var sockErr error
+	err = raw.Control(func(fd uintptr) {
+		if on, sockErr = syscall.GetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_KEEPALIVE); sockErr != nil {
+			return
+		}
+		secs, sockErr = syscall.GetsockoptInt(int(fd), syscall.IPPROTO_TCP, syscall.TCP_KEEPIDLE)
+	})
+	if err == nil {
+		err = sockErr
+	}
+	return on != 0, time.Duration(secs) * time.Second, err
+}
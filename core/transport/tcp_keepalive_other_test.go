@@ -0,0 +1,18 @@
+//go:build !linux
+// +build !linux
+
+package transport_test
+
+import (
+	"errors"
+	"net"
+	"time"
+)
+
+var errKeepAliveUnsupported = errors.New("reading keepalive options is not supported on this platform")
+
+// socketKeepAlive is not supported on this platform, tests of keepalive options are skipped.
+func socketKeepAlive(c net.Conn) (enabled bool, idle time.Duration, err error) {
+	err = errKeepAliveUnsupported
+	return
+}
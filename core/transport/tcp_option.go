@@ -0,0 +1,68 @@
+package transport
+
+import (
+	"net"
+	"time"
+
+	"github.com/rsocket/rsocket-go/logger"
+)
+
+// TCPConnOption customizes a raw TCP connection once it has been dialed or accepted.
+// Options are applied before any TLS wrapping.
+type TCPConnOption func(c *net.TCPConn) error
+
+// WithTCPKeepAlive enables or disables SO_KEEPALIVE on the socket.
+// A positive period sets the interval between OS-level keepalive probes.
+//
+// This is unrelated to the RSocket KEEPALIVE frame: SO_KEEPALIVE is handled by the
+// kernel and only detects dead peers at the TCP level, it never reaches the RSocket
+// layer and does not prove the remote responder is still alive.
+func WithTCPKeepAlive(enabled bool, period time.Duration) TCPConnOption {
+	return func(c *net.TCPConn) error {
+		if err := c.SetKeepAlive(enabled); err != nil {
+			return err
+		}
+		if !enabled || period <= 0 {
+			return nil
+		}
+		return c.SetKeepAlivePeriod(period)
+	}
+}
+
+// WithTCPNoDelay controls TCP_NODELAY on the socket, which is enabled by default in Go.
+// Disable it to enable the Nagle algorithm, it may batch small frames of bulk streams at the cost of latency.
+func WithTCPNoDelay(noDelay bool) TCPConnOption {
+	return func(c *net.TCPConn) error {
+		return c.SetNoDelay(noDelay)
+	}
+}
+
+func applyTCPConnOptions(c net.Conn, opts []TCPConnOption) error {
+	tc, ok := c.(*net.TCPConn)
+	if !ok {
+		return nil
+	}
+	for _, opt := range opts {
+		if err := opt(tc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tcpOptionListener applies options on every accepted connection.
+type tcpOptionListener struct {
+	net.Listener
+	opts []TCPConnOption
+}
+
+func (l tcpOptionListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if err := applyTCPConnOptions(c, l.opts); err != nil {
+		logger.Warnf("apply tcp options failed: %s\n", err)
+	}
+	return c, nil
+}
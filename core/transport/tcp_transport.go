@@ -6,6 +6,7 @@ import (
 	"io"
 	"net"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -16,6 +17,10 @@ type tcpServerTransport struct {
 	f        ListenerFactory
 	l        net.Listener
 	acceptor ServerTransportAcceptor
+	codec    FrameCodec
+	hexdump  bool
+	onError  AcceptErrorHandler
+	conns    connSemaphore
 	done     chan struct{}
 }
 
@@ -25,6 +30,28 @@ func (t *tcpServerTransport) Accept(acceptor ServerTransportAcceptor) {
 	t.acceptor = acceptor
 }
 
+func (t *tcpServerTransport) OnAcceptError(handler AcceptErrorHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onError = handler
+}
+
+func (t *tcpServerTransport) EnableHexdump() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.hexdump = true
+}
+
+func (t *tcpServerTransport) SetMaxConcurrentConns(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if n > 0 {
+		t.conns = make(connSemaphore, n)
+	} else {
+		t.conns = nil
+	}
+}
+
 func (t *tcpServerTransport) Close() (err error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -72,32 +99,77 @@ func (t *tcpServerTransport) Listen(ctx context.Context, notifier chan<- bool) (
 		}
 	}()
 
+	t.mu.Lock()
+	conns := t.conns
+	t.mu.Unlock()
+
 	// Start loop of accepting connections.
-	var c net.Conn
+	var (
+		c     net.Conn
+		delay time.Duration
+	)
+L:
 	for {
+		// wait for a free slot before accepting, pending connections are queued in the listen backlog.
+		if !conns.acquire(t.done) {
+			err = nil
+			break
+		}
 		c, err = t.l.Accept()
+		if err != nil {
+			conns.release()
+		}
 		if err == io.EOF || isClosedErr(err) {
 			err = nil
 			break
 		}
 		if err != nil {
-			err = errors.Wrap(err, "accept next conn failed")
-			break
+			if !t.continueAfter(err) {
+				err = errors.Wrap(err, "accept next conn failed")
+				break
+			}
+			// back off like net/http, the error may be persistent, eg: too many open files.
+			delay = nextAcceptDelay(delay)
+			select {
+			case <-time.After(delay):
+				continue
+			case <-t.done:
+				err = nil
+				break L
+			}
 		}
+		delay = 0
 		// Dispatch raw conn.
-		tp := NewTransport(NewTCPConn(c))
+		tp := NewTransport(NewTCPConnWithCodec(c, t.codec))
+		if t.hexdump {
+			tp.EnableHexdump()
+		}
 
 		if t.putTransport(tp) {
-			go t.acceptor(ctx, tp, func(tp *Transport) {
-				t.removeTransport(tp)
-			})
+			go func(tp *Transport) {
+				defer conns.release()
+				t.acceptor(ctx, tp, func(tp *Transport) {
+					t.removeTransport(tp)
+				})
+			}(tp)
 		} else {
+			conns.release()
 			_ = t.Close()
 		}
 	}
 	return
 }
 
+func (t *tcpServerTransport) continueAfter(err error) bool {
+	t.mu.Lock()
+	handler := t.onError
+	t.mu.Unlock()
+	if handler == nil {
+		return IsTemporaryError(err)
+	}
+	return handler(err)
+}
+
 func (t *tcpServerTransport) removeTransport(tp *Transport) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -120,29 +192,105 @@ func (t *tcpServerTransport) putTransport(tp *Transport) bool {
 	}
 }
 
+// connSemaphore limits the number of connections being served, a nil connSemaphore is unlimited.
+type connSemaphore chan struct{}
+
+func (s connSemaphore) acquire(done <-chan struct{}) bool {
+	if s == nil {
+		return true
+	}
+	select {
+	case s <- struct{}{}:
+		return true
+	case <-done:
+		return false
+	}
+}
+
+func (s connSemaphore) release() {
+	if s != nil {
+		<-s
+	}
+}
+
 // NewTCPServerTransport creates a new server-side transport.
 func NewTCPServerTransport(f ListenerFactory) ServerTransport {
+	return NewTCPServerTransportWithCodec(f, DefaultFrameCodec)
+}
+
+// NewTCPServerTransportWithCodec creates a new server-side transport, accepted connections use the frame codec.
+// DefaultFrameCodec will be used if codec is nil.
+func NewTCPServerTransportWithCodec(f ListenerFactory, codec FrameCodec) ServerTransport {
 	return &tcpServerTransport{
-		f:    f,
-		m:    make(map[*Transport]struct{}),
-		done: make(chan struct{}),
+		f:     f,
+		m:     make(map[*Transport]struct{}),
+		codec: codec,
+		done:  make(chan struct{}),
 	}
 }
 
 // NewTCPServerTransportWithAddr creates a new server-side transport.
-func NewTCPServerTransportWithAddr(network, addr string, tlsConfig *tls.Config) ServerTransport {
-	f := func(ctx context.Context) (net.Listener, error) {
+// Options are applied on every accepted connection.
+func NewTCPServerTransportWithAddr(network, addr string, tlsConfig *tls.Config, opts ...TCPConnOption) ServerTransport {
+	return NewTCPServerTransport(NewTCPListenerFactory(network, addr, tlsConfig, opts...))
+}
+
+// NewTCPListenerFactory creates a listener factory which listens on the address.
+// Options are applied on every accepted connection.
+func NewTCPListenerFactory(network, addr string, tlsConfig *tls.Config, opts ...TCPConnOption) ListenerFactory {
+	return NewTCPListenerFactoryWithBacklog(network, addr, tlsConfig, 0, opts...)
+}
+
+// NewTCPListenerFactoryWithBacklog creates a listener factory which listens on the address with the listen backlog,
+// which is the max number of connections completed by the kernel but not accepted yet.
+// A non-positive backlog keeps the default of Go, which is the system max. The kernel caps the backlog, eg: by
+// net.core.somaxconn on Linux, so raise the system max first for a larger queue.
+// The backlog is only supported on unix platforms, it is ignored on others.
+func NewTCPListenerFactoryWithBacklog(network, addr string, tlsConfig *tls.Config, backlog int, opts ...TCPConnOption) ListenerFactory {
+	return func(ctx context.Context) (net.Listener, error) {
 		var c net.ListenConfig
 		l, err := c.Listen(ctx, network, addr)
 		if err != nil {
 			return nil, err
 		}
+		if backlog > 0 {
+			if err := setListenBacklog(l, backlog); err != nil {
+				_ = l.Close()
+				return nil, errors.Wrap(err, "set listen backlog failed")
+			}
+		}
+		if len(opts) > 0 {
+			l = tcpOptionListener{Listener: l, opts: opts}
+		}
 		if tlsConfig == nil {
 			return l, nil
 		}
 		return tls.NewListener(l, tlsConfig), nil
 	}
-	return NewTCPServerTransport(f)
+}
+
+// SingleStackNetwork returns a network which listens on only one IP family for the address.
+// Go's "tcp" listener on a wildcard address accepts both IPv4 and IPv6, it will be replaced by
+// "tcp4" for an empty host or "0.0.0.0", and by "tcp6" for "::". Other networks are returned as is.
+func SingleStackNetwork(network, addr string) string {
+	if network != "tcp" {
+		return network
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return network
+	}
+	if host == "" {
+		return "tcp4"
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !ip.IsUnspecified() {
+		return network
+	}
+	if ip.To4() != nil {
+		return "tcp4"
+	}
+	return "tcp6"
 }
 
 // NewTCPClientTransport creates a new transport.
@@ -150,17 +298,27 @@ func NewTCPClientTransport(c net.Conn) *Transport {
 	return NewTransport(NewTCPConn(c))
 }
 
+// NewTCPClientTransportWithCodec creates a new transport which uses a custom frame codec.
+// DefaultFrameCodec will be used if codec is nil.
+func NewTCPClientTransportWithCodec(c net.Conn, codec FrameCodec) *Transport {
+	return NewTransport(NewTCPConnWithCodec(c, codec))
+}
+
 // NewTCPClientTransportWithAddr creates a new transport.
-func NewTCPClientTransportWithAddr(ctx context.Context, network, addr string, tlsConfig *tls.Config) (tp *Transport, err error) {
-	var conn net.Conn
-	var dial net.Dialer
-	conn, err = dial.DialContext(ctx, network, addr)
+// Options are applied on the dialed connection.
+func NewTCPClientTransportWithAddr(ctx context.Context, network, addr string, tlsConfig *tls.Config, opts ...TCPConnOption) (tp *Transport, err error) {
+	conn, timing, err := DialTCPWithTiming(ctx, network, addr, tlsConfig, opts...)
 	if err != nil {
 		return
 	}
-	if tlsConfig != nil {
-		conn = tls.Client(conn, tlsConfig)
-	}
 	tp = NewTCPClientTransport(conn)
+	tp.SetConnectTiming(timing)
+	return
+}
+
+// DialTCP dials the address and returns the raw connection.
+// Options are applied on the dialed connection before TLS wrapping.
+func DialTCP(ctx context.Context, network, addr string, tlsConfig *tls.Config, opts ...TCPConnOption) (conn net.Conn, err error) {
+	conn, _, err = DialTCPWithTiming(ctx, network, addr, tlsConfig, opts...)
 	return
 }
@@ -0,0 +1,84 @@
+package transport_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/rsocket/rsocket-go/core/transport"
+)
+
+// startupClients is the number of clients connecting at the same time, the fd limit must allow twice of it.
+const startupClients = 4000
+
+// BenchmarkTCPServerTransport_Startup measures the time to accept a burst of clients with different listen backlogs.
+// Run it with: go test -run none -bench Startup -benchtime 5x ./core/transport
+func BenchmarkTCPServerTransport_Startup(b *testing.B) {
+	for _, backlog := range []int{0, 4096, 1024} {
+		b.Run(fmt.Sprintf("backlog=%d", backlog), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				benchStartup(b, backlog)
+			}
+		})
+	}
+}
+
+func benchStartup(b *testing.B, backlog int) {
+	b.StopTimer()
+	addr := make(chan string, 1)
+	f := transport.NewTCPListenerFactoryWithBacklog("tcp", "127.0.0.1:0", nil, backlog)
+	tp := transport.NewTCPServerTransport(func(ctx context.Context) (net.Listener, error) {
+		l, err := f(ctx)
+		if err == nil {
+			addr <- l.Addr().String()
+		}
+		return l, err
+	})
+	defer tp.Close()
+
+	var accepted sync.WaitGroup
+	accepted.Add(startupClients)
+	closed := make(chan struct{})
+	tp.Accept(func(ctx context.Context, tp *transport.Transport, onClose func(*transport.Transport)) {
+		defer onClose(tp)
+		accepted.Done()
+		<-closed
+	})
+	defer close(closed)
+	notifier := make(chan bool)
+	go func() {
+		_ = tp.Listen(context.Background(), notifier)
+	}()
+	if !<-notifier {
+		b.Fatal("listen failed")
+	}
+	serverAddr := <-addr
+
+	conns := make(chan net.Conn, startupClients)
+	defer func() {
+		close(conns)
+		for c := range conns {
+			_ = c.Close()
+		}
+	}()
+	var dialed sync.WaitGroup
+	dialed.Add(startupClients)
+	b.StartTimer()
+	for i := 0; i < startupClients; i++ {
+		go func() {
+			defer dialed.Done()
+			c, err := net.Dial("tcp", serverAddr)
+			if err != nil {
+				b.Error(err)
+				accepted.Done()
+				return
+			}
+			conns <- c
+		}()
+	}
+	dialed.Wait()
+	accepted.Wait()
+	b.StopTimer()
+}
@@ -5,6 +5,8 @@ import (
 	"crypto/tls"
 	"io"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -150,6 +152,58 @@ func TestTcpServerTransport_AcceptBroken(t *testing.T) {
 	<-done
 }
 
+type temporaryErr struct{}
+
+func (temporaryErr) Error() string   { return "temporary error" }
+func (temporaryErr) Timeout() bool   { return false }
+func (temporaryErr) Temporary() bool { return true }
+
+func TestTcpServerTransport_AcceptError(t *testing.T) {
+	listen := func(tp transport.ServerTransport) error {
+		notifier := make(chan bool, 1)
+		err := tp.Listen(context.Background(), notifier)
+		assert.True(t, <-notifier)
+		return err
+	}
+	acceptErrors := func(listener *mockNetListener, errs ...error) {
+		var i int
+		listener.EXPECT().
+			Accept().
+			DoAndReturn(func() (net.Conn, error) {
+				if i < len(errs) {
+					i++
+					return nil, errs[i-1]
+				}
+				return nil, io.EOF
+			}).
+			AnyTimes()
+		listener.EXPECT().Close().Times(1)
+	}
+
+	// temporary errors are skipped by default.
+	ctrl, listener, tp := InitTcpServerTransport(t)
+	acceptErrors(listener, temporaryErr{}, temporaryErr{})
+	assert.NoError(t, listen(tp))
+	ctrl.Finish()
+
+	// the handler decides whether to continue.
+	ctrl, listener, tp = InitTcpServerTransport(t)
+	acceptErrors(listener, fakeErr, temporaryErr{})
+	var handled []error
+	tp.(transport.AcceptErrorNotifier).OnAcceptError(func(err error) bool {
+		handled = append(handled, err)
+		return err == fakeErr
+	})
+	err := listen(tp)
+	assert.Error(t, err)
+	assert.Equal(t, temporaryErr{}, errors.Cause(err))
+	assert.Equal(t, []error{fakeErr, temporaryErr{}}, handled)
+	ctrl.Finish()
+
+	assert.True(t, transport.IsTemporaryError(temporaryErr{}))
+	assert.False(t, transport.IsTemporaryError(fakeErr))
+}
+
 func TestNewTcpServerTransportWithAddr(t *testing.T) {
 	assert.NotPanics(t, func() {
 		tp := transport.NewTCPServerTransportWithAddr("tcp", ":9999", nil)
@@ -163,3 +217,183 @@ func TestNewTcpServerTransportWithAddr(t *testing.T) {
 		assert.NotNil(t, tp)
 	})
 }
+
+func TestNewTcpClientTransportWithAddr_KeepAlive(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer l.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		c, err := l.Accept()
+		if err == nil {
+			accepted <- c
+		}
+	}()
+
+	tp, err := transport.NewTCPClientTransportWithAddr(context.Background(), "tcp", l.Addr().String(), nil, transport.WithTCPKeepAlive(true, 10*time.Second), transport.WithTCPNoDelay(false))
+	assert.NoError(t, err)
+	assert.NotNil(t, tp)
+	defer tp.Close()
+
+	select {
+	case c := <-accepted:
+		_ = c.Close()
+	case <-time.After(3 * time.Second):
+		assert.Fail(t, "accept timeout")
+	}
+}
+
+func TestDialTCPWithTiming(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+	addr := srv.Listener.Addr().String()
+
+	conn, timing, err := transport.DialTCPWithTiming(context.Background(), "tcp", addr, nil)
+	assert.NoError(t, err)
+	_ = conn.Close()
+	assert.True(t, timing.Dial > 0)
+	assert.Zero(t, timing.TLSHandshake, "no TLS handshake without config")
+
+	conn, timing, err = transport.DialTCPWithTiming(context.Background(), "tcp", addr, &tls.Config{
+		InsecureSkipVerify: true,
+	})
+	assert.NoError(t, err)
+	assert.True(t, conn.(*tls.Conn).ConnectionState().HandshakeComplete)
+	_ = conn.Close()
+	assert.True(t, timing.Dial > 0)
+	assert.True(t, timing.TLSHandshake > 0)
+
+	// a failed handshake is reported by the dial.
+	_, _, err = transport.DialTCPWithTiming(context.Background(), "tcp", addr, &tls.Config{
+		ServerName: "invalid.example.com",
+	})
+	assert.Error(t, err)
+
+	tp, err := transport.NewTCPClientTransportWithAddr(context.Background(), "tcp", addr, &tls.Config{
+		InsecureSkipVerify: true,
+	})
+	assert.NoError(t, err)
+	defer tp.Close()
+	assert.True(t, tp.ConnectTiming().TLSHandshake > 0)
+}
+
+func TestWithTCPKeepAlive(t *testing.T) {
+	for _, it := range []struct {
+		enabled bool
+		period  time.Duration
+	}{
+		{true, 10 * time.Second},
+		// Go enables keepalive by default, so disabling it proves options are applied.
+		{false, 0},
+	} {
+		opts := []transport.TCPConnOption{transport.WithTCPKeepAlive(it.enabled, it.period), transport.WithTCPNoDelay(false)}
+		l, err := transport.NewTCPListenerFactory("tcp", "127.0.0.1:0", nil, opts...)(context.Background())
+		if !assert.NoError(t, err) {
+			return
+		}
+		dialed, _, err := transport.DialTCPWithTiming(context.Background(), "tcp", l.Addr().String(), nil, opts...)
+		assert.NoError(t, err)
+		accepted, err := l.Accept()
+		assert.NoError(t, err)
+
+		for name, c := range map[string]net.Conn{"accepted": accepted, "dialed": dialed} {
+			enabled, idle, err := socketKeepAlive(c)
+			if err != nil {
+				_ = l.Close()
+				t.Skip(err)
+			}
+			assert.Equal(t, it.enabled, enabled, "bad SO_KEEPALIVE of %s socket", name)
+			if it.enabled {
+				assert.Equal(t, it.period, idle, "bad keepalive period of %s socket", name)
+			}
+			_ = c.Close()
+		}
+		_ = l.Close()
+	}
+}
+
+func TestSingleStackNetwork(t *testing.T) {
+	for _, it := range []struct {
+		network, addr, expect string
+	}{
+		{"tcp", ":7878", "tcp4"},
+		{"tcp", "0.0.0.0:7878", "tcp4"},
+		{"tcp", "[::]:7878", "tcp6"},
+		{"tcp", "127.0.0.1:7878", "tcp"},
+		{"tcp", "[::1]:7878", "tcp"},
+		{"tcp", "localhost:7878", "tcp"},
+		{"tcp", "bad-addr", "tcp"},
+		{"tcp6", ":7878", "tcp6"},
+		{"unix", "/var/run/rsocket.sock", "unix"},
+	} {
+		assert.Equal(t, it.expect, transport.SingleStackNetwork(it.network, it.addr), "bad network for %s %s", it.network, it.addr)
+	}
+}
+
+func TestNewTCPListenerFactoryWithBacklog(t *testing.T) {
+	for _, backlog := range []int{0, 1, 1024} {
+		l, err := transport.NewTCPListenerFactoryWithBacklog("tcp", "127.0.0.1:0", nil, backlog)(context.Background())
+		assert.NoError(t, err)
+		c, err := net.Dial("tcp", l.Addr().String())
+		assert.NoError(t, err)
+		accepted, err := l.Accept()
+		assert.NoError(t, err)
+		_ = accepted.Close()
+		_ = c.Close()
+		_ = l.Close()
+	}
+}
+
+func TestTcpServerTransport_MaxConcurrentConns(t *testing.T) {
+	addr := make(chan string, 1)
+	f := transport.NewTCPListenerFactory("tcp", "127.0.0.1:0", nil)
+	tp := transport.NewTCPServerTransport(func(ctx context.Context) (net.Listener, error) {
+		l, err := f(ctx)
+		if err == nil {
+			addr <- l.Addr().String()
+		}
+		return l, err
+	})
+	defer tp.Close()
+	tp.(transport.ConnLimiter).SetMaxConcurrentConns(1)
+
+	accepted := make(chan chan struct{}, 2)
+	tp.Accept(func(ctx context.Context, tp *transport.Transport, onClose func(*transport.Transport)) {
+		defer onClose(tp)
+		done := make(chan struct{})
+		accepted <- done
+		<-done
+	})
+	notifier := make(chan bool)
+	go func() {
+		_ = tp.Listen(context.Background(), notifier)
+	}()
+	assert.True(t, <-notifier)
+	serverAddr := <-addr
+
+	for i := 0; i < 2; i++ {
+		c, err := net.Dial("tcp", serverAddr)
+		assert.NoError(t, err, "the second connection should wait in the backlog")
+		defer c.Close()
+	}
+
+	var first chan struct{}
+	select {
+	case first = <-accepted:
+	case <-time.After(3 * time.Second):
+		assert.FailNow(t, "accept timeout")
+	}
+	select {
+	case <-accepted:
+		assert.Fail(t, "the second connection should not be served before the first one returns")
+	case <-time.After(200 * time.Millisecond):
+	}
+	close(first)
+	select {
+	case second := <-accepted:
+		close(second)
+	case <-time.After(3 * time.Second):
+		assert.Fail(t, "the second connection should be served once the first one returns")
+	}
+}
@@ -0,0 +1,66 @@
+package transport
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+
+	"github.com/gorilla/websocket"
+)
+
+// TLSConn is implemented by connections which may be secured by TLS.
+type TLSConn interface {
+	// ConnectionState returns the TLS state of current connection, ok is false if it is not a TLS connection.
+	ConnectionState() (state tls.ConnectionState, ok bool)
+}
+
+// ConnectionState returns the TLS state of current connection, ok is false if it is not a TLS connection.
+func (p *TCPConn) ConnectionState() (state tls.ConnectionState, ok bool) {
+	if c, isTLS := p.conn.(*tls.Conn); isTLS {
+		return c.ConnectionState(), true
+	}
+	return
+}
+
+// ConnectionState returns the TLS state of current connection, ok is false if it is not a TLS connection.
+func (p *WebsocketConn) ConnectionState() (state tls.ConnectionState, ok bool) {
+	if c, isWebsocket := p.c.(*websocket.Conn); isWebsocket {
+		if tc, isTLS := c.UnderlyingConn().(*tls.Conn); isTLS {
+			return tc.ConnectionState(), true
+		}
+	}
+	return
+}
+
+// ConnectionState returns the TLS state of the wrapped connection.
+func (h hexdumpConn) ConnectionState() (state tls.ConnectionState, ok bool) {
+	if c, isTLS := h.Conn.(TLSConn); isTLS {
+		return c.ConnectionState()
+	}
+	return
+}
+
+// PeerCertificates returns the verified certificate chain of the peer, the first one is the leaf certificate.
+// It returns nil if the connection is not secured by TLS or the peer certificate is not verified,
+// eg: the server doesn't require client certificates.
+func (p *Transport) PeerCertificates() []*x509.Certificate {
+	c, ok := p.conn.(TLSConn)
+	if !ok {
+		return nil
+	}
+	state, ok := c.ConnectionState()
+	if !ok || len(state.VerifiedChains) < 1 {
+		return nil
+	}
+	return state.VerifiedChains[0]
+}
+
+// RequireClientCert returns a copy of the server TLS config which requires and verifies client certificates by the CAs.
+// The system root CAs will be used if clientCAs is nil.
+func RequireClientCert(config *tls.Config, clientCAs *x509.CertPool) *tls.Config {
+	c := config.Clone()
+	c.ClientAuth = tls.RequireAndVerifyClientCert
+	if clientCAs != nil {
+		c.ClientCAs = clientCAs
+	}
+	return c
+}
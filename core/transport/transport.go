@@ -9,22 +9,55 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/clock"
 	"github.com/rsocket/rsocket-go/core/framing"
 	"github.com/rsocket/rsocket-go/internal/common"
 	"github.com/rsocket/rsocket-go/logger"
+	"go.uber.org/atomic"
 )
 
-var (
-	errTransportClosed = errors.New("transport closed")
-	errNoHandler       = errors.New("you must register a handler")
-)
+// ErrClosed is returned when sending or flushing frames on a transport which has been closed.
+// Use errors.Is to detect it, eg: trigger reconnecting after a send fails.
+var ErrClosed = errors.New("transport closed")
+
+var errNoHandler = errors.New("you must register a handler")
 
 // FrameHandler is an alias of frame handler.
 type FrameHandler = func(frame core.BufferedFrame) (err error)
 
+// OutboundInterceptor is an alias of func which can rewrite outbound frames before they are written.
+type OutboundInterceptor = func(frame core.WriteableFrame) core.WriteableFrame
+
+// InboundInterceptor is an alias of func which can rewrite inbound frames before they are dispatched.
+type InboundInterceptor = func(frame core.BufferedFrame) core.BufferedFrame
+
 // ServerTransportAcceptor is an alias of server transport handler.
 type ServerTransportAcceptor = func(ctx context.Context, tp *Transport, onClose func(*Transport))
 
+// AcceptErrorHandler is an alias of func which is invoked when a server transport fails to accept a connection.
+// The server transport keeps accepting connections if it returns true, otherwise Listen returns the error.
+type AcceptErrorHandler = func(err error) (continued bool)
+
+// AcceptErrorNotifier is implemented by server transports which report accept errors to a handler.
+type AcceptErrorNotifier interface {
+	// OnAcceptError sets the handler of accept errors, default is IsTemporaryError.
+	OnAcceptError(handler AcceptErrorHandler)
+}
+
+// Hexdumper is implemented by transports which can dump raw bytes of frames, see NewHexdumpConn.
+type Hexdumper interface {
+	// EnableHexdump wraps connections by NewHexdumpConn, it must be called before they are started or listened.
+	EnableHexdump()
+}
+
+// ConnLimiter is implemented by server transports which limit the number of connections served at the same time.
+type ConnLimiter interface {
+	// SetMaxConcurrentConns sets the max number of connections served at the same time, it must be called before Listen.
+	// The acceptor of a connection, which runs Transport.Start, holds a slot until it returns. Once all slots
+	// are taken, new connections are not accepted and wait in the listen backlog. A non-positive n means unlimited.
+	SetMaxConcurrentConns(n int)
+}
+
 // ServerTransport is server-side RSocket transport.
 type ServerTransport interface {
 	io.Closer
@@ -62,11 +95,22 @@ const (
 // Transport is RSocket transport which is used to carry RSocket frames.
 type Transport struct {
 	sync.RWMutex
+	writeMu     sync.Mutex // serializes writes, keepalive echoes are written by the read loop
 	conn        Conn
 	maxLifetime time.Duration
+	interval    time.Duration
 	lastRcvPos  uint64
 	once        sync.Once
+	closed      *atomic.Bool
 	handlers    [handlerLen]FrameHandler
+	outbound    OutboundInterceptor
+	inbound     InboundInterceptor
+	clock       clock.Clock
+	onDrop      FrameDropHandler
+	timing      ConnectTiming
+	// bytes of frames read and written.
+	bytesRead    atomic.Uint64
+	bytesWritten atomic.Uint64
 }
 
 // NewTransport creates a new transport.
@@ -74,6 +118,9 @@ func NewTransport(c Conn) *Transport {
 	return &Transport{
 		conn:        c,
 		maxLifetime: common.DefaultKeepaliveMaxLifetime,
+		interval:    common.DefaultKeepaliveInterval,
+		closed:      atomic.NewBool(false),
+		clock:       clock.Real(),
 	}
 }
 
@@ -89,6 +136,19 @@ func (p *Transport) Handle(event EventType, handler FrameHandler) {
 	p.handlers[int(event)] = handler
 }
 
+// RegisteredEvents returns the EventTypes which have handlers registered by Handle, in the order of EventType.
+func (p *Transport) RegisteredEvents() []EventType {
+	p.RLock()
+	defer p.RUnlock()
+	var events []EventType
+	for i, handler := range p.handlers {
+		if handler != nil {
+			events = append(events, EventType(i))
+		}
+	}
+	return events
+}
+
 // Connection returns current connection.
 func (p *Transport) Connection() Conn {
 	return p.conn
@@ -102,47 +162,120 @@ func (p *Transport) SetLifetime(lifetime time.Duration) {
 	p.maxLifetime = lifetime
 }
 
-// Send send a frame.
+// SetKeepaliveInterval set keepalive interval for current transport.
+func (p *Transport) SetKeepaliveInterval(interval time.Duration) {
+	if interval < 1 {
+		return
+	}
+	p.interval = interval
+}
+
+// Lifetime returns max lifetime of current transport, it is negotiated by the SETUP frame.
+func (p *Transport) Lifetime() time.Duration {
+	return p.maxLifetime
+}
+
+// KeepaliveInterval returns keepalive interval of current transport, it is negotiated by the SETUP frame.
+func (p *Transport) KeepaliveInterval() time.Duration {
+	return p.interval
+}
+
+// SetClock sets the Clock which computes the read deadline of the connection, default is the real one.
+// It should be set before the transport starts.
+func (p *Transport) SetClock(c clock.Clock) {
+	p.clock = clock.OrReal(c)
+}
+
+// SetOutboundInterceptor sets an interceptor which will be invoked for every frame before it is written.
+// The returned frame will be written instead of the original one, so you can rewrite data or metadata centrally,
+// for example field-level encryption.
+// It should be set before the transport starts.
+//
+// Notice: the interceptor runs in the write path of every frame, so keep it cheap.
+func (p *Transport) SetOutboundInterceptor(interceptor OutboundInterceptor) {
+	p.outbound = interceptor
+}
+
+// SetInboundInterceptor sets an interceptor which will be invoked for every frame before it is dispatched.
+// If a different frame is returned, the interceptor takes the ownership of the original frame and should release it.
+// It should be set before the transport starts.
+//
+// Notice: the interceptor runs in the read loop of every frame, so keep it cheap.
+func (p *Transport) SetInboundInterceptor(interceptor InboundInterceptor) {
+	p.inbound = interceptor
+}
+
+// Send send a frame, it is safe to be called concurrently.
 func (p *Transport) Send(frame core.WriteableFrame, flush bool) (err error) {
+	sending := frame
 	defer func() {
 		// ensure frame done when send success.
 		if err == nil {
 			frame.Done()
+			if sending != frame {
+				sending.Done()
+			}
 		}
 	}()
-	if p == nil || p.conn == nil {
-		err = errTransportClosed
+	if p.isClosed() {
+		err = ErrClosed
 		return
 	}
-	err = p.conn.Write(frame)
-	if err != nil {
-		return
+	if p.outbound != nil {
+		if rewrote := p.outbound(frame); rewrote != nil {
+			sending = rewrote
+		}
 	}
-	if !flush {
-		return
+	p.writeMu.Lock()
+	err = p.conn.Write(sending)
+	if err == nil {
+		p.bytesWritten.Add(uint64(sending.Len()))
+	}
+	if err == nil && flush {
+		err = p.conn.Flush()
+	}
+	p.writeMu.Unlock()
+	if err != nil && p.isClosed() {
+		// the transport was closed while writing.
+		err = ErrClosed
 	}
-	err = p.conn.Flush()
 	return
 }
 
 // Flush flush all bytes in current connection.
 func (p *Transport) Flush() (err error) {
-	if p == nil || p.conn == nil {
-		err = errTransportClosed
+	if p.isClosed() {
+		err = ErrClosed
 		return
 	}
+	p.writeMu.Lock()
 	err = p.conn.Flush()
+	p.writeMu.Unlock()
+	if err != nil && p.isClosed() {
+		err = ErrClosed
+	}
 	return
 }
 
 // Close close current transport.
+// The closed flag is set before closing the connection, so the read loop of Start can recognize an intentional close.
 func (p *Transport) Close() (err error) {
 	p.once.Do(func() {
+		p.closed.Store(true)
 		err = p.conn.Close()
 	})
 	return
 }
 
+func (p *Transport) applySetup(setup *framing.SetupFrame) {
+	p.SetLifetime(setup.MaxLifetime())
+	p.SetKeepaliveInterval(setup.TimeBetweenKeepalive())
+}
+
+func (p *Transport) isClosed() bool {
+	return p == nil || p.conn == nil || p.closed.Load()
+}
+
 // ReadFirst reads first frame.
 func (p *Transport) ReadFirst(ctx context.Context) (frame core.BufferedFrame, err error) {
 	select {
@@ -150,8 +283,13 @@ func (p *Transport) ReadFirst(ctx context.Context) (frame core.BufferedFrame, er
 		err = ctx.Err()
 	default:
 		frame, err = p.conn.Read()
+		if err == nil {
+			p.bytesRead.Add(uint64(frame.Len()))
+		}
 		if err != nil {
 			err = errors.Wrap(err, "read first frame failed")
+		} else if setup, ok := frame.(*framing.SetupFrame); ok {
+			p.applySetup(setup)
 		}
 	}
 	if err != nil {
@@ -161,6 +299,7 @@ func (p *Transport) ReadFirst(ctx context.Context) (frame core.BufferedFrame, er
 }
 
 // Start start transport.
+// It returns nil when the connection reaches EOF or the transport is closed by Close.
 func (p *Transport) Start(ctx context.Context) error {
 	defer p.Close()
 	for {
@@ -170,6 +309,7 @@ func (p *Transport) Start(ctx context.Context) error {
 		default:
 			f, err := p.conn.Read()
 			if err == nil {
+				p.bytesRead.Add(uint64(f.Len()))
 				err = p.DispatchFrame(ctx, f)
 			}
 			if err == nil {
@@ -178,6 +318,10 @@ func (p *Transport) Start(ctx context.Context) error {
 			if err == io.EOF {
 				return nil
 			}
+			if p.isClosed() {
+				// errors of reading a connection closed by Close are expected, eg: use of closed network connection.
+				return nil
+			}
 			return errors.Wrap(err, "dispatch incoming frame failed:")
 		}
 	}
@@ -185,6 +329,11 @@ func (p *Transport) Start(ctx context.Context) error {
 
 // DispatchFrame delivery incoming frames.
 func (p *Transport) DispatchFrame(_ context.Context, frame core.BufferedFrame) (err error) {
+	if p.inbound != nil {
+		if rewrote := p.inbound(frame); rewrote != nil {
+			frame = rewrote
+		}
+	}
 	header := frame.Header()
 	t := header.Type()
 	sid := header.StreamID()
@@ -193,7 +342,7 @@ func (p *Transport) DispatchFrame(_ context.Context, frame core.BufferedFrame) (
 
 	switch t {
 	case core.FrameTypeSetup:
-		p.maxLifetime = frame.(*framing.SetupFrame).MaxLifetime()
+		p.applySetup(frame.(*framing.SetupFrame))
 		handler = p.getHandler(OnSetup)
 	case core.FrameTypeResume:
 		handler = p.getHandler(OnResume)
@@ -206,6 +355,8 @@ func (p *Transport) DispatchFrame(_ context.Context, frame core.BufferedFrame) (
 		if sid != 0 {
 			// skip invalid metadata push
 			logger.Warnf("rsocket: omit MetadataPush with non-zero stream id %d\n", sid)
+			p.frameDropped(frame, DroppedByInvalidStreamID)
+			frame.Release()
 			return
 		}
 		handler = p.getHandler(OnMetadataPush)
@@ -234,12 +385,22 @@ func (p *Transport) DispatchFrame(_ context.Context, frame core.BufferedFrame) (
 		ka := frame.(*framing.KeepaliveFrame)
 		p.lastRcvPos = ka.LastReceivedPosition()
 		handler = p.getHandler(OnKeepalive)
+		if handler == nil && ka.HasFlag(core.FlagRespond) {
+			// the echo is mandatory, send it even if nobody handles keepalive frames.
+			handler = p.echoKeepalive
+		}
 	case core.FrameTypeLease:
 		handler = p.getHandler(OnLease)
+	default:
+		if _, ok := frame.(*framing.UnknownFrame); ok {
+			p.frameDropped(frame, DroppedByUnknownType)
+			frame.Release()
+			return
+		}
 	}
 
 	// Set deadline.
-	deadline := time.Now().Add(p.maxLifetime)
+	deadline := p.clock.Now().Add(p.maxLifetime)
 	err = p.conn.SetDeadline(deadline)
 	if err != nil {
 		return
@@ -259,6 +420,13 @@ func (p *Transport) DispatchFrame(_ context.Context, frame core.BufferedFrame) (
 	return
 }
 
+// echoKeepalive responds a KEEPALIVE frame with the RESPOND flag, the data is kept and the flag is cleared.
+func (p *Transport) echoKeepalive(frame core.BufferedFrame) error {
+	defer frame.Release()
+	data := common.CloneBytes(frame.(*framing.KeepaliveFrame).Data())
+	return p.Send(framing.NewWriteableKeepaliveFrame(0, data, false), true)
+}
+
 func (p *Transport) getHandler(t EventType) FrameHandler {
 	p.RLock()
 	defer p.RUnlock()
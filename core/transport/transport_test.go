@@ -3,15 +3,20 @@ package transport_test
 import (
 	"context"
 	"io"
+	"net"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/pkg/errors"
 	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/clock"
 	"github.com/rsocket/rsocket-go/core/framing"
 	"github.com/rsocket/rsocket-go/core/transport"
+	"github.com/rsocket/rsocket-go/core/transport/transporttest"
+	"github.com/rsocket/rsocket-go/internal/common"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/atomic"
 )
 
@@ -38,6 +43,32 @@ func TestTransport_Start(t *testing.T) {
 	assert.NoError(t, err, "there should be no error here if io.EOF occurred")
 }
 
+func TestTransport_StartAfterClose(t *testing.T) {
+	ctrl, conn, tp := Init(t)
+	defer ctrl.Finish()
+
+	closed := make(chan struct{})
+	closedErr := errors.New("use of closed network connection")
+	conn.EXPECT().Close().DoAndReturn(func() error {
+		close(closed)
+		return nil
+	}).Times(1)
+	conn.EXPECT().Read().DoAndReturn(func() (core.BufferedFrame, error) {
+		<-closed
+		return nil, closedErr
+	}).Times(1)
+	conn.EXPECT().Write(gomock.Any()).Times(0)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- tp.Start(context.Background())
+	}()
+	assert.NoError(t, tp.Close())
+	assert.NoError(t, <-done, "a clean close should produce no error")
+	err := tp.Send(framing.NewWriteableCancelFrame(1), true)
+	assert.Equal(t, transport.ErrClosed, err)
+}
+
 func TestTransport_RegisterHandler(t *testing.T) {
 	ctrl, conn, tp := Init(t)
 	defer ctrl.Finish()
@@ -148,6 +179,20 @@ func TestTransport_RegisterHandler(t *testing.T) {
 	assert.Equal(t, int32(1), callsErrorWithZeroStreamID.Load(), "error frame with zero stream id has not been called")
 }
 
+func TestTransport_RegisteredEvents(t *testing.T) {
+	tp := transport.NewTransport(nil)
+	assert.Empty(t, tp.RegisteredEvents())
+	handler := func(frame core.BufferedFrame) error {
+		return nil
+	}
+	tp.Handle(transport.OnRequestStream, handler)
+	tp.Handle(transport.OnSetup, handler)
+	tp.Handle(transport.OnKeepalive, handler)
+	assert.Equal(t, []transport.EventType{transport.OnSetup, transport.OnRequestStream, transport.OnKeepalive}, tp.RegisteredEvents())
+	tp.Handle(transport.OnRequestStream, nil)
+	assert.Equal(t, []transport.EventType{transport.OnSetup, transport.OnKeepalive}, tp.RegisteredEvents())
+}
+
 func TestTransport_ReadFirst(t *testing.T) {
 	ctrl, conn, tp := Init(t)
 	defer ctrl.Finish()
@@ -165,6 +210,21 @@ func TestTransport_ReadFirst(t *testing.T) {
 	assert.Equal(t, expect, actual, "not match")
 }
 
+func TestTransport_KeepaliveSettings(t *testing.T) {
+	ctrl, conn, tp := Init(t)
+	defer ctrl.Finish()
+
+	assert.Equal(t, common.DefaultKeepaliveInterval, tp.KeepaliveInterval())
+	assert.Equal(t, common.DefaultKeepaliveMaxLifetime, tp.Lifetime())
+
+	setup := framing.NewSetupFrame(core.DefaultVersion, 10*time.Second, 30*time.Second, nil, []byte("text/plain"), []byte("text/plain"), fakeData, fakeMetadata, false)
+	conn.EXPECT().Read().Return(setup, nil).Times(1)
+	_, err := tp.ReadFirst(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 10*time.Second, tp.KeepaliveInterval())
+	assert.Equal(t, 30*time.Second, tp.Lifetime())
+}
+
 func TestTransport_Send(t *testing.T) {
 	ctrl, conn, tp := Init(t)
 	defer ctrl.Finish()
@@ -211,6 +271,23 @@ func TestTransport_Close(t *testing.T) {
 	assert.NoError(t, err, "close transport failed")
 }
 
+func TestTransport_ErrClosed(t *testing.T) {
+	ctrl, conn, tp := Init(t)
+	defer ctrl.Finish()
+
+	conn.EXPECT().Close().Times(1)
+	conn.EXPECT().Write(gomock.Any()).Times(0)
+	conn.EXPECT().Flush().Times(0)
+
+	assert.NoError(t, tp.Close())
+	err := tp.Send(framing.NewWriteableCancelFrame(1), true)
+	assert.True(t, errors.Is(err, transport.ErrClosed))
+	assert.True(t, errors.Is(errors.Wrap(tp.Flush(), "flush failed"), transport.ErrClosed))
+
+	var nilTransport *transport.Transport
+	assert.Equal(t, transport.ErrClosed, nilTransport.Send(framing.NewWriteableCancelFrame(1), false))
+}
+
 func TestTransport_HandlerReturnsError(t *testing.T) {
 	ctrl, conn, tp := Init(t)
 	defer ctrl.Finish()
@@ -237,3 +314,268 @@ func TestTransport_EmptyHandler(t *testing.T) {
 	err := tp.Start(context.Background())
 	assert.True(t, transport.IsNoHandlerError(errors.Cause(err)), "should be no handler error")
 }
+
+func TestTransport_OutboundInterceptor(t *testing.T) {
+	ctrl, conn, tp := Init(t)
+	defer ctrl.Finish()
+
+	rewrote := framing.NewWriteablePayloadFrame(1, fakeData, nil, core.FlagNext)
+	conn.EXPECT().Write(rewrote).Times(1)
+	conn.EXPECT().Flush().Times(1)
+
+	tp.SetOutboundInterceptor(func(frame core.WriteableFrame) core.WriteableFrame {
+		return rewrote
+	})
+
+	origin := framing.NewWriteablePayloadFrame(1, fakeData, fakeMetadata, core.FlagNext)
+	originDone, rewroteDone := atomic.NewBool(false), atomic.NewBool(false)
+	origin.HandleDone(func() {
+		originDone.Store(true)
+	})
+	rewrote.HandleDone(func() {
+		rewroteDone.Store(true)
+	})
+	err := tp.Send(origin, true)
+	assert.NoError(t, err, "send failed")
+	assert.True(t, originDone.Load(), "original frame should be done")
+	assert.True(t, rewroteDone.Load(), "rewrote frame should be done")
+}
+
+func TestTransport_InboundInterceptor(t *testing.T) {
+	ctrl, conn, tp := Init(t)
+	defer ctrl.Finish()
+
+	conn.EXPECT().SetDeadline(gomock.Any()).AnyTimes()
+
+	rewrote := framing.NewPayloadFrame(1, fakeData, nil, core.FlagNext)
+	tp.SetInboundInterceptor(func(frame core.BufferedFrame) core.BufferedFrame {
+		frame.Release()
+		return rewrote
+	})
+
+	var actual core.BufferedFrame
+	tp.Handle(transport.OnPayload, func(frame core.BufferedFrame) error {
+		actual = frame
+		return nil
+	})
+	err := tp.DispatchFrame(context.Background(), framing.NewPayloadFrame(1, fakeData, fakeMetadata, core.FlagNext))
+	assert.NoError(t, err, "dispatch failed")
+	assert.Equal(t, rewrote, actual, "should dispatch the rewrote frame")
+}
+
+func TestTransport_DispatchFrame_MetadataPushWithStreamID(t *testing.T) {
+	conn := transporttest.NewConn()
+	tp := transport.NewTransport(conn)
+	var dropped []transport.DropReason
+	tp.OnFrameDrop(func(frameType core.FrameType, streamID uint32, reason transport.DropReason) {
+		assert.Equal(t, core.FrameTypeMetadataPush, frameType)
+		assert.Equal(t, uint32(1), streamID)
+		dropped = append(dropped, reason)
+	})
+	called := atomic.NewBool(false)
+	tp.Handle(transport.OnMetadataPush, func(frame core.BufferedFrame) error {
+		called.Store(true)
+		return nil
+	})
+
+	err := tp.DispatchFrame(context.Background(), framing.NewMetadataPushFrame(fakeMetadata))
+	assert.NoError(t, err)
+	assert.True(t, called.Load())
+	called.Store(false)
+
+	h := core.NewFrameHeader(1, core.FrameTypeMetadataPush, core.FlagMetadata)
+	invalid, err := framing.FromBytes(append(h.Bytes(), fakeMetadata...))
+	require.NoError(t, err)
+	deadline := conn.Deadline()
+	err = tp.DispatchFrame(context.Background(), invalid)
+	assert.NoError(t, err)
+	assert.False(t, called.Load(), "metadata push with non-zero stream id should be skipped")
+	assert.Equal(t, []transport.DropReason{transport.DroppedByInvalidStreamID}, dropped)
+	assert.Equal(t, deadline, conn.Deadline(), "deadline should not be refreshed")
+}
+
+func TestTransport_DispatchFrame_ZeroStreamIDError(t *testing.T) {
+	conn := transporttest.NewConn()
+	tp := transport.NewTransport(conn)
+	var received core.FrameType
+	tp.Handle(transport.OnErrorWithZeroStreamID, func(frame core.BufferedFrame) error {
+		received = frame.Header().Type()
+		return nil
+	})
+	tp.Handle(transport.OnError, func(frame core.BufferedFrame) error {
+		assert.FailNow(t, "unreachable")
+		return nil
+	})
+	err := tp.DispatchFrame(context.Background(), framing.NewErrorFrame(0, core.ErrorCodeConnectionClose, []byte("bye")))
+	assert.Error(t, err)
+	assert.Equal(t, core.FrameTypeError, received)
+}
+
+func TestTransport_DispatchFrame_UnknownType(t *testing.T) {
+	unknown := core.FrameType(0x30)
+	conn := transporttest.NewConn()
+	tp := transport.NewTransport(conn)
+	var dropped []transport.DropReason
+	tp.OnFrameDrop(func(frameType core.FrameType, streamID uint32, reason transport.DropReason) {
+		assert.Equal(t, unknown, frameType)
+		dropped = append(dropped, reason)
+	})
+
+	h := core.NewFrameHeader(1, unknown, core.FlagIgnore)
+	frame, err := framing.FromBytes(append(h.Bytes(), fakeData...))
+	require.NoError(t, err)
+	assert.NoError(t, frame.Validate())
+	assert.NoError(t, tp.DispatchFrame(context.Background(), frame))
+	assert.Equal(t, []transport.DropReason{transport.DroppedByUnknownType}, dropped)
+	assert.Equal(t, "UNKNOWN_TYPE", dropped[0].String())
+
+	h = core.NewFrameHeader(1, unknown, 0)
+	_, err = framing.FromBytes(append(h.Bytes(), fakeData...))
+	assert.Equal(t, core.ErrInvalidFrame, err, "unknown frame without IGNORE flag should be invalid")
+}
+
+func TestTransport_DispatchFrame_MissingHandler(t *testing.T) {
+	conn := transporttest.NewConn()
+	tp := transport.NewTransport(conn)
+	err := tp.DispatchFrame(context.Background(), framing.NewRequestResponseFrame(1, fakeData, fakeMetadata, 0))
+	assert.True(t, transport.IsNoHandlerError(err))
+	assert.False(t, conn.Deadline().IsZero(), "deadline should be refreshed")
+
+	fakeDeadlineErr := errors.New("fake deadline error")
+	conn.SetDeadlineError(fakeDeadlineErr)
+	err = tp.DispatchFrame(context.Background(), framing.NewRequestResponseFrame(1, fakeData, fakeMetadata, 0))
+	assert.Equal(t, fakeDeadlineErr, err)
+}
+
+func TestTransport_SetClock(t *testing.T) {
+	conn := transporttest.NewConn()
+	tp := transport.NewTransport(conn)
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	tp.SetClock(clock.NewFake(now))
+	tp.SetLifetime(time.Minute)
+	_ = tp.DispatchFrame(context.Background(), framing.NewRequestResponseFrame(1, fakeData, fakeMetadata, 0))
+	assert.Equal(t, now.Add(time.Minute), conn.Deadline(), "deadline should be computed by the clock")
+}
+
+func TestTransport_StartWithFakeConn(t *testing.T) {
+	conn := transporttest.NewConn(
+		framing.NewRequestResponseFrame(1, fakeData, fakeMetadata, 0),
+		framing.NewRequestResponseFrame(3, fakeData, fakeMetadata, 0),
+	)
+	tp := transport.NewTransport(conn)
+	received := atomic.NewInt32(0)
+	tp.Handle(transport.OnRequestResponse, func(frame core.BufferedFrame) error {
+		received.Inc()
+		return tp.Send(framing.NewWriteablePayloadFrame(frame.Header().StreamID(), fakeData, nil, core.FlagComplete), true)
+	})
+	done := make(chan error, 1)
+	go func() {
+		done <- tp.Start(context.Background())
+	}()
+	assert.Eventually(t, func() bool {
+		return len(conn.Written()) == 2
+	}, 3*time.Second, 10*time.Millisecond)
+	_ = tp.Close()
+	<-done
+	assert.Equal(t, int32(2), received.Load())
+	assert.Equal(t, 2, conn.Flushes())
+	written := conn.Written()
+	assert.Equal(t, uint32(1), core.ParseFrameHeader(written[0]).StreamID())
+	assert.Equal(t, uint32(3), core.ParseFrameHeader(written[1]).StreamID())
+	assert.True(t, conn.IsClosed())
+}
+
+func TestTransport_DispatchFrame_KeepaliveRespond(t *testing.T) {
+	conn := transporttest.NewConn()
+	tp := transport.NewTransport(conn)
+
+	err := tp.DispatchFrame(context.Background(), framing.NewKeepaliveFrame(0, fakeData, true))
+	assert.NoError(t, err)
+	written := conn.Written()
+	require.Len(t, written, 1)
+	assert.Equal(t, 1, conn.Flushes())
+	echo, err := framing.FromBytes(written[0])
+	require.NoError(t, err)
+	assert.Equal(t, core.FrameTypeKeepalive, echo.Header().Type())
+	assert.False(t, echo.HasFlag(core.FlagRespond), "RESPOND flag should be cleared")
+	assert.Equal(t, fakeData, echo.(*framing.KeepaliveFrame).Data())
+
+	// no echo without the RESPOND flag.
+	err = tp.DispatchFrame(context.Background(), framing.NewKeepaliveFrame(0, fakeData, false))
+	assert.True(t, transport.IsNoHandlerError(err))
+	assert.Len(t, conn.Written(), 1)
+}
+
+func TestTransport_SendRaw(t *testing.T) {
+	conn := transporttest.NewConn()
+	tp := transport.NewTransport(conn)
+	var intercepted bool
+	tp.SetOutboundInterceptor(func(frame core.WriteableFrame) core.WriteableFrame {
+		intercepted = true
+		return nil
+	})
+
+	// a truncated REQUEST_N frame
+	raw := append(core.NewFrameHeader(1, core.FrameTypeRequestN, 0).Bytes(), 0x00, 0x01)
+	require.NoError(t, tp.SendRaw(raw))
+	require.NoError(t, tp.SendRaw([]byte{0xFF}))
+	assert.Equal(t, [][]byte{raw, {0xFF}}, conn.Written())
+	assert.Equal(t, 2, conn.Flushes())
+	assert.False(t, intercepted, "raw frames should bypass the interceptor")
+
+	_ = tp.Close()
+	assert.Equal(t, transport.ErrClosed, tp.SendRaw(raw))
+}
+
+func TestTransport_SendRawOverTCP(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	tp := transport.NewTransport(transport.NewTCPConn(client))
+	reader := transport.NewTCPConn(server)
+	raw := append(core.NewFrameHeader(1, core.FrameTypeRequestN, 0).Bytes(), 0x00, 0x00, 0x00, 0x05)
+	go func() {
+		_ = tp.SendRaw(raw)
+	}()
+
+	// the length prefix is added by the connection.
+	frame, err := reader.Read()
+	require.NoError(t, err)
+	defer frame.Release()
+	assert.Equal(t, core.FrameTypeRequestN, frame.Header().Type())
+	assert.Equal(t, uint32(5), frame.(*framing.RequestNFrame).N())
+}
+
+// exclusiveConn fails writes which overlap with another write.
+type exclusiveConn struct {
+	*transporttest.Conn
+	writing *atomic.Bool
+}
+
+func (c exclusiveConn) Write(frame core.WriteableFrame) error {
+	if !c.writing.CAS(false, true) {
+		return errors.New("concurrent write")
+	}
+	defer c.writing.Store(false)
+	time.Sleep(time.Millisecond)
+	return c.Conn.Write(frame)
+}
+
+func TestTransport_KeepaliveEchoWithSend(t *testing.T) {
+	conn := exclusiveConn{Conn: transporttest.NewConn(), writing: atomic.NewBool(false)}
+	tp := transport.NewTransport(conn)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			assert.NoError(t, tp.Send(framing.NewWriteablePayloadFrame(1, fakeData, nil, core.FlagNext), true))
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		assert.NoError(t, tp.DispatchFrame(context.Background(), framing.NewKeepaliveFrame(0, fakeData, true)))
+	}
+	<-done
+	assert.Len(t, conn.Written(), 40)
+}
@@ -0,0 +1,149 @@
+// Package transporttest provides utilities for testing RSocket transports.
+package transporttest
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/transport"
+)
+
+var _ transport.Conn = (*Conn)(nil)
+
+// Conn is a fake transport.Conn which can be used to unit-test frame handling without real transports.
+// Reads are scripted by the frames pushed, and writes are recorded as raw bytes.
+type Conn struct {
+	cond        *sync.Cond
+	reads       []core.BufferedFrame
+	written     [][]byte
+	flushes     int
+	deadline    time.Time
+	deadlineErr error
+	closed      bool
+	counter     *core.TrafficCounter
+}
+
+// NewConn creates a fake Conn which returns the given frames in order when reading.
+func NewConn(reads ...core.BufferedFrame) *Conn {
+	return &Conn{
+		cond:  sync.NewCond(&sync.Mutex{}),
+		reads: reads,
+	}
+}
+
+// Push appends frames which will be returned when reading.
+func (c *Conn) Push(frames ...core.BufferedFrame) {
+	c.cond.L.Lock()
+	c.reads = append(c.reads, frames...)
+	c.cond.L.Unlock()
+	c.cond.Broadcast()
+}
+
+// Read returns next scripted frame.
+// It blocks until a frame is pushed if no frame left, and returns io.EOF after closed.
+func (c *Conn) Read() (core.BufferedFrame, error) {
+	c.cond.L.Lock()
+	defer c.cond.L.Unlock()
+	for len(c.reads) < 1 && !c.closed {
+		c.cond.Wait()
+	}
+	if len(c.reads) < 1 {
+		return nil, io.EOF
+	}
+	next := c.reads[0]
+	c.reads[0] = nil
+	c.reads = c.reads[1:]
+	return next, nil
+}
+
+// Write records a copy of the frame.
+func (c *Conn) Write(frame core.WriteableFrame) error {
+	var bf bytes.Buffer
+	if _, err := frame.WriteTo(&bf); err != nil {
+		return err
+	}
+	c.cond.L.Lock()
+	defer c.cond.L.Unlock()
+	if c.closed {
+		return io.ErrClosedPipe
+	}
+	c.written = append(c.written, bf.Bytes())
+	if c.counter != nil {
+		c.counter.IncWriteBytes(bf.Len())
+	}
+	return nil
+}
+
+// Flush counts the flushes.
+func (c *Conn) Flush() error {
+	c.cond.L.Lock()
+	c.flushes++
+	c.cond.L.Unlock()
+	return nil
+}
+
+// SetDeadline records the deadline and returns the error set by SetDeadlineError.
+func (c *Conn) SetDeadline(deadline time.Time) error {
+	c.cond.L.Lock()
+	defer c.cond.L.Unlock()
+	c.deadline = deadline
+	return c.deadlineErr
+}
+
+// SetDeadlineError sets the error which will be returned by SetDeadline, nil means success.
+func (c *Conn) SetDeadlineError(err error) {
+	c.cond.L.Lock()
+	c.deadlineErr = err
+	c.cond.L.Unlock()
+}
+
+// SetCounter binds a counter of written bytes.
+func (c *Conn) SetCounter(counter *core.TrafficCounter) {
+	c.cond.L.Lock()
+	c.counter = counter
+	c.cond.L.Unlock()
+}
+
+// Close closes current Conn, pending reads will return io.EOF.
+func (c *Conn) Close() error {
+	c.cond.L.Lock()
+	c.closed = true
+	c.cond.L.Unlock()
+	c.cond.Broadcast()
+	return nil
+}
+
+// Deadline returns the last deadline set.
+func (c *Conn) Deadline() (deadline time.Time) {
+	c.cond.L.Lock()
+	deadline = c.deadline
+	c.cond.L.Unlock()
+	return
+}
+
+// Written returns raw bytes of written frames, use core.ParseFrameHeader to parse their headers.
+func (c *Conn) Written() (written [][]byte) {
+	c.cond.L.Lock()
+	written = append(written, c.written...)
+	c.cond.L.Unlock()
+	return
+}
+
+// Flushes returns the count of flushes.
+func (c *Conn) Flushes() (n int) {
+	c.cond.L.Lock()
+	n = c.flushes
+	c.cond.L.Unlock()
+	return
+}
+
+// IsClosed returns true if current Conn has been closed.
+func (c *Conn) IsClosed() (closed bool) {
+	c.cond.L.Lock()
+	closed = c.closed
+	c.cond.L.Unlock()
+	return
+}
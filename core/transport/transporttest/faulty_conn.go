@@ -0,0 +1,193 @@
+package transporttest
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/clock"
+	"github.com/rsocket/rsocket-go/core/transport"
+)
+
+var _ transport.Conn = (*FaultyConn)(nil)
+
+// Faults are the adverse network conditions injected by a FaultyConn into the frames it reads.
+type Faults struct {
+	// Latency delays every frame read.
+	Latency time.Duration
+	// Jitter adds a random delay in [0, Jitter) to the Latency, frames are still read in order.
+	Jitter time.Duration
+	// DropRate is the probability in [0, 1] that a frame is dropped, eg: a lost KEEPALIVE.
+	DropRate float64
+	// ReorderRate is the probability in [0, 1] that a frame is held back and read after the next one.
+	ReorderRate float64
+	// Seed seeds the random faults, the same seed injects the same faults.
+	Seed int64
+	// Clock measures the latency, inject a clock.Fake to advance it manually. Default is the real one.
+	Clock clock.Clock
+}
+
+// delayedFrame is a frame read ahead with its faults, they are decided in the order of reading to be reproducible.
+type delayedFrame struct {
+	frame   core.BufferedFrame
+	due     time.Time
+	drop    bool
+	reorder bool
+}
+
+// FaultyConn is a transport.Conn decorator which injects latency, jitter, drops and reorders into the frames read
+// from the wrapped connection, writes are passed through. Wrap the connections of both sides to slow down both
+// directions.
+//
+// Frames are read ahead from the wrapped connection, so the read deadline of the wrapped connection is still
+// refreshed by the frames dispatched, eg: a connection whose KEEPALIVE frames are all dropped times out as if
+// no frame had been received.
+type FaultyConn struct {
+	transport.Conn
+	faults    Faults
+	clock     clock.Clock
+	rand      *rand.Rand
+	frames    chan delayedFrame
+	err       error
+	held      core.BufferedFrame
+	late      core.BufferedFrame
+	startOnce sync.Once
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// NewFaultyConn wraps the connection and injects the faults into the frames read.
+func NewFaultyConn(conn transport.Conn, faults Faults) *FaultyConn {
+	return &FaultyConn{
+		Conn:   conn,
+		faults: faults,
+		clock:  clock.OrReal(faults.Clock),
+		rand:   rand.New(rand.NewSource(faults.Seed)),
+		frames: make(chan delayedFrame, 1024),
+		done:   make(chan struct{}),
+	}
+}
+
+// FaultyClientTransporter returns a transport.ClientTransporter which injects the faults into the frames read
+// by the transports of the given transporter, eg: rsocket.Connect().Transport(FaultyClientTransporter(tp, faults)).
+func FaultyClientTransporter(tp transport.ClientTransporter, faults Faults) transport.ClientTransporter {
+	return func(ctx context.Context) (*transport.Transport, error) {
+		origin, err := tp(ctx)
+		if err != nil {
+			return nil, err
+		}
+		faulty := transport.NewTransport(NewFaultyConn(origin.Connection(), faults))
+		faulty.SetConnectTiming(origin.ConnectTiming())
+		return faulty, nil
+	}
+}
+
+// Read returns the next frame of the wrapped connection once it is due, unless it is dropped or reordered.
+func (c *FaultyConn) Read() (core.BufferedFrame, error) {
+	c.startOnce.Do(func() {
+		go c.readAhead()
+	})
+	if late := c.late; late != nil {
+		c.late = nil
+		return late, nil
+	}
+	for {
+		next, ok := <-c.frames
+		if !ok {
+			// no frame to be reordered with, the held one is read as is.
+			if held := c.held; held != nil {
+				c.held = nil
+				return held, nil
+			}
+			return nil, c.err
+		}
+		if err := c.wait(next.due); err != nil {
+			next.frame.Release()
+			return nil, err
+		}
+		if next.drop {
+			next.frame.Release()
+			continue
+		}
+		if c.held != nil {
+			c.late, c.held = c.held, nil
+			return next.frame, nil
+		}
+		if next.reorder {
+			c.held = next.frame
+			continue
+		}
+		return next.frame, nil
+	}
+}
+
+// Close closes the wrapped connection.
+func (c *FaultyConn) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+	return c.Conn.Close()
+}
+
+// RemoteAddr returns the address of the peer of the wrapped connection.
+func (c *FaultyConn) RemoteAddr() net.Addr {
+	if conn, ok := c.Conn.(transport.AddrConn); ok {
+		return conn.RemoteAddr()
+	}
+	return nil
+}
+
+func (c *FaultyConn) readAhead() {
+	defer close(c.frames)
+	for {
+		next, err := c.Conn.Read()
+		if err != nil {
+			c.err = err
+			return
+		}
+		delayed := delayedFrame{
+			frame:   next,
+			due:     c.clock.Now().Add(c.delay()),
+			drop:    c.chance(c.faults.DropRate),
+			reorder: c.chance(c.faults.ReorderRate),
+		}
+		select {
+		case c.frames <- delayed:
+		case <-c.done:
+			next.Release()
+			c.err = io.EOF
+			return
+		}
+	}
+}
+
+func (c *FaultyConn) delay() time.Duration {
+	d := c.faults.Latency
+	if c.faults.Jitter > 0 {
+		d += time.Duration(c.rand.Int63n(int64(c.faults.Jitter)))
+	}
+	return d
+}
+
+func (c *FaultyConn) wait(due time.Time) error {
+	d := due.Sub(c.clock.Now())
+	if d <= 0 {
+		return nil
+	}
+	timer := c.clock.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return nil
+	case <-c.done:
+		return io.EOF
+	}
+}
+
+func (c *FaultyConn) chance(rate float64) bool {
+	return rate > 0 && c.rand.Float64() < rate
+}
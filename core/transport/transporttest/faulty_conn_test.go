@@ -0,0 +1,135 @@
+package transporttest_test
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/clock"
+	"github.com/rsocket/rsocket-go/core/framing"
+	"github.com/rsocket/rsocket-go/core/transport/transporttest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPayloads(n int) []core.BufferedFrame {
+	frames := make([]core.BufferedFrame, n)
+	for i := range frames {
+		frames[i] = framing.NewPayloadFrame(uint32(i+1), []byte("foo"), nil, core.FlagNext)
+	}
+	return frames
+}
+
+func readStreamIDs(t *testing.T, c *transporttest.FaultyConn, n int) []uint32 {
+	ids := make([]uint32, 0, n)
+	for i := 0; i < n; i++ {
+		next, err := c.Read()
+		assert.NoError(t, err)
+		ids = append(ids, next.Header().StreamID())
+	}
+	return ids
+}
+
+func TestFaultyConn_Latency(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	c := transporttest.NewFaultyConn(transporttest.NewConn(newPayloads(1)...), transporttest.Faults{
+		Latency: time.Second,
+		Clock:   fake,
+	})
+	defer c.Close()
+
+	read := make(chan core.BufferedFrame, 1)
+	go func() {
+		next, err := c.Read()
+		assert.NoError(t, err)
+		read <- next
+	}()
+	assert.Eventually(t, func() bool {
+		return fake.Waiters() == 1
+	}, 3*time.Second, time.Millisecond)
+	fake.Advance(999 * time.Millisecond)
+	select {
+	case <-read:
+		assert.Fail(t, "frame should be delayed")
+	case <-time.After(50 * time.Millisecond):
+	}
+	fake.Advance(time.Millisecond)
+	select {
+	case next := <-read:
+		assert.Equal(t, uint32(1), next.Header().StreamID())
+	case <-time.After(3 * time.Second):
+		assert.Fail(t, "frame should be read after the latency")
+	}
+}
+
+func TestFaultyConn_Drop(t *testing.T) {
+	frames := newPayloads(3)
+	conn := transporttest.NewConn(append([]core.BufferedFrame(nil), frames...)...)
+	c := transporttest.NewFaultyConn(conn, transporttest.Faults{
+		DropRate: 1,
+	})
+	_ = conn.Close()
+	_, err := c.Read()
+	assert.Equal(t, io.EOF, err)
+	for _, frame := range frames {
+		assert.Equal(t, int32(0), frame.RefCnt(), "dropped frame should be released")
+	}
+}
+
+func TestFaultyConn_Reorder(t *testing.T) {
+	conn := transporttest.NewConn(newPayloads(5)...)
+	c := transporttest.NewFaultyConn(conn, transporttest.Faults{
+		ReorderRate: 1,
+	})
+	defer c.Close()
+	assert.Equal(t, []uint32{2, 1, 4, 3}, readStreamIDs(t, c, 4))
+	// the last frame has nothing to be reordered with.
+	_ = conn.Close()
+	assert.Equal(t, []uint32{5}, readStreamIDs(t, c, 1))
+}
+
+func TestFaultyConn_Seed(t *testing.T) {
+	read := func() []uint32 {
+		conn := transporttest.NewConn(newPayloads(100)...)
+		c := transporttest.NewFaultyConn(conn, transporttest.Faults{
+			DropRate:    0.3,
+			ReorderRate: 0.3,
+			Seed:        42,
+		})
+		_ = conn.Close()
+		var ids []uint32
+		for {
+			next, err := c.Read()
+			if err != nil {
+				return ids
+			}
+			ids = append(ids, next.Header().StreamID())
+		}
+	}
+	first := read()
+	assert.True(t, len(first) > 0 && len(first) < 100, "some frames should be dropped")
+	assert.Equal(t, first, read(), "same seed should inject same faults")
+}
+
+func TestFaultyConn_Close(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	c := transporttest.NewFaultyConn(transporttest.NewConn(newPayloads(1)...), transporttest.Faults{
+		Latency: time.Hour,
+		Clock:   fake,
+	})
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.Read()
+		done <- err
+	}()
+	assert.Eventually(t, func() bool {
+		return fake.Waiters() == 1
+	}, 3*time.Second, time.Millisecond)
+	assert.NoError(t, c.Close())
+	select {
+	case err := <-done:
+		assert.Equal(t, io.EOF, err)
+	case <-time.After(3 * time.Second):
+		assert.Fail(t, "delayed read should be interrupted by close")
+	}
+}
@@ -30,6 +30,8 @@ type Conn interface {
 	// Read reads next frame from Conn.
 	Read() (core.BufferedFrame, error)
 	// Write writes a frame to Conn.
+	// If no part of the frame has been written and the Conn is still usable, it returns a temporary error as is,
+	// see IsTemporaryError, so that an idempotent control frame can be written again.
 	Write(core.WriteableFrame) error
 	// Flush.
 	Flush() error
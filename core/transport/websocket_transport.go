@@ -24,6 +24,7 @@ type wsServerTransport struct {
 	f        ListenerFactory
 	l        net.Listener
 	m        map[*Transport]struct{}
+	hexdump  bool
 	done     chan struct{}
 }
 
@@ -55,6 +56,12 @@ func (ws *wsServerTransport) Accept(acceptor ServerTransportAcceptor) {
 	ws.acceptor = acceptor
 }
 
+func (ws *wsServerTransport) EnableHexdump() {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.hexdump = true
+}
+
 func (ws *wsServerTransport) Listen(ctx context.Context, notifier chan<- bool) (err error) {
 	ws.l, err = ws.f(ctx)
 	if err != nil {
@@ -90,6 +97,9 @@ func (ws *wsServerTransport) Listen(ctx context.Context, notifier chan<- bool) (
 
 		// new websocket transport
 		tp := NewTransport(NewWebsocketConnection(c))
+		if ws.hexdump {
+			tp.EnableHexdump()
+		}
 
 		if ws.putTransport(tp) {
 			// accept async
@@ -188,9 +198,14 @@ func NewWebsocketClientTransport(ctx context.Context, url string, config *tls.Co
 			TLSClientConfig:  config,
 		}
 	}
+	start := time.Now()
 	conn, _, err := dial.DialContext(ctx, url, header)
 	if err != nil {
 		return nil, errors.Wrap(err, "dial websocket failed")
 	}
-	return NewTransport(NewWebsocketConnection(conn)), nil
+	tp := NewTransport(NewWebsocketConnection(conn))
+	tp.SetConnectTiming(ConnectTiming{
+		Dial: time.Since(start),
+	})
+	return tp, nil
 }
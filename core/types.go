@@ -30,6 +30,7 @@ const (
 	FrameTypeExt             FrameType = 0x3F
 )
 
+// String returns the name of frame type, eg: REQUEST_RESPONSE.
 func (f FrameType) String() string {
 	switch f {
 	case FrameTypeReserved:
@@ -72,6 +73,7 @@ func (f FrameType) String() string {
 // FrameFlag is flag of frame.
 type FrameFlag uint16
 
+// String returns the short names of enabled flags joined by "|", eg: N|CL.
 func (f FrameFlag) String() string {
 	foo := make([]string, 0)
 	if f.Check(FlagNext) {
@@ -94,14 +96,22 @@ func (f FrameFlag) String() string {
 
 // All frame flags
 const (
+	// FlagNext means the payload carries data and/or metadata (PAYLOAD, REQUEST_CHANNEL).
 	FlagNext FrameFlag = 1 << (5 + iota)
+	// FlagComplete means the stream is completed (PAYLOAD, REQUEST_CHANNEL).
 	FlagComplete
+	// FlagFollow means more fragments follow this frame.
 	FlagFollow
+	// FlagMetadata means the frame carries metadata.
 	FlagMetadata
+	// FlagIgnore means the frame can be ignored if it is not understood.
 	FlagIgnore
 
-	FlagResume  = FlagFollow
-	FlagLease   = FlagComplete
+	// FlagResume means the client requests resume capability (SETUP).
+	FlagResume = FlagFollow
+	// FlagLease means lease will be honored (SETUP).
+	FlagLease = FlagComplete
+	// FlagRespond means the receiver should respond with a KEEPALIVE (KEEPALIVE).
 	FlagRespond = FlagFollow
 )
 
@@ -110,8 +120,9 @@ func (f FrameFlag) Check(flag FrameFlag) bool {
 	return flag&f == flag
 }
 
+// Frame is the basic contract of all frames.
 type Frame interface {
-	// FrameHeader returns frame FrameHeader.
+	// Header returns frame FrameHeader.
 	Header() FrameHeader
 	// Len returns length of frame.
 	Len() int
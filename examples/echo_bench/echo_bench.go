@@ -24,7 +24,6 @@ import (
 var tp transport.ClientTransporter
 
 func init() {
-	flag.Parse()
 	rand.Seed(time.Now().UnixNano())
 	tp = rsocket.TCPClient().SetHostAndPort("127.0.0.1", 7878).Build()
 
@@ -38,10 +37,13 @@ func main() {
 		n           int
 		payloadSize int
 		mtu         int
+		batch       bool
 	)
 	flag.IntVar(&n, "n", 100*10000, "request amount.")
 	flag.IntVar(&payloadSize, "size", 1024, "payload data size.")
 	flag.IntVar(&mtu, "mtu", 0, "mut size, zero means disabled.")
+	flag.BoolVar(&batch, "batch", false, "subscribe all requests by mono.SubscribeAll.")
+	flag.Parse()
 
 	client, err := createClient(mtu)
 	if err != nil {
@@ -58,23 +60,30 @@ func main() {
 
 	errCount := atomic.NewInt32(0)
 
-	sub := rx.NewSubscriber(
-		rx.OnNext(func(input payload.Payload) error {
-			//m2, _ := elem.MetadataUTF8()
-			//assert.Equal(t, m1, m2, "metadata doesn't match")
-			wg.Done()
-			return nil
-		}),
-		rx.OnError(func(e error) {
-			wg.Done()
-			errCount.Inc()
-		}),
-	)
+	onNext := rx.OnNext(func(input payload.Payload) error {
+		//m2, _ := elem.MetadataUTF8()
+		//assert.Equal(t, m1, m2, "metadata doesn't match")
+		wg.Done()
+		return nil
+	})
+	onError := rx.OnError(func(e error) {
+		wg.Done()
+		errCount.Inc()
+	})
 	request := payload.New(data, nil)
-	for i := 0; i < n; i++ {
-		client.RequestResponse(request).SubscribeOn(scheduler.Parallel()).SubscribeWith(context.Background(), sub)
+	if batch {
+		monos := make([]mono.Mono, n)
+		for i := 0; i < n; i++ {
+			monos[i] = client.RequestResponse(request)
+		}
+		_ = mono.SubscribeAll(context.Background(), scheduler.Parallel(), monos, onNext, onError)
+	} else {
+		sub := rx.NewSubscriber(onNext, onError)
+		for i := 0; i < n; i++ {
+			client.RequestResponse(request).SubscribeOn(scheduler.Parallel()).SubscribeWith(context.Background(), sub)
+		}
+		wg.Wait()
 	}
-	wg.Wait()
 	cost := time.Since(now)
 	log.Println("TOTAL:", n)
 	log.Println("COST:", cost)
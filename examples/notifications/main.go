@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rsocket/rsocket-go"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx/flux"
+)
+
+const clientID = "alice"
+
+func main() {
+	readyCh := make(chan struct{})
+	registry := rsocket.NewPushRegistry()
+
+	// start a server in a go routine
+	go server(readyCh, registry)
+
+	// wait for the server to be ready
+	<-readyCh
+
+	// connect a client which receives notifications
+	done := make(chan struct{})
+	cli := client(done)
+	defer cli.Close()
+
+	// wait until the client has been registered
+	for len(registry.Clients()) < 1 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// push notifications to the client without being requested
+	notifications := flux.Create(func(ctx context.Context, sink flux.Sink) {
+		for i := 0; i < 5; i++ {
+			time.Sleep(100 * time.Millisecond)
+			sink.Next(payload.NewString(fmt.Sprintf("notification #%d", i), ""))
+		}
+		sink.Complete()
+	})
+	if err := registry.PushStream(context.Background(), clientID, notifications); err != nil {
+		panic(err)
+	}
+	<-done
+}
+
+func server(readyCh chan struct{}, registry *rsocket.PushRegistry) {
+	err := rsocket.Receive().
+		OnStart(func() {
+			close(readyCh)
+		}).
+		Acceptor(func(setup payload.SetupPayload, sendingSocket rsocket.CloseableRSocket) (rsocket.RSocket, error) {
+			// the client id is sent by the SETUP payload
+			registry.Register(setup.DataUTF8(), sendingSocket)
+			return rsocket.NewAbstractSocket(), nil
+		}).
+		Transport(rsocket.TCPServer().SetAddr(":7878").Build()).
+		Serve(context.Background())
+	panic(err)
+}
+
+func client(done chan struct{}) rsocket.Client {
+	cli, err := rsocket.Connect().
+		SetupPayload(payload.NewString(clientID, "")).
+		Acceptor(func(socket rsocket.RSocket) rsocket.RSocket {
+			// register a handler which receives push streams of the server
+			return rsocket.NewAbstractSocket(rsocket.PushStreamHandler(func(notifications flux.Flux) {
+				notifications.
+					DoOnNext(func(input payload.Payload) error {
+						fmt.Println("receive:", input.DataUTF8())
+						return nil
+					}).
+					DoOnComplete(func() {
+						fmt.Println("notifications completed")
+						close(done)
+					}).
+					Subscribe(context.Background())
+			}))
+		}).
+		Transport(rsocket.TCPClient().SetHostAndPort("127.0.0.1", 7878).Build()).
+		Start(context.Background())
+	if err != nil {
+		panic(err)
+	}
+	return cli
+}
@@ -2,12 +2,15 @@ package extension
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"math"
 
 	"github.com/rsocket/rsocket-go/internal/common"
 )
 
+var errInvalidCompositeMetadata = errors.New("invalid composite metadata bytes")
+
 // CompositeMetadata provides multi Metadata payloads with different MIME types.
 type CompositeMetadata []byte
 
@@ -65,10 +68,22 @@ func (c *CompositeMetadataScanner) decodeCompositeMetadataOnce(raw []byte) (leng
 	} else {
 		mimeTypeLen := int(idOrLen) + 1
 		size += mimeTypeLen
+		if len(raw) < size {
+			err = errInvalidCompositeMetadata
+			return
+		}
 		mimeType = string(raw[1 : 1+mimeTypeLen])
 	}
+	if len(raw) < size+3 {
+		err = errInvalidCompositeMetadata
+		return
+	}
 	metadataLen := common.NewUint24Bytes(raw[size : size+3]).AsInt()
 	length = size + 3 + metadataLen
+	if len(raw) < length {
+		err = errInvalidCompositeMetadata
+		return
+	}
 	metadata = raw[size+3 : length]
 	return
 }
@@ -105,6 +120,12 @@ func (c *CompositeMetadataBuilder) PushString(mimeType string, metadata string)
 func (c *CompositeMetadataBuilder) Build() (CompositeMetadata, error) {
 	bf := bytes.Buffer{}
 	for i := 0; i < len(c.k); i++ {
+		metadata := c.v[i]
+		metadataLen := len(metadata)
+		// the length of an entry is encoded as an uint24.
+		if metadataLen > common.MaxUint24 {
+			return nil, fmt.Errorf("length of metadata for MIME type %s is %d, which is over %d", c.k[i], metadataLen, common.MaxUint24)
+		}
 		switch mimeType := c.k[i].(type) {
 		case MIME:
 			bf.WriteByte(0x80 | byte(mimeType))
@@ -118,8 +139,6 @@ func (c *CompositeMetadataBuilder) Build() (CompositeMetadata, error) {
 		default:
 			panic("unreachable")
 		}
-		metadata := c.v[i]
-		metadataLen := len(metadata)
 		bf.Write(common.MustNewUint24(metadataLen).Bytes())
 		if metadataLen > 0 {
 			bf.Write(metadata)
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/rsocket/rsocket-go/internal/common"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -21,3 +22,18 @@ func TestCompositeMetadataBuilder_Build(t *testing.T) {
 		fmt.Println("mimeType:", mimeType, "metadata:", string(metadata))
 	}
 }
+
+func TestCompositeMetadataBuilder_BuildOversized(t *testing.T) {
+	oversized := make([]byte, common.MaxUint24+1)
+	_, err := NewCompositeMetadataBuilder().
+		PushString("text/plain", "text").
+		Push("application/custom", oversized).
+		Build()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "application/custom")
+	_, err = NewCompositeMetadataBuilder().PushWellKnown(ApplicationJSON, oversized).Build()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ApplicationJSON.String())
+	_, err = NewCompositeMetadataBuilder().PushWellKnown(ApplicationJSON, oversized[:common.MaxUint24]).Build()
+	assert.NoError(t, err)
+}
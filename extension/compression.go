@@ -0,0 +1,225 @@
+package extension
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx/flux"
+	"github.com/rsocket/rsocket-go/rx/mono"
+)
+
+const (
+	// AcceptEncodingMimeType is the MIME type of the entry in the CompositeMetadata of a request which opts the stream
+	// in compression, the value is the name of an encoding, eg: "gzip". Push one entry for every acceptable encoding,
+	// the responder picks the first one it supports. Streams without the entry are never compressed.
+	AcceptEncodingMimeType = "message/x.rsocket.accept-encoding.v0"
+	// ContentEncodingMimeType is the MIME type of the entry in the CompositeMetadata of a payload whose data is compressed,
+	// the value is the name of the encoding.
+	ContentEncodingMimeType = "message/x.rsocket.content-encoding.v0"
+)
+
+// Compressor compresses data of payloads by an encoding.
+type Compressor interface {
+	// Encoding returns the name of encoding, eg: "gzip".
+	Encoding() string
+	// Compress compresses the data.
+	Compress(data []byte) ([]byte, error)
+	// Decompress decompresses the data.
+	Decompress(data []byte) ([]byte, error)
+}
+
+// GzipCompressor is a Compressor of the "gzip" encoding.
+var GzipCompressor Compressor = gzipCompressor{}
+
+type gzipCompressor struct{}
+
+func (gzipCompressor) Encoding() string {
+	return "gzip"
+}
+
+func (gzipCompressor) Compress(data []byte) ([]byte, error) {
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func (gzipCompressor) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+// PushAcceptEncoding pushes the acceptable encodings into a CompositeMetadataBuilder, in order of preference.
+func PushAcceptEncoding(builder *CompositeMetadataBuilder, encodings ...string) *CompositeMetadataBuilder {
+	for _, encoding := range encodings {
+		builder.PushString(AcceptEncodingMimeType, encoding)
+	}
+	return builder
+}
+
+// CompressPayload returns a new payload whose data is compressed by the Compressor,
+// the content encoding entry is appended to its CompositeMetadata. The metadata must be a CompositeMetadata or absent.
+func CompressPayload(p payload.Payload, c Compressor) (payload.Payload, error) {
+	data, err := c.Compress(p.Data())
+	if err != nil {
+		return nil, err
+	}
+	entry, err := NewCompositeMetadataBuilder().PushString(ContentEncodingMimeType, c.Encoding()).Build()
+	if err != nil {
+		return nil, err
+	}
+	metadata, _ := p.Metadata()
+	return payload.New(data, append(append([]byte{}, metadata...), entry...)), nil
+}
+
+// DecompressPayload returns a new payload whose data is decompressed if the payload has a content encoding entry,
+// the entry is dropped from its CompositeMetadata. The payload is returned as is if it is not compressed.
+// It fails if the encoding is not supported by any Compressor.
+func DecompressPayload(p payload.Payload, compressors ...Compressor) (payload.Payload, error) {
+	metadata, ok := p.Metadata()
+	if !ok {
+		return p, nil
+	}
+	encodings, err := findCompositeMetadata(metadata, ContentEncodingMimeType)
+	if err != nil || len(encodings) < 1 {
+		return p, err
+	}
+	c, ok := pickCompressor(encodings[:1], compressors)
+	if !ok {
+		return nil, fmt.Errorf("unsupported content encoding: %s", encodings[0])
+	}
+	data, err := c.Decompress(p.Data())
+	if err != nil {
+		return nil, err
+	}
+	if metadata, err = dropCompositeMetadata(metadata, ContentEncodingMimeType); err != nil {
+		return nil, err
+	}
+	return payload.New(data, metadata), nil
+}
+
+// StreamCompression is a middleware of responders which compresses responses of the streams opted in compression
+// by AcceptEncodingMimeType, other streams are served as is. Compressed requests are decompressed before the handler.
+type StreamCompression struct {
+	compressors []Compressor
+}
+
+// NewStreamCompression creates a StreamCompression which supports the compressors, default is GzipCompressor.
+func NewStreamCompression(compressors ...Compressor) *StreamCompression {
+	if len(compressors) < 1 {
+		compressors = []Compressor{GzipCompressor}
+	}
+	return &StreamCompression{
+		compressors: compressors,
+	}
+}
+
+// RequestResponse returns a RequestResponse handler which compresses the response if the request accepts it.
+func (s *StreamCompression) RequestResponse(handler func(request payload.Payload) mono.Mono) func(payload.Payload) mono.Mono {
+	return func(request payload.Payload) mono.Mono {
+		request, c, err := s.negotiate(request)
+		if err != nil {
+			return mono.Error(err)
+		}
+		sending := handler(request)
+		if c == nil || sending == nil {
+			return sending
+		}
+		return sending.Map(func(response payload.Payload) (payload.Payload, error) {
+			return CompressPayload(response, c)
+		})
+	}
+}
+
+// RequestStream returns a RequestStream handler which compresses every response if the request accepts it.
+func (s *StreamCompression) RequestStream(handler func(request payload.Payload) flux.Flux) func(payload.Payload) flux.Flux {
+	return func(request payload.Payload) flux.Flux {
+		request, c, err := s.negotiate(request)
+		if err != nil {
+			return flux.Error(err)
+		}
+		sending := handler(request)
+		if c == nil || sending == nil {
+			return sending
+		}
+		return sending.Map(func(response payload.Payload) (payload.Payload, error) {
+			return CompressPayload(response, c)
+		})
+	}
+}
+
+// negotiate decompresses the request, and returns the Compressor of responses which is nil if the stream is not opted in.
+func (s *StreamCompression) negotiate(request payload.Payload) (payload.Payload, Compressor, error) {
+	request, err := DecompressPayload(request, s.compressors...)
+	if err != nil {
+		return nil, nil, err
+	}
+	metadata, ok := request.Metadata()
+	if !ok {
+		return request, nil, nil
+	}
+	accepted, err := findCompositeMetadata(metadata, AcceptEncodingMimeType)
+	if err != nil {
+		return nil, nil, err
+	}
+	c, _ := pickCompressor(accepted, s.compressors)
+	return request, c, nil
+}
+
+func pickCompressor(encodings []string, compressors []Compressor) (Compressor, bool) {
+	for _, encoding := range encodings {
+		for _, c := range compressors {
+			if c.Encoding() == encoding {
+				return c, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// findCompositeMetadata returns values of all entries of the MIME type in the CompositeMetadata.
+func findCompositeMetadata(metadata []byte, mimeType string) (values []string, err error) {
+	scanner := NewCompositeMetadataBytes(metadata).Scanner()
+	for scanner.Scan() {
+		var k, v string
+		if k, v, err = scanner.MetadataUTF8(); err != nil {
+			return
+		}
+		if k == mimeType {
+			values = append(values, v)
+		}
+	}
+	return
+}
+
+// dropCompositeMetadata returns a copy of the CompositeMetadata without entries of the MIME types.
+func dropCompositeMetadata(metadata []byte, mimeTypes ...string) ([]byte, error) {
+	builder := NewCompositeMetadataBuilder()
+	scanner := NewCompositeMetadataBytes(metadata).Scanner()
+L:
+	for scanner.Scan() {
+		mimeType, entry, err := scanner.Metadata()
+		if err != nil {
+			return nil, err
+		}
+		for _, excluded := range mimeTypes {
+			if mimeType == excluded {
+				continue L
+			}
+		}
+		builder.Push(mimeType, entry)
+	}
+	return builder.Build()
+}
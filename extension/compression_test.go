@@ -0,0 +1,92 @@
+package extension
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx/flux"
+	"github.com/rsocket/rsocket-go/rx/mono"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressPayload(t *testing.T) {
+	data := strings.Repeat("foobar", 100)
+	metadata, err := NewCompositeMetadataBuilder().PushString("application/x.custom", "foo").Build()
+	require.NoError(t, err)
+
+	compressed, err := CompressPayload(payload.New([]byte(data), metadata), GzipCompressor)
+	require.NoError(t, err)
+	assert.Less(t, len(compressed.Data()), len(data))
+	m, _ := compressed.Metadata()
+	encodings, err := findCompositeMetadata(m, ContentEncodingMimeType)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"gzip"}, encodings)
+
+	decompressed, err := DecompressPayload(compressed, GzipCompressor)
+	require.NoError(t, err)
+	assert.Equal(t, data, decompressed.DataUTF8())
+	m, _ = decompressed.Metadata()
+	assert.Equal(t, []byte(metadata), m)
+
+	plain := payload.NewString("plain", "")
+	res, err := DecompressPayload(plain, GzipCompressor)
+	assert.NoError(t, err)
+	assert.Equal(t, plain, res)
+
+	_, err = DecompressPayload(compressed)
+	assert.Error(t, err, "should fail on unsupported encoding")
+}
+
+func TestStreamCompression(t *testing.T) {
+	data := strings.Repeat("foobar", 100)
+	sc := NewStreamCompression()
+	rr := sc.RequestResponse(func(request payload.Payload) mono.Mono {
+		return mono.Just(payload.NewString(request.DataUTF8()+data, ""))
+	})
+	stream := sc.RequestStream(func(request payload.Payload) flux.Flux {
+		return flux.Just(payload.NewString(request.DataUTF8()+data, ""), payload.NewString(data, ""))
+	})
+	accept, err := PushAcceptEncoding(NewCompositeMetadataBuilder(), "br", "gzip").Build()
+	require.NoError(t, err)
+
+	// opted in
+	res, err := rr(payload.New([]byte("a"), accept)).Block(context.Background())
+	require.NoError(t, err)
+	assert.NotEqual(t, "a"+data, res.DataUTF8())
+	res, err = DecompressPayload(res, GzipCompressor)
+	assert.NoError(t, err)
+	assert.Equal(t, "a"+data, res.DataUTF8())
+
+	results, err := stream(payload.New([]byte("b"), accept)).BlockSlice(context.Background())
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	for i, expect := range []string{"b" + data, data} {
+		res, err := DecompressPayload(results[i], GzipCompressor)
+		assert.NoError(t, err)
+		assert.Equal(t, expect, res.DataUTF8())
+	}
+
+	// not opted in
+	res, err = rr(payload.NewString("c", "")).Block(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "c"+data, res.DataUTF8())
+	_, hasMetadata := res.Metadata()
+	assert.False(t, hasMetadata)
+
+	// unsupported encodings only
+	accept, err = PushAcceptEncoding(NewCompositeMetadataBuilder(), "br").Build()
+	require.NoError(t, err)
+	res, err = rr(payload.New([]byte("d"), accept)).Block(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "d"+data, res.DataUTF8())
+
+	// compressed request
+	req, err := CompressPayload(payload.NewString("e", ""), GzipCompressor)
+	require.NoError(t, err)
+	res, err = rr(req).Block(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "e"+data, res.DataUTF8())
+}
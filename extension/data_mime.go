@@ -0,0 +1,48 @@
+package extension
+
+import (
+	"fmt"
+	"math"
+)
+
+// EncodeDataMimeType encodes the data MIME type of a stream.
+// The result can be pushed into CompositeMetadata with MIME type MessageMimeType.
+// See: https://github.com/rsocket/rsocket/blob/master/Extensions/PerStreamDataMimeTypesDefinition.md
+func EncodeDataMimeType(mimeType string) (raw []byte, err error) {
+	if well, ok := ParseMIME(mimeType); ok {
+		raw = []byte{0x80 | byte(well)}
+		return
+	}
+	size := len(mimeType)
+	if size < 1 || size > math.MaxInt8 {
+		err = fmt.Errorf("illegal length of MIME type: %d", size)
+		return
+	}
+	raw = make([]byte, 0, size+1)
+	raw = append(raw, byte(size-1))
+	raw = append(raw, mimeType...)
+	return
+}
+
+// ParseDataMimeType parses the data MIME type of a stream.
+func ParseDataMimeType(raw []byte) (mimeType string, err error) {
+	if len(raw) < 1 {
+		err = fmt.Errorf("bad data MIME type: empty")
+		return
+	}
+	idOrLen := raw[0] & 0x7F
+	if raw[0]&0x80 == 0x80 {
+		mimeType = MIME(idOrLen).String()
+		if len(mimeType) < 1 {
+			err = fmt.Errorf("bad data MIME type: unknown id %d", idOrLen)
+		}
+		return
+	}
+	end := 1 + int(idOrLen) + 1
+	if end > len(raw) {
+		err = fmt.Errorf("bad data MIME type: illegal len %d", idOrLen+1)
+		return
+	}
+	mimeType = string(raw[1:end])
+	return
+}
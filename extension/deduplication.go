@@ -0,0 +1,97 @@
+package extension
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/rsocket/rsocket-go/logger"
+	"github.com/rsocket/rsocket-go/payload"
+)
+
+// IdempotencyKeyMimeType is the MIME type of the entry in the CompositeMetadata of a request which identifies it,
+// retries of the same request must carry the same key.
+const IdempotencyKeyMimeType = "message/x.rsocket.idempotency-key.v0"
+
+// PushIdempotencyKey pushes the idempotency key into a CompositeMetadataBuilder.
+func PushIdempotencyKey(builder *CompositeMetadataBuilder, key string) *CompositeMetadataBuilder {
+	return builder.PushString(IdempotencyKeyMimeType, key)
+}
+
+// Deduplication is a middleware which drops FireAndForget requests whose idempotency key has been seen in the TTL.
+//
+// FireAndForget has no acknowledgement, so a client which retries it on failures delivers every request at least once,
+// and the responder may receive duplicates. Deduplication turns it into at most once processing per key within the TTL:
+// the first request of a key is handled, and the others are dropped. A key is remembered before the handler is invoked,
+// so the request is not processed again even if the handler fails. Retries later than the TTL are processed again,
+// so it should be longer than the retry period of clients. Requests without the key are always handled.
+// It is safe for concurrent use.
+type Deduplication struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	seen    map[string]*list.Element
+	entries *list.List // in order of expiration
+}
+
+type dedupEntry struct {
+	key      string
+	expireAt time.Time
+}
+
+// NewDeduplication creates a Deduplication which remembers every idempotency key for the TTL, zero TTL remembers nothing.
+func NewDeduplication(ttl time.Duration) *Deduplication {
+	return &Deduplication{
+		ttl:     ttl,
+		seen:    make(map[string]*list.Element),
+		entries: list.New(),
+	}
+}
+
+// FireAndForget returns a FireAndForget handler which calls the handler only once for every idempotency key in the TTL.
+func (d *Deduplication) FireAndForget(handler func(request payload.Payload)) func(payload.Payload) {
+	return func(request payload.Payload) {
+		if key, ok := idempotencyKey(request); ok && !d.firstSeen(key) {
+			if logger.IsDebugEnabled() {
+				logger.Debugf("drop duplicated FIRE_AND_FORGET: key=%s\n", key)
+			}
+			return
+		}
+		handler(request)
+	}
+}
+
+// firstSeen remembers the key, it returns false if the key has been seen and not expired.
+func (d *Deduplication) firstSeen(key string) bool {
+	now := time.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for front := d.entries.Front(); front != nil; front = d.entries.Front() {
+		entry := front.Value.(*dedupEntry)
+		if now.Before(entry.expireAt) {
+			break
+		}
+		d.entries.Remove(front)
+		delete(d.seen, entry.key)
+	}
+	if _, ok := d.seen[key]; ok {
+		return false
+	}
+	d.seen[key] = d.entries.PushBack(&dedupEntry{
+		key:      key,
+		expireAt: now.Add(d.ttl),
+	})
+	return true
+}
+
+// idempotencyKey returns the first idempotency key in the CompositeMetadata of the request.
+func idempotencyKey(request payload.Payload) (key string, ok bool) {
+	metadata, ok := request.Metadata()
+	if !ok {
+		return
+	}
+	keys, err := findCompositeMetadata(metadata, IdempotencyKeyMimeType)
+	if err != nil || len(keys) < 1 {
+		return "", false
+	}
+	return keys[0], true
+}
@@ -0,0 +1,38 @@
+package extension
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeduplication(t *testing.T) {
+	withKey := func(key string) payload.Payload {
+		metadata, err := PushIdempotencyKey(NewCompositeMetadataBuilder(), key).Build()
+		require.NoError(t, err)
+		return payload.New([]byte(key), metadata)
+	}
+
+	var handled []string
+	handler := NewDeduplication(50 * time.Millisecond).
+		FireAndForget(func(request payload.Payload) {
+			handled = append(handled, request.DataUTF8())
+		})
+	handler(withKey("a"))
+	handler(withKey("a"))
+	handler(withKey("b"))
+	handler(withKey("a"))
+	// requests without the key are never deduplicated.
+	handler(payload.NewString("c", ""))
+	handler(payload.NewString("c", ""))
+	handler(payload.New([]byte("d"), []byte{0xFF}))
+	assert.Equal(t, []string{"a", "b", "c", "c", "d"}, handled)
+
+	// a retry after the TTL is processed again.
+	time.Sleep(100 * time.Millisecond)
+	handler(withKey("a"))
+	assert.Equal(t, []string{"a", "b", "c", "c", "d", "a"}, handled)
+}
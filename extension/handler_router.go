@@ -0,0 +1,166 @@
+package extension
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx/mono"
+)
+
+var errMissingRoute = errors.New("missing routing metadata")
+
+// Handler handles a request like a handler of net/http, the returned payload is the response.
+// The context is cancelled once the requester cancels the request.
+type Handler = func(ctx context.Context, request payload.Payload) (payload.Payload, error)
+
+// Middleware decorates a RequestResponse handler, eg: RequestAuthentication.RequestResponse and
+// ResponseCache.RequestResponse.
+type Middleware = func(handler func(request payload.Payload) mono.Mono) func(payload.Payload) mono.Mono
+
+// ErrorMapper returns the error code of the ERROR frame responded for an error returned by a Handler.
+type ErrorMapper = func(err error) core.ErrorCode
+
+// HandlerRouter dispatches RequestResponse requests to Handlers by the first routing tag of the request
+// CompositeMetadata, so services written in the style of net/http can be migrated route by route.
+// Errors implementing core.CustomError are responded with their own codes, other errors are mapped by the
+// ErrorMapper, default is ErrorCodeApplicationError. Requests of unknown routes are rejected with ErrorCodeInvalid.
+type HandlerRouter struct {
+	mu          sync.RWMutex
+	handlers    map[string]Handler
+	middlewares []Middleware
+	mapError    ErrorMapper
+}
+
+// NewHandlerRouter creates a new HandlerRouter.
+func NewHandlerRouter() *HandlerRouter {
+	return &HandlerRouter{
+		handlers: make(map[string]Handler),
+	}
+}
+
+// Handle registers the handler of a route, it replaces the handler registered with the same route.
+func (r *HandlerRouter) Handle(route string, handler Handler) *HandlerRouter {
+	r.mu.Lock()
+	r.handlers[route] = handler
+	r.mu.Unlock()
+	return r
+}
+
+// Use appends middlewares which decorate every route, the first one is the outermost.
+// Middlewares must be appended before RequestResponse is called.
+func (r *HandlerRouter) Use(middlewares ...Middleware) *HandlerRouter {
+	r.middlewares = append(r.middlewares, middlewares...)
+	return r
+}
+
+// MapError sets the ErrorMapper of errors returned by Handlers.
+func (r *HandlerRouter) MapError(mapper ErrorMapper) *HandlerRouter {
+	r.mapError = mapper
+	return r
+}
+
+// RequestResponse returns a RequestResponse handler which dispatches requests to the registered routes.
+func (r *HandlerRouter) RequestResponse() func(payload.Payload) mono.Mono {
+	dispatch := r.dispatch
+	for i := len(r.middlewares) - 1; i >= 0; i-- {
+		dispatch = r.middlewares[i](dispatch)
+	}
+	return dispatch
+}
+
+func (r *HandlerRouter) dispatch(request payload.Payload) mono.Mono {
+	route, err := findRoute(request)
+	if err != nil {
+		return mono.Error(handlerError{code: core.ErrorCodeInvalid, cause: err})
+	}
+	r.mu.RLock()
+	handler, ok := r.handlers[route]
+	r.mu.RUnlock()
+	if !ok {
+		return mono.Error(handlerError{code: core.ErrorCodeInvalid, cause: fmt.Errorf("no handler of route: %s", route)})
+	}
+	cancelled := make(chan struct{})
+	var once sync.Once
+	return mono.
+		Create(func(ctx context.Context, sink mono.Sink) {
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			go func() {
+				select {
+				case <-cancelled:
+					cancel()
+				case <-ctx.Done():
+				}
+			}()
+			response, err := handler(ctx, request)
+			if err != nil {
+				sink.Error(r.toCustomError(err))
+				return
+			}
+			sink.Success(response)
+		}).
+		DoOnCancel(func() {
+			once.Do(func() {
+				close(cancelled)
+			})
+		})
+}
+
+func (r *HandlerRouter) toCustomError(err error) error {
+	if _, ok := err.(core.CustomError); ok {
+		return err
+	}
+	code := core.ErrorCodeApplicationError
+	if r.mapError != nil {
+		code = r.mapError(err)
+	}
+	return handlerError{code: code, cause: err}
+}
+
+// findRoute returns the first routing tag in the CompositeMetadata of the request.
+func findRoute(request payload.Payload) (string, error) {
+	metadata, ok := request.Metadata()
+	if !ok {
+		return "", errMissingRoute
+	}
+	scanner := NewCompositeMetadataBytes(metadata).Scanner()
+	for scanner.Scan() {
+		mimeType, entry, err := scanner.Metadata()
+		if err != nil {
+			return "", err
+		}
+		if mimeType != MessageRouting.String() {
+			continue
+		}
+		tags, err := ParseRoutingTags(entry)
+		if err != nil {
+			return "", err
+		}
+		if len(tags) > 0 {
+			return tags[0], nil
+		}
+	}
+	return "", errMissingRoute
+}
+
+// handlerError is responded as an ERROR frame with the code.
+type handlerError struct {
+	code  core.ErrorCode
+	cause error
+}
+
+func (e handlerError) Error() string {
+	return e.cause.Error()
+}
+
+func (e handlerError) ErrorCode() core.ErrorCode {
+	return e.code
+}
+
+func (e handlerError) ErrorData() []byte {
+	return []byte(e.cause.Error())
+}
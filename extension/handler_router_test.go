@@ -0,0 +1,143 @@
+package extension_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/extension"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx/mono"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errNotFound = errors.New("not found")
+
+func newRoutedRequest(t *testing.T, route string, data string) payload.Payload {
+	routing, err := extension.EncodeRouting(route)
+	require.NoError(t, err)
+	metadata, err := extension.NewCompositeMetadataBuilder().PushWellKnown(extension.MessageRouting, routing).Build()
+	require.NoError(t, err)
+	return payload.New([]byte(data), metadata)
+}
+
+func requireErrorCode(t *testing.T, err error, code core.ErrorCode) {
+	require.Error(t, err)
+	e, ok := err.(core.CustomError)
+	require.True(t, ok, "error should have a code: %v", err)
+	assert.Equal(t, code, e.ErrorCode())
+}
+
+func TestHandlerRouter(t *testing.T) {
+	authenticated := extension.NewRequestAuthentication(func(request payload.Payload, auth *extension.Authentication) error {
+		return nil
+	})
+	router := extension.NewHandlerRouter().
+		Handle("echo", func(ctx context.Context, request payload.Payload) (payload.Payload, error) {
+			return payload.NewString("echo: "+request.DataUTF8(), ""), nil
+		}).
+		Handle("users", func(ctx context.Context, request payload.Payload) (payload.Payload, error) {
+			return nil, errNotFound
+		}).
+		Handle("custom", func(ctx context.Context, request payload.Payload) (payload.Payload, error) {
+			return nil, customError{}
+		}).
+		MapError(func(err error) core.ErrorCode {
+			if err == errNotFound {
+				return core.ErrorCodeRejected
+			}
+			return core.ErrorCodeApplicationError
+		})
+	handle := router.RequestResponse()
+	ctx := context.Background()
+
+	res, err := handle(newRoutedRequest(t, "echo", "foo")).Block(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "echo: foo", res.DataUTF8())
+
+	_, err = handle(newRoutedRequest(t, "users", "foo")).Block(ctx)
+	requireErrorCode(t, err, core.ErrorCodeRejected)
+	assert.Equal(t, "not found", string(err.(core.CustomError).ErrorData()))
+
+	_, err = handle(newRoutedRequest(t, "custom", "foo")).Block(ctx)
+	requireErrorCode(t, err, core.ErrorCodeCanceled)
+
+	_, err = handle(newRoutedRequest(t, "unknown", "foo")).Block(ctx)
+	requireErrorCode(t, err, core.ErrorCodeInvalid)
+
+	_, err = handle(payload.NewString("foo", "")).Block(ctx)
+	requireErrorCode(t, err, core.ErrorCodeInvalid)
+
+	// middlewares decorate every route, the request has no Authentication.
+	handle = router.Use(authenticated.RequestResponse).RequestResponse()
+	_, err = handle(newRoutedRequest(t, "echo", "foo")).Block(ctx)
+	requireErrorCode(t, err, core.ErrorCodeRejected)
+}
+
+func TestHandlerRouter_Middlewares(t *testing.T) {
+	var calls []string
+	trace := func(name string) extension.Middleware {
+		return func(handler func(request payload.Payload) mono.Mono) func(payload.Payload) mono.Mono {
+			return func(request payload.Payload) mono.Mono {
+				calls = append(calls, name)
+				return handler(request)
+			}
+		}
+	}
+	handle := extension.NewHandlerRouter().
+		Handle("echo", func(ctx context.Context, request payload.Payload) (payload.Payload, error) {
+			calls = append(calls, "handler")
+			return request, nil
+		}).
+		Use(trace("outer"), trace("inner")).
+		RequestResponse()
+	_, err := handle(newRoutedRequest(t, "echo", "foo")).Block(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"outer", "inner", "handler"}, calls)
+}
+
+func TestHandlerRouter_Cancel(t *testing.T) {
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	handle := extension.NewHandlerRouter().
+		Handle("slow", func(ctx context.Context, request payload.Payload) (payload.Payload, error) {
+			close(started)
+			<-ctx.Done()
+			close(cancelled)
+			return nil, ctx.Err()
+		}).
+		RequestResponse()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		_, err := handle(newRoutedRequest(t, "slow", "foo")).Block(ctx)
+		done <- err
+	}()
+	<-started
+	cancel()
+	select {
+	case <-cancelled:
+	case <-time.After(3 * time.Second):
+		assert.Fail(t, "context of the handler should be cancelled")
+	}
+	<-done
+}
+
+type customError struct{}
+
+func (customError) Error() string {
+	return "custom"
+}
+
+func (customError) ErrorCode() core.ErrorCode {
+	return core.ErrorCodeCanceled
+}
+
+func (customError) ErrorData() []byte {
+	return []byte("custom")
+}
@@ -0,0 +1,96 @@
+package extension
+
+import (
+	"fmt"
+
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx/flux"
+	"github.com/rsocket/rsocket-go/rx/mono"
+)
+
+// Decoder decodes payload data of a specific MIME type.
+type Decoder = func(data []byte) (interface{}, error)
+
+// MimeRouter selects the decoder of a request by the data MIME type of its stream.
+// The MIME type is read from the MessageMimeType entry of the request CompositeMetadata,
+// the default MIME type (usually the data MIME type of SETUP) will be used if it is absent.
+type MimeRouter struct {
+	defaultMimeType string
+	decoders        map[string]Decoder
+}
+
+// NewMimeRouter creates a new MimeRouter.
+func NewMimeRouter(defaultMimeType string) *MimeRouter {
+	return &MimeRouter{
+		defaultMimeType: defaultMimeType,
+		decoders:        make(map[string]Decoder),
+	}
+}
+
+// Register registers a decoder for a MIME type.
+func (r *MimeRouter) Register(mimeType string, decoder Decoder) *MimeRouter {
+	r.decoders[mimeType] = decoder
+	return r
+}
+
+// MimeType returns the data MIME type of a request.
+func (r *MimeRouter) MimeType(request payload.Payload) (mimeType string, err error) {
+	mimeType = r.defaultMimeType
+	metadata, ok := request.Metadata()
+	if !ok {
+		return
+	}
+	scanner := NewCompositeMetadataBytes(metadata).Scanner()
+	for scanner.Scan() {
+		var (
+			entryMimeType string
+			entry         []byte
+		)
+		entryMimeType, entry, err = scanner.Metadata()
+		if err != nil {
+			return
+		}
+		if entryMimeType == MessageMimeType.String() {
+			mimeType, err = ParseDataMimeType(entry)
+			return
+		}
+	}
+	return
+}
+
+// Decode decodes data of a request with the decoder selected by its data MIME type.
+func (r *MimeRouter) Decode(request payload.Payload) (mimeType string, v interface{}, err error) {
+	mimeType, err = r.MimeType(request)
+	if err != nil {
+		return
+	}
+	decoder, ok := r.decoders[mimeType]
+	if !ok {
+		err = fmt.Errorf("unsupported data MIME type: %s", mimeType)
+		return
+	}
+	v, err = decoder(request.Data())
+	return
+}
+
+// RequestResponse returns a RequestResponse handler which receives decoded requests.
+func (r *MimeRouter) RequestResponse(handler func(mimeType string, v interface{}, request payload.Payload) mono.Mono) func(payload.Payload) mono.Mono {
+	return func(request payload.Payload) mono.Mono {
+		mimeType, v, err := r.Decode(request)
+		if err != nil {
+			return mono.Error(err)
+		}
+		return handler(mimeType, v, request)
+	}
+}
+
+// RequestStream returns a RequestStream handler which receives decoded requests.
+func (r *MimeRouter) RequestStream(handler func(mimeType string, v interface{}, request payload.Payload) flux.Flux) func(payload.Payload) flux.Flux {
+	return func(request payload.Payload) flux.Flux {
+		mimeType, v, err := r.Decode(request)
+		if err != nil {
+			return flux.Error(err)
+		}
+		return handler(mimeType, v, request)
+	}
+}
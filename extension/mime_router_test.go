@@ -0,0 +1,69 @@
+package extension
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx/mono"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataMimeType(t *testing.T) {
+	for _, it := range []string{ApplicationJSON.String(), "application/x.custom"} {
+		raw, err := EncodeDataMimeType(it)
+		assert.NoError(t, err)
+		mimeType, err := ParseDataMimeType(raw)
+		assert.NoError(t, err)
+		assert.Equal(t, it, mimeType)
+	}
+	_, err := EncodeDataMimeType("")
+	assert.Error(t, err)
+	_, err = ParseDataMimeType(nil)
+	assert.Error(t, err)
+	_, err = ParseDataMimeType([]byte{0x10, 'a'})
+	assert.Error(t, err)
+}
+
+func TestMimeRouter(t *testing.T) {
+	router := NewMimeRouter(TextPlain.String()).
+		Register(TextPlain.String(), func(data []byte) (interface{}, error) {
+			return string(data), nil
+		}).
+		Register(ApplicationJSON.String(), func(data []byte) (interface{}, error) {
+			var v map[string]string
+			err := json.Unmarshal(data, &v)
+			return v["name"], err
+		})
+	handler := router.RequestResponse(func(mimeType string, v interface{}, request payload.Payload) mono.Mono {
+		return mono.Just(payload.NewString(strings.ToUpper(v.(string)), mimeType))
+	})
+
+	withMimeType := func(data string, mimeType string) payload.Payload {
+		entry, err := EncodeDataMimeType(mimeType)
+		assert.NoError(t, err)
+		metadata, err := NewCompositeMetadataBuilder().
+			PushWellKnownString(MessageRouting, "greet").
+			PushWellKnown(MessageMimeType, entry).
+			Build()
+		assert.NoError(t, err)
+		return payload.New([]byte(data), metadata)
+	}
+
+	res, err := handler(payload.NewString("foo", "")).Block(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "FOO", res.DataUTF8())
+	m, _ := res.MetadataUTF8()
+	assert.Equal(t, TextPlain.String(), m)
+
+	res, err = handler(withMimeType(`{"name":"bar"}`, ApplicationJSON.String())).Block(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "BAR", res.DataUTF8())
+	m, _ = res.MetadataUTF8()
+	assert.Equal(t, ApplicationJSON.String(), m)
+
+	_, err = handler(withMimeType("<name/>", ApplicationXML.String())).Block(context.Background())
+	assert.Error(t, err)
+}
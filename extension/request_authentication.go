@@ -0,0 +1,136 @@
+package extension
+
+import (
+	"errors"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/logger"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx/flux"
+	"github.com/rsocket/rsocket-go/rx/mono"
+)
+
+var errMissingAuthentication = errors.New("missing authentication")
+
+// AuthenticationProvider returns the Authentication of a request, eg: a rotating token or a token scoped to the route.
+type AuthenticationProvider = func(request payload.Payload) (*Authentication, error)
+
+// AuthenticationValidator validates the Authentication of a request, the request is rejected if it returns an error.
+type AuthenticationValidator = func(request payload.Payload, auth *Authentication) error
+
+// InjectAuthentication returns an interceptor of requesters which appends the Authentication returned by the provider
+// to the CompositeMetadata of every request, the metadata of requests must be a CompositeMetadata or absent.
+// It can be registered by rsocket.ClientBuilder.InterceptRequest, and validated by RequestAuthentication of responders.
+func InjectAuthentication(provider AuthenticationProvider) func(payload.Payload) (payload.Payload, error) {
+	return func(request payload.Payload) (payload.Payload, error) {
+		auth, err := provider(request)
+		if err != nil {
+			return nil, err
+		}
+		entry, err := NewCompositeMetadataBuilder().PushWellKnown(MessageAuthentication, auth.Bytes()).Build()
+		if err != nil {
+			return nil, err
+		}
+		metadata, _ := request.Metadata()
+		return payload.New(request.Data(), append(append([]byte{}, metadata...), entry...)), nil
+	}
+}
+
+// RequestAuthentication is a middleware of responders which validates the Authentication entry in the CompositeMetadata
+// of every request, on top of the authentication of SETUP, eg: rotating or scoped tokens.
+// Requests without a valid Authentication are rejected with ErrorCodeRejected before the handler is invoked,
+// FireAndForget requests are dropped since they have no response.
+type RequestAuthentication struct {
+	validate AuthenticationValidator
+}
+
+// NewRequestAuthentication creates a RequestAuthentication which validates the first Authentication of requests.
+func NewRequestAuthentication(validate AuthenticationValidator) *RequestAuthentication {
+	return &RequestAuthentication{
+		validate: validate,
+	}
+}
+
+// FireAndForget returns a FireAndForget handler which only calls the handler with authenticated requests.
+func (a *RequestAuthentication) FireAndForget(handler func(request payload.Payload)) func(payload.Payload) {
+	return func(request payload.Payload) {
+		if err := a.authenticate(request); err != nil {
+			if logger.IsDebugEnabled() {
+				logger.Debugf("drop unauthenticated FIRE_AND_FORGET: %v\n", err)
+			}
+			return
+		}
+		handler(request)
+	}
+}
+
+// RequestResponse returns a RequestResponse handler which only calls the handler with authenticated requests.
+func (a *RequestAuthentication) RequestResponse(handler func(request payload.Payload) mono.Mono) func(payload.Payload) mono.Mono {
+	return func(request payload.Payload) mono.Mono {
+		if err := a.authenticate(request); err != nil {
+			return mono.Error(err)
+		}
+		return handler(request)
+	}
+}
+
+// RequestStream returns a RequestStream handler which only calls the handler with authenticated requests.
+func (a *RequestAuthentication) RequestStream(handler func(request payload.Payload) flux.Flux) func(payload.Payload) flux.Flux {
+	return func(request payload.Payload) flux.Flux {
+		if err := a.authenticate(request); err != nil {
+			return flux.Error(err)
+		}
+		return handler(request)
+	}
+}
+
+// authenticate returns a rejected error if the request is not authenticated.
+func (a *RequestAuthentication) authenticate(request payload.Payload) error {
+	auth, err := findAuthentication(request)
+	if err == nil && auth == nil {
+		err = errMissingAuthentication
+	}
+	if err == nil {
+		err = a.validate(request, auth)
+	}
+	if err != nil {
+		return rejectedError{cause: err}
+	}
+	return nil
+}
+
+// findAuthentication returns the first Authentication in the CompositeMetadata of the request, or nil if it is absent.
+func findAuthentication(request payload.Payload) (*Authentication, error) {
+	metadata, ok := request.Metadata()
+	if !ok {
+		return nil, nil
+	}
+	scanner := NewCompositeMetadataBytes(metadata).Scanner()
+	for scanner.Scan() {
+		mimeType, entry, err := scanner.Metadata()
+		if err != nil {
+			return nil, err
+		}
+		if mimeType == MessageAuthentication.String() {
+			return ParseAuthentication(entry)
+		}
+	}
+	return nil, nil
+}
+
+// rejectedError is responded as an ERROR frame with ErrorCodeRejected.
+type rejectedError struct {
+	cause error
+}
+
+func (e rejectedError) Error() string {
+	return "rejected: " + e.cause.Error()
+}
+
+func (e rejectedError) ErrorCode() core.ErrorCode {
+	return core.ErrorCodeRejected
+}
+
+func (e rejectedError) ErrorData() []byte {
+	return []byte(e.cause.Error())
+}
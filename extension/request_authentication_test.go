@@ -0,0 +1,93 @@
+package extension_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/extension"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx/flux"
+	"github.com/rsocket/rsocket-go/rx/mono"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validateBearer(request payload.Payload, auth *extension.Authentication) error {
+	if auth.Type() != "bearer" || string(auth.Payload()) != "secret" {
+		return errors.New("bad token")
+	}
+	return nil
+}
+
+func TestInjectAuthentication(t *testing.T) {
+	inject := extension.InjectAuthentication(func(request payload.Payload) (*extension.Authentication, error) {
+		return extension.NewAuthentication("bearer", []byte("secret"))
+	})
+	metadata, err := extension.NewCompositeMetadataBuilder().PushString("application/x.custom", "foo").Build()
+	require.NoError(t, err)
+
+	injected, err := inject(payload.New([]byte("data"), metadata))
+	require.NoError(t, err)
+	assert.Equal(t, "data", injected.DataUTF8())
+	m, ok := injected.Metadata()
+	require.True(t, ok)
+	scanner := extension.NewCompositeMetadataBytes(m).Scanner()
+	var mimeTypes []string
+	for scanner.Scan() {
+		mimeType, _, err := scanner.Metadata()
+		require.NoError(t, err)
+		mimeTypes = append(mimeTypes, mimeType)
+	}
+	assert.Equal(t, []string{"application/x.custom", extension.MessageAuthentication.String()}, mimeTypes, "entries should be kept")
+
+	fakeErr := errors.New("no token")
+	_, err = extension.InjectAuthentication(func(request payload.Payload) (*extension.Authentication, error) {
+		return nil, fakeErr
+	})(payload.NewString("data", ""))
+	assert.Equal(t, fakeErr, err)
+}
+
+func TestRequestAuthentication(t *testing.T) {
+	auth := extension.NewRequestAuthentication(validateBearer)
+	requestResponse := auth.RequestResponse(func(request payload.Payload) mono.Mono {
+		return mono.Just(request)
+	})
+	requestStream := auth.RequestStream(func(request payload.Payload) flux.Flux {
+		return flux.Just(request)
+	})
+	var handled int
+	fireAndForget := auth.FireAndForget(func(request payload.Payload) {
+		handled++
+	})
+
+	withToken := func(token string) payload.Payload {
+		injected, err := extension.InjectAuthentication(func(request payload.Payload) (*extension.Authentication, error) {
+			return extension.NewAuthentication("bearer", []byte(token))
+		})(payload.NewString("data", ""))
+		require.NoError(t, err)
+		return injected
+	}
+
+	res, err := requestResponse(withToken("secret")).Block(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "data", res.DataUTF8())
+	results, err := requestStream(withToken("secret")).BlockSlice(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	fireAndForget(withToken("secret"))
+	assert.Equal(t, 1, handled)
+
+	for _, request := range []payload.Payload{withToken("wrong"), payload.NewString("data", "")} {
+		_, err = requestResponse(request).Block(context.Background())
+		assert.Error(t, err)
+		var e core.CustomError
+		require.True(t, errors.As(err, &e), "should be a CustomError")
+		assert.Equal(t, core.ErrorCodeRejected, e.ErrorCode())
+		_, err = requestStream(request).BlockSlice(context.Background())
+		assert.Error(t, err)
+		fireAndForget(request)
+		assert.Equal(t, 1, handled, "unauthenticated FireAndForget should be dropped")
+	}
+}
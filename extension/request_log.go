@@ -0,0 +1,156 @@
+package extension
+
+import (
+	"math"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/logger"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx"
+	"github.com/rsocket/rsocket-go/rx/flux"
+	"github.com/rsocket/rsocket-go/rx/mono"
+	"go.uber.org/atomic"
+)
+
+// RequestLog is the log of a sampled request.
+type RequestLog struct {
+	// Type is the frame type of the request, eg: core.FrameTypeRequestResponse.
+	Type core.FrameType
+	// Route is the first routing tag in the CompositeMetadata of the request, it is empty if absent.
+	Route string
+	// Latency is the duration from the request is received to it is terminated.
+	Latency time.Duration
+	// Outcome is SignalComplete, SignalError or SignalCancel.
+	Outcome rx.SignalType
+	// Err is the error of the request if the outcome is SignalError.
+	Err error
+}
+
+// RequestLogFunc writes the log of a sampled request.
+type RequestLogFunc = func(log RequestLog)
+
+// RequestLogger is a middleware of responders which logs the route, latency and outcome of a sample of requests.
+// Every 1/rate-th request is sampled, so the decision is a single atomic increment which allocates nothing,
+// and requests which are not sampled are passed to the handler as is.
+type RequestLogger struct {
+	period  uint64 // zero means never
+	counter *atomic.Uint64
+	log     RequestLogFunc
+}
+
+// NewRequestLogger creates a RequestLogger which samples requests by the rate, eg: 0.01 for 1% of requests.
+// A rate not less than 1 logs every request, and a non-positive rate logs nothing.
+// Logs are written by logger.Infof.
+func NewRequestLogger(rate float64) *RequestLogger {
+	var period uint64
+	if rate >= 1 {
+		period = 1
+	} else if rate > 0 {
+		period = uint64(math.Round(1 / rate))
+	}
+	return &RequestLogger{
+		period:  period,
+		counter: atomic.NewUint64(0),
+		log:     logRequest,
+	}
+}
+
+// LogFunc sets the function which writes logs of sampled requests, eg: into a structured logger.
+func (l *RequestLogger) LogFunc(fn RequestLogFunc) *RequestLogger {
+	if fn != nil {
+		l.log = fn
+	}
+	return l
+}
+
+// FireAndForget returns a FireAndForget handler which logs sampled requests once the handler returns.
+func (l *RequestLogger) FireAndForget(handler func(request payload.Payload)) func(payload.Payload) {
+	return func(request payload.Payload) {
+		if !l.sample() {
+			handler(request)
+			return
+		}
+		route, _ := findRoute(request)
+		start := time.Now()
+		handler(request)
+		l.log(RequestLog{
+			Type:    core.FrameTypeRequestFNF,
+			Route:   route,
+			Latency: time.Since(start),
+			Outcome: rx.SignalComplete,
+		})
+	}
+}
+
+// RequestResponse returns a RequestResponse handler which logs sampled requests once the response is terminated.
+func (l *RequestLogger) RequestResponse(handler func(request payload.Payload) mono.Mono) func(payload.Payload) mono.Mono {
+	return func(request payload.Payload) mono.Mono {
+		if !l.sample() {
+			return handler(request)
+		}
+		// the request may be released after the handler, so the route must be found first.
+		route, _ := findRoute(request)
+		start := time.Now()
+		sending := handler(request)
+		if sending == nil {
+			return nil
+		}
+		var err error
+		return sending.
+			DoOnError(func(e error) {
+				err = e
+			}).
+			DoFinally(func(s rx.SignalType) {
+				l.finish(core.FrameTypeRequestResponse, route, start, s, err)
+			})
+	}
+}
+
+// RequestStream returns a RequestStream handler which logs sampled requests once the stream is terminated.
+func (l *RequestLogger) RequestStream(handler func(request payload.Payload) flux.Flux) func(payload.Payload) flux.Flux {
+	return func(request payload.Payload) flux.Flux {
+		if !l.sample() {
+			return handler(request)
+		}
+		route, _ := findRoute(request)
+		start := time.Now()
+		sending := handler(request)
+		if sending == nil {
+			return nil
+		}
+		var err error
+		return sending.
+			DoOnError(func(e error) {
+				err = e
+			}).
+			DoFinally(func(s rx.SignalType) {
+				l.finish(core.FrameTypeRequestStream, route, start, s, err)
+			})
+	}
+}
+
+func (l *RequestLogger) sample() bool {
+	if l.period == 0 {
+		return false
+	}
+	return l.period == 1 || l.counter.Inc()%l.period == 0
+}
+
+func (l *RequestLogger) finish(requestType core.FrameType, route string, start time.Time, s rx.SignalType, err error) {
+	l.log(RequestLog{
+		Type:    requestType,
+		Route:   route,
+		Latency: time.Since(start),
+		Outcome: s,
+		Err:     err,
+	})
+}
+
+func logRequest(log RequestLog) {
+	if log.Err != nil {
+		logger.Infof("%s %q %s in %s: %v\n", log.Type, log.Route, log.Outcome, log.Latency, log.Err)
+		return
+	}
+	logger.Infof("%s %q %s in %s\n", log.Type, log.Route, log.Outcome, log.Latency)
+}
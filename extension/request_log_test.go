@@ -0,0 +1,107 @@
+package extension_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/extension"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx"
+	"github.com/rsocket/rsocket-go/rx/flux"
+	"github.com/rsocket/rsocket-go/rx/mono"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type requestLogRecorder struct {
+	mu   sync.Mutex
+	logs []extension.RequestLog
+}
+
+func (r *requestLogRecorder) log(log extension.RequestLog) {
+	r.mu.Lock()
+	r.logs = append(r.logs, log)
+	r.mu.Unlock()
+}
+
+func (r *requestLogRecorder) Logs() []extension.RequestLog {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]extension.RequestLog(nil), r.logs...)
+}
+
+func TestRequestLogger(t *testing.T) {
+	recorder := &requestLogRecorder{}
+	l := extension.NewRequestLogger(1).LogFunc(recorder.log)
+	ctx := context.Background()
+
+	rr := l.RequestResponse(func(request payload.Payload) mono.Mono {
+		if request.DataUTF8() == "fail" {
+			return mono.Error(errNotFound)
+		}
+		return mono.Just(request)
+	})
+	_, err := rr(newRoutedRequest(t, "users", "foo")).Block(ctx)
+	require.NoError(t, err)
+	_, err = rr(newRoutedRequest(t, "users", "fail")).Block(ctx)
+	require.Error(t, err)
+
+	rs := l.RequestStream(func(request payload.Payload) flux.Flux {
+		return flux.Just(request, request)
+	})
+	_, err = rs(payload.NewString("foo", "")).BlockLast(ctx)
+	require.NoError(t, err)
+
+	l.FireAndForget(func(request payload.Payload) {})(newRoutedRequest(t, "events", "foo"))
+
+	logs := recorder.Logs()
+	require.Len(t, logs, 4)
+	assert.Equal(t, core.FrameTypeRequestResponse, logs[0].Type)
+	assert.Equal(t, "users", logs[0].Route)
+	assert.Equal(t, rx.SignalComplete, logs[0].Outcome)
+	assert.NoError(t, logs[0].Err)
+	assert.Equal(t, rx.SignalError, logs[1].Outcome)
+	assert.True(t, errors.Is(logs[1].Err, errNotFound))
+	assert.Equal(t, core.FrameTypeRequestStream, logs[2].Type)
+	assert.Empty(t, logs[2].Route, "the request has no routing metadata")
+	assert.Equal(t, rx.SignalComplete, logs[2].Outcome)
+	assert.Equal(t, core.FrameTypeRequestFNF, logs[3].Type)
+	assert.Equal(t, "events", logs[3].Route)
+}
+
+func TestRequestLogger_Sampling(t *testing.T) {
+	for _, it := range []struct {
+		rate   float64
+		expect int
+	}{
+		{0.01, 10},
+		{0.1, 100},
+		{0.5, 500},
+		{2, 1000},
+		{0, 0},
+		{-1, 0},
+	} {
+		recorder := &requestLogRecorder{}
+		handle := extension.NewRequestLogger(it.rate).LogFunc(recorder.log).FireAndForget(func(request payload.Payload) {})
+		request := payload.NewString("foo", "")
+		for i := 0; i < 1000; i++ {
+			handle(request)
+		}
+		assert.Len(t, recorder.Logs(), it.expect, "bad sampled count of rate %v", it.rate)
+	}
+}
+
+func TestRequestLogger_NoAllocation(t *testing.T) {
+	sending := mono.Just(payload.NewString("foo", ""))
+	handle := extension.NewRequestLogger(0.000001).RequestResponse(func(request payload.Payload) mono.Mono {
+		return sending
+	})
+	request := payload.NewString("foo", "")
+	allocs := testing.AllocsPerRun(1000, func() {
+		handle(request)
+	})
+	assert.Zero(t, allocs, "requests which are not sampled should not allocate")
+}
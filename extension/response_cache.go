@@ -0,0 +1,183 @@
+package extension
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx/mono"
+)
+
+// CacheKeyFunc returns the cache key of a request, the request is not cacheable if ok is false.
+// Payloads with equal data and metadata (see payload.Equal) share the same cached response.
+type CacheKeyFunc = func(request payload.Payload) (key payload.Payload, ok bool)
+
+// CacheStore stores cached responses, it must be safe for concurrent use.
+type CacheStore interface {
+	// Get returns the cached response of the key.
+	Get(key payload.Payload) (response payload.Payload, ok bool)
+	// Put stores the response of the key, both of them can be retained safely.
+	Put(key payload.Payload, response payload.Payload)
+}
+
+// ResponseCache is a middleware which memoizes results of a RequestResponse handler by request.
+// A cache hit skips the handler, and concurrent misses of the same key may all call the handler.
+// Only successful responses are cached. It is safe for concurrent use if the CacheStore is.
+//
+// By default the whole request is the key, so requests with per-request metadata (eg: an authentication
+// token or a tracing id) never hit. Use KeyFunc to exclude them, eg: CacheKeyWithoutMetadata.
+// Be careful that a response cached for one caller will be returned to others with the same key.
+type ResponseCache struct {
+	key   CacheKeyFunc
+	store CacheStore
+}
+
+// NewResponseCache creates a ResponseCache with the store.
+func NewResponseCache(store CacheStore) *ResponseCache {
+	return &ResponseCache{
+		key: func(request payload.Payload) (payload.Payload, bool) {
+			return request, true
+		},
+		store: store,
+	}
+}
+
+// KeyFunc sets the function which generates the key of a request.
+func (c *ResponseCache) KeyFunc(fn CacheKeyFunc) *ResponseCache {
+	if fn != nil {
+		c.key = fn
+	}
+	return c
+}
+
+// RequestResponse returns a RequestResponse handler which serves cached responses before calling the handler.
+// Responses returned by cache hits are shared, they must not be modified.
+func (c *ResponseCache) RequestResponse(handler func(request payload.Payload) mono.Mono) func(payload.Payload) mono.Mono {
+	return func(request payload.Payload) mono.Mono {
+		key, ok := c.key(request)
+		if !ok {
+			return handler(request)
+		}
+		if cached, hit := c.store.Get(key); hit {
+			return mono.Just(cached)
+		}
+		// the request may be released after the handler, so the key must be copied.
+		key = payload.Clone(key)
+		sending := handler(request)
+		if sending == nil {
+			return nil
+		}
+		return sending.DoOnSuccess(func(response payload.Payload) error {
+			c.store.Put(key, payload.Clone(response))
+			return nil
+		})
+	}
+}
+
+// CacheKeyWithoutMetadata returns a CacheKeyFunc which drops entries of the MIME types from the CompositeMetadata
+// of requests, eg: MessageAuthentication.String(). All metadata is dropped if no MIME type is specified.
+// Requests with invalid CompositeMetadata are not cacheable.
+func CacheKeyWithoutMetadata(mimeTypes ...string) CacheKeyFunc {
+	return func(request payload.Payload) (key payload.Payload, ok bool) {
+		metadata, hasMetadata := request.Metadata()
+		if !hasMetadata || len(mimeTypes) < 1 {
+			return payload.New(request.Data(), nil), true
+		}
+		kept, err := dropCompositeMetadata(metadata, mimeTypes...)
+		if err != nil {
+			return
+		}
+		return payload.New(request.Data(), kept), true
+	}
+}
+
+// lruCacheStore is a CacheStore which evicts the least recently used entry when it is full.
+type lruCacheStore struct {
+	mu      sync.Mutex
+	maxSize int
+	ttl     time.Duration
+	entries *list.List
+	buckets map[uint64][]*list.Element // key=payload.Hash(key)
+}
+
+type lruCacheEntry struct {
+	hash     uint64
+	key      payload.Payload
+	response payload.Payload
+	expireAt time.Time
+}
+
+// NewLRUCacheStore creates a CacheStore which keeps at most maxSize responses, each one expires after the ttl.
+// Zero maxSize means unlimited, and zero ttl means never expire.
+func NewLRUCacheStore(maxSize int, ttl time.Duration) CacheStore {
+	return &lruCacheStore{
+		maxSize: maxSize,
+		ttl:     ttl,
+		entries: list.New(),
+		buckets: make(map[uint64][]*list.Element),
+	}
+}
+
+func (s *lruCacheStore) Get(key payload.Payload) (response payload.Payload, ok bool) {
+	hash := payload.Hash(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem := s.find(hash, key)
+	if elem == nil {
+		return
+	}
+	entry := elem.Value.(*lruCacheEntry)
+	if s.ttl > 0 && time.Now().After(entry.expireAt) {
+		s.remove(elem)
+		return
+	}
+	s.entries.MoveToFront(elem)
+	return entry.response, true
+}
+
+func (s *lruCacheStore) Put(key payload.Payload, response payload.Payload) {
+	hash := payload.Hash(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if elem := s.find(hash, key); elem != nil {
+		s.remove(elem)
+	}
+	entry := &lruCacheEntry{
+		hash:     hash,
+		key:      key,
+		response: response,
+	}
+	if s.ttl > 0 {
+		entry.expireAt = time.Now().Add(s.ttl)
+	}
+	s.buckets[hash] = append(s.buckets[hash], s.entries.PushFront(entry))
+	for s.maxSize > 0 && s.entries.Len() > s.maxSize {
+		s.remove(s.entries.Back())
+	}
+}
+
+func (s *lruCacheStore) find(hash uint64, key payload.Payload) *list.Element {
+	for _, elem := range s.buckets[hash] {
+		if payload.Equal(elem.Value.(*lruCacheEntry).key, key) {
+			return elem
+		}
+	}
+	return nil
+}
+
+func (s *lruCacheStore) remove(elem *list.Element) {
+	entry := s.entries.Remove(elem).(*lruCacheEntry)
+	bucket := s.buckets[entry.hash]
+	for i := range bucket {
+		if bucket[i] == elem {
+			bucket = append(bucket[:i], bucket[i+1:]...)
+			break
+		}
+	}
+	if len(bucket) < 1 {
+		delete(s.buckets, entry.hash)
+	} else {
+		s.buckets[entry.hash] = bucket
+	}
+}
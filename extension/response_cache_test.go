@@ -0,0 +1,118 @@
+package extension
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx/mono"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseCache(t *testing.T) {
+	calls := 0
+	handler := NewResponseCache(NewLRUCacheStore(2, 0)).
+		RequestResponse(func(request payload.Payload) mono.Mono {
+			calls++
+			return mono.Just(payload.NewString(fmt.Sprintf("%s-%d", request.DataUTF8(), calls), ""))
+		})
+	call := func(data string) string {
+		res, err := handler(payload.NewString(data, "")).Block(context.Background())
+		require.NoError(t, err)
+		return res.DataUTF8()
+	}
+
+	assert.Equal(t, "a-1", call("a"))
+	assert.Equal(t, "a-1", call("a"), "cache hit should skip the handler")
+	assert.Equal(t, "b-2", call("b"))
+	assert.Equal(t, "a-1", call("a"))
+	// c evicts b which is the least recently used one.
+	assert.Equal(t, "c-3", call("c"))
+	assert.Equal(t, "a-1", call("a"))
+	assert.Equal(t, "b-4", call("b"))
+	assert.Equal(t, 4, calls)
+
+	// errors are not cached.
+	fakeErr := errors.New("fake error")
+	failed := 0
+	handler = NewResponseCache(NewLRUCacheStore(0, 0)).
+		RequestResponse(func(request payload.Payload) mono.Mono {
+			failed++
+			return mono.Error(fakeErr)
+		})
+	for i := 0; i < 2; i++ {
+		_, err := handler(payload.NewString("a", "")).Block(context.Background())
+		assert.Equal(t, fakeErr, err)
+	}
+	assert.Equal(t, 2, failed)
+}
+
+func TestLRUCacheStore_TTL(t *testing.T) {
+	store := NewLRUCacheStore(0, 50*time.Millisecond)
+	store.Put(payload.NewString("a", ""), payload.NewString("foo", ""))
+	res, ok := store.Get(payload.NewString("a", ""))
+	assert.True(t, ok)
+	assert.Equal(t, "foo", res.DataUTF8())
+	_, ok = store.Get(payload.NewString("a", "x"))
+	assert.False(t, ok, "metadata is a part of key")
+	time.Sleep(100 * time.Millisecond)
+	_, ok = store.Get(payload.NewString("a", ""))
+	assert.False(t, ok, "entry should expire")
+}
+
+func TestCacheKeyWithoutMetadata(t *testing.T) {
+	withToken := func(token string) payload.Payload {
+		metadata, err := NewCompositeMetadataBuilder().
+			PushWellKnownString(MessageAuthentication, token).
+			PushString("application/x.custom", "foo").
+			Build()
+		require.NoError(t, err)
+		return payload.New([]byte("data"), metadata)
+	}
+
+	calls := 0
+	handler := NewResponseCache(NewLRUCacheStore(0, 0)).
+		KeyFunc(CacheKeyWithoutMetadata(MessageAuthentication.String())).
+		RequestResponse(func(request payload.Payload) mono.Mono {
+			calls++
+			return mono.Just(payload.NewString("ok", ""))
+		})
+	for _, token := range []string{"alice", "bob"} {
+		_, err := handler(withToken(token)).Block(context.Background())
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 1, calls, "authentication should be excluded from the key")
+
+	keyA, ok := CacheKeyWithoutMetadata(MessageAuthentication.String())(withToken("alice"))
+	assert.True(t, ok)
+	keyB, _ := CacheKeyWithoutMetadata(MessageAuthentication.String())(withToken("bob"))
+	assert.True(t, payload.Equal(keyA, keyB))
+	m, _ := keyA.Metadata()
+	scanner := NewCompositeMetadataBytes(m).Scanner()
+	assert.True(t, scanner.Scan())
+	mimeType, entry, err := scanner.MetadataUTF8()
+	assert.NoError(t, err)
+	assert.Equal(t, "application/x.custom", mimeType)
+	assert.Equal(t, "foo", entry)
+	assert.False(t, scanner.Scan())
+
+	key, ok := CacheKeyWithoutMetadata()(withToken("alice"))
+	assert.True(t, ok)
+	_, hasMetadata := key.Metadata()
+	assert.False(t, hasMetadata)
+
+	_, ok = CacheKeyWithoutMetadata(MessageAuthentication.String())(payload.New([]byte("data"), []byte{0xFF}))
+	assert.False(t, ok, "invalid metadata should not be cacheable")
+}
+
+func TestResponseCache_NilHandler(t *testing.T) {
+	handler := NewResponseCache(NewLRUCacheStore(0, 0)).
+		RequestResponse(func(request payload.Payload) mono.Mono {
+			return nil
+		})
+	assert.Nil(t, handler(payload.NewString("a", "")), "nil result should be passed through")
+}
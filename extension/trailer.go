@@ -0,0 +1,101 @@
+package extension
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// TrailerMimeType is the MIME type of the trailer entry in CompositeMetadata.
+//
+// RequestResponse carries a single payload, so a responder which wants to return completion metadata
+// (like trailers of gRPC, eg: status or timing) pushes a trailer entry into the CompositeMetadata of the response.
+// Both peers must agree on this convention: the response metadata must be a CompositeMetadata,
+// and the entry is encoded by EncodeTrailer, other entries of the CompositeMetadata are not affected.
+//
+// The entry is a sequence of key/value pairs, each pair is encoded as:
+//
+//	key length (1 byte, 1..255) | key | value length (uint16, big endian) | value
+const TrailerMimeType = "message/x.rsocket.trailer.v0"
+
+// EncodeTrailer encodes trailer pairs to raw bytes of a trailer entry, keys are sorted.
+func EncodeTrailer(trailer map[string]string) (raw []byte, err error) {
+	keys := make([]string, 0, len(trailer))
+	for k := range trailer {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := trailer[k]
+		if len(k) < 1 || len(k) > math.MaxUint8 {
+			err = fmt.Errorf("illegal length of trailer key: %d", len(k))
+			return
+		}
+		if len(v) > math.MaxUint16 {
+			err = fmt.Errorf("illegal length of trailer value: %d", len(v))
+			return
+		}
+		raw = append(raw, byte(len(k)))
+		raw = append(raw, k...)
+		raw = append(raw, byte(len(v)>>8), byte(len(v)))
+		raw = append(raw, v...)
+	}
+	return
+}
+
+// ParseTrailer parses raw bytes of a trailer entry.
+func ParseTrailer(raw []byte) (trailer map[string]string, err error) {
+	trailer = make(map[string]string)
+	cursor := 0
+	for cursor < len(raw) {
+		keyLen := int(raw[cursor])
+		cursor++
+		if cursor+keyLen+2 > len(raw) {
+			err = fmt.Errorf("bad trailer: illegal key length %d", keyLen)
+			return
+		}
+		k := string(raw[cursor : cursor+keyLen])
+		cursor += keyLen
+		valueLen := int(binary.BigEndian.Uint16(raw[cursor:]))
+		cursor += 2
+		if cursor+valueLen > len(raw) {
+			err = fmt.Errorf("bad trailer: illegal value length %d", valueLen)
+			return
+		}
+		trailer[k] = string(raw[cursor : cursor+valueLen])
+		cursor += valueLen
+	}
+	return
+}
+
+// PushTrailer encodes trailer pairs and pushes them into a CompositeMetadataBuilder as the trailer entry.
+func PushTrailer(builder *CompositeMetadataBuilder, trailer map[string]string) (*CompositeMetadataBuilder, error) {
+	raw, err := EncodeTrailer(trailer)
+	if err != nil {
+		return builder, err
+	}
+	return builder.Push(TrailerMimeType, raw), nil
+}
+
+// ReadTrailer reads the trailer entry from CompositeMetadata of a response.
+// It returns false if the trailer entry is absent.
+func ReadTrailer(metadata []byte) (trailer map[string]string, ok bool, err error) {
+	scanner := NewCompositeMetadataBytes(metadata).Scanner()
+	for scanner.Scan() {
+		var (
+			mimeType string
+			entry    []byte
+		)
+		mimeType, entry, err = scanner.Metadata()
+		if err != nil {
+			return
+		}
+		if mimeType == TrailerMimeType {
+			ok = true
+			trailer, err = ParseTrailer(entry)
+			return
+		}
+	}
+	return
+}
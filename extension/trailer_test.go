@@ -0,0 +1,70 @@
+package extension
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrailer(t *testing.T) {
+	trailer := map[string]string{
+		"status":  "OK",
+		"elapsed": "12ms",
+		"empty":   "",
+	}
+	raw, err := EncodeTrailer(trailer)
+	assert.NoError(t, err)
+	decoded, err := ParseTrailer(raw)
+	assert.NoError(t, err)
+	assert.Equal(t, trailer, decoded)
+
+	_, err = EncodeTrailer(map[string]string{"": "foo"})
+	assert.Error(t, err)
+	_, err = EncodeTrailer(map[string]string{strings.Repeat("k", 256): "foo"})
+	assert.Error(t, err)
+	_, err = EncodeTrailer(map[string]string{"foo": strings.Repeat("v", 1<<16)})
+	assert.Error(t, err)
+
+	_, err = ParseTrailer(raw[:len(raw)-1])
+	assert.Error(t, err)
+	_, err = ParseTrailer([]byte{3, 'f', 'o'})
+	assert.Error(t, err)
+}
+
+func TestReadTrailer(t *testing.T) {
+	builder, err := PushTrailer(NewCompositeMetadataBuilder().PushWellKnownString(MessageRouting, "foo"), map[string]string{
+		"status": "OK",
+	})
+	assert.NoError(t, err)
+	metadata, err := builder.Build()
+	assert.NoError(t, err)
+
+	trailer, ok, err := ReadTrailer(metadata)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "OK", trailer["status"])
+
+	metadata, err = NewCompositeMetadataBuilder().PushWellKnownString(MessageRouting, "foo").Build()
+	assert.NoError(t, err)
+	_, ok, err = ReadTrailer(metadata)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func ExamplePushTrailer() {
+	// responder: return data with trailer in the CompositeMetadata of the response.
+	builder, _ := PushTrailer(NewCompositeMetadataBuilder(), map[string]string{
+		"status": "OK",
+	})
+	metadata, _ := builder.Build()
+	response := payload.New([]byte("hello"), metadata)
+
+	// requester: read the trailer of the response.
+	m, _ := response.Metadata()
+	trailer, ok, _ := ReadTrailer(m)
+	fmt.Println(response.DataUTF8(), ok, trailer["status"])
+	// Output: hello true OK
+}
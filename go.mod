@@ -7,8 +7,10 @@ require (
 	github.com/google/uuid v1.1.2
 	github.com/gorilla/websocket v1.4.2
 	github.com/jjeffcaii/reactor-go v0.3.3
+	github.com/panjf2000/ants/v2 v2.4.2
 	github.com/pkg/errors v0.9.1
 	github.com/stretchr/testify v1.6.1
 	github.com/urfave/cli/v2 v2.1.1
 	go.uber.org/atomic v1.7.0
+	google.golang.org/protobuf v1.25.0
 )
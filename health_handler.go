@@ -0,0 +1,81 @@
+package rsocket
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jjeffcaii/reactor-go"
+)
+
+// HealthStatus is the JSON body reported by the handler returned by HealthHandler.
+type HealthStatus struct {
+	// Connected is false once the connection has been closed.
+	Connected bool `json:"connected"`
+	// State is the health state judged by the last keepalive round-trip, it is empty before the first one.
+	State string `json:"state,omitempty"`
+	// RTT is the round-trip time of the last keepalive frame.
+	RTT time.Duration `json:"rttNanos"`
+	// ActiveStreams is the amount of streams in progress.
+	ActiveStreams int `json:"activeStreams"`
+	// OutboundQueueDepth is the amount of outbound frames queued but not written yet.
+	OutboundQueueDepth int `json:"outboundQueueDepth"`
+}
+
+type healthHandler struct {
+	socket CloseableRSocket
+	mu     sync.Mutex
+	last   *HealthEvent
+	closed bool
+}
+
+// HealthHandler returns a http.Handler which reports the health of the socket as a JSON HealthStatus,
+// it can be mounted as the readiness or liveness endpoint of Kubernetes probes.
+// It responds 200 while the connection is alive, even if it is degraded, and 503 once it has been closed.
+// The RTT is measured by keepalive round-trips, so it is only reported for clients.
+func HealthHandler(socket CloseableRSocket) http.Handler {
+	h := &healthHandler{
+		socket: socket,
+	}
+	socket.HealthEvents().Subscribe(context.Background(),
+		reactor.OnNext(func(v reactor.Any) error {
+			event := v.(HealthEvent)
+			h.mu.Lock()
+			h.last = &event
+			h.mu.Unlock()
+			return nil
+		}),
+		reactor.OnComplete(func() {
+			h.mu.Lock()
+			h.closed = true
+			h.mu.Unlock()
+		}),
+	)
+	return h
+}
+
+func (h *healthHandler) status() (status HealthStatus) {
+	h.mu.Lock()
+	status.Connected = !h.closed
+	if h.last != nil {
+		status.State = h.last.State.String()
+		status.RTT = h.last.RTT
+	}
+	h.mu.Unlock()
+	if status.Connected {
+		status.ActiveStreams = h.socket.ActiveStreams()
+		status.OutboundQueueDepth = h.socket.OutboundQueueDepth()
+	}
+	return
+}
+
+func (h *healthHandler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	status := h.status()
+	w.Header().Set("Content-Type", "application/json")
+	if !status.Connected {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(status)
+}
@@ -0,0 +1,90 @@
+package rsocket_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/rsocket/rsocket-go"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx"
+	"github.com/rsocket/rsocket-go/rx/flux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func probeHealth(t *testing.T, handler http.Handler) (code int, status HealthStatus) {
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	return w.Code, status
+}
+
+func TestHealthHandler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	go func() {
+		_ = Receive().
+			OnStart(func() {
+				close(started)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+				return NewAbstractSocket(
+					RequestStream(func(request payload.Payload) flux.Flux {
+						// completes once the test finishes probing the active stream.
+						return flux.Create(func(ctx context.Context, sink flux.Sink) {
+							go func() {
+								<-finish
+								sink.Complete()
+							}()
+						})
+					}),
+				), nil
+			}).
+			Transport(TCPServer().SetAddr(":8110").Build()).
+			Serve(ctx)
+	}()
+	<-started
+
+	cli, err := Connect().
+		KeepAlive(50*time.Millisecond, 10*time.Second, 1).
+		Transport(TCPClient().SetAddr("127.0.0.1:8110").Build()).
+		Start(ctx)
+	require.NoError(t, err)
+
+	handler := HealthHandler(cli)
+	cli.RequestStream(payload.NewString("foo", "")).
+		Subscribe(ctx, rx.OnSubscribe(func(ctx context.Context, s rx.Subscription) {
+			s.Request(1)
+		}))
+
+	assert.Eventually(t, func() bool {
+		_, status := probeHealth(t, handler)
+		return status.State == HealthConnected.String() && status.ActiveStreams == 1
+	}, 3*time.Second, 20*time.Millisecond)
+	code, status := probeHealth(t, handler)
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, status.Connected)
+	assert.True(t, status.RTT > 0)
+
+	close(finish)
+	assert.Eventually(t, func() bool {
+		_, status := probeHealth(t, handler)
+		return status.ActiveStreams == 0
+	}, 3*time.Second, 20*time.Millisecond)
+
+	_ = cli.Close()
+	assert.Eventually(t, func() bool {
+		code, status = probeHealth(t, handler)
+		return code == http.StatusServiceUnavailable
+	}, 3*time.Second, 20*time.Millisecond)
+	assert.False(t, status.Connected)
+	assert.Zero(t, status.ActiveStreams)
+}
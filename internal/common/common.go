@@ -9,6 +9,8 @@ const (
 	DefaultKeepaliveInterval = 20 * time.Second
 	// DefaultKeepaliveMaxLifetime is default keepalive max lifetime.
 	DefaultKeepaliveMaxLifetime = 90 * time.Second
+	// DefaultSetupTimeout is default max time to wait for the server to confirm the SETUP frame.
+	DefaultSetupTimeout = 10 * time.Second
 )
 
 type Releasable interface {
@@ -26,8 +28,9 @@ func TryRelease(input interface{}) {
 	}
 }
 
+// CloneBytes returns a copy of b, an empty b is cloned as an empty slice rather than nil.
 func CloneBytes(b []byte) []byte {
-	if b == nil || len(b) < 1 {
+	if b == nil {
 		return nil
 	}
 	clone := make([]byte, len(b))
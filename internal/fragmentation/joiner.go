@@ -9,6 +9,7 @@ import (
 
 type implJoiner struct {
 	root *list.List // list of HeaderAndPayload
+	size int        // total bytes of data and metadata pushed
 }
 
 func (p *implJoiner) IncRef() (refs int32) {
@@ -60,6 +61,10 @@ func (p *implJoiner) Metadata() (metadata []byte, ok bool) {
 			ok = true
 		}
 	}
+	if ok && metadata == nil {
+		// present but empty.
+		metadata = []byte{}
+	}
 	return
 }
 
@@ -89,8 +94,13 @@ func (p *implJoiner) DataUTF8() (data string) {
 	return
 }
 
+func (p *implJoiner) Size() int {
+	return p.size
+}
+
 func (p *implJoiner) Push(elem HeaderAndPayload) (end bool) {
 	p.root.PushBack(elem)
+	p.size += PayloadSize(elem)
 	h := elem.Header()
 	end = !h.Flag().Check(core.FlagFollow)
 	return
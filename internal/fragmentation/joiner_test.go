@@ -7,6 +7,7 @@ import (
 
 	"github.com/rsocket/rsocket-go/core"
 	"github.com/rsocket/rsocket-go/core/framing"
+	"github.com/rsocket/rsocket-go/payload"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -39,3 +40,11 @@ func TestFragmentPayload(t *testing.T) {
 	assert.Equal(t, metadataSb.String(), m, "metadata doesn't match")
 	assert.Equal(t, dataSb.String(), fr.DataUTF8(), "data doesn't match")
 }
+
+func TestJoiner_Size(t *testing.T) {
+	joiner := NewJoiner(framing.NewPayloadFrame(1, []byte("foo"), []byte("bar"), core.FlagFollow|core.FlagMetadata))
+	assert.Equal(t, 6, joiner.Size())
+	joiner.Push(framing.NewPayloadFrame(1, []byte("qux"), nil, core.FlagComplete))
+	assert.Equal(t, 9, joiner.Size())
+	assert.Equal(t, 3, PayloadSize(payload.New([]byte("foo"), nil)))
+}
@@ -20,6 +20,8 @@ func Split(mtu int, data []byte, metadata []byte, onFrame HandleSplitResult) {
 }
 
 // SplitSkip skip some bytes and split data and metadata in frame.
+// It never copies bytes: data and metadata of every result are sub-slices of the inputs,
+// so the inputs must not be released or modified until all fragments have been written.
 func SplitSkip(mtu int, skip int, data []byte, metadata []byte, onFrame HandleSplitResult) {
 	mlen, dlen := len(metadata), len(data)
 	var idx, cursor1, cursor2 int
@@ -29,20 +31,20 @@ func SplitSkip(mtu int, skip int, data []byte, metadata []byte, onFrame HandleSp
 		if idx == 0 && skip > 0 {
 			left -= skip
 		}
-		hasMetadata := cursor1 < mlen
+		// empty metadata which is present should be sent in the first fragment.
+		hasMetadata := cursor1 < mlen || (idx == 0 && metadata != nil)
 		if hasMetadata {
 			left -= 3
 		}
 		begin1, begin2 := cursor1, cursor2
-		for wrote := 0; wrote < left; wrote++ {
-			if cursor1 < mlen {
-				cursor1++
-			} else if cursor2 < dlen {
-				cursor2++
-			}
+		cursor1 += minInt(left, mlen-cursor1)
+		cursor2 += minInt(left-(cursor1-begin1), dlen-cursor2)
+		// limit the capacity, appending to a fragment will never overwrite the next one.
+		var curMetadata []byte
+		if hasMetadata {
+			curMetadata = metadata[begin1:cursor1:cursor1]
 		}
-		curMetadata := metadata[begin1:cursor1]
-		curData := data[begin2:cursor2]
+		curData := data[begin2:cursor2:cursor2]
 		follow = cursor1+cursor2 < mlen+dlen
 		var flag core.FrameFlag
 		if follow {
@@ -68,3 +70,10 @@ func SplitSkip(mtu int, skip int, data []byte, metadata []byte, onFrame HandleSp
 		idx++
 	}
 }
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
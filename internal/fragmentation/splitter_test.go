@@ -37,3 +37,68 @@ func split2joiner(mtu int, data, metadata []byte) (joiner Joiner, err error) {
 	Split(mtu, data, metadata, fn)
 	return
 }
+
+func TestSplitter_EmptyMetadata(t *testing.T) {
+	const mtu = 128
+	data := []byte(common.RandAlphanumeric(512))
+
+	var flags []core.FrameFlag
+	Split(mtu, data, []byte{}, func(idx int, result SplitResult) {
+		flags = append(flags, result.Flag)
+		if idx > 0 {
+			assert.Nil(t, result.Metadata)
+		}
+	})
+	assert.True(t, len(flags) > 1)
+	for i, flag := range flags {
+		assert.Equal(t, i == 0, flag.Check(core.FlagMetadata), "only the first fragment carries the empty metadata")
+	}
+
+	joiner, err := split2joiner(mtu, data, []byte{})
+	assert.NoError(t, err)
+	m, ok := joiner.Metadata()
+	assert.True(t, ok, "empty metadata should be present")
+	assert.NotNil(t, m)
+	assert.Empty(t, m)
+	assert.Equal(t, data, joiner.Data())
+
+	joiner, err = split2joiner(mtu, data, nil)
+	assert.NoError(t, err)
+	_, ok = joiner.Metadata()
+	assert.False(t, ok)
+}
+
+func TestSplitter_ZeroCopy(t *testing.T) {
+	const mtu = 128
+	data := []byte(common.RandAlphanumeric(1024))
+	metadata := []byte(common.RandAlphanumeric(300))
+
+	var offset1, offset2, fragments int
+	Split(mtu, data, metadata, func(idx int, result SplitResult) {
+		fragments++
+		if len(result.Metadata) > 0 {
+			assert.True(t, &metadata[offset1] == &result.Metadata[0], "metadata should not be copied")
+			assert.Equal(t, len(result.Metadata), cap(result.Metadata))
+		}
+		if len(result.Data) > 0 {
+			assert.True(t, &data[offset2] == &result.Data[0], "data should not be copied")
+			assert.Equal(t, len(result.Data), cap(result.Data))
+		}
+		offset1 += len(result.Metadata)
+		offset2 += len(result.Data)
+		size := core.FrameHeaderLen + len(result.Metadata) + len(result.Data)
+		if result.Flag.Check(core.FlagMetadata) {
+			size += 3
+		}
+		assert.True(t, size <= mtu, "fragment exceeds mtu")
+	})
+	assert.Equal(t, len(metadata), offset1)
+	assert.Equal(t, len(data), offset2)
+	assert.True(t, fragments > 10)
+
+	noop := func(int, SplitResult) {}
+	allocs := testing.AllocsPerRun(100, func() {
+		Split(mtu, data, metadata, noop)
+	})
+	assert.Equal(t, float64(0), allocs, "split should not allocate")
+}
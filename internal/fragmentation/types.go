@@ -33,6 +33,8 @@ type Joiner interface {
 	First() core.BufferedFrame
 	// Push append a new frame and returns true if joiner is end.
 	Push(elem HeaderAndPayload) (end bool)
+	// Size returns total bytes of data and metadata joined so far.
+	Size() int
 }
 
 // NewJoiner returns a new joiner.
@@ -41,9 +43,19 @@ func NewJoiner(first HeaderAndPayload) Joiner {
 	root.PushBack(first)
 	return &implJoiner{
 		root: root,
+		size: PayloadSize(first),
 	}
 }
 
+// PayloadSize returns total bytes of data and metadata in the payload.
+func PayloadSize(p payload.Payload) int {
+	size := len(p.Data())
+	if m, ok := p.Metadata(); ok {
+		size += len(m)
+	}
+	return size
+}
+
 // IsValidFragment validate fragment size.
 func IsValidFragment(fragment int) (err error) {
 	if fragment < MinFragment || fragment > MaxFragment {
@@ -0,0 +1,69 @@
+package socket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/framing"
+	"github.com/rsocket/rsocket-go/core/transport"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx/mono"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDuplexConnection_RespondRequestResponseAsync(t *testing.T) {
+	const delay = 100 * time.Millisecond
+	dc := NewServerDuplexConnection(1024, nil)
+	dc.SetResponder(&AbstractRSocket{
+		RR: func(request payload.Payload) mono.Mono {
+			switch request.DataUTF8() {
+			case "callback":
+				// completed later by another goroutine.
+				return mono.Create(func(ctx context.Context, sink mono.Sink) {
+					time.AfterFunc(delay, func() {
+						sink.Success(payload.NewString("callback", ""))
+					})
+				})
+			case "blocking":
+				// completed later by the subscribing goroutine.
+				return mono.Create(func(ctx context.Context, sink mono.Sink) {
+					time.Sleep(delay)
+					sink.Success(payload.NewString("blocking", ""))
+				})
+			default:
+				return mono.Just(payload.NewString("fast", ""))
+			}
+		},
+	})
+	conn := &recordConn{closed: make(chan struct{})}
+	defer close(conn.closed)
+	tp := transport.NewTransport(conn)
+	dc.SetTransport(tp)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = dc.LoopWrite(ctx)
+	}()
+
+	// frames are dispatched like the read loop does.
+	start := time.Now()
+	assert.NoError(t, tp.DispatchFrame(ctx, framing.NewRequestResponseFrame(1, []byte("callback"), nil, 0)))
+	assert.NoError(t, tp.DispatchFrame(ctx, framing.NewRequestResponseFrame(3, []byte("blocking"), nil, 0)))
+	assert.NoError(t, tp.DispatchFrame(ctx, framing.NewKeepaliveFrame(0, nil, true)))
+	assert.NoError(t, tp.DispatchFrame(ctx, framing.NewRequestResponseFrame(5, []byte("fast"), nil, 0)))
+	assert.True(t, time.Since(start) < delay/2, "dispatching should not wait for pending responses")
+
+	// other frames are processed while the responses are pending.
+	assert.Eventually(t, func() bool {
+		return conn.count(0, core.FrameTypeKeepalive) == 1 && conn.count(5, core.FrameTypePayload) == 1
+	}, delay/2, time.Millisecond)
+	assert.Zero(t, conn.count(1, core.FrameTypePayload))
+	assert.Zero(t, conn.count(3, core.FrameTypePayload))
+
+	assert.Eventually(t, func() bool {
+		return conn.count(1, core.FrameTypePayload) == 1 && conn.count(3, core.FrameTypePayload) == 1
+	}, 3*time.Second, 10*time.Millisecond)
+	assert.True(t, time.Since(start) >= delay)
+}
@@ -1,13 +1,16 @@
 package socket
 
 import (
+	"context"
 	"sync"
 	"time"
 
+	"github.com/rsocket/rsocket-go/core"
 	"github.com/rsocket/rsocket-go/logger"
 	"github.com/rsocket/rsocket-go/payload"
 	"github.com/rsocket/rsocket-go/rx/flux"
 	"github.com/rsocket/rsocket-go/rx/mono"
+	"go.uber.org/atomic"
 )
 
 // BaseSocket is basic socket.
@@ -16,12 +19,15 @@ type BaseSocket struct {
 	closers  []func(error)
 	once     sync.Once
 	reqLease *leaser
+	timing   atomic.Value // transport.ConnectTiming
 }
 
 // FireAndForget sends FireAndForget request.
 func (p *BaseSocket) FireAndForget(message payload.Payload) {
 	if err := p.reqLease.allow(); err != nil {
-		logger.Warnf("request FireAndForget failed: %v\n", err)
+		p.socket.leaseRejected(core.FrameTypeRequestFNF)
+		logger.Warnf("request FireAndForget failed: %v, conn=%s\n", err, p.socket.connID)
+		return
 	}
 	p.socket.FireAndForget(message)
 }
@@ -34,14 +40,30 @@ func (p *BaseSocket) MetadataPush(message payload.Payload) {
 // RequestResponse sends RequestResponse request.
 func (p *BaseSocket) RequestResponse(message payload.Payload) mono.Mono {
 	if err := p.reqLease.allow(); err != nil {
+		p.socket.leaseRejected(core.FrameTypeRequestResponse)
 		return mono.Error(err)
 	}
 	return p.socket.RequestResponse(message)
 }
 
+// KeepaliveSettings returns keepalive settings negotiated by the SETUP frame.
+func (p *BaseSocket) KeepaliveSettings() KeepaliveSettings {
+	return p.socket.KeepaliveSettings()
+}
+
+// RequestResponseSync sends RequestResponse request and blocks until the response arrives.
+func (p *BaseSocket) RequestResponseSync(ctx context.Context, message payload.Payload) (payload.Payload, error) {
+	if err := p.reqLease.allow(); err != nil {
+		p.socket.leaseRejected(core.FrameTypeRequestResponse)
+		return nil, err
+	}
+	return p.socket.RequestResponseSync(ctx, message)
+}
+
 // RequestStream sends RequestStream request.
 func (p *BaseSocket) RequestStream(message payload.Payload) flux.Flux {
 	if err := p.reqLease.allow(); err != nil {
+		p.socket.leaseRejected(core.FrameTypeRequestStream)
 		return flux.Error(err)
 	}
 	return p.socket.RequestStream(message)
@@ -50,11 +72,41 @@ func (p *BaseSocket) RequestStream(message payload.Payload) flux.Flux {
 // RequestChannel sends RequestChannel request.
 func (p *BaseSocket) RequestChannel(messages flux.Flux) flux.Flux {
 	if err := p.reqLease.allow(); err != nil {
+		p.socket.leaseRejected(core.FrameTypeRequestChannel)
 		return flux.Error(err)
 	}
 	return p.socket.RequestChannel(messages)
 }
 
+// OnMetadataPush registers a handler of METADATA_PUSH frames sent by the peer.
+func (p *BaseSocket) OnMetadataPush(handler func(metadata []byte)) {
+	p.socket.OnMetadataPush(handler)
+}
+
+// CancelAll cancels all active requests sent by current socket, the connection is kept.
+func (p *BaseSocket) CancelAll() int {
+	return p.socket.CancelAll()
+}
+
+// WaitReady blocks until current socket is ready to send requests: the transport has been bound after the SETUP
+// (or RESUME) handshake, and the first LEASE has been received if lease is enabled.
+func (p *BaseSocket) WaitReady(ctx context.Context) error {
+	if err := p.socket.WaitReady(ctx); err != nil {
+		return err
+	}
+	if p.reqLease == nil {
+		return nil
+	}
+	select {
+	case <-p.reqLease.received:
+		return nil
+	case <-p.socket.writeDone:
+		return errSocketClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // OnClose registers handler when socket closed.
 func (p *BaseSocket) OnClose(fn func(error)) {
 	if fn != nil {
@@ -70,7 +122,7 @@ func (p *BaseSocket) Close() (err error) {
 			func(fn func(error)) {
 				defer func() {
 					if e := tryRecover(recover()); e != nil {
-						logger.Errorf("handle socket closer failed: %s\n", e)
+						logger.Errorf("handle socket closer failed: %s, conn=%s\n", e, p.socket.connID)
 					}
 				}()
 				fn(err)
@@ -81,9 +133,9 @@ func (p *BaseSocket) Close() (err error) {
 }
 
 func (p *BaseSocket) refreshLease(ttl time.Duration, n int64) {
-	deadline := time.Now().Add(ttl)
+	deadline := p.socket.clock.Now().Add(ttl)
 	if p.reqLease == nil {
-		p.reqLease = newLeaser(deadline, n)
+		p.reqLease = newLeaser(deadline, n, p.socket.clock)
 	} else {
 		p.reqLease.refresh(deadline, n)
 	}
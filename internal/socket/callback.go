@@ -4,10 +4,13 @@ import (
 	"io"
 
 	"github.com/jjeffcaii/reactor-go"
+	"github.com/rsocket/rsocket-go/core"
 	"github.com/rsocket/rsocket-go/internal/common"
+	"github.com/rsocket/rsocket-go/payload"
 	"github.com/rsocket/rsocket-go/rx"
 	"github.com/rsocket/rsocket-go/rx/flux"
 	"github.com/rsocket/rsocket-go/rx/mono"
+	"go.uber.org/atomic"
 )
 
 type callback interface {
@@ -35,9 +38,40 @@ func (s requestResponseCallback) stopWithError(err error) {
 	common.TryRelease(s.cache)
 }
 
+// requestResponseSyncCallback delivers the response of a blocking RequestResponse without any reactive pipeline.
+type requestResponseSyncCallback struct {
+	done chan requestResponseSyncResult
+}
+
+type requestResponseSyncResult struct {
+	res payload.Payload
+	err error
+}
+
+func newRequestResponseSyncCallback() requestResponseSyncCallback {
+	return requestResponseSyncCallback{
+		done: make(chan requestResponseSyncResult, 1),
+	}
+}
+
+// offer delivers the first result only, the later ones will be dropped.
+func (s requestResponseSyncCallback) offer(res payload.Payload, err error) {
+	select {
+	case s.done <- requestResponseSyncResult{res: res, err: err}:
+	default:
+	}
+}
+
+func (s requestResponseSyncCallback) stopWithError(err error) {
+	s.offer(nil, err)
+}
+
 type requestChannelCallback struct {
-	snd rx.Subscription
-	rcv flux.Processor
+	snd          rx.Subscription
+	sndCompleted *atomic.Bool
+	rcv          flux.Processor
+	rcvDone      *atomic.Bool
+	result       chan<- error
 }
 
 func (s requestChannelCallback) stopWithError(err error) {
@@ -48,27 +82,48 @@ func (s requestChannelCallback) stopWithError(err error) {
 	s.rcv.Error(err)
 }
 
+// cancelSending cancels the outbound source after the peer sent CANCEL, the inbound side is kept open.
+// The outbound side is completed silently: no more PAYLOAD is sent, and the receiving side doesn't wait for its result.
+func (s requestChannelCallback) cancelSending() {
+	if !s.sndCompleted.CAS(false, true) {
+		return
+	}
+	s.snd.Cancel()
+	defer func() {
+		_ = recover()
+	}()
+	close(s.result)
+}
+
 type requestResponseCallbackReverse struct {
-	su reactor.Subscription
+	su          reactor.Subscription
+	requestType core.FrameType // REQUEST_STREAM if the responding Flux is converted from a Mono
+	teardown    *responderTeardown
+	stall       *stallDetector
 }
 
 func (s requestResponseCallbackReverse) stopWithError(err error) {
+	s.stall.stop()
 	s.su.Cancel()
 	// TODO: fill err
 }
 
 type requestStreamCallbackReverse struct {
-	su rx.Subscription
+	su       rx.Subscription
+	teardown *responderTeardown
+	stall    *stallDetector
 }
 
 func (s requestStreamCallbackReverse) stopWithError(err error) {
+	s.stall.stop()
 	s.su.Cancel()
 	// TODO: fill error
 }
 
 type respondChannelCallback struct {
-	snd rx.Subscription
-	rcv flux.Processor
+	snd     rx.Subscription
+	rcv     flux.Processor
+	rcvDone *atomic.Bool
 }
 
 func (s respondChannelCallback) stopWithError(err error) {
@@ -0,0 +1,42 @@
+package socket
+
+import (
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/framing"
+)
+
+type activeRequest struct {
+	sid         uint32
+	requestType core.FrameType
+	cb          callback
+}
+
+// CancelAll cancels all active requests of the requester side, the connection is kept.
+// A CANCEL frame will be sent for every request, then it will be terminated with core.ErrRequestCancelled.
+func (dc *DuplexConnection) CancelAll() int {
+	var actives []activeRequest
+	dc.messages.Range(func(sid uint32, v interface{}) bool {
+		next := activeRequest{sid: sid}
+		switch cb := v.(type) {
+		case *requestResponseCallback:
+			next.requestType, next.cb = core.FrameTypeRequestResponse, cb
+		case requestResponseSyncCallback:
+			next.requestType, next.cb = core.FrameTypeRequestResponse, cb
+		case requestStreamCallback:
+			next.requestType, next.cb = core.FrameTypeRequestStream, cb
+		case requestChannelCallback:
+			next.requestType, next.cb = core.FrameTypeRequestChannel, cb
+		default:
+			return true
+		}
+		actives = append(actives, next)
+		return true
+	})
+	for _, it := range actives {
+		dc.unregister(it.sid)
+		dc.sendFrame(framing.NewWriteableCancelFrame(it.sid))
+		dc.requestCancelled(it.requestType, true)
+		it.cb.stopWithError(core.ErrRequestCancelled)
+	}
+	return len(actives)
+}
@@ -0,0 +1,66 @@
+package socket
+
+import (
+	"io"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/transport"
+)
+
+// purgeMark is queued after a stream is cancelled by the remote requester.
+// The writer drops every PAYLOAD or ERROR frame of the stream which is queued before the mark,
+// and it forgets the cancelled stream once the mark is reached. The mark itself is never written.
+type purgeMark struct {
+	h core.FrameHeader
+}
+
+func newPurgeMark(sid uint32) purgeMark {
+	return purgeMark{
+		h: core.NewFrameHeader(sid, core.FrameTypeCancel, 0),
+	}
+}
+
+func (p purgeMark) Header() core.FrameHeader {
+	return p.h
+}
+
+func (p purgeMark) Len() int {
+	return 0
+}
+
+func (p purgeMark) WriteTo(io.Writer) (int64, error) {
+	return 0, nil
+}
+
+func (p purgeMark) Done() {
+}
+
+func (p purgeMark) HandleDone(func()) {
+}
+
+// purgeStream marks a stream as cancelled, its queued but unsent frames will be dropped.
+func (dc *DuplexConnection) purgeStream(sid uint32) {
+	dc.cancelled.Store(sid, struct{}{})
+	dc.sendFrame(newPurgeMark(sid))
+}
+
+// purge returns true if the frame should not be written.
+func (dc *DuplexConnection) purge(tp *transport.Transport, out core.WriteableFrame, flush bool) (skip bool, err error) {
+	if mark, ok := out.(purgeMark); ok {
+		dc.cancelled.Delete(mark.h.StreamID())
+		skip = true
+	} else {
+		h := out.Header()
+		switch h.Type() {
+		case core.FrameTypePayload, core.FrameTypeError:
+			_, skip = dc.cancelled.Load(h.StreamID())
+		}
+		if skip {
+			out.Done()
+		}
+	}
+	if skip && flush {
+		err = tp.Flush()
+	}
+	return
+}
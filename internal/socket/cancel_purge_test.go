@@ -0,0 +1,115 @@
+package socket
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/framing"
+	"github.com/rsocket/rsocket-go/core/transport"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+)
+
+type recordConn struct {
+	sync.Mutex
+	headers []core.FrameHeader
+	closed  chan struct{}
+}
+
+func (r *recordConn) Close() error {
+	return nil
+}
+
+func (r *recordConn) SetDeadline(time.Time) error {
+	return nil
+}
+
+func (r *recordConn) SetCounter(*core.TrafficCounter) {
+}
+
+func (r *recordConn) Read() (core.BufferedFrame, error) {
+	<-r.closed
+	return nil, context.Canceled
+}
+
+func (r *recordConn) Write(frame core.WriteableFrame) error {
+	r.Lock()
+	r.headers = append(r.headers, frame.Header())
+	r.Unlock()
+	frame.Done()
+	return nil
+}
+
+func (r *recordConn) Flush() error {
+	return nil
+}
+
+func (r *recordConn) count(sid uint32, frameType core.FrameType) (n int) {
+	r.Lock()
+	defer r.Unlock()
+	for _, h := range r.headers {
+		if h.StreamID() == sid && h.Type() == frameType {
+			n++
+		}
+	}
+	return
+}
+
+type cancelSubscription struct {
+	cancelled *atomic.Bool
+}
+
+func (c cancelSubscription) Request(int) {
+}
+
+func (c cancelSubscription) Cancel() {
+	c.cancelled.Store(true)
+}
+
+func TestDuplexConnection_PurgeCancelledStream(t *testing.T) {
+	dc := NewServerDuplexConnection(1024, nil)
+	su := cancelSubscription{cancelled: atomic.NewBool(false)}
+	dc.register(1, requestStreamCallbackReverse{su: su, teardown: newResponderTeardown(nil)})
+
+	released := atomic.NewInt32(0)
+	// queue frames of a high-rate stream before the transport is ready.
+	for i := 0; i < 20; i++ {
+		p := payload.NewString("foo", "bar")
+		dc.sendPayload(1, p, core.FlagNext)
+		dc.sendPayload(3, p, core.FlagNext)
+		dc.outs <- framing.NewWriteablePayloadFrame(1, []byte("qux"), nil, core.FlagNext)
+	}
+	queued := framing.NewWriteablePayloadFrame(1, nil, nil, core.FlagComplete)
+	queued.HandleDone(func() {
+		released.Inc()
+	})
+	dc.outs <- queued
+
+	assert.NoError(t, dc.onFrameCancel(framing.NewCancelFrame(1)))
+	assert.True(t, su.cancelled.Load())
+
+	// frames after purging will be sent.
+	dc.sendPayload(1, payload.NewString("late", ""), core.FlagNext)
+
+	conn := &recordConn{closed: make(chan struct{})}
+	defer close(conn.closed)
+	dc.SetTransport(transport.NewTransport(conn))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = dc.LoopWrite(ctx)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return conn.count(3, core.FrameTypePayload) == 20 && conn.count(1, core.FrameTypePayload) == 1
+	}, 3*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(1), released.Load(), "dropped frame should be released")
+	assert.Equal(t, 0, conn.count(1, core.FrameTypeCancel), "purge mark should not be written")
+	_, ok := dc.cancelled.Load(1)
+	assert.False(t, ok)
+}
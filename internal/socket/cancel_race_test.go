@@ -0,0 +1,170 @@
+package socket
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core/framing"
+	"github.com/rsocket/rsocket-go/core/transport"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx/flux"
+	"github.com/rsocket/rsocket-go/rx/mono"
+	"github.com/stretchr/testify/assert"
+)
+
+// raceResponder completes every stream once the gate of the stream is opened.
+type raceResponder struct {
+	AbstractRSocket
+	gates sync.Map
+}
+
+func (r *raceResponder) gate(sid uint32) chan struct{} {
+	v, _ := r.gates.LoadOrStore(sid, make(chan struct{}))
+	return v.(chan struct{})
+}
+
+func newRaceResponder() *raceResponder {
+	r := &raceResponder{}
+	r.RR = func(request payload.Payload) mono.Mono {
+		return mono.Create(func(ctx context.Context, sink mono.Sink) {
+			sid, _ := StreamIDFromContext(ctx)
+			go func() {
+				<-r.gate(sid)
+				sink.Success(payload.NewString("foo", "bar"))
+			}()
+		})
+	}
+	r.RS = func(request payload.Payload) flux.Flux {
+		return flux.Create(func(ctx context.Context, sink flux.Sink) {
+			sid, _ := StreamIDFromContext(ctx)
+			go func() {
+				<-r.gate(sid)
+				sink.Next(payload.NewString("foo", "bar"))
+				sink.Complete()
+			}()
+		})
+	}
+	return r
+}
+
+func TestDuplexConnection_CompleteCancelRace(t *testing.T) {
+	const streams = 100
+	responder := newRaceResponder()
+	dc := NewServerDuplexConnection(16*1024, nil)
+	dc.SetResponder(responder)
+
+	conn := &recordConn{closed: make(chan struct{})}
+	defer close(conn.closed)
+	dc.SetTransport(transport.NewTransport(conn))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = dc.LoopWrite(ctx)
+	}()
+
+	var requests []*framing.RequestStreamFrame
+	var rrRequests []*framing.RequestResponseFrame
+	for i := 0; i < streams; i++ {
+		sid := uint32(4*i + 1)
+		stream := framing.NewRequestStreamFrame(sid, 10, []byte("data"), nil, 0)
+		requests = append(requests, stream)
+		assert.NoError(t, dc.onFrameRequestStream(stream))
+		rr := framing.NewRequestResponseFrame(sid+2, []byte("data"), nil, 0)
+		rrRequests = append(rrRequests, rr)
+		assert.NoError(t, dc.onFrameRequestResponse(rr))
+	}
+	registered := func(sid uint32) bool {
+		_, ok := dc.messages.Load(sid)
+		return ok
+	}
+	assert.Eventually(t, func() bool {
+		for i := 0; i < streams; i++ {
+			if !registered(uint32(4*i+1)) || !registered(uint32(4*i+3)) {
+				return false
+			}
+		}
+		return true
+	}, 3*time.Second, 10*time.Millisecond)
+
+	// complete and cancel every stream at the same time.
+	var wg sync.WaitGroup
+	for i := 0; i < streams; i++ {
+		for _, sid := range []uint32{uint32(4*i + 1), uint32(4*i + 3)} {
+			wg.Add(2)
+			go func(sid uint32) {
+				defer wg.Done()
+				close(responder.gate(sid))
+			}(sid)
+			go func(sid uint32) {
+				defer wg.Done()
+				assert.NotPanics(t, func() {
+					_ = dc.onFrameCancel(framing.NewCancelFrame(sid))
+				})
+			}(sid)
+		}
+	}
+	wg.Wait()
+
+	assert.Eventually(t, func() bool {
+		for i := 0; i < streams; i++ {
+			if registered(uint32(4*i+1)) || registered(uint32(4*i+3)) {
+				return false
+			}
+		}
+		return true
+	}, 3*time.Second, 10*time.Millisecond, "all streams should be torn down")
+	// a request payload is released once at most: by the completion, or never if the stream has been cancelled first.
+	for i := 0; i < streams; i++ {
+		assert.LessOrEqual(t, requests[i].RefCnt(), int32(1))
+		assert.LessOrEqual(t, rrRequests[i].RefCnt(), int32(1))
+	}
+}
+
+func TestDuplexConnection_CancelKeepsRequest(t *testing.T) {
+	gate := make(chan struct{})
+	echoed := make(chan struct{})
+	dc := NewServerDuplexConnection(16*1024, nil)
+	dc.SetResponder(&AbstractRSocket{
+		RR: func(request payload.Payload) mono.Mono {
+			return mono.Create(func(ctx context.Context, sink mono.Sink) {
+				go func() {
+					defer close(echoed)
+					<-gate
+					// the handler still holds the request after the stream has been cancelled.
+					sink.Success(request)
+				}()
+			})
+		},
+	})
+	conn := &recordConn{closed: make(chan struct{})}
+	defer close(conn.closed)
+	dc.SetTransport(transport.NewTransport(conn))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = dc.LoopWrite(ctx)
+	}()
+
+	req := framing.NewRequestResponseFrame(1, []byte("data"), nil, 0)
+	assert.NoError(t, dc.onFrameRequestResponse(req))
+	assert.Eventually(t, func() bool {
+		_, ok := dc.messages.Load(uint32(1))
+		return ok
+	}, 3*time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, dc.onFrameCancel(framing.NewCancelFrame(1)))
+	assert.Equal(t, int32(1), req.RefCnt(), "the request should not be released by CANCEL")
+	close(gate)
+	<-echoed
+	assert.Zero(t, dc.ActiveStreams())
+}
+
+func TestDuplexConnection_CancelUncancellableStream(t *testing.T) {
+	dc := NewServerDuplexConnection(16*1024, nil)
+	dc.register(1, &requestResponseCallback{})
+	assert.NotPanics(t, func() {
+		assert.NoError(t, dc.onFrameCancel(framing.NewCancelFrame(1)))
+	})
+}
@@ -0,0 +1,111 @@
+package socket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/framing"
+	"github.com/rsocket/rsocket-go/core/transport"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx"
+	"github.com/rsocket/rsocket-go/rx/flux"
+	"github.com/stretchr/testify/assert"
+)
+
+// gateConn blocks writing PAYLOAD frames until the gate is opened, so they are queued like on a slow link.
+type gateConn struct {
+	recordConn
+	gate chan struct{}
+}
+
+func (g *gateConn) Write(frame core.WriteableFrame) error {
+	if frame.Header().Type() == core.FrameTypePayload {
+		<-g.gate
+	}
+	return g.recordConn.Write(frame)
+}
+
+func TestDuplexConnection_RequestChannelCancelledByResponder(t *testing.T) {
+	dc := NewClientDuplexConnection(1024, time.Hour)
+	conn := &gateConn{
+		recordConn: recordConn{closed: make(chan struct{})},
+		gate:       make(chan struct{}),
+	}
+	defer close(conn.closed)
+	dc.SetTransport(transport.NewTransport(conn))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = dc.LoopWrite(ctx)
+	}()
+
+	const sid = 1
+	items := make([]*framing.PayloadFrame, 10)
+	for i := range items {
+		items[i] = framing.NewPayloadFrame(0, []byte("upload"), nil, core.FlagNext)
+	}
+	sndSignal := make(chan rx.SignalType, 1)
+	uploading := flux.
+		Create(func(ctx context.Context, sink flux.Sink) {
+			// never completes, it is stopped by the responder.
+			for _, it := range items {
+				sink.Next(it)
+			}
+		}).
+		DoFinally(func(s rx.SignalType) {
+			sndSignal <- s
+		})
+	completed := make(chan struct{})
+	nexts := 0
+	dc.RequestChannel(uploading).
+		DoOnComplete(func() {
+			close(completed)
+		}).
+		Subscribe(context.Background(), rx.OnNext(func(input payload.Payload) error {
+			nexts++
+			return nil
+		}))
+	assert.Eventually(t, func() bool {
+		return conn.count(sid, core.FrameTypeRequestChannel) == 1
+	}, 3*time.Second, 10*time.Millisecond)
+
+	// the responder asks for the upload, the payloads are queued behind the slow link.
+	assert.NoError(t, dc.onFrameRequestN(framing.NewRequestNFrame(sid, 100, 0)))
+	assert.Eventually(t, func() bool {
+		return dc.OutboundQueueDepth() >= len(items)-2
+	}, 3*time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, dc.onFrameCancel(framing.NewCancelFrame(sid)))
+	select {
+	case s := <-sndSignal:
+		assert.Equal(t, rx.SignalCancel, s, "source should be cancelled")
+	case <-time.After(3 * time.Second):
+		assert.Fail(t, "source should be stopped after the responder cancels")
+	}
+	close(conn.gate)
+	assert.Eventually(t, func() bool {
+		return dc.OutboundQueueDepth() == 0
+	}, 3*time.Second, 10*time.Millisecond)
+	for _, it := range items {
+		assert.Equal(t, int32(1), it.RefCnt(), "queued payload should be released")
+	}
+	// at most the payload blocked in the transport is written.
+	assert.True(t, conn.count(sid, core.FrameTypePayload) <= 1)
+
+	// the inbound side is kept open, and it completes without sending COMPLETE of the cancelled outbound side.
+	_, ok := dc.messages.Load(uint32(sid))
+	assert.True(t, ok, "inbound side should be open")
+	assert.NoError(t, dc.onFramePayload(framing.NewPayloadFrame(sid, []byte("foo"), nil, core.FlagNext|core.FlagComplete)))
+	select {
+	case <-completed:
+	case <-time.After(3 * time.Second):
+		assert.Fail(t, "inbound side should complete")
+	}
+	assert.Equal(t, 1, nexts)
+	for _, flag := range conn.flags(sid, core.FrameTypePayload) {
+		assert.False(t, flag.Check(core.FlagComplete), "cancelled outbound side should not be completed")
+	}
+	assert.Equal(t, 0, conn.count(sid, core.FrameTypeError))
+}
@@ -0,0 +1,67 @@
+package socket
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/framing"
+	"github.com/rsocket/rsocket-go/core/transport"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx"
+	"github.com/rsocket/rsocket-go/rx/flux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+)
+
+func TestDuplexConnection_RespondChannelInitialRequestN(t *testing.T) {
+	const total = 10
+	calls := atomic.NewInt32(0)
+	dc := NewServerDuplexConnection(1024, nil)
+	dc.SetResponder(&AbstractRSocket{
+		RC: func(requests flux.Flux) flux.Flux {
+			calls.Inc()
+			requests.Subscribe(context.Background(), rx.OnNext(func(input payload.Payload) error {
+				return nil
+			}))
+			return flux.Create(func(ctx context.Context, sink flux.Sink) {
+				for i := 0; i < total; i++ {
+					sink.Next(payload.NewString(fmt.Sprint(i), ""))
+				}
+				sink.Complete()
+			})
+		},
+	})
+	conn := &recordConn{closed: make(chan struct{})}
+	defer close(conn.closed)
+	dc.SetTransport(transport.NewTransport(conn))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = dc.LoopWrite(ctx)
+	}()
+
+	assert.NoError(t, dc.onFrameRequestChannel(framing.NewRequestChannelFrame(1, 2, []byte("foo"), nil, core.FlagNext)))
+	assert.Eventually(t, func() bool {
+		return len(conn.flags(1, core.FrameTypePayload)) == 2
+	}, 3*time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, conn.flags(1, core.FrameTypePayload), 2, "only the initial request n should be emitted")
+
+	assert.NoError(t, dc.onFrameRequestN(framing.NewRequestNFrame(1, 3, 0)))
+	assert.Eventually(t, func() bool {
+		return len(conn.flags(1, core.FrameTypePayload)) == 5
+	}, 3*time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, conn.flags(1, core.FrameTypePayload), 5)
+
+	// a zero initial request n is rejected before the handler is invoked.
+	assert.NoError(t, dc.onFrameRequestChannel(framing.NewRequestChannelFrame(3, 0, []byte("foo"), nil, core.FlagNext)))
+	assert.Eventually(t, func() bool {
+		return len(conn.flags(3, core.FrameTypeError)) == 1
+	}, 3*time.Second, 10*time.Millisecond)
+	assert.Empty(t, conn.flags(3, core.FrameTypePayload))
+	assert.Equal(t, int32(1), calls.Load())
+}
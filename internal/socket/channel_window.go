@@ -0,0 +1,90 @@
+package socket
+
+import (
+	"sync"
+
+	"github.com/rsocket/rsocket-go/rx"
+)
+
+// outboundWindow bounds outbound payloads of a RequestChannel which have been requested from the source
+// but not written yet. Credits granted by REQUEST_N of the peer are kept, and they will be forwarded to
+// the source only when there is room in the window, so a large REQUEST_N never floods the outbound queue.
+type outboundWindow struct {
+	mu       sync.Mutex
+	su       rx.Subscription
+	size     int
+	credits  int
+	inflight int
+}
+
+func newOutboundWindow(size int) *outboundWindow {
+	return &outboundWindow{
+		size: size,
+	}
+}
+
+// SetChannelOutboundWindow sets the max amount of outbound payloads in flight for every RequestChannel,
+// zero means no limit and the demand of the peer is forwarded to the source directly.
+func (dc *DuplexConnection) SetChannelOutboundWindow(size int) {
+	if size < 0 {
+		size = 0
+	}
+	dc.channelWindow = size
+}
+
+func (w *outboundWindow) bind(su rx.Subscription) {
+	w.mu.Lock()
+	w.su = su
+	w.mu.Unlock()
+}
+
+// Request grants credits from the peer.
+func (w *outboundWindow) Request(n int) {
+	if n < 1 {
+		return
+	}
+	w.mu.Lock()
+	if w.credits += n; w.credits > rx.RequestMax || w.credits < 0 {
+		w.credits = rx.RequestMax
+	}
+	w.mu.Unlock()
+	w.pump()
+}
+
+func (w *outboundWindow) Cancel() {
+	w.mu.Lock()
+	su := w.su
+	w.mu.Unlock()
+	if su != nil {
+		su.Cancel()
+	}
+}
+
+// written releases a slot after a payload has been written.
+// It is invoked by the writer, so the source is requested in another goroutine,
+// otherwise the writer may block on emitting into the outbound queue which is drained by itself.
+func (w *outboundWindow) written() {
+	w.mu.Lock()
+	if w.inflight > 0 {
+		w.inflight--
+	}
+	w.mu.Unlock()
+	go w.pump()
+}
+
+func (w *outboundWindow) pump() {
+	w.mu.Lock()
+	n := w.size - w.inflight
+	if n > w.credits {
+		n = w.credits
+	}
+	su := w.su
+	if n < 1 || su == nil {
+		w.mu.Unlock()
+		return
+	}
+	w.credits -= n
+	w.inflight += n
+	w.mu.Unlock()
+	su.Request(n)
+}
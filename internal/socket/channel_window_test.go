@@ -0,0 +1,72 @@
+package socket
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/framing"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx/flux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+)
+
+func TestRequestChannel_OutboundWindow(t *testing.T) {
+	const window, total = 3, 20
+	dc := NewClientDuplexConnection(1024, 90*time.Second)
+	dc.SetChannelOutboundWindow(window)
+
+	// a fast source which emits all requested items immediately.
+	var items []payload.Payload
+	for i := 0; i < total; i++ {
+		items = append(items, payload.NewString(fmt.Sprintf("item-%d", i), ""))
+	}
+	requested := atomic.NewInt32(0)
+	source := flux.Just(items...).DoOnRequest(func(n int) {
+		requested.Add(int32(n))
+	})
+	result := make(chan error, 1)
+	sub := requestChannelSubscriber{
+		sid:          1,
+		n:            1,
+		dc:           dc,
+		sndRequested: atomic.NewBool(false),
+		sndCompleted: atomic.NewBool(false),
+		rcv:          flux.CreateProcessor(),
+		rcvDone:      atomic.NewBool(false),
+		result:       result,
+		window:       newOutboundWindow(window),
+	}
+	source.SubscribeWith(context.Background(), sub)
+	assert.Equal(t, int32(1), requested.Load())
+
+	// the slow peer grants a lot of credits at once, but the window is respected.
+	assert.NoError(t, dc.onFrameRequestN(framing.NewRequestNFrame(1, 1000, 0)))
+	assert.Eventually(t, func() bool {
+		return len(dc.outs) == window
+	}, 3*time.Second, 10*time.Millisecond)
+
+	var written int
+	for written < total {
+		select {
+		case next := <-dc.outs:
+			assert.True(t, len(dc.outs) < window, "window exceeded")
+			if next.Header().Type() == core.FrameTypePayload || next.Header().Type() == core.FrameTypeRequestChannel {
+				written++
+			}
+			next.Done()
+		case <-time.After(3 * time.Second):
+			assert.FailNow(t, "source is not resumed")
+		}
+		assert.True(t, int(requested.Load())-written <= window, "too many items requested from source")
+	}
+	select {
+	case err, ok := <-result:
+		assert.False(t, ok, "should complete: %v", err)
+	case <-time.After(3 * time.Second):
+		assert.FailNow(t, "source should complete")
+	}
+}
@@ -0,0 +1,15 @@
+package socket
+
+import (
+	"github.com/rsocket/rsocket-go/core/clock"
+)
+
+// SetClock sets the Clock of time-based features, eg: keepalive, lease expiration and the stall detector.
+// It is also set to the transport, and it must be set before the connection starts. Default is the real one.
+func (dc *DuplexConnection) SetClock(c clock.Clock) {
+	dc.clock = clock.OrReal(c)
+	if dc.keepaliver != nil {
+		dc.keepaliver.Stop()
+		dc.keepaliver = NewKeepaliverWithClock(dc.keepaliver.interval, dc.clock)
+	}
+}
@@ -0,0 +1,26 @@
+package socket
+
+import (
+	"time"
+
+	"github.com/rsocket/rsocket-go/core/transport"
+	"github.com/rsocket/rsocket-go/logger"
+)
+
+// ConnectTiming returns the time spent in establishing the current connection, it is zero before connected.
+func (p *BaseSocket) ConnectTiming() (timing transport.ConnectTiming) {
+	if v, ok := p.timing.Load().(transport.ConnectTiming); ok {
+		timing = v
+	}
+	return
+}
+
+// setupSent records the connect timing once the SETUP (or RESUME) frame which is sent since start has been flushed.
+func (p *BaseSocket) setupSent(tp *transport.Transport, start time.Time) {
+	timing := tp.ConnectTiming()
+	timing.Setup = time.Since(start)
+	p.timing.Store(timing)
+	if logger.IsDebugEnabled() {
+		logger.Debugf("rsocket: connection established: %s, conn=%s\n", timing, p.socket.connID)
+	}
+}
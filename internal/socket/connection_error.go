@@ -0,0 +1,14 @@
+package socket
+
+// OnConnectionError registers a handler of ERROR frames with stream id 0 received by the client, eg: CONNECTION_ERROR.
+// It is invoked before the connection is closed, since such an error always terminates the connection.
+// It should be registered before setup.
+func (dc *DuplexConnection) OnConnectionError(handler func(err error)) {
+	dc.onConnErr = handler
+}
+
+func (dc *DuplexConnection) connectionError(err error) {
+	if dc.onConnErr != nil {
+		dc.onConnErr(err)
+	}
+}
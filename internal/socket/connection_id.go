@@ -0,0 +1,30 @@
+package socket
+
+import (
+	"context"
+)
+
+// SetConnectionID sets the ID of the connection, it appears in logs of the connection and in the context of every
+// responder stream. It must be set before the connection is started.
+func (dc *DuplexConnection) SetConnectionID(id string) {
+	dc.connID = id
+}
+
+// ConnectionID returns the ID of the connection, it is empty if not set.
+func (dc *DuplexConnection) ConnectionID() string {
+	return dc.connID
+}
+
+// ConnectionID returns the ID of the connection.
+func (p *BaseSocket) ConnectionID() string {
+	return p.socket.ConnectionID()
+}
+
+// ConnectionIDFromContext returns the ID of the connection which current responder stream belongs to.
+func ConnectionIDFromContext(ctx context.Context) (id string, ok bool) {
+	v, ok := ctx.Value(streamContextKey{}).(streamContextValue)
+	if !ok || v.connID == "" {
+		return "", false
+	}
+	return v.connID, true
+}
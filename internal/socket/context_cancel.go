@@ -0,0 +1,44 @@
+package socket
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jjeffcaii/reactor-go"
+)
+
+// contextWatcher cancels a request once the context of its subscriber is done, since the subscribe context is only
+// checked by reactor-go when subscribing.
+type contextWatcher struct {
+	once    sync.Once
+	stopped chan struct{}
+}
+
+func newContextWatcher() *contextWatcher {
+	return &contextWatcher{
+		stopped: make(chan struct{}),
+	}
+}
+
+// watch calls cancel with reactor.ErrSubscribeCancelled if the context is done before the watcher is stopped, the
+// request is terminated like a cancelled subscription, eg: a CANCEL frame is sent.
+func (w *contextWatcher) watch(ctx context.Context, cancel func(err error)) {
+	// contexts which are never done, eg: context.Background(), are not watched.
+	if ctx.Done() == nil {
+		return
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			cancel(reactor.ErrSubscribeCancelled)
+		case <-w.stopped:
+		}
+	}()
+}
+
+// stop stops watching, it must be called once the request terminates.
+func (w *contextWatcher) stop() {
+	w.once.Do(func() {
+		close(w.stopped)
+	})
+}
@@ -0,0 +1,71 @@
+package socket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/transport"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx"
+	"github.com/rsocket/rsocket-go/rx/flux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDuplexConnection_CancelBySubscribeContext(t *testing.T) {
+	for name, subscribe := range map[string]func(dc *DuplexConnection, ctx context.Context, finally func(rx.SignalType)){
+		"RequestResponse": func(dc *DuplexConnection, ctx context.Context, finally func(rx.SignalType)) {
+			dc.RequestResponse(payload.NewString("foo", "")).DoFinally(finally).Subscribe(ctx)
+		},
+		"RequestStream": func(dc *DuplexConnection, ctx context.Context, finally func(rx.SignalType)) {
+			dc.RequestStream(payload.NewString("foo", "")).DoFinally(finally).Subscribe(ctx)
+		},
+		"RequestChannel": func(dc *DuplexConnection, ctx context.Context, finally func(rx.SignalType)) {
+			requests := flux.Create(func(ctx context.Context, sink flux.Sink) {
+				sink.Next(payload.NewString("foo", ""))
+			})
+			dc.RequestChannel(requests).DoFinally(finally).Subscribe(ctx)
+		},
+	} {
+		subscribe := subscribe
+		t.Run(name, func(t *testing.T) {
+			dc := NewClientDuplexConnection(1024, time.Hour)
+			conn := &recordConn{closed: make(chan struct{})}
+			defer close(conn.closed)
+			dc.SetTransport(transport.NewTransport(conn))
+			loopCtx, stop := context.WithCancel(context.Background())
+			defer stop()
+			go func() {
+				_ = dc.LoopWrite(loopCtx)
+			}()
+
+			const sid = 1
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan rx.SignalType, 1)
+			subscribe(dc, ctx, func(s rx.SignalType) {
+				done <- s
+			})
+			assert.Eventually(t, func() bool {
+				return conn.count(sid, core.FrameTypeRequestResponse)+
+					conn.count(sid, core.FrameTypeRequestStream)+
+					conn.count(sid, core.FrameTypeRequestChannel) == 1
+			}, 3*time.Second, 10*time.Millisecond)
+
+			cancel()
+			select {
+			case s := <-done:
+				assert.Equal(t, rx.SignalCancel, s)
+			case <-time.After(3 * time.Second):
+				assert.Fail(t, "request should be terminated after the context is cancelled")
+			}
+			assert.Eventually(t, func() bool {
+				return conn.count(sid, core.FrameTypeCancel) == 1
+			}, 3*time.Second, 10*time.Millisecond, "CANCEL should be sent")
+			assert.Eventually(t, func() bool {
+				_, ok := dc.messages.Load(uint32(sid))
+				return !ok
+			}, 3*time.Second, 10*time.Millisecond, "stream should be unregistered")
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package socket
+
+import (
+	"github.com/pkg/errors"
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/transport"
+	"github.com/rsocket/rsocket-go/logger"
+)
+
+const _controlChanSize = 16
+
+// isControlFrame returns true if the frame controls the connection, they are written ahead of frames of streams.
+// METADATA_PUSH is also a frame of stream 0, but it carries data of the application so it keeps the order.
+func isControlFrame(f core.WriteableFrame) bool {
+	h := f.Header()
+	if h.StreamID() != 0 {
+		return false
+	}
+	switch h.Type() {
+	case core.FrameTypeKeepalive, core.FrameTypeLease, core.FrameTypeError:
+		return true
+	default:
+		return false
+	}
+}
+
+// sendControl queues a connection-control frame, it returns false if the frame should be queued as a normal frame,
+// eg: the connection has been closed or there are too many pending control frames.
+func (dc *DuplexConnection) sendControl(f core.WriteableFrame) bool {
+	if dc.closed.Load() {
+		return false
+	}
+	select {
+	case dc.control <- f:
+		return true
+	default:
+		return false
+	}
+}
+
+// writeControl writes pending connection-control frames and the due keepalive, then flushes them.
+// It is called between frames of streams, so a saturated writer cannot delay keepalives until the peer times out.
+func (dc *DuplexConnection) writeControl() {
+	tp := dc.currentTransport()
+	if tp == nil {
+		return
+	}
+	var written bool
+	for {
+		var out core.WriteableFrame
+		select {
+		case out = <-dc.control:
+		default:
+			if dc.keepaliver == nil {
+				break
+			}
+			select {
+			case <-dc.keepaliver.C():
+				if err := dc.send(tp, dc.newKeepaliveFrame(), false); err == nil {
+					written = true
+				} else if !errors.Is(err, transport.ErrClosed) {
+					logger.Errorf("send keepalive frame failed: %s, conn=%s\n", err.Error(), dc.connID)
+				}
+				continue
+			default:
+			}
+		}
+		if out == nil {
+			break
+		}
+		if dc.drainOne(out) {
+			written = true
+		}
+	}
+	if written {
+		if err := tp.Flush(); err != nil {
+			logger.Errorf("flush failed: %v, conn=%s\n", err, dc.connID)
+		}
+	}
+}
+
+// writeNow writes a frame and flushes, the frame is kept to be sent after reconnecting if it fails.
+func (dc *DuplexConnection) writeNow(out core.WriteableFrame) {
+	tp := dc.currentTransport()
+	if tp == nil {
+		dc.outsPriority = append(dc.outsPriority, out)
+		return
+	}
+	if err := dc.send(tp, out, true); err != nil {
+		dc.outsPriority = append(dc.outsPriority, out)
+		logger.Errorf("send frame failed: %s, conn=%s\n", err.Error(), dc.connID)
+	}
+}
+
+// releaseControl releases control frames which are never written, it must be called after the writer exits.
+func (dc *DuplexConnection) releaseControl() {
+	for {
+		select {
+		case out := <-dc.control:
+			out.Done()
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package socket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/clock"
+	"github.com/rsocket/rsocket-go/core/framing"
+	"github.com/rsocket/rsocket-go/core/transport"
+	"github.com/stretchr/testify/assert"
+)
+
+// hookConn records written frames and calls the hook after each of them.
+type hookConn struct {
+	*recordConn
+	onWrite func(n int)
+}
+
+func (h *hookConn) Write(frame core.WriteableFrame) error {
+	_ = h.recordConn.Write(frame)
+	h.Lock()
+	n := len(h.headers)
+	h.Unlock()
+	h.onWrite(n)
+	return nil
+}
+
+func TestDuplexConnection_ControlFramesUnderSaturation(t *testing.T) {
+	const total = 60
+	fake := clock.NewFake(time.Now())
+	dc := newDuplexConnection(1024, NewKeepaliverWithClock(time.Second, fake), &clientStreamIDs{}, nil)
+	for i := 0; i < total; i++ {
+		dc.outs <- framing.NewWriteablePayloadFrame(1, []byte{byte(i)}, nil, core.FlagNext)
+	}
+
+	conn := &hookConn{
+		recordConn: &recordConn{closed: make(chan struct{})},
+		onWrite: func(n int) {
+			switch n {
+			case 10:
+				// the keepalive is due while the writer is busy.
+				fake.Advance(time.Second)
+			case 20:
+				// the queue is full, but an error of the connection is not queued behind it.
+				assert.True(t, dc.sendFrame(framing.NewWriteableErrorFrame(0, core.ErrorCodeConnectionError, []byte("bye"))))
+			}
+		},
+	}
+	defer close(conn.closed)
+	dc.SetTransport(transport.NewTransport(conn))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = dc.LoopWrite(ctx)
+	}()
+
+	assert.Eventually(t, func() bool {
+		conn.Lock()
+		defer conn.Unlock()
+		return len(conn.headers) == total+2
+	}, 3*time.Second, 10*time.Millisecond)
+
+	conn.Lock()
+	defer conn.Unlock()
+	assert.Equal(t, core.FrameTypeKeepalive, conn.headers[10].Type())
+	assert.Equal(t, uint32(0), conn.headers[20].StreamID())
+	assert.Equal(t, core.FrameTypeError, conn.headers[20].Type())
+}
+
+func TestDuplexConnection_ControlFramesOnClose(t *testing.T) {
+	for name, keepaliver := range map[string]func() *Keepaliver{
+		"without keepalive": func() *Keepaliver { return nil },
+		"with keepalive":    func() *Keepaliver { return NewKeepaliver(time.Hour) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < 50; i++ {
+				dc := newDuplexConnection(1024, keepaliver(), &clientStreamIDs{}, nil)
+				conn := &recordConn{closed: make(chan struct{})}
+				dc.SetTransport(transport.NewTransport(conn))
+				go func() {
+					_ = dc.LoopWrite(context.Background())
+				}()
+				// the connection is closed right after the error is sent, the error should never be lost.
+				assert.True(t, dc.sendFrame(framing.NewWriteableErrorFrame(0, core.ErrorCodeConnectionError, []byte("bye"))))
+				_ = dc.Close()
+				close(conn.closed)
+				if !assert.Equal(t, 1, conn.count(0, core.FrameTypeError)) {
+					return
+				}
+			}
+		})
+	}
+}
+
+func TestIsControlFrame(t *testing.T) {
+	assert.True(t, isControlFrame(framing.NewWriteableKeepaliveFrame(0, nil, true)))
+	assert.True(t, isControlFrame(framing.NewWriteableLeaseFrame(time.Second, 1, nil)))
+	assert.True(t, isControlFrame(framing.NewWriteableErrorFrame(0, core.ErrorCodeConnectionError, nil)))
+	assert.False(t, isControlFrame(framing.NewWriteableErrorFrame(1, core.ErrorCodeApplicationError, nil)))
+	assert.False(t, isControlFrame(framing.NewWriteableMetadataPushFrame([]byte("foo"))))
+}
+
+func TestDuplexConnection_SetupAck(t *testing.T) {
+	dc := NewClientDuplexConnection(1024, time.Hour)
+	acked := dc.armSetupAck([]byte("setup"))
+
+	_ = dc.onFrameKeepalive(framing.NewKeepaliveFrame(0, []byte("other"), false))
+	select {
+	case <-acked:
+		assert.Fail(t, "a KEEPALIVE with other data should not confirm the SETUP")
+	default:
+	}
+
+	_ = dc.onFrameKeepalive(framing.NewKeepaliveFrame(0, []byte("setup"), false))
+	select {
+	case <-acked:
+	default:
+		assert.Fail(t, "the echoed KEEPALIVE should confirm the SETUP")
+	}
+}
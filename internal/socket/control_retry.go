@@ -0,0 +1,62 @@
+package socket
+
+import (
+	"time"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/transport"
+)
+
+// isRetrySafe returns true if the frame can be written again after a transient write error.
+// A duplicated KEEPALIVE only refreshes the peer and a duplicated CANCEL is ignored as a frame of an unknown stream,
+// a lost REQUEST_N stalls the stream forever while a duplicated one grants a few more credits.
+// Other frames are never retried: frames carrying data (REQUEST_*, PAYLOAD, METADATA_PUSH and their fragments)
+// may have been partially written, an ERROR terminates a stream, and a LEASE grants requests.
+func isRetrySafe(f core.WriteableFrame) bool {
+	switch f.Header().Type() {
+	case core.FrameTypeKeepalive, core.FrameTypeCancel, core.FrameTypeRequestN:
+		return true
+	default:
+		return false
+	}
+}
+
+// isTransientWriteError returns true if the Conn failed to write the frame without writing any part of it and is still
+// usable, it reports that by returning a temporary error as is, eg: a net.Error whose Temporary() is true.
+// A wrapped error is never retried: TCPConn retries temporary errors itself before the bytes are committed and breaks
+// the connection once a write fails, and a WebsocketConn cannot be written again after a failed write.
+func isTransientWriteError(err error) bool {
+	return err != nil && err != transport.ErrClosed && transport.IsTemporaryError(err)
+}
+
+// SetControlFrameRetry sets the max times a retry-safe control frame (KEEPALIVE, CANCEL and REQUEST_N) is written again
+// after a transient write error, it waits for the backoff between retries. Zero means disabled.
+func (dc *DuplexConnection) SetControlFrameRetry(maxRetries int, backoff time.Duration) {
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	if backoff < 0 {
+		backoff = 0
+	}
+	dc.ctrlRetries = maxRetries
+	dc.ctrlBackoff = backoff
+}
+
+// sendRetrying sends a frame, it is written again on transient errors if it is retry-safe.
+// A failed flush is never retried since the frame may have been partially written.
+func (dc *DuplexConnection) sendRetrying(tp *transport.Transport, out core.WriteableFrame, flush bool) error {
+	if dc.ctrlRetries < 1 || !isRetrySafe(out) {
+		return tp.Send(out, flush)
+	}
+	err := tp.Send(out, false)
+	for retries := 0; retries < dc.ctrlRetries && isTransientWriteError(err); retries++ {
+		if dc.ctrlBackoff > 0 {
+			<-dc.clock.NewTimer(dc.ctrlBackoff).C()
+		}
+		err = tp.Send(out, false)
+	}
+	if err == nil && flush {
+		err = tp.Flush()
+	}
+	return err
+}
@@ -0,0 +1,129 @@
+package socket
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/framing"
+	"github.com/rsocket/rsocket-go/core/transport"
+	"github.com/stretchr/testify/assert"
+)
+
+type temporaryError struct{}
+
+func (temporaryError) Error() string {
+	return "temporary write error"
+}
+
+func (temporaryError) Temporary() bool {
+	return true
+}
+
+func (temporaryError) Timeout() bool {
+	return false
+}
+
+// flakyConn fails the next writes with the error.
+type flakyConn struct {
+	recordConn
+	mu       sync.Mutex
+	err      error
+	failures int
+	attempts int
+}
+
+func (f *flakyConn) Write(frame core.WriteableFrame) error {
+	f.mu.Lock()
+	f.attempts++
+	if f.failures > 0 {
+		f.failures--
+		f.mu.Unlock()
+		return f.err
+	}
+	f.mu.Unlock()
+	return f.recordConn.Write(frame)
+}
+
+func TestIsRetrySafe(t *testing.T) {
+	assert.True(t, isRetrySafe(framing.NewWriteableKeepaliveFrame(0, nil, true)))
+	assert.True(t, isRetrySafe(framing.NewWriteableCancelFrame(1)))
+	assert.True(t, isRetrySafe(framing.NewWriteableRequestNFrame(1, 1, 0)))
+	assert.False(t, isRetrySafe(framing.NewWriteablePayloadFrame(1, []byte("foo"), nil, core.FlagNext)))
+	assert.False(t, isRetrySafe(framing.NewWriteableRequestResponseFrame(1, []byte("foo"), nil, 0)))
+	assert.False(t, isRetrySafe(framing.NewWriteableMetadataPushFrame([]byte("foo"))))
+	assert.False(t, isRetrySafe(framing.NewWriteableErrorFrame(1, core.ErrorCodeApplicationError, []byte("foo"))))
+	assert.False(t, isRetrySafe(framing.NewWriteableLeaseFrame(time.Second, 1, nil)))
+}
+
+func TestDuplexConnection_ControlFrameRetry(t *testing.T) {
+	for _, it := range []struct {
+		name     string
+		frame    core.WriteableFrame
+		retries  int
+		failures int
+		err      error
+		ok       bool
+		attempts int
+	}{
+		{"keepalive", framing.NewWriteableKeepaliveFrame(0, nil, true), 3, 2, temporaryError{}, true, 3},
+		{"cancel", framing.NewWriteableCancelFrame(1), 3, 1, temporaryError{}, true, 2},
+		{"request_n", framing.NewWriteableRequestNFrame(1, 1, 0), 3, 3, temporaryError{}, true, 4},
+		{"too many failures", framing.NewWriteableRequestNFrame(1, 1, 0), 3, 4, temporaryError{}, false, 4},
+		{"permanent error", framing.NewWriteableKeepaliveFrame(0, nil, true), 3, 1, errors.New("broken"), false, 1},
+		{"wrapped error", framing.NewWriteableKeepaliveFrame(0, nil, true), 3, 1, pkgerrors.Wrap(temporaryError{}, "write frame failed"), false, 1},
+		{"data frame", framing.NewWriteablePayloadFrame(1, []byte("foo"), nil, core.FlagNext), 3, 1, temporaryError{}, false, 1},
+		{"disabled", framing.NewWriteableKeepaliveFrame(0, nil, true), 0, 1, temporaryError{}, false, 1},
+	} {
+		t.Run(it.name, func(t *testing.T) {
+			dc := NewClientDuplexConnection(1024, time.Hour)
+			dc.SetControlFrameRetry(it.retries, time.Millisecond)
+			conn := &flakyConn{err: it.err, failures: it.failures}
+			tp := transport.NewTransport(conn)
+			err := dc.send(tp, it.frame, true)
+			if it.ok {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, conn.count(it.frame.Header().StreamID(), it.frame.Header().Type()), "frame should be written once")
+			} else {
+				assert.Equal(t, it.err, err)
+				assert.Equal(t, 0, conn.count(it.frame.Header().StreamID(), it.frame.Header().Type()))
+			}
+			assert.Equal(t, it.attempts, conn.attempts)
+		})
+	}
+}
+
+// stalledNetConn fails every write by a temporary error.
+type stalledNetConn struct {
+	net.Conn
+	mu     sync.Mutex
+	writes int
+}
+
+func (s *stalledNetConn) Write(b []byte) (int, error) {
+	s.mu.Lock()
+	s.writes++
+	s.mu.Unlock()
+	return 0, temporaryError{}
+}
+
+func (s *stalledNetConn) Close() error {
+	return nil
+}
+
+func TestDuplexConnection_ControlFrameRetry_TCP(t *testing.T) {
+	dc := NewClientDuplexConnection(1024, time.Hour)
+	dc.SetControlFrameRetry(3, time.Millisecond)
+	nc := &stalledNetConn{}
+	tp := transport.NewTransport(transport.NewTCPConn(nc))
+	assert.Error(t, dc.send(tp, framing.NewWriteableKeepaliveFrame(0, nil, true), true))
+	writes := nc.writes
+	// the frame has been retried by the TCPConn before it is committed, it is broken and never written again.
+	assert.True(t, writes > 1, "temporary errors should be retried by the TCPConn")
+	assert.Error(t, dc.send(tp, framing.NewWriteableKeepaliveFrame(0, nil, true), true))
+	assert.Equal(t, writes, nc.writes, "a broken TCPConn should not be written")
+}
@@ -0,0 +1,47 @@
+package socket
+
+import (
+	"github.com/jjeffcaii/reactor-go/scheduler"
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/framing"
+	"github.com/rsocket/rsocket-go/logger"
+	"github.com/rsocket/rsocket-go/rx"
+)
+
+var (
+	rejectedSaturation  = []byte("Responder is saturated.")
+	rejectedUnavailable = []byte("Responder is unavailable.")
+)
+
+// SetDispatchScheduler sets the scheduler which subscribes responses of REQUEST_RESPONSE and REQUEST_STREAM,
+// nil means a new goroutine for every response. Responses subscribed on a scheduler by the handler already are
+// not dispatched again. If the scheduler rejects a response, eg: rx.BoundedScheduler with rx.RejectOnSaturation,
+// the request is rejected with ERROR[REJECTED]. The read loop never waits for the scheduler: if it blocks, eg:
+// rx.BoundedScheduler with rx.BlockOnSaturation, the response waits for a free worker while frames are still read,
+// so a worker waiting for REQUEST_N or CANCEL of its stream cannot deadlock the connection.
+func (dc *DuplexConnection) SetDispatchScheduler(sc scheduler.Scheduler) {
+	dc.dispatcher = sc
+}
+
+// dispatch runs the subscription of a response on the dispatch scheduler, sub receives the error if it is rejected.
+func (dc *DuplexConnection) dispatch(requestType core.FrameType, sid uint32, sub rx.Subscriber, subscribe func()) {
+	if dc.dispatcher == nil {
+		go subscribe()
+		return
+	}
+	go func() {
+		err := dc.dispatcher.Worker().Do(subscribe)
+		if err == nil {
+			return
+		}
+		if logger.IsDebugEnabled() {
+			logger.Debugf("reject %s of stream %d: %v, conn=%s\n", requestType, sid, err, dc.connID)
+		}
+		if err == core.ErrSchedulerSaturated {
+			dc.requestRejected(requestType, RejectedBySaturation)
+			sub.OnError(framing.NewWriteableErrorFrame(sid, core.ErrorCodeRejected, rejectedSaturation))
+			return
+		}
+		sub.OnError(framing.NewWriteableErrorFrame(sid, core.ErrorCodeRejected, rejectedUnavailable))
+	}()
+}
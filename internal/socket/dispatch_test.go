@@ -0,0 +1,159 @@
+package socket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/framing"
+	"github.com/rsocket/rsocket-go/core/transport"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx"
+	"github.com/rsocket/rsocket-go/rx/flux"
+	"github.com/rsocket/rsocket-go/rx/mono"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+)
+
+type rejectRequestMetrics struct {
+	saturated *atomic.Int32
+}
+
+func (r rejectRequestMetrics) OnRequestRejected(requestType core.FrameType, reason RejectReason) {
+	if reason == RejectedBySaturation {
+		r.saturated.Inc()
+	}
+}
+
+func (r rejectRequestMetrics) OnRequestCancelled(requestType core.FrameType, requester bool) {
+}
+
+func TestDuplexConnection_DispatchScheduler(t *testing.T) {
+	sc, err := rx.NewBoundedScheduler(1, rx.RejectOnSaturation)
+	require.NoError(t, err)
+	defer sc.Close()
+	// occupy the only worker.
+	release := make(chan struct{})
+	require.NoError(t, sc.Do(func() {
+		<-release
+	}))
+
+	dc := NewServerDuplexConnection(1024, nil)
+	dc.SetDispatchScheduler(sc)
+	metrics := rejectRequestMetrics{saturated: atomic.NewInt32(0)}
+	dc.SetRequestMetrics(metrics)
+	dc.SetResponder(&AbstractRSocket{
+		RR: func(request payload.Payload) mono.Mono {
+			return mono.Just(payload.NewString("foo", ""))
+		},
+		RS: func(request payload.Payload) flux.Flux {
+			return flux.Just(payload.NewString("foo", ""), payload.NewString("bar", ""))
+		},
+	})
+	conn := &recordConn{closed: make(chan struct{})}
+	defer close(conn.closed)
+	tp := transport.NewTransport(conn)
+	dc.SetTransport(tp)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = dc.LoopWrite(ctx)
+	}()
+
+	assert.NoError(t, tp.DispatchFrame(ctx, framing.NewRequestResponseFrame(1, []byte("foo"), nil, 0)))
+	assert.NoError(t, tp.DispatchFrame(ctx, framing.NewRequestStreamFrame(3, 10, []byte("foo"), nil, 0)))
+	assert.Eventually(t, func() bool {
+		return conn.count(1, core.FrameTypeError) == 1 && conn.count(3, core.FrameTypeError) == 1
+	}, 3*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(2), metrics.saturated.Load())
+	assert.Equal(t, uint64(2), sc.Rejected())
+	assert.Zero(t, dc.ActiveStreams())
+
+	close(release)
+}
+
+func TestDuplexConnection_DispatchSchedulerBlock(t *testing.T) {
+	sc, err := rx.NewBoundedScheduler(1, rx.BlockOnSaturation)
+	require.NoError(t, err)
+	defer sc.Close()
+	release := make(chan struct{})
+	require.NoError(t, sc.Do(func() {
+		<-release
+	}))
+
+	dc := NewServerDuplexConnection(1024, nil)
+	dc.SetDispatchScheduler(sc)
+	dc.SetResponder(&AbstractRSocket{
+		RR: func(request payload.Payload) mono.Mono {
+			return mono.Just(payload.NewString("foo", ""))
+		},
+	})
+	conn := &recordConn{closed: make(chan struct{})}
+	defer close(conn.closed)
+	tp := transport.NewTransport(conn)
+	dc.SetTransport(tp)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = dc.LoopWrite(ctx)
+	}()
+
+	// the read loop keeps reading frames while responses wait for a free worker.
+	dispatched := make(chan error, 1)
+	go func() {
+		if err := tp.DispatchFrame(ctx, framing.NewRequestResponseFrame(1, []byte("foo"), nil, 0)); err != nil {
+			dispatched <- err
+			return
+		}
+		dispatched <- tp.DispatchFrame(ctx, framing.NewRequestResponseFrame(3, []byte("foo"), nil, 0))
+	}()
+	select {
+	case err := <-dispatched:
+		assert.NoError(t, err)
+	case <-time.After(3 * time.Second):
+		require.FailNow(t, "dispatching should not wait for a free worker")
+	}
+	time.Sleep(100 * time.Millisecond)
+	assert.Zero(t, conn.count(1, core.FrameTypePayload))
+	assert.Zero(t, conn.count(3, core.FrameTypePayload))
+
+	close(release)
+	assert.Eventually(t, func() bool {
+		return conn.count(1, core.FrameTypePayload) == 1 && conn.count(3, core.FrameTypePayload) == 1
+	}, 3*time.Second, 10*time.Millisecond)
+	assert.Zero(t, sc.Rejected())
+}
+
+func TestDuplexConnection_DispatchSchedulerClosed(t *testing.T) {
+	sc, err := rx.NewBoundedScheduler(1, rx.RejectOnSaturation)
+	require.NoError(t, err)
+	_ = sc.Close()
+
+	dc := NewServerDuplexConnection(1024, nil)
+	dc.SetDispatchScheduler(sc)
+	metrics := rejectRequestMetrics{saturated: atomic.NewInt32(0)}
+	dc.SetRequestMetrics(metrics)
+	dc.SetResponder(&AbstractRSocket{
+		RR: func(request payload.Payload) mono.Mono {
+			return mono.Just(payload.NewString("foo", ""))
+		},
+	})
+	conn := &recordConn{closed: make(chan struct{})}
+	defer close(conn.closed)
+	tp := transport.NewTransport(conn)
+	dc.SetTransport(tp)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = dc.LoopWrite(ctx)
+	}()
+
+	assert.NoError(t, tp.DispatchFrame(ctx, framing.NewRequestResponseFrame(1, []byte("foo"), nil, 0)))
+	assert.Eventually(t, func() bool {
+		return conn.count(1, core.FrameTypeError) == 1
+	}, 3*time.Second, 10*time.Millisecond)
+	assert.Zero(t, metrics.saturated.Load(), "a closed scheduler is not saturated")
+	assert.Zero(t, sc.Rejected())
+}
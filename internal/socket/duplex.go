@@ -8,6 +8,7 @@ import (
 	"github.com/jjeffcaii/reactor-go/scheduler"
 	"github.com/pkg/errors"
 	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/clock"
 	"github.com/rsocket/rsocket-go/core/framing"
 	"github.com/rsocket/rsocket-go/core/transport"
 	"github.com/rsocket/rsocket-go/internal/bytesconv"
@@ -33,31 +34,68 @@ var errRequestFailed = errors.New("rsocket: send request failed")
 var _errRespondFailed = errors.New("rsocket: create responder failed")
 
 var (
-	unsupportedRequestStream   = []byte("Request-Stream not implemented.")
-	unsupportedRequestResponse = []byte("Request-Response not implemented.")
-	unsupportedRequestChannel  = []byte("Request-Channel not implemented.")
+	// a nil publisher returned by the responder is a bug of the handler, it is responded as an APPLICATION_ERROR.
+	nilRequestStream   = []byte("Request-Stream handler returned a nil Flux.")
+	nilRequestResponse = []byte("Request-Response handler returned a nil Mono.")
+	nilRequestChannel  = []byte("Request-Channel handler returned a nil Flux.")
+	rejectedDraining   = []byte("Server is draining.")
+	invalidInitialN    = []byte("Initial request n must be positive.")
 )
 
 // DuplexConnection represents a socket of RSocket which can be a requester or a responder.
 type DuplexConnection struct {
-	locker       sync.RWMutex
-	counter      *core.TrafficCounter
-	tp           *transport.Transport
-	outs         chan core.WriteableFrame
-	outsPriority []core.WriteableFrame
-	responder    Responder
-	messages     *map32 // key=streamID, value=callback
-	sids         StreamID
-	mtu          int
-	fragments    *map32 // key=streamID, value=Joiner
-	writeDone    chan struct{}
-	keepaliver   *Keepaliver
-	cond         sync.Cond
-	sc           scheduler.Scheduler
-	e            error
-	leases       lease.Factory
-	closed       *atomic.Bool
-	ready        *atomic.Bool
+	locker          sync.RWMutex
+	counter         *core.TrafficCounter
+	tp              *transport.Transport
+	outs            chan core.WriteableFrame
+	control         chan core.WriteableFrame // connection-control frames written ahead of outs
+	outsPriority    []core.WriteableFrame
+	ordering        FrameOrdering
+	batch           []core.WriteableFrame
+	responder       Responder
+	messages        *map32 // key=streamID, value=callback
+	sids            StreamID
+	mtu             int
+	fragments       *map32 // key=streamID, value=Joiner
+	writeDone       chan struct{}
+	connected       *readyGate
+	keepaliver      *Keepaliver
+	cond            sync.Cond
+	sc              scheduler.Scheduler
+	e               error
+	leases          lease.Factory
+	closed          *atomic.Bool
+	ready           *atomic.Bool
+	maxResponseSize int
+	draining        func() bool
+	replay          *replayBuffer
+	listener        StreamListener
+	metrics         RequestMetrics
+	keepalive       KeepaliveSettings
+	streams         *map32 // key=streamID, value=open time
+	reconnect       *reconnectQueue
+	cancelled       *map32 // key=streamID, value=struct{}, streams cancelled by remote requester
+	outLimit        *outboundLimit
+	fragMetrics     FragmentationMetrics
+	onMetaPush      func(metadata []byte)
+	channelWindow   int
+	ctrlRetries     int
+	ctrlBackoff     time.Duration
+	reassembling    atomic.Int32
+	maxReassembly   int32
+	health          *healthHub
+	stallAfter      time.Duration
+	clock           clock.Clock
+	onDrop          transport.FrameDropHandler
+	coalesceN       time.Duration
+	fair            *fairQueue // pending frames of FairOrdering
+	budget          *memoryBudget
+	dispatcher      scheduler.Scheduler
+	setup           payload.SetupPayload
+	setupAck        chan struct{}
+	setupAckData    []byte
+	onConnErr       func(err error)
+	connID          string
 }
 
 // SetError sets error for current socket.
@@ -80,6 +118,9 @@ func (dc *DuplexConnection) nextStreamID() (sid uint32) {
 	for {
 		// There's no required to check StreamID conflicts.
 		sid, firstLap = dc.sids.Next()
+		if sid == 0 {
+			continue
+		}
 		if firstLap {
 			return
 		}
@@ -99,6 +140,7 @@ func (dc *DuplexConnection) Close() error {
 	defer func() {
 		dc.messages.Destroy()
 		dc.fragments.Range(func(u uint32, i interface{}) bool {
+			dc.reassemblingReleased(i.(fragmentation.Joiner))
 			common.TryRelease(i)
 			return true
 		})
@@ -108,7 +150,11 @@ func (dc *DuplexConnection) Close() error {
 	if dc.keepaliver != nil {
 		dc.keepaliver.Stop()
 	}
+	dc.health.close()
 	_ = dc.sc.Close()
+	if dc.outLimit != nil {
+		dc.outLimit.close()
+	}
 	close(dc.outs)
 
 	dc.cond.L.Lock()
@@ -116,6 +162,7 @@ func (dc *DuplexConnection) Close() error {
 	dc.cond.L.Unlock()
 
 	<-dc.writeDone
+	dc.releaseControl()
 
 	dc.locker.Lock()
 	if tp := dc.tp; tp != nil {
@@ -161,6 +208,20 @@ func (dc *DuplexConnection) Close() error {
 
 // FireAndForget start a request of FireAndForget.
 func (dc *DuplexConnection) FireAndForget(sending payload.Payload) {
+	if dc.transportReady() {
+		dc.fireAndForget(sending)
+		return
+	}
+	// the caller may reuse the payload once it returns, so it is copied before waiting for reconnecting.
+	sending = payload.Clone(sending)
+	dc.whenReady(context.Background(), func() {
+		dc.fireAndForget(sending)
+	}, func(err error) {
+		logger.Warnf("request FireAndForget failed: %v, conn=%s\n", err, dc.connID)
+	})
+}
+
+func (dc *DuplexConnection) fireAndForget(sending payload.Payload) {
 	data := sending.Data()
 	size := core.FrameHeaderLen + len(sending.Data())
 	m, ok := sending.Metadata()
@@ -189,7 +250,16 @@ func (dc *DuplexConnection) MetadataPush(payload payload.Payload) {
 		return
 	}
 	metadata, _ := payload.Metadata()
-	dc.sendFrame(framing.NewWriteableMetadataPushFrame(metadata))
+	if dc.transportReady() {
+		dc.sendFrame(framing.NewWriteableMetadataPushFrame(metadata))
+		return
+	}
+	metadata = common.CloneBytes(metadata)
+	dc.whenReady(context.Background(), func() {
+		dc.sendFrame(framing.NewWriteableMetadataPushFrame(metadata))
+	}, func(err error) {
+		logger.Warnf("request MetadataPush failed: %v, conn=%s\n", err, dc.connID)
+	})
 }
 
 // RequestResponse start a request of RequestResponse.
@@ -198,6 +268,8 @@ func (dc *DuplexConnection) RequestResponse(req payload.Payload) (res mono.Mono)
 		res = mono.Error(errSocketClosed)
 		return
 	}
+	// the request is sent once subscribed if the transport is reconnecting.
+	deferred := !dc.transportReady()
 
 	sid := dc.nextStreamID()
 	processor := mono.CreateProcessor()
@@ -207,18 +279,75 @@ func (dc *DuplexConnection) RequestResponse(req payload.Payload) (res mono.Mono)
 	}
 
 	dc.register(sid, handler)
+	dc.streamOpen(sid, core.FrameTypeRequestResponse, true)
 
+	watcher := newContextWatcher()
 	res = processor.
+		DoOnSubscribe(func(ctx context.Context, su rx.Subscription) {
+			watcher.watch(ctx, processor.Error)
+			if deferred {
+				dc.whenReady(ctx, func() {
+					dc.sendRequestResponse(sid, req)
+				}, processor.Error)
+			}
+		}).
 		DoFinally(func(s rx.SignalType) {
+			watcher.stop()
 			if handler.cache != nil {
 				common.TryRelease(handler.cache)
 			}
 			if s == rx.SignalCancel {
 				dc.sendFrame(framing.NewWriteableCancelFrame(sid))
+				dc.requestCancelled(core.FrameTypeRequestResponse, true)
 			}
 			dc.unregister(sid)
+			dc.streamClose(sid, s)
 		})
 
+	if !deferred {
+		dc.sendRequestResponse(sid, req)
+	}
+	return
+}
+
+// RequestResponseSync start a request of RequestResponse and blocks until the response arrives.
+// It skips the reactive pipeline, the returned payload is a copy which can be retained safely.
+// A CANCEL frame will be sent if the context is done before the response.
+func (dc *DuplexConnection) RequestResponseSync(ctx context.Context, req payload.Payload) (payload.Payload, error) {
+	if dc.closed.Load() {
+		return nil, errSocketClosed
+	}
+	if err := dc.awaitTransport(ctx); err != nil {
+		return nil, err
+	}
+
+	sid := dc.nextStreamID()
+	handler := newRequestResponseSyncCallback()
+
+	dc.register(sid, handler)
+	dc.streamOpen(sid, core.FrameTypeRequestResponse, true)
+
+	dc.sendRequestResponse(sid, req)
+
+	select {
+	case <-ctx.Done():
+		dc.unregister(sid)
+		dc.sendFrame(framing.NewWriteableCancelFrame(sid))
+		dc.requestCancelled(core.FrameTypeRequestResponse, true)
+		dc.streamClose(sid, rx.SignalCancel)
+		return nil, ctx.Err()
+	case result := <-handler.done:
+		dc.unregister(sid)
+		if result.err != nil {
+			dc.streamClose(sid, rx.SignalError)
+		} else {
+			dc.streamClose(sid, rx.SignalComplete)
+		}
+		return result.res, result.err
+	}
+}
+
+func (dc *DuplexConnection) sendRequestResponse(sid uint32, req payload.Payload) {
 	data := req.Data()
 	metadata, _ := req.Metadata()
 
@@ -264,8 +393,6 @@ func (dc *DuplexConnection) RequestResponse(req payload.Payload) (res mono.Mono)
 			dc.killCallback(sid)
 		}
 	})
-
-	return
 }
 
 // RequestStream start a request of RequestStream.
@@ -281,16 +408,27 @@ func (dc *DuplexConnection) RequestStream(sending payload.Payload) (ret flux.Flu
 	dc.register(sid, requestStreamCallback{pc: pc})
 
 	requested := atomic.NewBool(false)
+	requestN := dc.newRequestNSender(sid)
 
 	// Create a queue to save those payloads to be released.
 	toBeReleased := queue.NewLKQueue()
 
+	subscribed := context.Background()
+	watcher := newContextWatcher()
 	ret = pc.
+		DoOnSubscribe(func(ctx context.Context, su rx.Subscription) {
+			subscribed = ctx
+			watcher.watch(ctx, requestStreamCallback{pc: pc}.stopWithError)
+		}).
 		DoFinally(func(sig rx.SignalType) {
+			watcher.stop()
+			requestN.stop()
 			if sig == rx.SignalCancel {
 				dc.sendFrame(framing.NewWriteableCancelFrame(sid))
+				dc.requestCancelled(core.FrameTypeRequestStream, true)
 			}
 			dc.unregister(sid)
+			dc.streamClose(sid, sig)
 			for {
 				next := toBeReleased.Dequeue()
 				if next == nil {
@@ -306,6 +444,7 @@ func (dc *DuplexConnection) RequestStream(sending payload.Payload) (ret flux.Flu
 			if _, ok := input.(common.Releasable); ok {
 				toBeReleased.Enqueue(input)
 			}
+			requestN.received()
 			return nil
 		}).
 		DoOnRequest(func(n int) {
@@ -313,39 +452,42 @@ func (dc *DuplexConnection) RequestStream(sending payload.Payload) (ret flux.Flu
 
 			// Send RequestN at first time.
 			if !requested.CAS(false, true) {
-				done := make(chan struct{})
-				frameN := framing.NewWriteableRequestNFrame(sid, n32, 0)
-				frameN.HandleDone(func() {
-					close(done)
-				})
-				if dc.sendFrame(frameN) {
-					<-done
-				}
+				requestN.request(n)
 				return
 			}
+			requestN.granted(n)
+
+			open := func() {
+				dc.streamOpen(sid, core.FrameTypeRequestStream, true)
 
-			data := sending.Data()
-			metadata, _ := sending.Metadata()
+				data := sending.Data()
+				metadata, _ := sending.Metadata()
 
-			size := framing.CalcPayloadFrameSize(data, metadata) + 4
-			if !dc.shouldSplit(size) {
-				if ok := dc.sendFrame(framing.NewWriteableRequestStreamFrame(sid, n32, data, metadata, 0)); !ok {
-					dc.killCallback(sid)
+				size := framing.CalcPayloadFrameSize(data, metadata) + 4
+				if !dc.shouldSplit(size) {
+					if ok := dc.sendFrame(framing.NewWriteableRequestStreamFrame(sid, n32, data, metadata, 0)); !ok {
+						dc.killCallback(sid)
+					}
+					return
 				}
+
+				dc.doSplitSkip(4, data, metadata, func(index int, result fragmentation.SplitResult) {
+					var f core.WriteableFrame
+					if index == 0 {
+						f = framing.NewWriteableRequestStreamFrame(sid, n32, result.Data, result.Metadata, result.Flag)
+					} else {
+						f = framing.NewWriteablePayloadFrame(sid, result.Data, result.Metadata, result.Flag|core.FlagNext)
+					}
+					if ok := dc.sendFrame(f); !ok {
+						dc.killCallback(sid)
+					}
+				})
+			}
+			if !dc.transportReady() {
+				dc.whenReady(subscribed, open, requestStreamCallback{pc: pc}.stopWithError)
 				return
 			}
-
-			dc.doSplitSkip(4, data, metadata, func(index int, result fragmentation.SplitResult) {
-				var f core.WriteableFrame
-				if index == 0 {
-					f = framing.NewWriteableRequestStreamFrame(sid, n32, result.Data, result.Metadata, result.Flag)
-				} else {
-					f = framing.NewWriteablePayloadFrame(sid, result.Data, result.Metadata, result.Flag|core.FlagNext)
-				}
-				if ok := dc.sendFrame(f); !ok {
-					dc.killCallback(sid)
-				}
-			})
+			open()
 		})
 	return
 }
@@ -370,14 +512,29 @@ func (dc *DuplexConnection) RequestChannel(sending flux.Flux) (ret flux.Flux) {
 	receiving := flux.CreateProcessor()
 
 	rcvRequested := atomic.NewBool(false)
+	sndCompleted := atomic.NewBool(false)
+	rcvDone := atomic.NewBool(false)
 
 	toBeReleased := queue.NewLKQueue()
 
-	sendResult := make(chan error)
+	// buffered, so the sending side never blocks on a cancelled channel which doesn't wait for its result.
+	sendResult := make(chan error, 1)
+
+	requestN := dc.newRequestNSender(sid)
 
+	subscribed := context.Background()
+	watcher := newContextWatcher()
 	ret = receiving.
+		DoOnSubscribe(func(ctx context.Context, su rx.Subscription) {
+			subscribed = ctx
+			watcher.watch(ctx, receiving.Error)
+		}).
 		DoFinally(func(sig rx.SignalType) {
+			watcher.stop()
+			requestN.stop()
+			cb, registered := dc.messages.Load(sid)
 			dc.unregister(sid)
+			dc.streamClose(sid, sig)
 			// release resources.
 			for {
 				next := toBeReleased.Dequeue()
@@ -386,11 +543,20 @@ func (dc *DuplexConnection) RequestChannel(sending flux.Flux) (ret flux.Flux) {
 				}
 				next.(common.Releasable).Release()
 			}
+			// both sides are cancelled, the sending side may never terminate.
+			if sig == rx.SignalCancel {
+				if sending, ok := cb.(requestChannelCallback); registered && ok {
+					sending.snd.Cancel()
+				}
+				dc.sendFrame(framing.NewWriteableCancelFrame(sid))
+				dc.requestCancelled(core.FrameTypeRequestChannel, true)
+				return
+			}
 			// process sending result
 			e, ok := <-sendResult
 			if ok {
 				dc.writeError(sid, e)
-			} else {
+			} else if sndCompleted.CAS(false, true) {
 				complete := framing.NewWriteablePayloadFrame(sid, nil, nil, core.FlagComplete)
 				done := make(chan struct{})
 				complete.HandleDone(func() {
@@ -408,52 +574,64 @@ func (dc *DuplexConnection) RequestChannel(sending flux.Flux) (ret flux.Flux) {
 			if _, ok := next.(common.Releasable); ok {
 				toBeReleased.Enqueue(next)
 			}
+			requestN.received()
 			return nil
 		}).
 		DoOnRequest(func(initN int) {
 			n := ToUint32RequestN(initN)
 			if !rcvRequested.CAS(false, true) {
-				frameN := framing.NewWriteableRequestNFrame(sid, n, 0)
-				done := make(chan struct{})
-				frameN.HandleDone(func() {
-					close(done)
-				})
-				if dc.sendFrame(frameN) {
-					<-done
-				}
+				requestN.request(initN)
 				return
 			}
-
-			sub := requestChannelSubscriber{
-				sid:          sid,
-				n:            n,
-				dc:           dc,
-				sndRequested: atomic.NewBool(false),
-				rcv:          receiving,
-				result:       sendResult,
+			requestN.granted(initN)
+
+			open := func() {
+				dc.streamOpen(sid, core.FrameTypeRequestChannel, true)
+
+				sub := requestChannelSubscriber{
+					sid:          sid,
+					n:            n,
+					dc:           dc,
+					sndRequested: atomic.NewBool(false),
+					sndCompleted: sndCompleted,
+					rcv:          receiving,
+					rcvDone:      rcvDone,
+					result:       sendResult,
+				}
+				if dc.channelWindow > 0 {
+					sub.window = newOutboundWindow(dc.channelWindow)
+				}
+				sending.SubscribeOn(scheduler.Parallel()).SubscribeWith(context.Background(), sub)
 			}
-			sending.SubscribeOn(scheduler.Parallel()).SubscribeWith(context.Background(), sub)
+			if !dc.transportReady() {
+				dc.whenReady(subscribed, open, receiving.Error)
+				return
+			}
+			open()
 		})
 	return ret
 }
 
 func (dc *DuplexConnection) onFrameRequestResponse(frame core.BufferedFrame) error {
 	// fragment
-	receiving, ok := dc.doFragment(frame.(*framing.RequestResponseFrame))
+	receiving, ok, err := dc.doFragment(frame.(*framing.RequestResponseFrame))
 	if !ok {
-		return nil
+		return err
 	}
 	return dc.respondRequestResponse(receiving)
 }
 
 func (dc *DuplexConnection) respondRequestResponse(receiving fragmentation.HeaderAndPayload) error {
+	if dc.rejectDraining(receiving) {
+		return nil
+	}
 	sid := receiving.Header().StreamID()
 
 	// execute socket handler
 	sending, err := func() (mono mono.Mono, err error) {
 		defer func() {
 			if e := recover(); e != nil {
-				logger.Errorf("respond REQUEST_RESPONSE failed: %s\n", e)
+				logger.Errorf("respond REQUEST_RESPONSE failed: %s, conn=%s\n", e, dc.connID)
 				err = _errRespondFailed
 			}
 		}()
@@ -466,52 +644,72 @@ func (dc *DuplexConnection) respondRequestResponse(receiving fragmentation.Heade
 		dc.writeError(sid, err)
 		return nil
 	}
-	// sending error with unsupported handler
+	// sending error with nil result
 	if sending == nil {
 		common.TryRelease(receiving)
-		dc.writeError(sid, framing.NewWriteableErrorFrame(sid, core.ErrorCodeApplicationError, unsupportedRequestResponse))
+		dc.writeError(sid, framing.NewWriteableErrorFrame(sid, core.ErrorCodeApplicationError, nilRequestResponse))
 		return nil
 	}
 
-	// async subscribe publisher
-	sub := borrowRequestResponseSubscriber(dc, sid, receiving)
+	dc.streamOpen(sid, core.FrameTypeRequestResponse, false)
+
+	// async subscribe publisher, so the read loop never waits for a Mono which completes later.
+	sub := borrowRequestResponseSubscriber(dc, core.FrameTypeRequestResponse, sid, receiving, nil)
+	ctx := dc.newStreamContext(sid, core.FrameTypeRequestResponse)
 	if mono.IsSubscribeAsync(sending) {
-		sending.SubscribeWith(context.Background(), sub)
+		sending.SubscribeWith(ctx, sub)
 	} else {
-		go func() {
-			sending.SubscribeWith(context.Background(), sub)
-		}()
+		dc.dispatch(core.FrameTypeRequestResponse, sid, sub, func() {
+			sending.SubscribeWith(ctx, sub)
+		})
 	}
 
 	return nil
 }
 
 func (dc *DuplexConnection) onFrameRequestChannel(input core.BufferedFrame) error {
-	receiving, ok := dc.doFragment(input.(*framing.RequestChannelFrame))
+	receiving, ok, err := dc.doFragment(input.(*framing.RequestChannelFrame))
 	if !ok {
-		return nil
+		return err
 	}
 	return dc.respondRequestChannel(receiving)
 }
 
 func (dc *DuplexConnection) respondRequestChannel(req fragmentation.HeaderAndPayload) error {
+	if dc.rejectDraining(req) {
+		return nil
+	}
 	// seek initRequestN
 	initRequestN := extractRequestStreamInitN(req)
 
 	sid := req.Header().StreamID()
+
+	// the responder never emits more than the initial request n until REQUEST_N frames arrive, zero is invalid.
+	if initRequestN < 1 {
+		common.TryRelease(req)
+		dc.writeError(sid, framing.NewWriteableErrorFrame(sid, core.ErrorCodeInvalid, invalidInitialN))
+		return nil
+	}
 	receivingProcessor := flux.CreateProcessor()
 
 	finallyRequests := atomic.NewInt32(0)
+	rcvDone := atomic.NewBool(false)
 
 	toBeReleased := queue.NewLKQueue()
 
+	requestN := dc.newRequestNSender(sid)
+
 	receiving := receivingProcessor.
 		DoFinally(func(sig rx.SignalType) {
+			requestN.stop()
 			if finallyRequests.Inc() == 2 {
 				dc.unregister(sid)
+				dc.streamClose(sid, sig)
 			}
 			if sig == rx.SignalCancel {
+				rcvDone.Store(true)
 				dc.sendFrame(framing.NewWriteableCancelFrame(sid))
+				dc.requestCancelled(core.FrameTypeRequestChannel, true)
 			}
 			for {
 				next := toBeReleased.Dequeue()
@@ -528,41 +726,36 @@ func (dc *DuplexConnection) respondRequestChannel(req fragmentation.HeaderAndPay
 			if _, ok := input.(common.Releasable); ok {
 				toBeReleased.Enqueue(input)
 			}
+			requestN.received()
 			return nil
 		}).
-		DoOnRequest(func(n int) {
-			frameN := framing.NewWriteableRequestNFrame(sid, ToUint32RequestN(n), 0)
-			done := make(chan struct{})
-			frameN.HandleDone(func() {
-				close(done)
-			})
-			if dc.sendFrame(frameN) {
-				<-done
-			}
-		}).
+		DoOnRequest(requestN.request).
 		SubscribeOn(scheduler.Parallel())
 
 	// TODO: if receiving == sending ???
 	sending, err := func() (flux flux.Flux, err error) {
 		defer func() {
 			if e := recover(); e != nil {
-				logger.Errorf("respond REQUEST_CHANNEL failed: %s\n", e)
+				logger.Errorf("respond REQUEST_CHANNEL failed: %s, conn=%s\n", e, dc.connID)
 				err = _errRespondFailed
 			}
 		}()
 		flux = dc.responder.RequestChannel(receiving)
 		if flux == nil {
-			err = framing.NewWriteableErrorFrame(sid, core.ErrorCodeApplicationError, unsupportedRequestChannel)
+			err = framing.NewWriteableErrorFrame(sid, core.ErrorCodeApplicationError, nilRequestChannel)
 		}
 		return
 	}()
 
 	if err != nil {
-		common.TryRelease(receiving)
+		// the request has not been emitted to the receiving Flux yet.
+		common.TryRelease(req)
 		dc.writeError(sid, err)
 		return nil
 	}
 
+	dc.streamOpen(sid, core.FrameTypeRequestChannel, false)
+
 	receivingProcessor.Next(req)
 
 	// Ensure registering message success before func end.
@@ -575,10 +768,11 @@ func (dc *DuplexConnection) respondRequestChannel(req fragmentation.HeaderAndPay
 			n:          initRequestN,
 			dc:         dc,
 			rcv:        receivingProcessor,
+			rcvDone:    rcvDone,
 			subscribed: subscribed,
 			calls:      finallyRequests,
 		}
-		sending.SubscribeWith(context.Background(), sub)
+		sending.SubscribeWith(dc.newStreamContext(sid, core.FrameTypeRequestChannel), sub)
 	}()
 
 	<-subscribed
@@ -589,26 +783,36 @@ func (dc *DuplexConnection) respondRequestChannel(req fragmentation.HeaderAndPay
 func (dc *DuplexConnection) respondMetadataPush(input core.BufferedFrame) (err error) {
 	defer func() {
 		if e := recover(); e != nil {
-			logger.Errorf("respond METADATA_PUSH failed: %s\n", e)
+			logger.Errorf("respond METADATA_PUSH failed: %s, conn=%s\n", e, dc.connID)
 		}
 	}()
+	if handler := dc.currentMetadataPushHandler(); handler != nil {
+		metadata, _ := input.(*framing.MetadataPushFrame).Metadata()
+		metadata = common.CloneBytes(metadata)
+		input.Release()
+		handler(metadata)
+		return
+	}
 	dc.responder.MetadataPush(input.(*framing.MetadataPushFrame))
 	return
 }
 
 func (dc *DuplexConnection) onFrameFNF(frame core.BufferedFrame) error {
-	receiving, ok := dc.doFragment(frame.(*framing.FireAndForgetFrame))
+	receiving, ok, err := dc.doFragment(frame.(*framing.FireAndForgetFrame))
 	if !ok {
-		return nil
+		return err
 	}
 	return dc.respondFNF(receiving)
 }
 
 func (dc *DuplexConnection) respondFNF(receiving fragmentation.HeaderAndPayload) (err error) {
+	if dc.rejectDraining(receiving) {
+		return
+	}
 	defer func() {
 		common.TryRelease(receiving)
 		if e := recover(); e != nil {
-			logger.Errorf("rsocket: respond FIRE_AND_FORGET failed: %s\n", e)
+			logger.Errorf("rsocket: respond FIRE_AND_FORGET failed: %s, conn=%s\n", e, dc.connID)
 		}
 	}()
 	dc.responder.FireAndForget(receiving)
@@ -616,15 +820,18 @@ func (dc *DuplexConnection) respondFNF(receiving fragmentation.HeaderAndPayload)
 }
 
 func (dc *DuplexConnection) onFrameRequestStream(frame core.BufferedFrame) error {
-	receiving, ok := dc.doFragment(frame.(*framing.RequestStreamFrame))
+	receiving, ok, err := dc.doFragment(frame.(*framing.RequestStreamFrame))
 	if !ok {
-		return nil
+		return err
 	}
 
 	return dc.respondRequestStream(receiving)
 }
 
 func (dc *DuplexConnection) respondRequestStream(receiving fragmentation.HeaderAndPayload) error {
+	if dc.rejectDraining(receiving) {
+		return nil
+	}
 	sid := receiving.Header().StreamID()
 	n := extractRequestStreamInitN(receiving)
 
@@ -632,13 +839,13 @@ func (dc *DuplexConnection) respondRequestStream(receiving fragmentation.HeaderA
 	sending, err := func() (resp flux.Flux, err error) {
 		defer func() {
 			if e := recover(); e != nil {
-				logger.Errorf("respond REQUEST_STREAM failed: %s\n", e)
+				logger.Errorf("respond REQUEST_STREAM failed: %s, conn=%s\n", e, dc.connID)
 				err = _errRespondFailed
 			}
 		}()
 		resp = dc.responder.RequestStream(receiving)
 		if resp == nil {
-			err = framing.NewWriteableErrorFrame(sid, core.ErrorCodeApplicationError, unsupportedRequestStream)
+			err = framing.NewWriteableErrorFrame(sid, core.ErrorCodeApplicationError, nilRequestStream)
 		}
 		return
 	}()
@@ -650,9 +857,28 @@ func (dc *DuplexConnection) respondRequestStream(receiving fragmentation.HeaderA
 		return nil
 	}
 
+	dc.streamOpen(sid, core.FrameTypeRequestStream, false)
+
+	// a Flux converted from a Mono is responded like REQUEST_RESPONSE, so the item and the completion share one frame.
+	if single, ok := mono.FromFlux(sending); ok {
+		sub := borrowRequestResponseSubscriber(dc, core.FrameTypeRequestStream, sid, receiving, dc.newStallDetector(sid, n))
+		ctx := dc.newStreamContext(sid, core.FrameTypeRequestStream)
+		if mono.IsSubscribeAsync(single) {
+			single.SubscribeWith(ctx, sub)
+		} else {
+			dc.dispatch(core.FrameTypeRequestStream, sid, sub, func() {
+				single.SubscribeWith(ctx, sub)
+			})
+		}
+		return nil
+	}
+
 	// async subscribe publisher
 	sub := borrowRequestStreamSubscriber(receiving, dc, sid, n)
-	sending.SubscribeOn(scheduler.Parallel()).SubscribeWith(context.Background(), sub)
+	ctx := dc.newStreamContext(sid, core.FrameTypeRequestStream)
+	dc.dispatch(core.FrameTypeRequestStream, sid, sub, func() {
+		sending.SubscribeWith(ctx, sub)
+	})
 
 	return nil
 }
@@ -677,6 +903,50 @@ func (dc *DuplexConnection) writeError(sid uint32, e error) {
 	}
 }
 
+// SetMaxResponsePayloadSize sets the max bytes of a reassembled response payload.
+// Zero means unlimited.
+func (dc *DuplexConnection) SetMaxResponsePayloadSize(size int) {
+	dc.maxResponseSize = size
+}
+
+// SetKeepaliveSettings sets keepalive settings negotiated by the SETUP frame.
+func (dc *DuplexConnection) SetKeepaliveSettings(interval, lifetime time.Duration) {
+	dc.locker.Lock()
+	dc.keepalive = KeepaliveSettings{
+		Interval:    interval,
+		MaxLifetime: lifetime,
+	}
+	dc.locker.Unlock()
+}
+
+// KeepaliveSettings returns keepalive settings negotiated by the SETUP frame.
+func (dc *DuplexConnection) KeepaliveSettings() (settings KeepaliveSettings) {
+	dc.locker.RLock()
+	settings = dc.keepalive
+	dc.locker.RUnlock()
+	return
+}
+
+// SetDraining sets a func which reports whether current socket is draining.
+// New requests will be rejected with a REJECTED error while draining, existing streams are not affected.
+func (dc *DuplexConnection) SetDraining(isDraining func() bool) {
+	dc.draining = isDraining
+}
+
+// rejectDraining returns true if the incoming request has been rejected because of draining.
+func (dc *DuplexConnection) rejectDraining(receiving fragmentation.HeaderAndPayload) bool {
+	if dc.draining == nil || !dc.draining() {
+		return false
+	}
+	h := receiving.Header()
+	common.TryRelease(receiving)
+	dc.requestRejected(h.Type(), RejectedByDraining)
+	if h.Type() != core.FrameTypeRequestFNF {
+		dc.writeError(h.StreamID(), framing.NewWriteableErrorFrame(h.StreamID(), core.ErrorCodeRejected, rejectedDraining))
+	}
+	return true
+}
+
 // SetResponder sets a responder for current socket.
 func (dc *DuplexConnection) SetResponder(responder Responder) {
 	dc.responder = responder
@@ -685,23 +955,27 @@ func (dc *DuplexConnection) SetResponder(responder Responder) {
 func (dc *DuplexConnection) onFrameKeepalive(frame core.BufferedFrame) (err error) {
 	defer frame.Release()
 	f := frame.(*framing.KeepaliveFrame)
+	if dc.replay != nil {
+		dc.replay.Ack(f.LastReceivedPosition())
+	}
 	if !f.HasFlag(core.FlagRespond) {
+		dc.setupAcked(f.Data())
+		dc.keepaliveReturned(f.Data())
 		return
-
 	}
 	// TODO: optimize, if keepalive frame support modify data.
 	data := common.CloneBytes(f.Data())
-	k := framing.NewWriteableKeepaliveFrame(f.LastReceivedPosition(), data, false)
+	k := framing.NewWriteableKeepaliveFrame(dc.counter.ReadBytes(), data, false)
 	dc.sendFrame(k)
 	return
 }
 
 func (dc *DuplexConnection) deleteFragment(sid uint32) {
-	v, ok := dc.fragments.Load(sid)
+	v, ok := dc.fragments.LoadAndDelete(sid)
 	if !ok {
 		return
 	}
-	dc.fragments.Delete(sid)
+	dc.reassemblingReleased(v.(fragmentation.Joiner))
 	common.TryRelease(v)
 }
 
@@ -713,17 +987,45 @@ func (dc *DuplexConnection) onFrameCancel(frame core.BufferedFrame) (err error)
 
 	v, ok := dc.messages.Load(sid)
 	if !ok {
-		logger.Warnf("unmatched frame CANCEL(id=%d), maybe original request has been cancelled\n", sid)
+		if logger.IsDebugEnabled() {
+			logger.Debugf("unmatched frame CANCEL(id=%d), maybe original request has been cancelled, conn=%s\n", sid, dc.connID)
+		}
+		dc.frameDropped(core.FrameTypeCancel, sid, transport.DroppedByUnmatchedStream)
 		return
 	}
 
+	// only the outbound side of a requested channel is cancelled, the stream is closed once the inbound side terminates.
+	if _, ok := v.(requestChannelCallback); !ok {
+		dc.streamClose(sid, rx.SignalCancel)
+	}
+
 	switch vv := v.(type) {
+	// The responding publisher may complete at the same time, and it may not signal any more after cancelled,
+	// so the stream is unregistered here. The request payload is left to the terminal signal, see responderTeardown.
 	case requestResponseCallbackReverse:
+		dc.requestCancelled(vv.requestType, false)
+		vv.stall.stop()
+		vv.teardown.cancel()
 		vv.su.Cancel()
+		dc.unregister(sid)
+		dc.purgeStream(sid)
 	case requestStreamCallbackReverse:
+		dc.requestCancelled(core.FrameTypeRequestStream, false)
+		vv.stall.stop()
+		vv.teardown.cancel()
 		vv.su.Cancel()
+		dc.unregister(sid)
+		dc.purgeStream(sid)
+	case respondChannelCallback:
+		dc.requestCancelled(core.FrameTypeRequestChannel, false)
+		vv.snd.Cancel()
+	case requestChannelCallback:
+		// the responder cancels the outbound side of a channel, queued payloads of the source are dropped.
+		dc.requestCancelled(core.FrameTypeRequestChannel, true)
+		vv.cancelSending()
+		dc.purgeStream(sid)
 	default:
-		panic("cannot cancel")
+		logger.Warnf("ignore frame CANCEL(id=%d) of a stream which cannot be cancelled by the peer, conn=%s\n", sid, dc.connID)
 	}
 
 	return
@@ -740,13 +1042,18 @@ func (dc *DuplexConnection) onFrameError(input core.BufferedFrame) error {
 	v, ok := dc.messages.Load(sid)
 	if !ok {
 		dc.deleteFragment(sid)
-		logger.Warnf("unmatched frame ERROR(id=%d), maybe original request has been cancelled\n", sid)
+		if logger.IsDebugEnabled() {
+			logger.Debugf("unmatched frame ERROR(id=%d), maybe original request has been cancelled, conn=%s\n", sid, dc.connID)
+		}
+		dc.frameDropped(core.FrameTypeError, sid, transport.DroppedByUnmatchedStream)
 		return nil
 	}
 
 	switch vv := v.(type) {
 	case *requestResponseCallback:
 		vv.pc.Error(err)
+	case requestResponseSyncCallback:
+		vv.offer(nil, err)
 	case requestStreamCallback:
 		vv.pc.Error(err)
 	case requestChannelCallback:
@@ -764,13 +1071,20 @@ func (dc *DuplexConnection) onFrameRequestN(input core.BufferedFrame) error {
 	v, ok := dc.messages.Load(sid)
 	if !ok {
 		dc.deleteFragment(sid)
-		logger.Warnf("unmatched frame REQUEST_N(id=%d), maybe original request has been cancelled\n", sid)
+		if logger.IsDebugEnabled() {
+			logger.Debugf("unmatched frame REQUEST_N(id=%d), maybe original request has been cancelled, conn=%s\n", sid, dc.connID)
+		}
+		dc.frameDropped(core.FrameTypeRequestN, sid, transport.DroppedByUnmatchedStream)
 		return nil
 	}
 	n := ToIntRequestN(f.N())
 	switch vv := v.(type) {
 	case requestStreamCallbackReverse:
+		vv.stall.request(n)
 		vv.su.Request(n)
+	case requestResponseCallbackReverse:
+		// a REQUEST_STREAM responded by a Mono, which has been requested unbounded already.
+		vv.stall.request(n)
 	case requestChannelCallback:
 		vv.snd.Request(n)
 	case respondChannelCallback:
@@ -779,33 +1093,74 @@ func (dc *DuplexConnection) onFrameRequestN(input core.BufferedFrame) error {
 	return nil
 }
 
-func (dc *DuplexConnection) doFragment(input fragmentation.HeaderAndPayload) (out fragmentation.HeaderAndPayload, ok bool) {
+func (dc *DuplexConnection) doFragment(input fragmentation.HeaderAndPayload) (out fragmentation.HeaderAndPayload, ok bool, err error) {
 	h := input.Header()
 	sid := h.StreamID()
 	v, exist := dc.fragments.Load(sid)
+	if !exist && !h.Flag().Check(core.FlagFollow) {
+		return input, true, nil
+	}
+	if !exist && !dc.allowReassembly(input) {
+		return
+	}
+	if ok, err = dc.reserveFragment(input); !ok {
+		return
+	}
 	if exist {
 		joiner := v.(fragmentation.Joiner)
 		ok = joiner.Push(input)
 		if ok {
-			dc.fragments.Delete(sid)
+			if _, deleted := dc.fragments.LoadAndDelete(sid); deleted {
+				dc.reassemblingReleased(joiner)
+			}
 			out = joiner
 		}
 		return
 	}
-	ok = !h.Flag().Check(core.FlagFollow)
-	if ok {
-		out = input
-		return
-	}
+	ok = false
 	dc.fragments.Store(sid, fragmentation.NewJoiner(input))
+	dc.reassemblingChanged(1)
 	return
 }
 
-func (dc *DuplexConnection) onFramePayload(frame core.BufferedFrame) error {
-	next, ok := dc.doFragment(frame.(*framing.PayloadFrame))
+// checkResponseSize returns false if the response payload being received exceeds the max size.
+// The request will be terminated with an error and a CANCEL frame will be sent to the responder.
+func (dc *DuplexConnection) checkResponseSize(input fragmentation.HeaderAndPayload) bool {
+	if dc.maxResponseSize < 1 {
+		return true
+	}
+	sid := input.Header().StreamID()
+	v, ok := dc.messages.Load(sid)
 	if !ok {
+		return true
+	}
+	switch v.(type) {
+	case *requestResponseCallback, requestResponseSyncCallback, requestStreamCallback, requestChannelCallback:
+	default:
+		return true
+	}
+	size := fragmentation.PayloadSize(input)
+	if joiner, ok := dc.fragments.Load(sid); ok {
+		size += joiner.(fragmentation.Joiner).Size()
+	}
+	if size <= dc.maxResponseSize {
+		return true
+	}
+	common.TryRelease(input)
+	dc.deleteFragment(sid)
+	dc.sendFrame(framing.NewWriteableCancelFrame(sid))
+	v.(callback).stopWithError(core.ErrResponseTooLarge)
+	return false
+}
+
+func (dc *DuplexConnection) onFramePayload(frame core.BufferedFrame) error {
+	if !dc.checkResponseSize(frame.(*framing.PayloadFrame)) {
 		return nil
 	}
+	next, ok, err := dc.doFragment(frame.(*framing.PayloadFrame))
+	if !ok {
+		return err
+	}
 	h := next.Header()
 
 	switch h.Type() {
@@ -823,14 +1178,42 @@ func (dc *DuplexConnection) onFramePayload(frame core.BufferedFrame) error {
 	v, ok := dc.messages.Load(sid)
 	if !ok {
 		common.TryRelease(next)
-		logger.Warnf("unmatched frame PAYLOAD(id=%d), maybe original request has been cancelled\n", sid)
+		if logger.IsDebugEnabled() {
+			logger.Debugf("unmatched frame PAYLOAD(id=%d), maybe original request has been cancelled, conn=%s\n", sid, dc.connID)
+		}
+		dc.frameDropped(core.FrameTypePayload, sid, transport.DroppedByUnmatchedStream)
+		return nil
+	}
+	if isReceivingDone(v) {
+		common.TryRelease(next)
+		if logger.IsDebugEnabled() {
+			logger.Debugf("drop frame PAYLOAD(id=%d) after the receiving side has been terminated, conn=%s\n", sid, dc.connID)
+		}
+		dc.frameDropped(core.FrameTypePayload, sid, transport.DroppedByTerminatedStream)
 		return nil
 	}
 
+	if h.Flag().Check(core.FlagNext) {
+		m, _ := next.Metadata()
+		dc.streamPayload(sid, true, len(next.Data())+len(m))
+	}
+
 	switch handler := v.(type) {
 	case *requestResponseCallback:
+		if isAbsentPayload(next) {
+			common.TryRelease(next)
+			handler.pc.Success(nil)
+			break
+		}
 		handler.cache = next
 		handler.pc.Success(next)
+	case requestResponseSyncCallback:
+		if isAbsentPayload(next) {
+			handler.offer(nil, nil)
+		} else {
+			handler.offer(payload.Clone(next), nil)
+		}
+		common.TryRelease(next)
 	case requestStreamCallback:
 		fg := h.Flag()
 		isNext := fg.Check(core.FlagNext)
@@ -854,6 +1237,7 @@ func (dc *DuplexConnection) onFramePayload(frame core.BufferedFrame) error {
 			if !isNext {
 				common.TryRelease(next)
 			}
+			handler.rcvDone.Store(true)
 			handler.rcv.Complete()
 		}
 	case respondChannelCallback:
@@ -866,17 +1250,31 @@ func (dc *DuplexConnection) onFramePayload(frame core.BufferedFrame) error {
 			if !isNext {
 				common.TryRelease(next)
 			}
+			handler.rcvDone.Store(true)
 			handler.rcv.Complete()
 		}
 	}
 	return nil
 }
 
+// isReceivingDone returns true if the stream is a channel whose receiving side has been terminated, late frames of
+// the peer may still arrive since the sending side is open.
+func isReceivingDone(v interface{}) bool {
+	switch cb := v.(type) {
+	case requestChannelCallback:
+		return cb.rcvDone.Load()
+	case respondChannelCallback:
+		return cb.rcvDone.Load()
+	}
+	return false
+}
+
 func (dc *DuplexConnection) clearTransport() {
 	dc.locker.Lock()
 	defer dc.locker.Unlock()
 	dc.tp = nil
 	dc.ready.Store(false)
+	dc.connected.shut()
 }
 
 func (dc *DuplexConnection) currentTransport() (tp *transport.Transport) {
@@ -888,6 +1286,8 @@ func (dc *DuplexConnection) currentTransport() (tp *transport.Transport) {
 
 // SetTransport sets a transport for current socket.
 func (dc *DuplexConnection) SetTransport(tp *transport.Transport) (ok bool) {
+	tp.SetClock(dc.clock)
+	tp.OnFrameDrop(dc.onDrop)
 	tp.Handle(transport.OnCancel, dc.onFrameCancel)
 	tp.Handle(transport.OnError, dc.onFrameError)
 	tp.Handle(transport.OnRequestN, dc.onFrameRequestN)
@@ -910,27 +1310,115 @@ func (dc *DuplexConnection) SetTransport(tp *transport.Transport) (ok bool) {
 	dc.tp = tp
 	dc.cond.Signal()
 	dc.locker.Unlock()
+	dc.connected.open()
 	return
 }
 
+// SetReconnectQueue makes requests issued while reconnecting wait for the next transport instead of failing.
+// At most maxItems requests can wait at the same time, and each one waits at most maxWait.
+func (dc *DuplexConnection) SetReconnectQueue(maxItems int, maxWait time.Duration) {
+	if maxItems < 1 || maxWait <= 0 {
+		dc.reconnect = nil
+		return
+	}
+	dc.reconnect = newReconnectQueue(maxItems, maxWait)
+}
+
+// transportReady returns true if requests can be sent without waiting for reconnecting.
+func (dc *DuplexConnection) transportReady() bool {
+	return dc.reconnect == nil || dc.ready.Load()
+}
+
+// awaitTransport blocks until the transport is ready, see SetReconnectQueue.
+func (dc *DuplexConnection) awaitTransport(ctx context.Context) error {
+	if dc.transportReady() {
+		return nil
+	}
+	return dc.reconnect.await(ctx, dc.clock, dc.connected.wait(), dc.writeDone)
+}
+
+// whenReady waits for the transport in background, then calls send, or fail if it cannot be waited for.
+// Requests issued while reconnecting use it, so neither building nor subscribing a publisher blocks the caller.
+func (dc *DuplexConnection) whenReady(ctx context.Context, send func(), fail func(err error)) {
+	go func() {
+		if err := dc.awaitTransport(ctx); err != nil {
+			fail(err)
+			return
+		}
+		send()
+	}()
+}
+
 func (dc *DuplexConnection) sendFrame(f core.WriteableFrame) (ok bool) {
+	if isControlFrame(f) && dc.sendControl(f) {
+		return true
+	}
+	if dc.outLimit != nil {
+		if f, ok = dc.outLimit.wrap(f); !ok {
+			f.Done()
+			return
+		}
+		defer dc.outLimit.sent()
+	}
 	defer func() {
-		ok = recover() == nil
+		if recover() != nil {
+			ok = false
+		}
 		if !ok {
-			f.Done()
+			// the frame may have been queued in FairOrdering mode.
+			if f = dc.dequeueFair(f); f != nil {
+				f.Done()
+			}
 		}
 	}()
-	dc.outs <- f
+	f = dc.enqueueFair(dc.budgetFrame(f))
+	ok = dc.queueOut(f)
 	return
 }
 
+// queueOut queues a frame to the writer. A frame limited by SetMaxOutboundBufferBytes is dropped once the connection
+// is closing, since the outbound queue will be closed.
+func (dc *DuplexConnection) queueOut(f core.WriteableFrame) bool {
+	if dc.outLimit == nil {
+		dc.outs <- f
+		return true
+	}
+	select {
+	case dc.outs <- f:
+		return true
+	case <-dc.outLimit.done:
+		return false
+	}
+}
+
+// isAbsentPayload returns true if a response completes without any payload, it is a blank PAYLOAD frame without NEXT flag.
+// An empty payload is sent with NEXT flag, and a response with content is accepted even if the NEXT flag is missing.
+func isAbsentPayload(p fragmentation.HeaderAndPayload) bool {
+	if p.Header().Flag().Check(core.FlagNext) || len(p.Data()) > 0 {
+		return false
+	}
+	_, ok := p.Metadata()
+	return !ok
+}
+
 func (dc *DuplexConnection) sendPayload(
 	sid uint32,
 	sending payload.Payload,
 	frameFlag core.FrameFlag,
+) {
+	dc.sendPayloadThen(sid, sending, frameFlag, nil)
+}
+
+// sendPayloadThen is like sendPayload, then will be invoked when the last frame of the payload is done.
+func (dc *DuplexConnection) sendPayloadThen(
+	sid uint32,
+	sending payload.Payload,
+	frameFlag core.FrameFlag,
+	then func(),
 ) {
 	d := sending.Data()
 	m, _ := sending.Metadata()
+	dc.streamPayload(sid, false, len(d)+len(m))
 	size := framing.CalcPayloadFrameSize(d, m)
 
 	releasable, isReleasable := sending.(common.Releasable)
@@ -938,12 +1426,22 @@ func (dc *DuplexConnection) sendPayload(
 		releasable.IncRef()
 	}
 
+	var done func()
+	if isReleasable || then != nil {
+		done = func() {
+			if isReleasable {
+				releasable.Release()
+			}
+			if then != nil {
+				then()
+			}
+		}
+	}
+
 	if !dc.shouldSplit(size) {
 		toBeSent := framing.NewWriteablePayloadFrame(sid, d, m, frameFlag)
-		if isReleasable {
-			toBeSent.HandleDone(func() {
-				releasable.Release()
-			})
+		if done != nil {
+			toBeSent.HandleDone(done)
 		}
 		dc.sendFrame(toBeSent)
 		return
@@ -959,10 +1457,8 @@ func (dc *DuplexConnection) sendPayload(
 		// lazy release at last frame
 		next := framing.NewWriteablePayloadFrame(sid, result.Data, result.Metadata, flag)
 
-		if !result.Flag.Check(core.FlagFollow) {
-			next.HandleDone(func() {
-				releasable.Release()
-			})
+		if done != nil && !result.Flag.Check(core.FlagFollow) {
+			next.HandleDone(done)
 		}
 		// TODO: error handling
 		dc.sendFrame(next)
@@ -977,11 +1473,11 @@ func (dc *DuplexConnection) drainWithKeepaliveAndLease(leaseChan <-chan lease.Le
 	select {
 	case <-dc.keepaliver.C():
 		ok = true
-		out = framing.NewWriteableKeepaliveFrame(dc.counter.ReadBytes(), nil, true)
+		out = dc.newKeepaliveFrame()
 		if tp := dc.currentTransport(); tp != nil {
-			err := tp.Send(out, true)
+			err := dc.send(tp, out, true)
 			if err != nil {
-				logger.Errorf("send keepalive frame failed: %s\n", err.Error())
+				logger.Errorf("send keepalive frame failed: %s, conn=%s\n", err.Error(), dc.connID)
 			}
 		}
 	case ls, success := <-leaseChan:
@@ -992,18 +1488,22 @@ func (dc *DuplexConnection) drainWithKeepaliveAndLease(leaseChan <-chan lease.Le
 		out = framing.NewWriteableLeaseFrame(ls.TimeToLive, ls.NumberOfRequests, ls.Metadata)
 		if tp := dc.currentTransport(); tp == nil {
 			dc.outsPriority = append(dc.outsPriority, out)
-		} else if err := tp.Send(out, true); err != nil {
-			logger.Errorf("send frame failed: %s\n", err.Error())
+		} else if err := dc.send(tp, out, true); err != nil {
+			logger.Errorf("send frame failed: %s, conn=%s\n", err.Error(), dc.connID)
 			dc.outsPriority = append(dc.outsPriority, out)
 		}
+	case out = <-dc.control:
+		ok = true
+		dc.writeNow(out)
 	case out, ok = <-dc.outs:
 		if !ok {
 			return
 		}
+		out = dc.dequeueFair(out)
 		if tp := dc.currentTransport(); tp == nil {
 			dc.outsPriority = append(dc.outsPriority, out)
-		} else if err := tp.Send(out, true); err != nil {
-			logger.Errorf("send frame failed: %s\n", err.Error())
+		} else if err := dc.send(tp, out, true); err != nil {
+			logger.Errorf("send frame failed: %s, conn=%s\n", err.Error(), dc.connID)
 			dc.outsPriority = append(dc.outsPriority, out)
 		}
 	}
@@ -1019,24 +1519,28 @@ func (dc *DuplexConnection) drainWithKeepalive() (ok bool) {
 	select {
 	case <-dc.keepaliver.C():
 		ok = true
-		out = framing.NewWriteableKeepaliveFrame(dc.counter.ReadBytes(), nil, true)
+		out = dc.newKeepaliveFrame()
 		tp := dc.tp
 		if tp == nil {
 			return
 		}
-		err := tp.Send(out, true)
+		err := dc.send(tp, out, true)
 		if err != nil {
-			logger.Errorf("send keepalive frame failed: %s\n", err.Error())
+			logger.Errorf("send keepalive frame failed: %s, conn=%s\n", err.Error(), dc.connID)
 		}
+	case out = <-dc.control:
+		ok = true
+		dc.writeNow(out)
 	case out, ok = <-dc.outs:
 		if !ok {
 			return
 		}
+		out = dc.dequeueFair(out)
 
 		if tp := dc.currentTransport(); tp == nil {
 			dc.outsPriority = append(dc.outsPriority, out)
-		} else if err := tp.Send(out, true); err != nil {
-			logger.Errorf("send frame failed: %s\n", err.Error())
+		} else if err := dc.send(tp, out, true); err != nil {
+			logger.Errorf("send frame failed: %s, conn=%s\n", err.Error(), dc.connID)
 			dc.outsPriority = append(dc.outsPriority, out)
 		}
 	}
@@ -1044,12 +1548,16 @@ func (dc *DuplexConnection) drainWithKeepalive() (ok bool) {
 }
 
 func (dc *DuplexConnection) drain(leaseChan <-chan lease.Lease) bool {
+	if dc.ordering == PerStreamOrdering {
+		return dc.drainInterleaved(leaseChan)
+	}
 	var flush bool
 	cycle := len(dc.outs)
 	if cycle < 1 {
 		cycle = 1
 	}
 	for i := 0; i < cycle; i++ {
+		dc.writeControl()
 		select {
 		case next, ok := <-leaseChan:
 			if !ok {
@@ -1058,18 +1566,22 @@ func (dc *DuplexConnection) drain(leaseChan <-chan lease.Lease) bool {
 			if dc.drainOne(framing.NewWriteableLeaseFrame(next.TimeToLive, next.NumberOfRequests, next.Metadata)) {
 				flush = true
 			}
+		case out := <-dc.control:
+			if dc.drainOne(out) {
+				flush = true
+			}
 		case out, ok := <-dc.outs:
 			if !ok {
 				return false
 			}
-			if dc.drainOne(out) {
+			if dc.drainOne(dc.dequeueFair(out)) {
 				flush = true
 			}
 		}
 	}
 	if flush {
 		if err := dc.tp.Flush(); err != nil {
-			logger.Errorf("flush failed: %v\n", err)
+			logger.Errorf("flush failed: %v, conn=%s\n", err, dc.connID)
 		}
 	}
 	return true
@@ -1081,10 +1593,10 @@ func (dc *DuplexConnection) drainOne(out core.WriteableFrame) (ok bool) {
 		dc.outsPriority = append(dc.outsPriority, out)
 		return
 	}
-	err := tp.Send(out, false)
+	err := dc.send(tp, out, false)
 	if err != nil {
 		dc.outsPriority = append(dc.outsPriority, out)
-		logger.Errorf("send frame failed: %s\n", err.Error())
+		logger.Errorf("send frame failed: %s, conn=%s\n", err.Error(), dc.connID)
 		return
 	}
 	ok = true
@@ -1108,13 +1620,13 @@ func (dc *DuplexConnection) drainOutBack() {
 	var out core.WriteableFrame
 	for i := range dc.outsPriority {
 		out = dc.outsPriority[i]
-		if err := tp.Send(out, false); err != nil {
+		if err := dc.send(tp, out, false); err != nil {
 			out.Done()
-			logger.Errorf("send frame failed: %v\n", err)
+			logger.Errorf("send frame failed: %v, conn=%s\n", err, dc.connID)
 		}
 	}
 	if err := tp.Flush(); err != nil {
-		logger.Errorf("flush failed: %v\n", err)
+		logger.Errorf("flush failed: %v, conn=%s\n", err, dc.connID)
 	}
 }
 
@@ -1136,18 +1648,7 @@ func (dc *DuplexConnection) loopWriteWithKeepaliver(ctx context.Context, leaseCh
 			// ignore
 		}
 
-		select {
-		case <-dc.keepaliver.C():
-			kf := framing.NewWriteableKeepaliveFrame(dc.counter.ReadBytes(), nil, true)
-			if tp := dc.currentTransport(); tp != nil {
-				err := tp.Send(kf, true)
-				if err != nil {
-					logger.Errorf("send keepalive frame failed: %s\n", err.Error())
-				}
-			}
-		default:
-		}
-
+		dc.writeControl()
 		dc.drainOutBack()
 		if leaseChan == nil && !dc.drainWithKeepalive() {
 			break
@@ -1165,7 +1666,14 @@ func (dc *DuplexConnection) cleanOuts() {
 
 // LoopWrite start write loop
 func (dc *DuplexConnection) LoopWrite(ctx context.Context) error {
-	defer close(dc.writeDone)
+	defer func() {
+		// the writer may exit on the closed outs while control frames are pending,
+		// eg: the ERROR frame sent right before closing the connection.
+		if dc.closed.Load() {
+			dc.writeControl()
+		}
+		close(dc.writeDone)
+	}()
 
 	var leaseChan chan lease.Lease
 	if dc.leases != nil {
@@ -1206,12 +1714,63 @@ func (dc *DuplexConnection) LoopWrite(ctx context.Context) error {
 	return nil
 }
 
+// send sends a frame and keeps a copy of it if replay is enabled.
+func (dc *DuplexConnection) send(tp *transport.Transport, out core.WriteableFrame, flush bool) error {
+	if limited, ok := out.(*limitedFrame); ok {
+		err := dc.send(tp, limited.WriteableFrame, flush)
+		if err == nil {
+			limited.release()
+		}
+		return err
+	}
+	if skip, err := dc.purge(tp, out, flush); skip {
+		return err
+	}
+	if dc.replay == nil || !out.Header().Resumable() {
+		return dc.sendRetrying(tp, out, flush)
+	}
+	raw := serializeFrame(out)
+	if err := dc.sendRetrying(tp, out, flush); err != nil {
+		return err
+	}
+	dc.replay.Append(raw)
+	return nil
+}
+
+// enableReplay keeps sent resumable frames which can be replayed after resuming.
+func (dc *DuplexConnection) enableReplay() {
+	dc.replay = newReplayBuffer()
+}
+
+// replayTo writes frames after the position to the transport.
+// It must be called before the transport is set.
+func (dc *DuplexConnection) replayTo(tp *transport.Transport, position uint64) error {
+	if dc.replay == nil {
+		return errReplayPosition
+	}
+	frames, err := dc.replay.Replay(position)
+	if err != nil {
+		return err
+	}
+	for _, next := range frames {
+		if err := tp.Send(next, false); err != nil {
+			return err
+		}
+	}
+	return tp.Flush()
+}
+
 func (dc *DuplexConnection) doSplit(data, metadata []byte, handler fragmentation.HandleSplitResult) {
-	fragmentation.Split(dc.mtu, data, metadata, handler)
+	dc.doSplitSkip(0, data, metadata, handler)
 }
 
 func (dc *DuplexConnection) doSplitSkip(skip int, data, metadata []byte, handler fragmentation.HandleSplitResult) {
-	fragmentation.SplitSkip(dc.mtu, skip, data, metadata, handler)
+	var fragments int
+	fragmentation.SplitSkip(dc.mtu, skip, data, metadata, func(index int, result fragmentation.SplitResult) {
+		fragments++
+		handler(index, result)
+	})
+	dc.fragmentedSent(fragments)
 }
 
 func (dc *DuplexConnection) shouldSplit(size int) bool {
@@ -1246,16 +1805,21 @@ func newDuplexConnection(mtu int, ka *Keepaliver, sids StreamID, leases lease.Fa
 	c := &DuplexConnection{
 		leases:     leases,
 		outs:       make(chan core.WriteableFrame, _outChanSize),
+		control:    make(chan core.WriteableFrame, _controlChanSize),
 		mtu:        mtu,
 		messages:   newMap32(),
 		sids:       sids,
 		fragments:  newMap32(),
+		cancelled:  newMap32(),
 		writeDone:  make(chan struct{}),
+		connected:  newReadyGate(),
 		counter:    core.NewTrafficCounter(),
 		keepaliver: ka,
 		sc:         scheduler.NewSingle(_schedulerSize),
 		closed:     atomic.NewBool(false),
 		ready:      atomic.NewBool(false),
+		health:     newHealthHub(),
+		clock:      clock.Real(),
 	}
 	c.cond.L = &c.locker
 	return c
@@ -0,0 +1,29 @@
+package socket
+
+// FragmentationMetrics receives events of fragmentation, it can be bridged to any metrics system.
+// A high rate of fragmented payloads usually means the MTU is too small, methods should return quickly.
+type FragmentationMetrics interface {
+	// OnFragmentedSent is invoked when a payload is split into multiple frames for sending.
+	// Fragments is the amount of frames, it can be recorded as a fragments-per-payload histogram.
+	OnFragmentedSent(fragments int)
+	// OnReassembling is invoked when the amount of received payloads being reassembled changes, it can be used as a gauge.
+	OnReassembling(inProgress int)
+}
+
+// SetFragmentationMetrics sets a receiver of fragmentation events.
+func (dc *DuplexConnection) SetFragmentationMetrics(metrics FragmentationMetrics) {
+	dc.fragMetrics = metrics
+}
+
+func (dc *DuplexConnection) fragmentedSent(fragments int) {
+	if dc.fragMetrics != nil && fragments > 1 {
+		dc.fragMetrics.OnFragmentedSent(fragments)
+	}
+}
+
+func (dc *DuplexConnection) reassemblingChanged(delta int32) {
+	n := dc.reassembling.Add(delta)
+	if dc.fragMetrics != nil {
+		dc.fragMetrics.OnReassembling(int(n))
+	}
+}
@@ -0,0 +1,76 @@
+package socket
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/framing"
+	"github.com/rsocket/rsocket-go/internal/common"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordFragmentationMetrics struct {
+	sync.Mutex
+	sent        []int
+	reassembled []int
+}
+
+func (r *recordFragmentationMetrics) OnFragmentedSent(fragments int) {
+	r.Lock()
+	r.sent = append(r.sent, fragments)
+	r.Unlock()
+}
+
+func (r *recordFragmentationMetrics) OnReassembling(inProgress int) {
+	r.Lock()
+	r.reassembled = append(r.reassembled, inProgress)
+	r.Unlock()
+}
+
+func TestDuplexConnection_FragmentationMetrics(t *testing.T) {
+	metrics := &recordFragmentationMetrics{}
+	dc := NewServerDuplexConnection(128, nil)
+	dc.SetFragmentationMetrics(metrics)
+
+	// small payload will not be fragmented.
+	dc.sendPayload(1, payload.NewString("small", ""), core.FlagNext)
+	dc.sendPayload(1, payload.NewString(strings.Repeat("x", 1000), ""), core.FlagNext)
+	frames := len(dc.outs)
+	for i := 0; i < frames; i++ {
+		(<-dc.outs).Done()
+	}
+	assert.Equal(t, []int{frames - 1}, metrics.sent)
+	assert.True(t, frames > 2)
+
+	_, ok, _ := dc.doFragment(framing.NewPayloadFrame(3, []byte("foo"), nil, core.FlagNext|core.FlagFollow))
+	assert.False(t, ok)
+	_, ok, _ = dc.doFragment(framing.NewPayloadFrame(5, []byte("foo"), nil, core.FlagNext|core.FlagFollow))
+	assert.False(t, ok)
+	joined, ok, _ := dc.doFragment(framing.NewPayloadFrame(3, []byte("bar"), nil, core.FlagNext))
+	assert.True(t, ok)
+	common.TryRelease(joined)
+	// discard the incomplete one.
+	dc.deleteFragment(5)
+	assert.Equal(t, []int{1, 2, 1, 0}, metrics.reassembled)
+}
+
+func TestDuplexConnection_FragmentWithoutCopy(t *testing.T) {
+	dc := NewServerDuplexConnection(128, nil)
+	data := []byte(strings.Repeat("x", 1000))
+	borrowed := common.CountBorrowed()
+	dc.sendPayload(1, payload.New(data, nil), core.FlagNext)
+	assert.Equal(t, borrowed, common.CountBorrowed(), "fragments should not borrow buffers")
+
+	var offset int
+	frames := len(dc.outs)
+	for i := 0; i < frames; i++ {
+		next := (<-dc.outs).(*framing.WriteablePayloadFrame)
+		assert.True(t, &data[offset] == &next.Data()[0], "fragment should reference the payload")
+		offset += len(next.Data())
+		next.Done()
+	}
+	assert.Equal(t, len(data), offset)
+}
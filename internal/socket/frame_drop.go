@@ -0,0 +1,26 @@
+package socket
+
+import (
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/transport"
+)
+
+// SetFrameDropHandler sets the handler of frames which are dropped without being handled, with the reason.
+// It is also set to the transport, so frames dropped by the transport are reported too.
+// It must be set before the connection starts.
+func (dc *DuplexConnection) SetFrameDropHandler(handler transport.FrameDropHandler) {
+	dc.onDrop = handler
+}
+
+func (dc *DuplexConnection) frameDropped(frameType core.FrameType, sid uint32, reason transport.DropReason) {
+	if dc.onDrop != nil {
+		dc.onDrop(frameType, sid, reason)
+	}
+}
+
+// leaseRejected reports a request which is rejected by the requester because no lease is available,
+// the request frame is never sent, so the stream id is zero.
+func (dc *DuplexConnection) leaseRejected(requestType core.FrameType) {
+	dc.requestRejected(requestType, RejectedByLease)
+	dc.frameDropped(requestType, 0, transport.DroppedByLease)
+}
@@ -0,0 +1,67 @@
+package socket
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/framing"
+	"github.com/rsocket/rsocket-go/core/transport"
+	"github.com/rsocket/rsocket-go/lease"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/stretchr/testify/assert"
+)
+
+type droppedFrame struct {
+	frameType core.FrameType
+	sid       uint32
+	reason    transport.DropReason
+}
+
+type dropRecorder struct {
+	sync.Mutex
+	dropped []droppedFrame
+}
+
+func (r *dropRecorder) handle(frameType core.FrameType, sid uint32, reason transport.DropReason) {
+	r.Lock()
+	r.dropped = append(r.dropped, droppedFrame{frameType, sid, reason})
+	r.Unlock()
+}
+
+func TestDuplexConnection_FrameDrop_UnmatchedStream(t *testing.T) {
+	r := &dropRecorder{}
+	dc := NewServerDuplexConnection(1024, nil)
+	dc.SetFrameDropHandler(r.handle)
+
+	assert.NoError(t, dc.onFrameCancel(framing.NewCancelFrame(1)))
+	assert.NoError(t, dc.onFrameRequestN(framing.NewRequestNFrame(3, 1, 0)))
+	assert.NoError(t, dc.onFrameError(framing.NewErrorFrame(5, core.ErrorCodeApplicationError, []byte("foo"))))
+	assert.NoError(t, dc.onFramePayload(framing.NewPayloadFrame(7, []byte("foo"), nil, core.FlagNext)))
+	assert.Equal(t, []droppedFrame{
+		{core.FrameTypeCancel, 1, transport.DroppedByUnmatchedStream},
+		{core.FrameTypeRequestN, 3, transport.DroppedByUnmatchedStream},
+		{core.FrameTypeError, 5, transport.DroppedByUnmatchedStream},
+		{core.FrameTypePayload, 7, transport.DroppedByUnmatchedStream},
+	}, r.dropped)
+}
+
+func TestBaseSocket_FrameDrop_Lease(t *testing.T) {
+	r := &dropRecorder{}
+	dc := NewClientDuplexConnection(1024, time.Hour)
+	dc.SetFrameDropHandler(r.handle)
+	bs := NewBaseSocket(dc)
+	// no lease has been received.
+	bs.refreshLease(0, 0)
+
+	bs.FireAndForget(payload.NewString("foo", ""))
+	_, err := bs.RequestResponse(payload.NewString("foo", "")).Block(context.Background())
+	assert.Equal(t, lease.ErrLeaseNotRcv, err)
+	assert.Equal(t, []droppedFrame{
+		{core.FrameTypeRequestFNF, 0, transport.DroppedByLease},
+		{core.FrameTypeRequestResponse, 0, transport.DroppedByLease},
+	}, r.dropped)
+	assert.Empty(t, dc.outs, "rejected requests should not be sent")
+}
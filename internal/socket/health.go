@@ -0,0 +1,175 @@
+package socket
+
+import (
+	"context"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/jjeffcaii/reactor-go"
+	"github.com/jjeffcaii/reactor-go/flux"
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/framing"
+	"go.uber.org/atomic"
+)
+
+// HealthState is the state of a connection observed by keepalive round-trips.
+type HealthState int8
+
+const (
+	// HealthConnected means the keepalive round-trip completed within the keepalive interval.
+	HealthConnected HealthState = iota
+	// HealthDegraded means the keepalive round-trip took longer than the keepalive interval.
+	HealthDegraded
+)
+
+func (s HealthState) String() string {
+	switch s {
+	case HealthConnected:
+		return "CONNECTED"
+	case HealthDegraded:
+		return "DEGRADED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// HealthEvent is emitted on every keepalive round-trip.
+type HealthEvent struct {
+	// RTT is the round-trip time of the KEEPALIVE frame.
+	RTT time.Duration
+	// State is the health state judged by RTT.
+	State HealthState
+}
+
+// HealthReporter reports connection health driven by keepalive frames.
+type HealthReporter interface {
+	// HealthEvents returns a Flux which emits a HealthEvent on every keepalive round-trip,
+	// it completes when the connection is closed. Every returned Flux can be subscribed only once.
+	HealthEvents() flux.Flux
+}
+
+// healthHub dispatches health events to all subscribers.
+type healthHub struct {
+	mu     sync.Mutex
+	seq    uint64
+	sinks  map[uint64]flux.Sink
+	closed bool
+}
+
+func newHealthHub() *healthHub {
+	return &healthHub{
+		sinks: make(map[uint64]flux.Sink),
+	}
+}
+
+func (h *healthHub) subscribe(sink flux.Sink) (id uint64) {
+	h.mu.Lock()
+	closed := h.closed
+	if !closed {
+		h.seq++
+		id = h.seq
+		h.sinks[id] = sink
+	}
+	h.mu.Unlock()
+	if closed {
+		sink.Complete()
+	}
+	return
+}
+
+func (h *healthHub) unsubscribe(id uint64) {
+	h.mu.Lock()
+	delete(h.sinks, id)
+	h.mu.Unlock()
+}
+
+// snapshot returns all sinks, they must be signaled without the lock because a subscriber may unsubscribe in its callbacks.
+func (h *healthHub) snapshot(closing bool) (sinks []flux.Sink) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.closed = closing
+	sinks = make([]flux.Sink, 0, len(h.sinks))
+	for _, sink := range h.sinks {
+		sinks = append(sinks, sink)
+	}
+	return
+}
+
+func (h *healthHub) emit(event HealthEvent) {
+	for _, sink := range h.snapshot(false) {
+		sink.Next(event)
+	}
+}
+
+func (h *healthHub) close() {
+	for _, sink := range h.snapshot(true) {
+		sink.Complete()
+	}
+}
+
+// HealthEvents returns a Flux of health events measured by keepalive round-trips.
+// Only the side which sends KEEPALIVE frames (the client) can measure the RTT.
+func (dc *DuplexConnection) HealthEvents() flux.Flux {
+	id := atomic.NewUint64(0)
+	return flux.
+		Create(func(ctx context.Context, sink flux.Sink) {
+			id.Store(dc.health.subscribe(sink))
+		}).
+		DoFinally(func(reactor.SignalType) {
+			dc.health.unsubscribe(id.Load())
+		})
+}
+
+// newKeepaliveFrame creates a KEEPALIVE frame which carries the sending time, the peer will echo it back.
+func (dc *DuplexConnection) newKeepaliveFrame() core.WriteableFrame {
+	return framing.NewWriteableKeepaliveFrame(dc.counter.ReadBytes(), dc.newKeepaliveData(), true)
+}
+
+// newKeepaliveData returns the data of a KEEPALIVE frame, it is the sending time.
+func (dc *DuplexConnection) newKeepaliveData() []byte {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, uint64(dc.clock.Now().UnixNano()))
+	return data
+}
+
+// keepaliveReturned emits a health event when a KEEPALIVE frame sent by current side returns.
+func (dc *DuplexConnection) keepaliveReturned(data []byte) {
+	if len(data) != 8 {
+		return
+	}
+	rtt := time.Duration(dc.clock.Now().UnixNano() - int64(binary.BigEndian.Uint64(data)))
+	if rtt < 0 {
+		return
+	}
+	state := HealthConnected
+	if interval := dc.KeepaliveSettings().Interval; interval > 0 && rtt > interval {
+		state = HealthDegraded
+	}
+	dc.health.emit(HealthEvent{
+		RTT:   rtt,
+		State: state,
+	})
+}
+
+// HealthEvents returns a Flux of health events measured by keepalive round-trips.
+func (p *BaseSocket) HealthEvents() flux.Flux {
+	return p.socket.HealthEvents()
+}
+
+// ActiveStreams returns the amount of streams in progress, both requested and responded by current side.
+func (dc *DuplexConnection) ActiveStreams() (n int) {
+	dc.messages.Range(func(uint32, interface{}) bool {
+		n++
+		return true
+	})
+	return
+}
+
+// ActiveStreams returns the amount of streams in progress.
+func (p *BaseSocket) ActiveStreams() int {
+	return p.socket.ActiveStreams()
+}
@@ -2,17 +2,20 @@ package socket
 
 import (
 	"time"
+
+	"github.com/rsocket/rsocket-go/core/clock"
 )
 
 // Keepaliver controls connection keepalive.
 type Keepaliver struct {
-	ticker *time.Ticker
-	done   chan struct{}
+	ticker   clock.Ticker
+	interval time.Duration
+	done     chan struct{}
 }
 
 // C returns ticker.C.
 func (p Keepaliver) C() <-chan time.Time {
-	return p.ticker.C
+	return p.ticker.C()
 }
 
 // Done returns done chan.
@@ -31,8 +34,14 @@ func (p Keepaliver) Stop() {
 
 // NewKeepaliver creates a new keepaliver.
 func NewKeepaliver(interval time.Duration) *Keepaliver {
+	return NewKeepaliverWithClock(interval, clock.Real())
+}
+
+// NewKeepaliverWithClock creates a new keepaliver which ticks by the Clock.
+func NewKeepaliverWithClock(interval time.Duration, c clock.Clock) *Keepaliver {
 	return &Keepaliver{
-		ticker: time.NewTicker(interval),
-		done:   make(chan struct{}),
+		ticker:   clock.OrReal(c).NewTicker(interval),
+		interval: interval,
+		done:     make(chan struct{}),
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/rsocket/rsocket-go/core/clock"
 	"github.com/rsocket/rsocket-go/internal/socket"
 	"github.com/stretchr/testify/assert"
 )
@@ -29,3 +30,23 @@ L:
 	}
 	assert.Equal(t, 10, beats, "beats should be 10")
 }
+
+func TestKeepaliver_Clock(t *testing.T) {
+	c := clock.NewFake(time.Now())
+	k := socket.NewKeepaliverWithClock(time.Hour, c)
+	defer k.Stop()
+	for i := 0; i < 3; i++ {
+		c.Advance(time.Hour)
+		select {
+		case <-k.C():
+		default:
+			assert.Fail(t, "should beat once the clock advanced")
+		}
+	}
+	c.Advance(time.Minute)
+	select {
+	case <-k.C():
+		assert.Fail(t, "should not beat before the interval")
+	default:
+	}
+}
@@ -0,0 +1,159 @@
+package socket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/framing"
+	"github.com/rsocket/rsocket-go/core/transport"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx"
+	"github.com/rsocket/rsocket-go/rx/flux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+)
+
+func newLateFrameConnection(t *testing.T) (*DuplexConnection, *dropRecorder, func()) {
+	r := &dropRecorder{}
+	dc := NewClientDuplexConnection(1024, time.Hour)
+	dc.SetFrameDropHandler(r.handle)
+	conn := &recordConn{closed: make(chan struct{})}
+	dc.SetTransport(transport.NewTransport(conn))
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		_ = dc.LoopWrite(ctx)
+	}()
+	return dc, r, func() {
+		cancel()
+		close(conn.closed)
+	}
+}
+
+func TestDuplexConnection_LatePayloadAfterComplete(t *testing.T) {
+	dc, r, stop := newLateFrameConnection(t)
+	defer stop()
+
+	nexts := atomic.NewInt32(0)
+	done := make(chan struct{})
+	dc.RequestStream(payload.NewString("foo", "")).
+		DoFinally(func(s rx.SignalType) {
+			close(done)
+		}).
+		Subscribe(context.Background(), rx.OnNext(func(input payload.Payload) error {
+			nexts.Inc()
+			return nil
+		}))
+	const sid = 1
+	assert.Eventually(t, func() bool {
+		_, ok := dc.messages.Load(uint32(sid))
+		return ok
+	}, 3*time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, dc.onFramePayload(framing.NewPayloadFrame(sid, []byte("foo"), nil, core.FlagNext|core.FlagComplete)))
+	late := framing.NewPayloadFrame(sid, []byte("bar"), nil, core.FlagNext)
+	assert.NotPanics(t, func() {
+		assert.NoError(t, dc.onFramePayload(late))
+		assert.NoError(t, dc.onFrameRequestN(framing.NewRequestNFrame(sid, 1, 0)))
+	})
+	<-done
+
+	assert.Equal(t, int32(1), nexts.Load())
+	assert.Equal(t, int32(0), late.RefCnt(), "late frame should be released")
+	_, ok := dc.messages.Load(uint32(sid))
+	assert.False(t, ok, "stream should be unregistered")
+	r.Lock()
+	defer r.Unlock()
+	assert.Equal(t, []droppedFrame{
+		{core.FrameTypePayload, sid, transport.DroppedByUnmatchedStream},
+		{core.FrameTypeRequestN, sid, transport.DroppedByUnmatchedStream},
+	}, r.dropped)
+}
+
+func TestDuplexConnection_LatePayloadAfterChannelComplete(t *testing.T) {
+	dc, r, stop := newLateFrameConnection(t)
+	defer stop()
+
+	nexts := atomic.NewInt32(0)
+	completed := make(chan struct{})
+	dc.RequestChannel(flux.Just(payload.NewString("foo", ""))).
+		DoOnComplete(func() {
+			close(completed)
+		}).
+		Subscribe(context.Background(), rx.OnNext(func(input payload.Payload) error {
+			nexts.Inc()
+			return nil
+		}))
+	const sid = 1
+	assert.Eventually(t, func() bool {
+		_, ok := dc.messages.Load(uint32(sid))
+		return ok
+	}, 3*time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, dc.onFramePayload(framing.NewPayloadFrame(sid, []byte("foo"), nil, core.FlagNext|core.FlagComplete)))
+	late := framing.NewPayloadFrame(sid, []byte("bar"), nil, core.FlagNext|core.FlagComplete)
+	assert.NotPanics(t, func() {
+		assert.NoError(t, dc.onFramePayload(late))
+	})
+	<-completed
+
+	assert.Equal(t, int32(1), nexts.Load())
+	assert.Equal(t, int32(0), late.RefCnt(), "late frame should be released")
+	r.Lock()
+	defer r.Unlock()
+	assert.Equal(t, []droppedFrame{
+		{core.FrameTypePayload, sid, transport.DroppedByUnmatchedStream},
+	}, r.dropped)
+}
+
+func TestDuplexConnection_LatePayloadAfterRespondChannelComplete(t *testing.T) {
+	r := &dropRecorder{}
+	dc := NewServerDuplexConnection(1024, nil)
+	dc.SetFrameDropHandler(r.handle)
+	nexts := atomic.NewInt32(0)
+	completed := make(chan struct{})
+	dc.SetResponder(&AbstractRSocket{
+		RC: func(requests flux.Flux) flux.Flux {
+			requests.
+				DoOnComplete(func() {
+					close(completed)
+				}).
+				Subscribe(context.Background(), rx.OnNext(func(input payload.Payload) error {
+					nexts.Inc()
+					return nil
+				}))
+			// the responder keeps its side open.
+			return flux.Create(func(ctx context.Context, sink flux.Sink) {})
+		},
+	})
+	conn := &recordConn{closed: make(chan struct{})}
+	defer close(conn.closed)
+	dc.SetTransport(transport.NewTransport(conn))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = dc.LoopWrite(ctx)
+	}()
+
+	const sid = 1
+	assert.NoError(t, dc.onFrameRequestChannel(framing.NewRequestChannelFrame(sid, 1, []byte("foo"), nil, core.FlagNext)))
+	assert.NoError(t, dc.onFramePayload(framing.NewPayloadFrame(sid, nil, nil, core.FlagComplete)))
+	<-completed
+	late := framing.NewPayloadFrame(sid, []byte("bar"), nil, core.FlagNext)
+	assert.NotPanics(t, func() {
+		assert.NoError(t, dc.onFramePayload(late))
+		assert.NoError(t, dc.onFramePayload(framing.NewPayloadFrame(sid, nil, nil, core.FlagComplete)))
+	})
+
+	assert.Equal(t, int32(1), nexts.Load())
+	assert.Equal(t, int32(0), late.RefCnt(), "late frame should be released")
+	_, ok := dc.messages.Load(uint32(sid))
+	assert.True(t, ok, "the responding side is still open")
+	r.Lock()
+	defer r.Unlock()
+	assert.Equal(t, []droppedFrame{
+		{core.FrameTypePayload, sid, transport.DroppedByTerminatedStream},
+		{core.FrameTypePayload, sid, transport.DroppedByTerminatedStream},
+	}, r.dropped)
+}
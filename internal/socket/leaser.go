@@ -3,6 +3,7 @@ package socket
 import (
 	"time"
 
+	"github.com/rsocket/rsocket-go/core/clock"
 	"github.com/rsocket/rsocket-go/lease"
 	"go.uber.org/atomic"
 )
@@ -11,13 +12,17 @@ type leaser struct {
 	deadline    *atomic.Int64
 	tickets     *atomic.Int64
 	initialized *atomic.Bool
+	received    chan struct{} // closed once the first LEASE has been received
+	clock       clock.Clock
 }
 
 func (p *leaser) refresh(deadline time.Time, tickets int64) {
 	if p != nil {
 		p.deadline.Store(deadline.UnixNano())
 		p.tickets.Store(tickets)
-		p.initialized.Store(true)
+		if p.initialized.CAS(false, true) {
+			close(p.received)
+		}
 	}
 }
 
@@ -27,7 +32,7 @@ func (p *leaser) allow() (err error) {
 	}
 	if !p.initialized.Load() {
 		err = lease.ErrLeaseNotRcv
-	} else if time.Now().UnixNano() > p.deadline.Load() {
+	} else if p.clock.Now().UnixNano() > p.deadline.Load() {
 		err = lease.ErrLeaseExpired
 	} else if p.tickets.Dec() < 0 {
 		err = lease.ErrLeaseNoMoreRequests
@@ -50,10 +55,12 @@ func (p *leaser) allow() (err error) {
 //	return
 //}
 
-func newLeaser(deadline time.Time, n int64) *leaser {
+func newLeaser(deadline time.Time, n int64, c clock.Clock) *leaser {
 	return &leaser{
+		clock:       c,
 		deadline:    atomic.NewInt64(deadline.UnixNano()),
 		tickets:     atomic.NewInt64(n),
 		initialized: atomic.NewBool(false),
+		received:    make(chan struct{}),
 	}
 }
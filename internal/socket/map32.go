@@ -44,6 +44,16 @@ func (p *map32) Delete(key uint32) {
 	p.Unlock()
 }
 
+func (p *map32) LoadAndDelete(key uint32) (v interface{}, ok bool) {
+	p.Lock()
+	v, ok = p.store[key]
+	if ok {
+		delete(p.store, key)
+	}
+	p.Unlock()
+	return
+}
+
 func newMap32() *map32 {
 	return &map32{
 		store: make(map[uint32]interface{}),
@@ -0,0 +1,135 @@
+package socket
+
+import (
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/framing"
+	"github.com/rsocket/rsocket-go/internal/common"
+	"github.com/rsocket/rsocket-go/internal/fragmentation"
+	"github.com/rsocket/rsocket-go/logger"
+	"go.uber.org/atomic"
+)
+
+var _errMemoryBudgetExceeded = []byte("Connection memory budget exceeded.")
+
+// memoryBudget sums bytes held by a connection: payloads being reassembled and frames queued but not written yet.
+type memoryBudget struct {
+	max          int64
+	closeConn    bool
+	reassembling *atomic.Int64
+	outbound     *atomic.Int64
+}
+
+// budgetedFrame is a queued frame whose bytes are counted by memoryBudget.
+type budgetedFrame struct {
+	core.WriteableFrame
+	budget   *memoryBudget
+	size     int64
+	released *atomic.Bool
+}
+
+func (b *budgetedFrame) Done() {
+	if b.released.CAS(false, true) {
+		b.budget.outbound.Sub(b.size)
+	}
+	b.WriteableFrame.Done()
+}
+
+// SetMaxConnectionMemory sets the max bytes held by the connection across all streams, zero means unlimited.
+// It sums payloads being reassembled from fragments and outbound frames queued but not written yet.
+// Once a received fragment exceeds the budget, the payload is dropped and its stream is terminated:
+// an incoming request is rejected with ERROR[REJECTED], and a requester stream fails with core.ErrMemoryBudgetExceeded
+// and sends CANCEL. If closeConn is true, the whole connection is closed with ERROR[CONNECTION_ERROR] instead.
+func (dc *DuplexConnection) SetMaxConnectionMemory(n int, closeConn bool) {
+	if n < 1 {
+		dc.budget = nil
+		return
+	}
+	dc.budget = &memoryBudget{
+		max:          int64(n),
+		closeConn:    closeConn,
+		reassembling: atomic.NewInt64(0),
+		outbound:     atomic.NewInt64(0),
+	}
+}
+
+// ConnectionMemory returns bytes held by the connection, it is always zero if no budget is set.
+func (dc *DuplexConnection) ConnectionMemory() int {
+	if dc.budget == nil {
+		return 0
+	}
+	return int(dc.budget.reassembling.Load() + dc.budget.outbound.Load())
+}
+
+// budgetFrame counts the bytes of an outbound frame until it is done.
+func (dc *DuplexConnection) budgetFrame(frame core.WriteableFrame) core.WriteableFrame {
+	size := int64(frame.Len())
+	if dc.budget == nil || size < 1 {
+		return frame
+	}
+	dc.budget.outbound.Add(size)
+	return &budgetedFrame{
+		WriteableFrame: frame,
+		budget:         dc.budget,
+		size:           size,
+		released:       atomic.NewBool(false),
+	}
+}
+
+// reassemblingReleased is invoked when a payload is not being reassembled anymore.
+func (dc *DuplexConnection) reassemblingReleased(joiner fragmentation.Joiner) {
+	dc.reassemblingChanged(-1)
+	if dc.budget != nil {
+		dc.budget.reassembling.Sub(int64(joiner.Size()))
+	}
+}
+
+// reserveFragment counts a received fragment, it returns false if the memory budget is exceeded.
+// The fragment and the payload being reassembled will be released, and the stream will be terminated.
+// A non-nil error means the connection should be closed.
+func (dc *DuplexConnection) reserveFragment(input fragmentation.HeaderAndPayload) (ok bool, err error) {
+	if dc.budget == nil {
+		return true, nil
+	}
+	size := int64(fragmentation.PayloadSize(input))
+	held := dc.budget.reassembling.Load() + dc.budget.outbound.Load()
+	if held+size <= dc.budget.max {
+		dc.budget.reassembling.Add(size)
+		return true, nil
+	}
+	sid := input.Header().StreamID()
+	logger.Warnf("connection memory budget exceeded: held=%d, fragment=%d, max=%d, stream=%d, conn=%s\n", held, size, dc.budget.max, sid, dc.connID)
+	// following fragments are PAYLOAD frames, the type of the request is the first one.
+	t := input.Header().Type()
+	if joiner, ok := dc.fragments.Load(sid); ok {
+		t = joiner.(fragmentation.Joiner).Header().Type()
+	}
+	common.TryRelease(input)
+	dc.deleteFragment(sid)
+	if dc.budget.closeConn {
+		dc.sendFrame(framing.NewWriteableErrorFrame(0, core.ErrorCodeConnectionError, _errMemoryBudgetExceeded))
+		return false, core.ErrMemoryBudgetExceeded
+	}
+	dc.terminateFragmented(sid, t, _errMemoryBudgetExceeded, core.ErrMemoryBudgetExceeded)
+	return false, nil
+}
+
+// terminateFragmented terminates a stream whose fragmented payload is dropped, t is the type of the first fragment.
+// An incoming request is rejected with ERROR[REJECTED], and a requester stream fails with err and sends CANCEL.
+func (dc *DuplexConnection) terminateFragmented(sid uint32, t core.FrameType, msg []byte, err error) {
+	v, exist := dc.messages.Load(sid)
+	if !exist {
+		// a request being reassembled, FireAndForget cannot be answered.
+		if t != core.FrameTypeRequestFNF {
+			dc.sendFrame(framing.NewWriteableErrorFrame(sid, core.ErrorCodeRejected, msg))
+		}
+		return
+	}
+	switch vv := v.(type) {
+	case *requestResponseCallback, requestResponseSyncCallback, requestStreamCallback, requestChannelCallback:
+		dc.sendFrame(framing.NewWriteableCancelFrame(sid))
+		vv.(callback).stopWithError(err)
+	case respondChannelCallback:
+		dc.sendFrame(framing.NewWriteableErrorFrame(sid, core.ErrorCodeRejected, msg))
+		vv.stopWithError(err)
+	}
+}
@@ -0,0 +1,90 @@
+package socket
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/framing"
+	"github.com/stretchr/testify/assert"
+)
+
+// nextErrorCode takes the next outbound frame which must be an ERROR frame, and returns its stream id and error code.
+// Errors of the connection are queued as control frames.
+func nextErrorCode(t *testing.T, dc *DuplexConnection) (sid uint32, code core.ErrorCode) {
+	var next core.WriteableFrame
+	select {
+	case next = <-dc.control:
+	case next = <-dc.outs:
+	}
+	defer next.Done()
+	assert.Equal(t, core.FrameTypeError, next.Header().Type())
+	b := &bytes.Buffer{}
+	_, _ = next.WriteTo(b)
+	return next.Header().StreamID(), core.ErrorCode(binary.BigEndian.Uint32(b.Bytes()[core.FrameHeaderLen:]))
+}
+
+func TestDuplexConnection_MaxConnectionMemory(t *testing.T) {
+	dc := NewServerDuplexConnection(1024, nil)
+	dc.SetMaxConnectionMemory(10, false)
+
+	_, ok, err := dc.doFragment(framing.NewFireAndForgetFrame(1, []byte("abcd"), nil, core.FlagFollow))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	_, ok, err = dc.doFragment(framing.NewPayloadFrame(1, []byte("efgh"), nil, core.FlagFollow))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 8, dc.ConnectionMemory())
+
+	// a request exceeding the budget is rejected.
+	_, ok, err = dc.doFragment(framing.NewRequestStreamFrame(3, 1, []byte("xyz"), nil, core.FlagFollow))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	sid, code := nextErrorCode(t, dc)
+	assert.Equal(t, uint32(3), sid)
+	assert.Equal(t, core.ErrorCodeRejected, code)
+	assert.Equal(t, 8, dc.ConnectionMemory())
+
+	// FireAndForget is dropped without any response.
+	_, ok, err = dc.doFragment(framing.NewPayloadFrame(1, []byte("ijk"), nil, 0))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 0, dc.ConnectionMemory())
+	assert.Empty(t, dc.outs)
+
+	// payloads without fragments are not counted.
+	joined, ok, err := dc.doFragment(framing.NewFireAndForgetFrame(5, []byte("0123456789abc"), nil, 0))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	joined.(core.BufferedFrame).Release()
+
+	// queued outbound frames are counted until they are written.
+	frame := framing.NewWriteablePayloadFrame(7, []byte("foo"), nil, core.FlagNext)
+	assert.True(t, dc.sendFrame(frame))
+	assert.Equal(t, frame.Len(), dc.ConnectionMemory())
+	_, ok, err = dc.doFragment(framing.NewRequestStreamFrame(9, 1, []byte("xy"), nil, core.FlagFollow))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	(<-dc.outs).Done()
+	sid, code = nextErrorCode(t, dc)
+	assert.Equal(t, uint32(9), sid)
+	assert.Equal(t, core.ErrorCodeRejected, code)
+	assert.Equal(t, 0, dc.ConnectionMemory())
+}
+
+func TestDuplexConnection_MaxConnectionMemoryClose(t *testing.T) {
+	dc := NewServerDuplexConnection(1024, nil)
+	dc.SetMaxConnectionMemory(4, true)
+
+	_, ok, err := dc.doFragment(framing.NewRequestStreamFrame(1, 1, []byte("abcd"), nil, core.FlagFollow))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	_, ok, err = dc.doFragment(framing.NewPayloadFrame(1, []byte("e"), nil, 0))
+	assert.Equal(t, core.ErrMemoryBudgetExceeded, err)
+	assert.False(t, ok)
+	sid, code := nextErrorCode(t, dc)
+	assert.Equal(t, uint32(0), sid)
+	assert.Equal(t, core.ErrorCodeConnectionError, code)
+	assert.Equal(t, 0, dc.ConnectionMemory())
+}
@@ -0,0 +1,17 @@
+package socket
+
+// OnMetadataPush registers a handler of METADATA_PUSH frames sent by the peer, it can be used to exchange
+// small configurations after SETUP, eg: feature flags. The metadata is a copy which can be retained safely.
+// A registered handler takes over METADATA_PUSH from the responder, nil handler restores the responder.
+func (dc *DuplexConnection) OnMetadataPush(handler func(metadata []byte)) {
+	dc.locker.Lock()
+	dc.onMetaPush = handler
+	dc.locker.Unlock()
+}
+
+func (dc *DuplexConnection) currentMetadataPushHandler() (handler func(metadata []byte)) {
+	dc.locker.RLock()
+	handler = dc.onMetaPush
+	dc.locker.RUnlock()
+	return
+}
@@ -6,7 +6,10 @@ import (
 	"github.com/pkg/errors"
 	"github.com/rsocket/rsocket-go/core"
 	"github.com/rsocket/rsocket-go/core/framing"
+	"github.com/rsocket/rsocket-go/internal/common"
 	"github.com/rsocket/rsocket-go/internal/fragmentation"
+	"github.com/rsocket/rsocket-go/lease"
+	"github.com/rsocket/rsocket-go/payload"
 	"github.com/rsocket/rsocket-go/rx"
 )
 
@@ -21,20 +24,50 @@ type SetupInfo struct {
 	Data              []byte
 	MetadataMimeType  []byte
 	Metadata          []byte
+	// PayloadFactory generates data and metadata for every SETUP frame, it takes precedence over Data and Metadata.
+	PayloadFactory func() payload.Payload
+	// Interceptor is invoked with every SETUP frame just before it is sent.
+	Interceptor func(setup *framing.WriteableSetupFrame)
+	// OnLease is invoked with every LEASE frame received, the metadata is a copy.
+	OnLease func(lease lease.Lease)
+	// Timeout is the max time to wait for the server to confirm the SETUP frame, zero means not waiting.
+	Timeout time.Duration
+}
+
+// KeepaliveSettings represents keepalive settings negotiated by the SETUP frame.
+type KeepaliveSettings struct {
+	// Interval is the time between KEEPALIVE frames sent by the client.
+	Interval time.Duration
+	// MaxLifetime is the max time without any KEEPALIVE frame before the connection is considered dead.
+	MaxLifetime time.Duration
 }
 
 func (p *SetupInfo) toFrame() core.WriteableFrame {
-	return framing.NewWriteableSetupFrame(
+	data, metadata := p.Data, p.Metadata
+	if p.PayloadFactory != nil {
+		data, metadata = nil, nil
+		if generated := p.PayloadFactory(); generated != nil {
+			data = common.CloneBytes(generated.Data())
+			if m, ok := generated.Metadata(); ok {
+				metadata = common.CloneBytes(m)
+			}
+		}
+	}
+	frame := framing.NewWriteableSetupFrame(
 		p.Version,
 		p.KeepaliveInterval,
 		p.KeepaliveLifetime,
 		p.Token,
 		p.MetadataMimeType,
 		p.DataMimeType,
-		p.Data,
-		p.Metadata,
+		data,
+		metadata,
 		p.Lease,
 	)
+	if p.Interceptor != nil {
+		p.Interceptor(frame)
+	}
+	return frame
 }
 
 // ToIntRequestN converts n to valid request n.
@@ -0,0 +1,110 @@
+package socket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/framing"
+	"github.com/rsocket/rsocket-go/core/transport"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx/flux"
+	"github.com/rsocket/rsocket-go/rx/mono"
+	"github.com/stretchr/testify/assert"
+)
+
+func (r *recordConn) flags(sid uint32, frameType core.FrameType) (flags []core.FrameFlag) {
+	r.Lock()
+	defer r.Unlock()
+	for _, h := range r.headers {
+		if h.StreamID() == sid && h.Type() == frameType {
+			flags = append(flags, h.Flag())
+		}
+	}
+	return
+}
+
+func TestDuplexConnection_RespondStreamWithMono(t *testing.T) {
+	dc := NewServerDuplexConnection(1024, nil)
+	dc.SetResponder(&AbstractRSocket{
+		RS: func(request payload.Payload) flux.Flux {
+			switch request.DataUTF8() {
+			case "mono":
+				return mono.Just(payload.NewString("foo", "")).ToFlux()
+			case "empty":
+				return mono.Empty().ToFlux()
+			default:
+				return flux.Just(payload.NewString("foo", ""))
+			}
+		},
+	})
+	conn := &recordConn{closed: make(chan struct{})}
+	defer close(conn.closed)
+	dc.SetTransport(transport.NewTransport(conn))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = dc.LoopWrite(ctx)
+	}()
+
+	for sid, data := range map[uint32]string{1: "mono", 3: "empty", 5: "flux"} {
+		assert.NoError(t, dc.onFrameRequestStream(framing.NewRequestStreamFrame(sid, 1, []byte(data), nil, 0)))
+	}
+	assert.Eventually(t, func() bool {
+		return len(conn.flags(1, core.FrameTypePayload)) == 1 &&
+			len(conn.flags(3, core.FrameTypePayload)) == 1 &&
+			len(conn.flags(5, core.FrameTypePayload)) == 2
+	}, 3*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []core.FrameFlag{core.FlagNext | core.FlagComplete}, conn.flags(1, core.FrameTypePayload),
+		"a Mono should be sent as a single NEXT+COMPLETE frame")
+	assert.Equal(t, []core.FrameFlag{core.FlagComplete}, conn.flags(3, core.FrameTypePayload))
+	assert.Equal(t, []core.FrameFlag{core.FlagNext, core.FlagComplete}, conn.flags(5, core.FrameTypePayload))
+}
+
+type cancelRequestMetrics struct {
+	cancelled chan core.FrameType
+}
+
+func (c cancelRequestMetrics) OnRequestRejected(requestType core.FrameType, reason RejectReason) {
+}
+
+func (c cancelRequestMetrics) OnRequestCancelled(requestType core.FrameType, requester bool) {
+	if !requester {
+		c.cancelled <- requestType
+	}
+}
+
+func TestDuplexConnection_RespondStreamWithMono_Cancel(t *testing.T) {
+	dc := NewServerDuplexConnection(1024, nil)
+	metrics := cancelRequestMetrics{cancelled: make(chan core.FrameType, 1)}
+	dc.SetRequestMetrics(metrics)
+	subscribed := make(chan struct{})
+	dc.SetResponder(&AbstractRSocket{
+		RS: func(request payload.Payload) flux.Flux {
+			return mono.Create(func(ctx context.Context, sink mono.Sink) {
+				// never signals
+				close(subscribed)
+			}).ToFlux()
+		},
+	})
+	conn := &recordConn{closed: make(chan struct{})}
+	defer close(conn.closed)
+	dc.SetTransport(transport.NewTransport(conn))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = dc.LoopWrite(ctx)
+	}()
+
+	assert.NoError(t, dc.onFrameRequestStream(framing.NewRequestStreamFrame(1, 1, []byte("mono"), nil, 0)))
+	<-subscribed
+	assert.NoError(t, dc.onFrameCancel(framing.NewCancelFrame(1)))
+	select {
+	case requestType := <-metrics.cancelled:
+		assert.Equal(t, core.FrameTypeRequestStream, requestType)
+	case <-time.After(3 * time.Second):
+		assert.Fail(t, "cancel should be counted")
+	}
+	assert.Zero(t, dc.ActiveStreams())
+}
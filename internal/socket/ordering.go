@@ -0,0 +1,217 @@
+package socket
+
+import (
+	"sync"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/framing"
+	"github.com/rsocket/rsocket-go/lease"
+	"github.com/rsocket/rsocket-go/logger"
+)
+
+// FrameOrdering controls the order in which outbound frames of different streams are written.
+type FrameOrdering int8
+
+// All frame orderings
+const (
+	// StrictOrdering writes frames exactly in the order they are emitted, across all streams.
+	// A large payload which is split into many fragments delays frames of other streams emitted after it.
+	StrictOrdering FrameOrdering = iota
+	// PerStreamOrdering keeps the order of frames in every stream, but interleaves frames of different streams
+	// in round-robin within every write batch, so small responses are not blocked behind bulk streams.
+	// Frames of a batch are still coalesced into one flush, the reordering costs a little CPU per batch.
+	PerStreamOrdering
+	// FairOrdering keeps the order of frames in every stream, and writes pending frames of different streams
+	// in round-robin across batches, so a flooding stream cannot starve the others by filling the outbound queue.
+	// Frames of the connection (stream 0) are written first. It costs a lock per frame.
+	FairOrdering
+)
+
+func (o FrameOrdering) String() string {
+	switch o {
+	case StrictOrdering:
+		return "STRICT"
+	case PerStreamOrdering:
+		return "PER_STREAM"
+	case FairOrdering:
+		return "FAIR"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// SetFrameOrdering sets the order of outbound frames across streams, default is StrictOrdering.
+// It must be set before the connection starts.
+func (dc *DuplexConnection) SetFrameOrdering(ordering FrameOrdering) {
+	dc.ordering = ordering
+	if ordering == FairOrdering {
+		dc.fair = newFairQueue()
+	} else {
+		dc.fair = nil
+	}
+}
+
+// drainInterleaved is like drain, but frames of a batch are interleaved by stream before written.
+func (dc *DuplexConnection) drainInterleaved(leaseChan <-chan lease.Lease) (alive bool) {
+	cycle := len(dc.outs)
+	if cycle < 1 {
+		cycle = 1
+	}
+	batch := dc.batch[:0]
+	alive = true
+Loop:
+	for i := 0; i < cycle; i++ {
+		select {
+		case next, ok := <-leaseChan:
+			if !ok {
+				alive = false
+				break Loop
+			}
+			batch = append(batch, framing.NewWriteableLeaseFrame(next.TimeToLive, next.NumberOfRequests, next.Metadata))
+		case out := <-dc.control:
+			batch = append(batch, out)
+		case out, ok := <-dc.outs:
+			if !ok {
+				alive = false
+				break Loop
+			}
+			batch = append(batch, dc.dequeueFair(out))
+		}
+	}
+	var flush bool
+	for _, out := range interleaveFrames(batch) {
+		dc.writeControl()
+		if dc.drainOne(out) {
+			flush = true
+		}
+	}
+	for i := range batch {
+		batch[i] = nil
+	}
+	dc.batch = batch[:0]
+	if flush {
+		if err := dc.tp.Flush(); err != nil {
+			logger.Errorf("flush failed: %v, conn=%s\n", err, dc.connID)
+		}
+	}
+	return
+}
+
+// interleaveFrames reorders frames in round-robin by stream, the order of frames in a stream is kept.
+// Frames of the connection (stream 0) are moved to the front.
+func interleaveFrames(frames []core.WriteableFrame) []core.WriteableFrame {
+	if len(frames) < 3 {
+		return frames
+	}
+	var (
+		sids    []uint32
+		streams = make(map[uint32][]core.WriteableFrame)
+		result  = make([]core.WriteableFrame, 0, len(frames))
+	)
+	for _, it := range frames {
+		sid := it.Header().StreamID()
+		if sid == 0 {
+			result = append(result, it)
+			continue
+		}
+		if _, ok := streams[sid]; !ok {
+			sids = append(sids, sid)
+		}
+		streams[sid] = append(streams[sid], it)
+	}
+	for len(result) < len(frames) {
+		for _, sid := range sids {
+			if pending := streams[sid]; len(pending) > 0 {
+				result = append(result, pending[0])
+				streams[sid] = pending[1:]
+			}
+		}
+	}
+	return result
+}
+
+// _fairToken is sent through the outbound channel instead of frames in FairOrdering mode,
+// every token is exchanged for the next frame picked by the fairQueue, so the channel still limits pending frames.
+var _fairToken core.WriteableFrame = &fairToken{}
+
+type fairToken struct {
+	core.WriteableFrame
+}
+
+// enqueueFair queues the frame if FairOrdering is enabled, and returns the token which should be sent instead.
+func (dc *DuplexConnection) enqueueFair(f core.WriteableFrame) core.WriteableFrame {
+	if dc.fair == nil {
+		return f
+	}
+	dc.fair.push(f)
+	return _fairToken
+}
+
+// dequeueFair exchanges a token received from the outbound channel for the next frame.
+func (dc *DuplexConnection) dequeueFair(out core.WriteableFrame) core.WriteableFrame {
+	if out != _fairToken {
+		return out
+	}
+	return dc.fair.pop()
+}
+
+// fairQueue keeps pending frames of every stream, and pops them in round-robin by stream.
+type fairQueue struct {
+	mu      sync.Mutex
+	control []core.WriteableFrame // frames of stream 0
+	sids    []uint32              // streams which have pending frames, in round-robin order
+	cursor  int
+	streams map[uint32][]core.WriteableFrame
+}
+
+func newFairQueue() *fairQueue {
+	return &fairQueue{
+		streams: make(map[uint32][]core.WriteableFrame),
+	}
+}
+
+func (q *fairQueue) push(f core.WriteableFrame) {
+	sid := f.Header().StreamID()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if sid == 0 {
+		q.control = append(q.control, f)
+		return
+	}
+	pending, ok := q.streams[sid]
+	if !ok {
+		q.sids = append(q.sids, sid)
+	}
+	q.streams[sid] = append(pending, f)
+}
+
+// pop returns the next frame, or nil if no frame is pending.
+func (q *fairQueue) pop() (f core.WriteableFrame) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.control) > 0 {
+		f = q.control[0]
+		q.control[0] = nil
+		q.control = q.control[1:]
+		return
+	}
+	if len(q.sids) < 1 {
+		return
+	}
+	if q.cursor >= len(q.sids) {
+		q.cursor = 0
+	}
+	sid := q.sids[q.cursor]
+	pending := q.streams[sid]
+	f = pending[0]
+	pending[0] = nil
+	if len(pending) > 1 {
+		q.streams[sid] = pending[1:]
+		q.cursor++
+		return
+	}
+	// the stream is drained, the cursor points to the next stream after removing it.
+	delete(q.streams, sid)
+	q.sids = append(q.sids[:q.cursor], q.sids[q.cursor+1:]...)
+	return
+}
@@ -0,0 +1,164 @@
+package socket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/framing"
+	"github.com/rsocket/rsocket-go/core/transport"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeOrdered(t *testing.T, ordering FrameOrdering, frames []core.WriteableFrame) []uint32 {
+	dc := NewServerDuplexConnection(1024, nil)
+	dc.SetFrameOrdering(ordering)
+	for _, it := range frames {
+		dc.outs <- it
+	}
+	conn := &recordConn{closed: make(chan struct{})}
+	defer close(conn.closed)
+	dc.SetTransport(transport.NewTransport(conn))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = dc.LoopWrite(ctx)
+	}()
+
+	assert.Eventually(t, func() bool {
+		conn.Lock()
+		defer conn.Unlock()
+		return len(conn.headers) == len(frames)
+	}, 3*time.Second, 10*time.Millisecond)
+
+	conn.Lock()
+	defer conn.Unlock()
+	sids := make([]uint32, 0, len(conn.headers))
+	for _, h := range conn.headers {
+		sids = append(sids, h.StreamID())
+	}
+	return sids
+}
+
+func bulkFrames() (frames []core.WriteableFrame) {
+	// a fragmented bulk payload of stream 1, then small payloads of stream 3 and 5.
+	for i := 0; i < 4; i++ {
+		frames = append(frames, framing.NewWriteablePayloadFrame(1, []byte{byte(i)}, nil, core.FlagNext|core.FlagFollow))
+	}
+	frames = append(frames, framing.NewWriteablePayloadFrame(1, []byte{4}, nil, core.FlagNext))
+	frames = append(frames, framing.NewWriteablePayloadFrame(3, []byte("x"), nil, core.FlagNext|core.FlagComplete))
+	frames = append(frames, framing.NewWriteableKeepaliveFrame(0, nil, false))
+	frames = append(frames, framing.NewWriteablePayloadFrame(5, []byte("y"), nil, core.FlagNext|core.FlagComplete))
+	return
+}
+
+func TestDuplexConnection_StrictOrdering(t *testing.T) {
+	sids := writeOrdered(t, StrictOrdering, bulkFrames())
+	assert.Equal(t, []uint32{1, 1, 1, 1, 1, 3, 0, 5}, sids)
+}
+
+func TestDuplexConnection_PerStreamOrdering(t *testing.T) {
+	sids := writeOrdered(t, PerStreamOrdering, bulkFrames())
+	assert.Equal(t, []uint32{0, 1, 3, 5, 1, 1, 1, 1}, sids)
+}
+
+func TestInterleaveFrames(t *testing.T) {
+	var frames []core.WriteableFrame
+	for i := 0; i < 3; i++ {
+		frames = append(frames, framing.NewWriteablePayloadFrame(7, []byte{byte(i)}, nil, core.FlagNext))
+	}
+	for i := 0; i < 2; i++ {
+		frames = append(frames, framing.NewWriteablePayloadFrame(9, []byte{byte(i)}, nil, core.FlagNext))
+	}
+	result := interleaveFrames(frames)
+	assert.Len(t, result, len(frames))
+	expect := []core.WriteableFrame{frames[0], frames[3], frames[1], frames[4], frames[2]}
+	for i := range expect {
+		assert.True(t, expect[i] == result[i], "bad frame at %d", i)
+	}
+	for _, it := range frames {
+		it.Done()
+	}
+}
+
+func TestFrameOrdering_String(t *testing.T) {
+	assert.Equal(t, "STRICT", StrictOrdering.String())
+	assert.Equal(t, "PER_STREAM", PerStreamOrdering.String())
+	assert.Equal(t, "FAIR", FairOrdering.String())
+	assert.Equal(t, "UNKNOWN", FrameOrdering(-1).String())
+}
+
+func TestDuplexConnection_FairOrdering(t *testing.T) {
+	const flood, sparse = 200, 5
+	dc := NewServerDuplexConnection(1024, nil)
+	dc.SetFrameOrdering(FairOrdering)
+
+	// the flooding stream fills the outbound queue before the sparse one emits.
+	go func() {
+		for i := 0; i < flood; i++ {
+			dc.sendFrame(framing.NewWriteablePayloadFrame(1, []byte{byte(i)}, nil, core.FlagNext))
+		}
+	}()
+	assert.Eventually(t, func() bool {
+		return len(dc.outs) == cap(dc.outs)
+	}, 3*time.Second, time.Millisecond)
+	for i := 0; i < sparse; i++ {
+		go dc.sendFrame(framing.NewWriteablePayloadFrame(3, []byte("x"), nil, core.FlagNext))
+	}
+	assert.Eventually(t, func() bool {
+		dc.fair.mu.Lock()
+		defer dc.fair.mu.Unlock()
+		return len(dc.fair.streams[3]) == sparse
+	}, 3*time.Second, time.Millisecond)
+
+	conn := &recordConn{closed: make(chan struct{})}
+	defer close(conn.closed)
+	dc.SetTransport(transport.NewTransport(conn))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = dc.LoopWrite(ctx)
+	}()
+	assert.Eventually(t, func() bool {
+		conn.Lock()
+		defer conn.Unlock()
+		return len(conn.headers) == flood+sparse
+	}, 3*time.Second, 10*time.Millisecond)
+
+	conn.Lock()
+	defer conn.Unlock()
+	var positions []int
+	for i, h := range conn.headers {
+		if h.StreamID() == 3 {
+			positions = append(positions, i)
+		}
+	}
+	assert.Len(t, positions, sparse)
+	// frames of both streams are written alternately, the sparse one is not queued behind the flood.
+	assert.True(t, positions[sparse-1] <= sparse*2, "sparse stream is starved: %v", positions)
+}
+
+func TestFairQueue(t *testing.T) {
+	q := newFairQueue()
+	assert.Nil(t, q.pop())
+	var frames []core.WriteableFrame
+	for i := 0; i < 3; i++ {
+		frames = append(frames, framing.NewWriteablePayloadFrame(7, []byte{byte(i)}, nil, core.FlagNext))
+	}
+	frames = append(frames, framing.NewWriteablePayloadFrame(9, []byte{0}, nil, core.FlagNext))
+	frames = append(frames, framing.NewWriteableKeepaliveFrame(0, nil, false))
+	for _, it := range frames {
+		q.push(it)
+	}
+	expect := []core.WriteableFrame{frames[4], frames[0], frames[3], frames[1], frames[2]}
+	for i := range expect {
+		assert.True(t, expect[i] == q.pop(), "bad frame at %d", i)
+	}
+	assert.Nil(t, q.pop())
+	assert.Empty(t, q.streams)
+	for _, it := range frames {
+		it.Done()
+	}
+}
@@ -0,0 +1,12 @@
+package socket
+
+// OutboundQueueDepth returns the amount of outbound frames queued but not taken by the writer yet, including pending
+// connection-control frames. A growing depth indicates that the peer (or the network) cannot keep up with the writer.
+func (dc *DuplexConnection) OutboundQueueDepth() int {
+	return len(dc.outs) + len(dc.control)
+}
+
+// OutboundQueueDepth returns the amount of outbound frames queued but not written yet.
+func (p *BaseSocket) OutboundQueueDepth() int {
+	return p.socket.OutboundQueueDepth()
+}
@@ -0,0 +1,37 @@
+package socket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/framing"
+	"github.com/rsocket/rsocket-go/core/transport"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDuplexConnection_OutboundQueueDepth(t *testing.T) {
+	dc := NewClientDuplexConnection(1024, time.Hour)
+	assert.Equal(t, 0, dc.OutboundQueueDepth())
+
+	// frames are queued until the transport is set.
+	for i := 0; i < 3; i++ {
+		assert.True(t, dc.sendFrame(framing.NewWriteablePayloadFrame(1, []byte("foo"), nil, core.FlagNext)))
+	}
+	assert.True(t, dc.sendFrame(framing.NewWriteableKeepaliveFrame(0, nil, true)))
+	assert.Equal(t, 4, dc.OutboundQueueDepth())
+
+	conn := &recordConn{closed: make(chan struct{})}
+	defer close(conn.closed)
+	dc.SetTransport(transport.NewTransport(conn))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = dc.LoopWrite(ctx)
+	}()
+	assert.Eventually(t, func() bool {
+		return conn.count(1, core.FrameTypePayload) == 3
+	}, 3*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 0, dc.OutboundQueueDepth())
+}
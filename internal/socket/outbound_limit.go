@@ -0,0 +1,119 @@
+package socket
+
+import (
+	"sync"
+
+	"github.com/rsocket/rsocket-go/core"
+	"go.uber.org/atomic"
+)
+
+// outboundLimit caps total bytes of frames which are queued but not written to the transport yet.
+// Producers will be blocked once the limit is exceeded, eg: the peer stops reading.
+type outboundLimit struct {
+	max     int
+	size    int
+	sending int // frames which have been admitted but not queued yet
+	closed  bool
+	done    chan struct{}
+	cond    *sync.Cond
+}
+
+// limitedFrame is a queued frame whose bytes are counted by outboundLimit.
+type limitedFrame struct {
+	core.WriteableFrame
+	limit    *outboundLimit
+	size     int
+	released *atomic.Bool
+}
+
+func (l *limitedFrame) Done() {
+	l.release()
+	l.WriteableFrame.Done()
+}
+
+func (l *limitedFrame) release() {
+	if l.released.CAS(false, true) {
+		l.limit.release(l.size)
+	}
+}
+
+func newOutboundLimit(max int) *outboundLimit {
+	return &outboundLimit{
+		max:  max,
+		done: make(chan struct{}),
+		cond: sync.NewCond(&sync.Mutex{}),
+	}
+}
+
+// wrap blocks until the frame can be queued, it returns false if the limit has been closed.
+// A frame larger than the limit is allowed only when nothing is queued.
+// If it returns true, sent must be called once the frame has been queued or dropped.
+func (o *outboundLimit) wrap(frame core.WriteableFrame) (core.WriteableFrame, bool) {
+	size := frame.Len()
+	o.cond.L.Lock()
+	defer o.cond.L.Unlock()
+	for !o.closed && size > 0 && o.size > 0 && o.size+size > o.max {
+		o.cond.Wait()
+	}
+	if o.closed {
+		return frame, false
+	}
+	o.sending++
+	if size < 1 {
+		return frame, true
+	}
+	o.size += size
+	return &limitedFrame{
+		WriteableFrame: frame,
+		limit:          o,
+		size:           size,
+		released:       atomic.NewBool(false),
+	}, true
+}
+
+func (o *outboundLimit) release(size int) {
+	o.cond.L.Lock()
+	o.size -= size
+	o.cond.L.Unlock()
+	o.cond.Broadcast()
+}
+
+func (o *outboundLimit) buffered() (size int) {
+	o.cond.L.Lock()
+	size = o.size
+	o.cond.L.Unlock()
+	return
+}
+
+// sent marks a frame admitted by wrap as queued or dropped.
+func (o *outboundLimit) sent() {
+	o.cond.L.Lock()
+	o.sending--
+	o.cond.L.Unlock()
+	o.cond.Broadcast()
+}
+
+// close wakes up blocked producers and drops frames which are being queued, then it waits until no producer
+// is queueing, so the outbound queue can be closed safely.
+func (o *outboundLimit) close() {
+	o.cond.L.Lock()
+	defer o.cond.L.Unlock()
+	if !o.closed {
+		o.closed = true
+		close(o.done)
+		o.cond.Broadcast()
+	}
+	for o.sending > 0 {
+		o.cond.Wait()
+	}
+}
+
+// SetMaxOutboundBufferBytes sets the max bytes of queued outbound frames, zero means unlimited.
+// Requests and responses will be blocked when the limit is exceeded until queued frames are written.
+func (dc *DuplexConnection) SetMaxOutboundBufferBytes(n int) {
+	if n < 1 {
+		dc.outLimit = nil
+		return
+	}
+	dc.outLimit = newOutboundLimit(n)
+}
@@ -0,0 +1,90 @@
+package socket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/transport"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+)
+
+// stallConn simulates a peer which does not read: writes block until resumed.
+type stallConn struct {
+	recordConn
+	resume chan struct{}
+}
+
+func (s *stallConn) Write(frame core.WriteableFrame) error {
+	<-s.resume
+	return s.recordConn.Write(frame)
+}
+
+func TestDuplexConnection_MaxOutboundBufferBytes(t *testing.T) {
+	const total, limit = 50, 4096
+	dc := NewServerDuplexConnection(16*1024, nil)
+	dc.SetMaxOutboundBufferBytes(limit)
+
+	conn := &stallConn{
+		recordConn: recordConn{closed: make(chan struct{})},
+		resume:     make(chan struct{}),
+	}
+	defer close(conn.closed)
+	dc.SetTransport(transport.NewTransport(conn))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = dc.LoopWrite(ctx)
+	}()
+
+	sent := atomic.NewInt32(0)
+	data := make([]byte, 1024)
+	go func() {
+		for i := 0; i < total; i++ {
+			dc.sendPayload(1, payload.New(data, nil), core.FlagNext)
+			sent.Inc()
+		}
+	}()
+
+	time.Sleep(200 * time.Millisecond)
+	assert.True(t, sent.Load() < total, "producer should be throttled")
+	assert.True(t, dc.outLimit.buffered() <= limit, "buffered bytes should not exceed the limit")
+
+	close(conn.resume)
+	assert.Eventually(t, func() bool {
+		return sent.Load() == total && conn.count(1, core.FrameTypePayload) == total
+	}, 3*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 0, dc.outLimit.buffered())
+}
+
+func TestDuplexConnection_MaxOutboundBufferBytes_Close(t *testing.T) {
+	dc := NewServerDuplexConnection(16*1024, nil)
+	dc.SetMaxOutboundBufferBytes(1024)
+	go func() {
+		_ = dc.LoopWrite(context.Background())
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// no transport, the second frame will be blocked until the connection is closed.
+		for i := 0; i < 2; i++ {
+			dc.sendPayload(1, payload.New(make([]byte, 1024), nil), core.FlagNext)
+		}
+	}()
+	select {
+	case <-done:
+		assert.FailNow(t, "producer should be blocked")
+	case <-time.After(100 * time.Millisecond):
+	}
+	_ = dc.Close()
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		assert.FailNow(t, "producer should be woken up after closing")
+	}
+}
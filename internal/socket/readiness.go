@@ -0,0 +1,61 @@
+package socket
+
+import (
+	"context"
+	"sync"
+)
+
+// readyGate can be awaited until it is opened, it will be shut again once the transport is lost.
+type readyGate struct {
+	mu sync.Mutex
+	ch chan struct{}
+}
+
+func newReadyGate() *readyGate {
+	return &readyGate{
+		ch: make(chan struct{}),
+	}
+}
+
+func (g *readyGate) wait() (ch <-chan struct{}) {
+	g.mu.Lock()
+	ch = g.ch
+	g.mu.Unlock()
+	return
+}
+
+func (g *readyGate) open() {
+	g.mu.Lock()
+	select {
+	case <-g.ch:
+	default:
+		close(g.ch)
+	}
+	g.mu.Unlock()
+}
+
+func (g *readyGate) shut() {
+	g.mu.Lock()
+	select {
+	case <-g.ch:
+		g.ch = make(chan struct{})
+	default:
+	}
+	g.mu.Unlock()
+}
+
+// WaitReady blocks until a transport has been bound, eg: the SETUP or RESUME handshake of a client has been sent.
+// It returns an error if current socket has been closed or the context is done.
+func (dc *DuplexConnection) WaitReady(ctx context.Context) error {
+	if dc.closed.Load() {
+		return errSocketClosed
+	}
+	select {
+	case <-dc.connected.wait():
+		return nil
+	case <-dc.writeDone:
+		return errSocketClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,44 @@
+package socket
+
+import (
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/internal/common"
+	"github.com/rsocket/rsocket-go/internal/fragmentation"
+	"github.com/rsocket/rsocket-go/logger"
+)
+
+var _errTooManyReassemblies = []byte("Too many payloads being reassembled.")
+
+// SetMaxReassemblies sets the max amount of received payloads being reassembled from fragments at the same time,
+// zero means unlimited. Once it is reached, the first fragment of another payload is dropped and its stream is
+// terminated: an incoming request is rejected with ERROR[REJECTED], and a requester stream fails with
+// core.ErrTooManyReassemblies and sends CANCEL. Payloads being reassembled already are not affected.
+func (dc *DuplexConnection) SetMaxReassemblies(n int) {
+	if n < 0 {
+		n = 0
+	}
+	dc.maxReassembly = int32(n)
+}
+
+// Reassemblies returns the amount of received payloads being reassembled from fragments.
+func (dc *DuplexConnection) Reassemblies() int {
+	return int(dc.reassembling.Load())
+}
+
+// Reassemblies returns the amount of received payloads being reassembled from fragments.
+func (p *BaseSocket) Reassemblies() int {
+	return p.socket.Reassemblies()
+}
+
+// allowReassembly returns false if the first fragment of a payload exceeds the max amount of reassemblies.
+// The fragment will be released, and the stream will be terminated.
+func (dc *DuplexConnection) allowReassembly(input fragmentation.HeaderAndPayload) bool {
+	if dc.maxReassembly < 1 || dc.reassembling.Load() < dc.maxReassembly {
+		return true
+	}
+	h := input.Header()
+	logger.Warnf("too many payloads being reassembled: max=%d, stream=%d, conn=%s\n", dc.maxReassembly, h.StreamID(), dc.connID)
+	common.TryRelease(input)
+	dc.terminateFragmented(h.StreamID(), h.Type(), _errTooManyReassemblies, core.ErrTooManyReassemblies)
+	return false
+}
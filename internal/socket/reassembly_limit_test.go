@@ -0,0 +1,57 @@
+package socket
+
+import (
+	"testing"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/framing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDuplexConnection_MaxReassemblies(t *testing.T) {
+	dc := NewServerDuplexConnection(1024, nil)
+	dc.SetMaxReassemblies(2)
+
+	for _, sid := range []uint32{1, 3} {
+		_, ok, err := dc.doFragment(framing.NewRequestResponseFrame(sid, []byte("ab"), nil, core.FlagFollow))
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	}
+	assert.Equal(t, 2, dc.Reassemblies())
+
+	// a new fragmented request is rejected.
+	_, ok, err := dc.doFragment(framing.NewRequestStreamFrame(5, 1, []byte("xy"), nil, core.FlagFollow))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	sid, code := nextErrorCode(t, dc)
+	assert.Equal(t, uint32(5), sid)
+	assert.Equal(t, core.ErrorCodeRejected, code)
+	assert.Equal(t, 2, dc.Reassemblies())
+
+	// FireAndForget is dropped without any response.
+	_, ok, err = dc.doFragment(framing.NewFireAndForgetFrame(7, []byte("xy"), nil, core.FlagFollow))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Empty(t, dc.outs)
+
+	// payloads being reassembled and payloads without fragments are not affected.
+	_, ok, err = dc.doFragment(framing.NewPayloadFrame(1, []byte("cd"), nil, core.FlagFollow))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	joined, ok, err := dc.doFragment(framing.NewPayloadFrame(1, []byte("ef"), nil, 0))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "abcdef", string(joined.Data()))
+	assert.Equal(t, 1, dc.Reassemblies())
+	whole, ok, err := dc.doFragment(framing.NewRequestResponseFrame(9, []byte("whole"), nil, 0))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	whole.(core.BufferedFrame).Release()
+
+	// a slot is freed once a payload is reassembled.
+	_, ok, err = dc.doFragment(framing.NewRequestResponseFrame(11, []byte("ab"), nil, core.FlagFollow))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 2, dc.Reassemblies())
+	assert.Empty(t, dc.outs)
+}
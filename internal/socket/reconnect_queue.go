@@ -0,0 +1,50 @@
+package socket
+
+import (
+	"context"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/clock"
+	"go.uber.org/atomic"
+)
+
+// reconnectQueue holds requests which are issued while the transport is reconnecting.
+// A request waits until the transport is ready again, fails immediately if too many requests
+// are waiting already, or fails after waiting longer than maxWait.
+type reconnectQueue struct {
+	maxItems int32
+	maxWait  time.Duration
+	waiting  *atomic.Int32
+}
+
+func newReconnectQueue(maxItems int, maxWait time.Duration) *reconnectQueue {
+	return &reconnectQueue{
+		maxItems: int32(maxItems),
+		maxWait:  maxWait,
+		waiting:  atomic.NewInt32(0),
+	}
+}
+
+// await blocks until ready is closed by the next transport, the context is done or maxWait elapses on clk.
+func (q *reconnectQueue) await(ctx context.Context, clk clock.Clock, ready, done <-chan struct{}) error {
+	if q.waiting.Inc() > q.maxItems {
+		q.waiting.Dec()
+		return core.ErrReconnectQueueFull
+	}
+	defer q.waiting.Dec()
+
+	timer := clk.NewTimer(q.maxWait)
+	defer timer.Stop()
+
+	select {
+	case <-ready:
+		return nil
+	case <-done:
+		return errSocketClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C():
+		return core.ErrReconnectTimeout
+	}
+}
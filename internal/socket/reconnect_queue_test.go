@@ -0,0 +1,103 @@
+package socket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/clock"
+	"github.com/rsocket/rsocket-go/core/transport"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDuplexConnection_ReconnectQueue(t *testing.T) {
+	dc := NewClientDuplexConnection(1024, 90*time.Second)
+	dc.SetReconnectQueue(1, 3*time.Second)
+
+	waited := make(chan error)
+	go func() {
+		waited <- dc.awaitTransport(context.Background())
+	}()
+
+	// wait for the first request entering the queue.
+	for dc.reconnect.waiting.Load() < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, core.ErrReconnectQueueFull, dc.awaitTransport(context.Background()))
+
+	dc.SetTransport(transport.NewTransport(nil))
+	select {
+	case err := <-waited:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		assert.Fail(t, "request is not released after reconnecting")
+	}
+	assert.NoError(t, dc.awaitTransport(context.Background()))
+
+	dc.clearTransport()
+	dc.SetReconnectQueue(1, 50*time.Millisecond)
+	assert.Equal(t, core.ErrReconnectTimeout, dc.awaitTransport(context.Background()))
+}
+
+func TestDuplexConnection_NoReconnectQueue(t *testing.T) {
+	dc := NewClientDuplexConnection(1024, 90*time.Second)
+	dc.SetReconnectQueue(0, time.Second)
+	assert.Nil(t, dc.reconnect)
+	assert.NoError(t, dc.awaitTransport(context.Background()))
+}
+
+func TestDuplexConnection_ReconnectQueueOnSubscribe(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	dc := NewClientDuplexConnection(1024, 90*time.Second)
+	dc.SetClock(fake)
+	dc.SetReconnectQueue(10, time.Minute)
+
+	// building requests never blocks while reconnecting.
+	built := make(chan struct{})
+	go func() {
+		defer close(built)
+		dc.RequestResponse(payload.NewString("foo", ""))
+		dc.RequestStream(payload.NewString("foo", ""))
+		dc.FireAndForget(payload.NewString("foo", ""))
+	}()
+	select {
+	case <-built:
+	case <-time.After(time.Second):
+		require.FailNow(t, "building requests should not wait for reconnecting")
+	}
+
+	// the subscribe context stops waiting.
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := dc.RequestResponse(payload.NewString("foo", "")).Block(ctx)
+		done <- err
+	}()
+	cancel()
+	select {
+	case err := <-done:
+		assert.Error(t, err)
+	case <-time.After(time.Second):
+		require.FailNow(t, "waiting should stop once the context is done")
+	}
+
+	// the wait is timed by the clock of the connection.
+	waiters := fake.Waiters()
+	go func() {
+		_, err := dc.RequestResponse(payload.NewString("foo", "")).Block(context.Background())
+		done <- err
+	}()
+	assert.Eventually(t, func() bool {
+		return fake.Waiters() > waiters
+	}, time.Second, time.Millisecond)
+	fake.Advance(time.Minute)
+	select {
+	case err := <-done:
+		assert.Equal(t, core.ErrReconnectTimeout, err)
+	case <-time.After(time.Second):
+		require.FailNow(t, "waiting should time out by the clock")
+	}
+}
@@ -0,0 +1,140 @@
+package socket
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/rsocket/rsocket-go/core"
+)
+
+var errReplayPosition = errors.New("rsocket: position is not available for replay")
+
+// replayBuffer keeps copies of sent resumable frames which have not been acknowledged by the peer yet.
+// Positions are implied positions of the protocol: the sum of lengths of all resumable frames
+// sent before, which matches the position counted by the receiver.
+type replayBuffer struct {
+	mu     sync.Mutex
+	frames []replayFrame
+	first  uint64 // position of the first buffered byte
+	next   uint64 // position after the last buffered byte
+}
+
+// replayFrame is a serialized frame which can be written again.
+type replayFrame struct {
+	pos uint64
+	raw []byte
+}
+
+func (r replayFrame) Header() core.FrameHeader {
+	return core.ParseFrameHeader(r.raw)
+}
+
+func (r replayFrame) Len() int {
+	return len(r.raw)
+}
+
+func (r replayFrame) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(r.raw)
+	return int64(n), err
+}
+
+func (r replayFrame) Done() {
+}
+
+func (r replayFrame) HandleDone(func()) {
+}
+
+func newReplayBuffer() *replayBuffer {
+	return &replayBuffer{}
+}
+
+// serializeFrame copies the raw bytes of a frame before sending.
+// It must be called before the frame is done because its payload may be released then.
+func serializeFrame(frame core.WriteableFrame) (raw []byte) {
+	var bf bytes.Buffer
+	bf.Grow(frame.Len())
+	_, _ = frame.WriteTo(&bf)
+	raw = bf.Bytes()
+	return
+}
+
+// Append appends a sent frame and moves the outbound position forward.
+func (b *replayBuffer) Append(raw []byte) {
+	b.mu.Lock()
+	b.frames = append(b.frames, replayFrame{
+		pos: b.next,
+		raw: raw,
+	})
+	b.next += uint64(len(raw))
+	b.mu.Unlock()
+}
+
+// Position returns the outbound position.
+func (b *replayBuffer) Position() (pos uint64) {
+	b.mu.Lock()
+	pos = b.next
+	b.mu.Unlock()
+	return
+}
+
+// First returns the first available position which can be replayed.
+func (b *replayBuffer) First() (pos uint64) {
+	b.mu.Lock()
+	pos = b.first
+	b.mu.Unlock()
+	return
+}
+
+// Ack discards frames which have been received by the peer completely.
+func (b *replayBuffer) Ack(pos uint64) {
+	b.mu.Lock()
+	b.ack(pos)
+	b.mu.Unlock()
+}
+
+func (b *replayBuffer) ack(pos uint64) {
+	if pos <= b.first || pos > b.next {
+		return
+	}
+	i := 0
+	for i < len(b.frames) && b.frames[i].pos+uint64(len(b.frames[i].raw)) <= pos {
+		b.frames[i] = replayFrame{}
+		i++
+	}
+	b.frames = b.frames[i:]
+	if len(b.frames) > 0 {
+		b.first = b.frames[0].pos
+	} else {
+		b.first = b.next
+	}
+}
+
+// Reset discards all frames and moves positions back to zero.
+func (b *replayBuffer) Reset() {
+	b.mu.Lock()
+	b.frames = nil
+	b.first, b.next = 0, 0
+	b.mu.Unlock()
+}
+
+// Replay returns frames after the position which was last received by the peer.
+// An error will be returned if the position is out of the buffered range or not at a frame boundary.
+func (b *replayBuffer) Replay(pos uint64) (frames []core.WriteableFrame, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if pos < b.first || pos > b.next {
+		err = errReplayPosition
+		return
+	}
+	b.ack(pos)
+	if len(b.frames) > 0 && b.frames[0].pos != pos {
+		err = errReplayPosition
+		return
+	}
+	for _, it := range b.frames {
+		frames = append(frames, it)
+	}
+	return
+}
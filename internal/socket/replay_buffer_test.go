@@ -0,0 +1,73 @@
+package socket
+
+import (
+	"testing"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/framing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplayBuffer(t *testing.T) {
+	b := newReplayBuffer()
+
+	var positions []uint64
+	for i := 0; i < 3; i++ {
+		positions = append(positions, b.Position())
+		f := framing.NewWriteablePayloadFrame(uint32(2*i+1), []byte("hello"), nil, core.FlagNext)
+		b.Append(serializeFrame(f))
+	}
+	frameLen := positions[1] - positions[0]
+	assert.Equal(t, uint64(framing.CalcPayloadFrameSize([]byte("hello"), nil)), frameLen)
+	assert.Equal(t, uint64(0), b.First())
+	assert.Equal(t, 3*frameLen, b.Position())
+
+	// replay all
+	frames, err := b.Replay(0)
+	assert.NoError(t, err)
+	assert.Len(t, frames, 3)
+	for i, f := range frames {
+		assert.Equal(t, uint32(2*i+1), f.Header().StreamID())
+		assert.Equal(t, core.FrameTypePayload, f.Header().Type())
+	}
+
+	// replay from the second frame boundary
+	frames, err = b.Replay(positions[1])
+	assert.NoError(t, err)
+	assert.Len(t, frames, 2)
+	assert.Equal(t, uint32(3), frames[0].Header().StreamID())
+	assert.Equal(t, positions[1], b.First())
+
+	// frames before the first position have been discarded
+	_, err = b.Replay(0)
+	assert.Equal(t, errReplayPosition, err)
+
+	// not a frame boundary
+	_, err = b.Replay(positions[2] + 1)
+	assert.Equal(t, errReplayPosition, err)
+
+	// beyond the outbound position
+	_, err = b.Replay(b.Position() + 1)
+	assert.Equal(t, errReplayPosition, err)
+
+	// ack everything
+	b.Ack(b.Position())
+	assert.Equal(t, b.Position(), b.First())
+	frames, err = b.Replay(b.Position())
+	assert.NoError(t, err)
+	assert.Empty(t, frames)
+}
+
+func TestDuplexConnection_Replay(t *testing.T) {
+	dc := NewServerDuplexConnection(1024, nil)
+	assert.Equal(t, errReplayPosition, dc.replayTo(nil, 0))
+
+	dc.enableReplay()
+	raw := serializeFrame(framing.NewWriteablePayloadFrame(1, []byte("hello"), nil, core.FlagNext))
+	dc.replay.Append(raw)
+	assert.Equal(t, uint64(len(raw)), dc.replay.Position())
+
+	// keepalive frame acknowledges the position
+	dc.onFrameKeepalive(framing.NewKeepaliveFrame(uint64(len(raw)), nil, false))
+	assert.Equal(t, uint64(len(raw)), dc.replay.First())
+}
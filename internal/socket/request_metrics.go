@@ -0,0 +1,59 @@
+package socket
+
+import (
+	"github.com/rsocket/rsocket-go/core"
+)
+
+// RejectReason is the reason why a request is rejected.
+type RejectReason int8
+
+// All reject reasons
+const (
+	// RejectedByLease means the request is rejected by requester because no lease is available.
+	RejectedByLease RejectReason = iota
+	// RejectedByDraining means the request is rejected by responder because it is draining.
+	RejectedByDraining
+	// RejectedBySaturation means the request is rejected by responder because its dispatch scheduler is saturated.
+	RejectedBySaturation
+)
+
+func (r RejectReason) String() string {
+	switch r {
+	case RejectedByLease:
+		return "LEASE"
+	case RejectedByDraining:
+		return "DRAINING"
+	case RejectedBySaturation:
+		return "SATURATION"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// RequestMetrics receives counter events of requests which are rejected or cancelled.
+// It can be bridged to any metrics system, methods should return quickly.
+type RequestMetrics interface {
+	// OnRequestRejected is invoked when a request is rejected before it is handled.
+	OnRequestRejected(requestType core.FrameType, reason RejectReason)
+	// OnRequestCancelled is invoked when a stream is cancelled.
+	// Requester is true if the stream was started and cancelled by current side,
+	// otherwise it was cancelled by the remote requester.
+	OnRequestCancelled(requestType core.FrameType, requester bool)
+}
+
+// SetRequestMetrics sets a receiver of request counter events.
+func (dc *DuplexConnection) SetRequestMetrics(metrics RequestMetrics) {
+	dc.metrics = metrics
+}
+
+func (dc *DuplexConnection) requestRejected(requestType core.FrameType, reason RejectReason) {
+	if dc.metrics != nil {
+		dc.metrics.OnRequestRejected(requestType, reason)
+	}
+}
+
+func (dc *DuplexConnection) requestCancelled(requestType core.FrameType, requester bool) {
+	if dc.metrics != nil {
+		dc.metrics.OnRequestCancelled(requestType, requester)
+	}
+}
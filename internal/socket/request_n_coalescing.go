@@ -0,0 +1,149 @@
+package socket
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core/clock"
+	"github.com/rsocket/rsocket-go/core/framing"
+	"github.com/rsocket/rsocket-go/rx"
+)
+
+// SetRequestNCoalescing sets the window in which REQUEST_N signals of a receiving stream are accumulated
+// into one REQUEST_N frame. Demand is only held back while the peer still has credits to send payloads,
+// it is sent immediately once all granted payloads are received, so the consumer never waits longer than
+// the window. Zero disables coalescing.
+func (dc *DuplexConnection) SetRequestNCoalescing(window time.Duration) {
+	if window < 0 {
+		window = 0
+	}
+	dc.coalesceN = window
+}
+
+// sendRequestN sends a REQUEST_N frame and waits until it is written.
+func (dc *DuplexConnection) sendRequestN(sid uint32, n uint32) {
+	frameN := framing.NewWriteableRequestNFrame(sid, n, 0)
+	done := make(chan struct{})
+	frameN.HandleDone(func() {
+		close(done)
+	})
+	if dc.sendFrame(frameN) {
+		<-done
+	}
+}
+
+// requestNSender sends REQUEST_N frames of a receiving stream, it accumulates demand if coalescing is enabled.
+// It accounts payloads granted to the peer against payloads received, so that the demand sent never exceeds
+// the demand of the subscriber.
+type requestNSender struct {
+	mu          sync.Mutex
+	dc          *DuplexConnection
+	sid         uint32
+	window      time.Duration
+	pending     int
+	outstanding int         // payloads granted to the peer but not received yet
+	timer       clock.Timer // non-nil while pending demand is waiting for the window
+	stopped     bool
+}
+
+func (dc *DuplexConnection) newRequestNSender(sid uint32) *requestNSender {
+	return &requestNSender{
+		dc:     dc,
+		sid:    sid,
+		window: dc.coalesceN,
+	}
+}
+
+// granted records the demand which has been sent by the request frame.
+func (s *requestNSender) granted(n int) {
+	s.mu.Lock()
+	s.outstanding = addRequestN(s.outstanding, n)
+	s.mu.Unlock()
+}
+
+// request sends the demand, or accumulates it until the window closes or the peer runs out of credits.
+// Nothing is sent once the demand is unbounded, since further REQUEST_N frames make no difference to the peer.
+func (s *requestNSender) request(n int) {
+	if n < 1 {
+		return
+	}
+	s.mu.Lock()
+	if s.stopped || s.outstanding >= rx.RequestMax {
+		s.mu.Unlock()
+		return
+	}
+	if s.window <= 0 {
+		s.outstanding = addRequestN(s.outstanding, n)
+		s.mu.Unlock()
+		s.dc.sendRequestN(s.sid, ToUint32RequestN(n))
+		return
+	}
+	s.pending = addRequestN(s.pending, n)
+	if s.outstanding > 0 {
+		if s.timer == nil {
+			s.timer = s.dc.clock.AfterFunc(s.window, s.flush)
+		}
+		s.mu.Unlock()
+		return
+	}
+	n = s.drain()
+	s.mu.Unlock()
+	s.dc.sendRequestN(s.sid, ToUint32RequestN(n))
+}
+
+// received is invoked on every payload received, pending demand is flushed once no credit is left.
+func (s *requestNSender) received() {
+	s.mu.Lock()
+	if s.outstanding > 0 && s.outstanding < rx.RequestMax {
+		s.outstanding--
+	}
+	if s.window <= 0 || s.stopped || s.outstanding > 0 || s.pending < 1 {
+		s.mu.Unlock()
+		return
+	}
+	n := s.drain()
+	s.mu.Unlock()
+	s.dc.sendRequestN(s.sid, ToUint32RequestN(n))
+}
+
+func (s *requestNSender) flush() {
+	s.mu.Lock()
+	if s.stopped || s.pending < 1 {
+		s.timer = nil
+		s.mu.Unlock()
+		return
+	}
+	n := s.drain()
+	s.mu.Unlock()
+	s.dc.sendRequestN(s.sid, ToUint32RequestN(n))
+}
+
+// drain takes the pending demand and grants it to the peer, it must be called with the lock.
+func (s *requestNSender) drain() (n int) {
+	n, s.pending = s.pending, 0
+	s.outstanding = addRequestN(s.outstanding, n)
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	return
+}
+
+// stop discards pending demand when the stream is finished.
+func (s *requestNSender) stop() {
+	s.mu.Lock()
+	s.stopped = true
+	s.pending = 0
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.mu.Unlock()
+}
+
+func addRequestN(a, b int) int {
+	if b >= rx.RequestMax || a+b >= rx.RequestMax {
+		return rx.RequestMax
+	}
+	return a + b
+}
@@ -0,0 +1,97 @@
+package socket
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordRequestN consumes outbound frames and returns n of every REQUEST_N frame.
+func recordRequestN(ctx context.Context, dc *DuplexConnection) <-chan uint32 {
+	sent := make(chan uint32, 16)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case next := <-dc.outs:
+				if next.Header().Type() == core.FrameTypeRequestN {
+					b := &bytes.Buffer{}
+					_, _ = next.WriteTo(b)
+					sent <- binary.BigEndian.Uint32(b.Bytes()[b.Len()-4:])
+				}
+				next.Done()
+			}
+		}
+	}()
+	return sent
+}
+
+func TestDuplexConnection_RequestNCoalescing(t *testing.T) {
+	const window = 10 * time.Millisecond
+	fake := clock.NewFake(time.Now())
+	dc := NewClientDuplexConnection(1024, time.Hour)
+	dc.SetClock(fake)
+	dc.SetRequestNCoalescing(window)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sent := recordRequestN(ctx, dc)
+	// the keepalive ticker is a waiter too.
+	idle := fake.Waiters()
+
+	s := dc.newRequestNSender(1)
+	// the peer still has credits, so demand is accumulated until the window closes.
+	s.granted(4)
+	for i := 0; i < 3; i++ {
+		s.request(1)
+		s.received()
+	}
+	assert.Empty(t, sent)
+	fake.Advance(window)
+	assert.Equal(t, uint32(3), <-sent, "signals in the window should be accumulated")
+	assert.Equal(t, idle, fake.Waiters())
+
+	// the peer has no credit, demand is sent at once.
+	for i := 0; i < 4; i++ {
+		s.received()
+	}
+	s.request(2)
+	assert.Equal(t, uint32(2), <-sent)
+
+	// pending demand is flushed before the window closes once the peer runs out of credits.
+	s.request(1)
+	s.received()
+	assert.Empty(t, sent)
+	s.received()
+	assert.Equal(t, uint32(1), <-sent)
+	assert.Equal(t, idle, fake.Waiters())
+
+	// pending demand is discarded when the stream is finished.
+	s.request(5)
+	s.stop()
+	fake.Advance(window)
+	s.received()
+	s.request(6)
+	assert.Equal(t, idle, fake.Waiters())
+	assert.Empty(t, sent)
+}
+
+func TestDuplexConnection_RequestNCoalescingDisabled(t *testing.T) {
+	dc := NewClientDuplexConnection(1024, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sent := recordRequestN(ctx, dc)
+
+	s := dc.newRequestNSender(1)
+	s.granted(10)
+	for i := uint32(1); i <= 3; i++ {
+		s.request(int(i))
+		assert.Equal(t, i, <-sent)
+	}
+}
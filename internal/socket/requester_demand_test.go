@@ -0,0 +1,122 @@
+package socket
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/framing"
+	"github.com/rsocket/rsocket-go/core/transport"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx"
+	"github.com/stretchr/testify/assert"
+)
+
+// demandConn records n of REQUEST_STREAM and REQUEST_N frames written.
+type demandConn struct {
+	recordConn
+	mu      sync.Mutex
+	demands []uint32
+}
+
+func (c *demandConn) Write(frame core.WriteableFrame) error {
+	switch frame.Header().Type() {
+	case core.FrameTypeRequestStream, core.FrameTypeRequestN:
+		b := &bytes.Buffer{}
+		_, _ = frame.WriteTo(b)
+		c.mu.Lock()
+		c.demands = append(c.demands, binary.BigEndian.Uint32(b.Bytes()[core.FrameHeaderLen:]))
+		c.mu.Unlock()
+	}
+	return c.recordConn.Write(frame)
+}
+
+func (c *demandConn) sent() []uint32 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]uint32(nil), c.demands...)
+}
+
+func newDemandConnection() (*DuplexConnection, *demandConn, func()) {
+	dc := NewClientDuplexConnection(1024, time.Hour)
+	conn := &demandConn{recordConn: recordConn{closed: make(chan struct{})}}
+	dc.SetTransport(transport.NewTransport(conn))
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		_ = dc.LoopWrite(ctx)
+	}()
+	return dc, conn, func() {
+		cancel()
+		close(conn.closed)
+	}
+}
+
+func TestDuplexConnection_RequestStreamDemand(t *testing.T) {
+	dc, conn, stop := newDemandConnection()
+	defer stop()
+
+	var su rx.Subscription
+	var received int
+	done := make(chan struct{})
+	dc.RequestStream(payload.NewString("foo", "")).
+		DoFinally(func(s rx.SignalType) {
+			close(done)
+		}).
+		Subscribe(context.Background(),
+			rx.OnSubscribe(func(ctx context.Context, s rx.Subscription) {
+				su = s
+				s.Request(3)
+			}),
+			rx.OnNext(func(input payload.Payload) error {
+				// request more once all requested payloads have been consumed.
+				if received++; received%3 == 0 {
+					su.Request(3)
+				}
+				return nil
+			}))
+
+	assert.Eventually(t, func() bool {
+		return len(conn.sent()) == 1
+	}, 3*time.Second, 10*time.Millisecond)
+	const sid = 1
+	for i := 0; i < 9; i++ {
+		assert.Equal(t, uint32(3*(i/3+1)), sum(conn.sent()), "demand should be granted in increments of 3")
+		assert.NoError(t, dc.onFramePayload(framing.NewPayloadFrame(sid, []byte("bar"), nil, core.FlagNext)))
+	}
+	assert.NoError(t, dc.onFramePayload(framing.NewPayloadFrame(sid, nil, nil, core.FlagComplete)))
+	<-done
+
+	assert.Equal(t, 9, received)
+	// REQUEST_STREAM(3) and a REQUEST_N(3) after every 3 payloads.
+	assert.Equal(t, []uint32{3, 3, 3, 3}, conn.sent())
+}
+
+func TestDuplexConnection_RequestStreamUnboundedDemand(t *testing.T) {
+	dc, conn, stop := newDemandConnection()
+	defer stop()
+
+	var su rx.Subscription
+	dc.RequestStream(payload.NewString("foo", "")).
+		Subscribe(context.Background(),
+			rx.OnSubscribe(func(ctx context.Context, s rx.Subscription) {
+				su = s
+				s.Request(rx.RequestMax - 1)
+			}))
+	su.Request(10)
+	su.Request(10)
+	su.Request(0)
+
+	// nothing is sent once the demand is unbounded.
+	assert.Equal(t, []uint32{rx.RequestMax - 1, 10}, conn.sent())
+}
+
+func sum(demands []uint32) (n uint32) {
+	for _, d := range demands {
+		n += d
+	}
+	return
+}
@@ -0,0 +1,46 @@
+package socket
+
+import (
+	"github.com/rsocket/rsocket-go/internal/common"
+	"github.com/rsocket/rsocket-go/internal/fragmentation"
+	"go.uber.org/atomic"
+)
+
+// responderTeardown guards the teardown of a responding stream.
+// The terminal signal of the responding publisher (eg: COMPLETE) may race with a CANCEL frame from the requester.
+// The handler may still hold the request payload after it has been cancelled, so only the terminal signal releases it,
+// and a cancelled stream which never signals leaves it to the garbage collector. The subscriber of a cancelled stream
+// is never returned to the pool either, since the publisher may still be calling it.
+type responderTeardown struct {
+	done      *atomic.Bool
+	cancelled *atomic.Bool
+	receiving fragmentation.HeaderAndPayload
+}
+
+func newResponderTeardown(receiving fragmentation.HeaderAndPayload) *responderTeardown {
+	return &responderTeardown{
+		done:      atomic.NewBool(false),
+		cancelled: atomic.NewBool(false),
+		receiving: receiving,
+	}
+}
+
+// cancel marks the stream as cancelled by the requester.
+func (t *responderTeardown) cancel() {
+	t.cancelled.Store(true)
+}
+
+// isCancelled returns true if the stream has been cancelled by the requester.
+func (t *responderTeardown) isCancelled() bool {
+	return t.cancelled.Load()
+}
+
+// release releases the request payload, it returns false if it has been released before.
+// It must only be called on the terminal signal of the publisher.
+func (t *responderTeardown) release() bool {
+	if !t.done.CAS(false, true) {
+		return false
+	}
+	common.TryRelease(t.receiving)
+	return true
+}
@@ -19,6 +19,7 @@ const _resumeTimeout = 10 * time.Second
 type resumeClientSocket struct {
 	*BaseSocket
 	connects *atomic.Int32
+	fresh    *atomic.Bool // a new session should be set up because the last one was rejected
 	setup    *SetupInfo
 	tp       transport.ClientTransporter
 }
@@ -69,9 +70,13 @@ func (r *resumeClientSocket) connect(ctx context.Context, timeout time.Duration)
 	}
 	tp.Connection().SetCounter(r.socket.counter)
 	tp.SetLifetime(r.setup.KeepaliveLifetime)
+	tp.SetKeepaliveInterval(r.setup.KeepaliveInterval)
+	r.socket.SetKeepaliveSettings(r.setup.KeepaliveInterval, r.setup.KeepaliveLifetime)
 
+	stopped := make(chan struct{})
 	go func(ctx context.Context, tp *transport.Transport) {
 		defer func() {
+			close(stopped)
 			r.socket.clearTransport()
 			if r.isClosed() {
 				_ = r.Close()
@@ -82,27 +87,43 @@ func (r *resumeClientSocket) connect(ctx context.Context, timeout time.Duration)
 		}()
 		err := tp.Start(ctx)
 		if err != nil && logger.IsDebugEnabled() {
-			logger.Debugf("resumable client stopped: %s\n", err)
+			logger.Debugf("resumable client stopped: %s, conn=%s\n", err, r.socket.connID)
 		}
 	}(ctx, tp)
 
-	// connect first time.
-	if len(r.setup.Token) < 1 || connects == 1 {
+	// connect first time, or setup a new session after the last one was rejected.
+	if len(r.setup.Token) < 1 || connects == 1 || r.fresh.CAS(true, false) {
 		tp.Handle(transport.OnErrorWithZeroStreamID, func(frame core.BufferedFrame) (err error) {
 			defer frame.Release()
-			r.socket.SetError(frame.(*framing.ErrorFrame).ToError())
+			connErr := frame.(*framing.ErrorFrame).ToError()
+			r.socket.SetError(connErr)
 			r.markAsClosing()
+			r.socket.connectionError(connErr)
 			return
 		})
+		start := time.Now()
 		err = tp.Send(r.setup.toFrame(), true)
+		if err == nil {
+			r.setupSent(tp, start)
+		}
 		r.socket.SetTransport(tp)
+		// only the first SETUP is awaited by Start, later ones are sent by reconnecting in background.
+		if err == nil && connects == 1 && r.setup.Timeout > 0 {
+			if err = r.awaitSetup(ctx, r.setup.Timeout, stopped); err != nil {
+				_ = r.Close()
+			}
+		}
 		return
 	}
 
 	resumeErr := make(chan error)
 
+	// position last received by server, frames after it will be replayed.
+	var serverPosition uint64
+
 	tp.Handle(transport.OnResumeOK, func(frame core.BufferedFrame) (err error) {
 		defer frame.Release()
+		serverPosition = frame.(*framing.ResumeOKFrame).LastReceivedClientPosition()
 		close(resumeErr)
 		return
 	})
@@ -114,7 +135,7 @@ func (r *resumeClientSocket) connect(ctx context.Context, timeout time.Duration)
 		if errFrame.ErrorCode() == core.ErrorCodeRejectedResume {
 			defer func() {
 				if err := recover(); err != nil {
-					logger.Warnf("handle reject resume failed: %s\n", err)
+					logger.Warnf("handle reject resume failed: %s, conn=%s\n", err, r.socket.connID)
 				}
 			}()
 			resumeErr <- errFrame.ToError()
@@ -123,23 +144,35 @@ func (r *resumeClientSocket) connect(ctx context.Context, timeout time.Duration)
 		return nil
 	})
 
+	start := time.Now()
 	err = tp.Send(framing.NewWriteableResumeFrame(
 		core.DefaultVersion,
 		r.setup.Token,
-		r.socket.counter.WriteBytes(),
+		r.socket.replay.First(),
 		r.socket.counter.ReadBytes(),
 	), true)
 
 	if err != nil {
 		return err
 	}
+	r.setupSent(tp, start)
 
 	select {
 	case <-time.After(_resumeTimeout):
 		err = errors.New("resume timeout")
 	case reject, ok := <-resumeErr:
 		if ok {
-			logger.Errorf("resume failed: %s\n", reject.Error())
+			// REJECTED_RESUME: the session has gone on the server side, eg: expired.
+			// Close current transport, then it will reconnect with a new SETUP.
+			logger.Warnf("resume rejected, setup a new session: %s, conn=%s\n", reject.Error(), r.socket.connID)
+			r.socket.resetSession(reject)
+			r.fresh.Store(true)
+			_ = tp.Close()
+			return
+		}
+		reject = r.socket.replayTo(tp, serverPosition)
+		if reject != nil {
+			logger.Errorf("resume failed: %s, conn=%s\n", reject.Error(), r.socket.connID)
 			r.markAsClosing()
 			err = r.connect(ctx, timeout)
 		} else {
@@ -159,9 +192,13 @@ func (r *resumeClientSocket) isClosed() bool {
 
 // NewResumableClientSocket creates a client-side socket with resume support.
 func NewResumableClientSocket(tp transport.ClientTransporter, socket *DuplexConnection) ClientSocket {
+	if socket != nil {
+		socket.enableReplay()
+	}
 	return &resumeClientSocket{
 		BaseSocket: NewBaseSocket(socket),
 		connects:   atomic.NewInt32(0),
+		fresh:      atomic.NewBool(false),
 		tp:         tp,
 	}
 }
@@ -3,6 +3,7 @@ package socket
 import (
 	"context"
 
+	"github.com/rsocket/rsocket-go/core/framing"
 	"github.com/rsocket/rsocket-go/core/transport"
 )
 
@@ -21,9 +22,26 @@ func (p *resumeServerSocket) SetResponder(responder Responder) {
 }
 
 func (p *resumeServerSocket) SetTransport(tp *transport.Transport) {
+	tp.Connection().SetCounter(p.socket.counter)
 	p.socket.SetTransport(tp)
 }
 
+func (p *resumeServerSocket) Resume(frame *framing.ResumeFrame, tp *transport.Transport) error {
+	// Client cannot replay frames which have not been received by server.
+	received := p.socket.counter.ReadBytes()
+	if frame.FirstAvailableClientPosition() > received {
+		return errReplayPosition
+	}
+	if err := tp.Send(framing.NewWriteableResumeOKFrame(received), false); err != nil {
+		return err
+	}
+	if err := p.socket.replayTo(tp, frame.LastReceivedServerPosition()); err != nil {
+		return err
+	}
+	p.SetTransport(tp)
+	return nil
+}
+
 func (p *resumeServerSocket) Token() (token []byte, ok bool) {
 	token, ok = p.token, true
 	return
@@ -38,6 +56,9 @@ func (p *resumeServerSocket) Start(ctx context.Context) error {
 
 // NewResumableServerSocket creates a new server-side socket with resume support.
 func NewResumableServerSocket(socket *DuplexConnection, token []byte) ServerSocket {
+	if socket != nil {
+		socket.enableReplay()
+	}
 	return &resumeServerSocket{
 		BaseSocket: NewBaseSocket(socket),
 		token:      token,
@@ -80,3 +80,34 @@ func TestResumableServerSocket_Start(t *testing.T) {
 
 	<-done
 }
+
+func TestResumableServerSocket_Resume(t *testing.T) {
+	ctrl, conn, tp := InitTransport(t)
+	defer ctrl.Finish()
+
+	var written []core.FrameType
+	conn.EXPECT().SetCounter(gomock.Any()).AnyTimes()
+	conn.EXPECT().Write(gomock.Any()).DoAndReturn(func(frame core.WriteableFrame) error {
+		written = append(written, frame.Header().Type())
+		return nil
+	}).AnyTimes()
+	conn.EXPECT().Flush().AnyTimes()
+
+	c := socket.NewServerDuplexConnection(fragmentation.MaxFragment, nil)
+	ss := socket.NewResumableServerSocket(c, fakeToken)
+
+	// client claims frames which have not been received by server are discarded.
+	err := ss.Resume(framing.NewResumeFrame(core.DefaultVersion, fakeToken, 10, 0), tp)
+	assert.Error(t, err)
+	assert.Empty(t, written)
+
+	// server has nothing to replay.
+	err = ss.Resume(framing.NewResumeFrame(core.DefaultVersion, fakeToken, 0, 0), tp)
+	assert.NoError(t, err)
+	assert.Equal(t, []core.FrameType{core.FrameTypeResumeOK}, written)
+
+	// simple server socket cannot be resumed.
+	err = socket.NewSimpleServerSocket(socket.NewServerDuplexConnection(fragmentation.MaxFragment, nil)).
+		Resume(framing.NewResumeFrame(core.DefaultVersion, fakeToken, 0, 0), tp)
+	assert.Error(t, err)
+}
@@ -0,0 +1,25 @@
+package socket
+
+// resetSession drops the state of a session which has been rejected by the peer, so a new session can be set up
+// on the same socket. All active streams are terminated with the error because they can never be resumed.
+func (dc *DuplexConnection) resetSession(err error) {
+	var (
+		sids []uint32
+		cbs  []callback
+	)
+	dc.messages.Range(func(sid uint32, v interface{}) bool {
+		if cb, ok := v.(callback); ok {
+			sids = append(sids, sid)
+			cbs = append(cbs, cb)
+		}
+		return true
+	})
+	for i := range sids {
+		dc.unregister(sids[i])
+		cbs[i].stopWithError(err)
+	}
+	if dc.replay != nil {
+		dc.replay.Reset()
+	}
+	dc.counter.Reset()
+}
@@ -0,0 +1,65 @@
+package socket
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/framing"
+)
+
+var errClosedDuringSetup = errors.New("rsocket: connection closed during setup")
+
+// awaitSetup confirms the SETUP frame which has been sent, since there is no response to SETUP.
+// It sends a KEEPALIVE frame which requires a response, the server only reads it after it has accepted the SETUP.
+// The frame is queued to the writer like other frames, and only the response which echoes its data confirms the SETUP.
+// It returns core.ErrSetupTimeout if the response doesn't return in time, or the error sent by the server
+// if the connection is closed, eg: REJECTED_SETUP.
+func (p *BaseSocket) awaitSetup(ctx context.Context, timeout time.Duration, stopped <-chan struct{}) (err error) {
+	data := p.socket.newKeepaliveData()
+	acked := p.socket.armSetupAck(data)
+	if !p.socket.sendFrame(framing.NewWriteableKeepaliveFrame(p.socket.counter.ReadBytes(), data, true)) {
+		if err = p.socket.GetError(); err == nil {
+			err = errClosedDuringSetup
+		}
+		return
+	}
+	timer := p.socket.clock.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-acked:
+		return nil
+	case <-stopped:
+		if err = p.socket.GetError(); err == nil {
+			err = errClosedDuringSetup
+		}
+	case <-timer.C():
+		err = core.ErrSetupTimeout
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	return
+}
+
+// armSetupAck returns a channel which is closed once the KEEPALIVE frame carrying data returns.
+func (dc *DuplexConnection) armSetupAck(data []byte) <-chan struct{} {
+	ack := make(chan struct{})
+	dc.locker.Lock()
+	dc.setupAck = ack
+	dc.setupAckData = data
+	dc.locker.Unlock()
+	return ack
+}
+
+// setupAcked confirms the SETUP if data is echoed from the KEEPALIVE frame sent by awaitSetup.
+func (dc *DuplexConnection) setupAcked(data []byte) {
+	dc.locker.Lock()
+	if dc.setupAck != nil && bytes.Equal(dc.setupAckData, data) {
+		close(dc.setupAck)
+		dc.setupAck = nil
+		dc.setupAckData = nil
+	}
+	dc.locker.Unlock()
+}
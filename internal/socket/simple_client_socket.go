@@ -7,6 +7,8 @@ import (
 	"github.com/rsocket/rsocket-go/core"
 	"github.com/rsocket/rsocket-go/core/framing"
 	"github.com/rsocket/rsocket-go/core/transport"
+	"github.com/rsocket/rsocket-go/internal/common"
+	"github.com/rsocket/rsocket-go/lease"
 	"github.com/rsocket/rsocket-go/logger"
 )
 
@@ -22,35 +24,58 @@ func (p *simpleClientSocket) Setup(ctx context.Context, connectTimeout time.Dura
 	}
 	tp.Connection().SetCounter(p.socket.counter)
 	tp.SetLifetime(setup.KeepaliveLifetime)
+	tp.SetKeepaliveInterval(setup.KeepaliveInterval)
+	p.socket.SetKeepaliveSettings(setup.KeepaliveInterval, setup.KeepaliveLifetime)
 
 	p.socket.SetTransport(tp)
 
 	if setup.Lease {
 		p.refreshLease(0, 0)
 		tp.Handle(transport.OnLease, func(frame core.BufferedFrame) (err error) {
-			lease := frame.(*framing.LeaseFrame)
-			p.refreshLease(lease.TimeToLive(), int64(lease.NumberOfRequests()))
+			f := frame.(*framing.LeaseFrame)
+			defer f.Release()
+			p.refreshLease(f.TimeToLive(), int64(f.NumberOfRequests()))
+			if setup.OnLease != nil {
+				setup.OnLease(lease.Lease{
+					TimeToLive:       f.TimeToLive(),
+					NumberOfRequests: f.NumberOfRequests(),
+					Metadata:         common.CloneBytes(f.Metadata()),
+				})
+			}
 			return
 		})
 	}
 
 	tp.Handle(transport.OnErrorWithZeroStreamID, func(frame core.BufferedFrame) (err error) {
-		p.socket.SetError(frame.(*framing.ErrorFrame))
+		f := frame.(*framing.ErrorFrame)
+		p.socket.SetError(f)
+		p.socket.connectionError(f.ToError())
 		return
 	})
 
+	stopped := make(chan struct{})
 	go func(ctx context.Context, tp *transport.Transport) {
 		if err := tp.Start(ctx); err != nil {
-			logger.Warnf("client exit failed: %+v\n", err)
+			logger.Warnf("client exit failed: %+v, conn=%s\n", err, p.socket.connID)
 		}
+		close(stopped)
 		_ = p.Close()
 	}(ctx, tp)
 
 	go func() {
 		_ = p.socket.LoopWrite(ctx)
 	}()
+	start := time.Now()
 	setupFrame := setup.toFrame()
 	err = p.socket.tp.Send(setupFrame, true)
+	if err == nil {
+		p.setupSent(tp, start)
+	}
+	if err == nil && setup.Timeout > 0 {
+		if err = p.awaitSetup(ctx, setup.Timeout, stopped); err != nil {
+			_ = p.Close()
+		}
+	}
 	return
 }
 
@@ -10,11 +10,13 @@ import (
 	"github.com/rsocket/rsocket-go/core"
 	"github.com/rsocket/rsocket-go/core/framing"
 	"github.com/rsocket/rsocket-go/core/transport"
+	"github.com/rsocket/rsocket-go/core/transport/transporttest"
 	"github.com/rsocket/rsocket-go/internal/fragmentation"
 	"github.com/rsocket/rsocket-go/internal/socket"
 	"github.com/rsocket/rsocket-go/payload"
 	"github.com/rsocket/rsocket-go/rx"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/atomic"
 )
 
@@ -142,3 +144,250 @@ func extractMetadata(p payload.Payload) []byte {
 	m, _ := p.Metadata()
 	return m
 }
+
+func TestClient_MaxResponsePayloadSize(t *testing.T) {
+	ctrl, conn, tp := InitTransport(t)
+	defer ctrl.Finish()
+
+	readChan := make(chan core.BufferedFrame, 64)
+	cancels := make(chan uint32, 64)
+
+	conn.EXPECT().Close().Times(1)
+	conn.EXPECT().SetCounter(gomock.Any()).Times(1)
+	conn.EXPECT().Write(gomock.Any()).DoAndReturn(func(frame core.WriteableFrame) error {
+		if frame.Header().Type() == core.FrameTypeCancel {
+			cancels <- frame.Header().StreamID()
+		}
+		return nil
+	}).AnyTimes()
+	conn.EXPECT().Flush().AnyTimes()
+	conn.EXPECT().Read().DoAndReturn(func() (core.BufferedFrame, error) {
+		next, ok := <-readChan
+		if !ok {
+			return nil, io.EOF
+		}
+		return next, nil
+	}).AnyTimes()
+	conn.EXPECT().SetDeadline(gomock.Any()).AnyTimes()
+
+	ds := socket.NewClientDuplexConnection(fragmentation.MaxFragment, 90*time.Second)
+	ds.SetMaxResponsePayloadSize(len(fakeData) + len(fakeMetadata))
+	cli := socket.NewClient(func(ctx context.Context) (*transport.Transport, error) {
+		return tp, nil
+	}, ds)
+	defer cli.Close()
+
+	err := cli.Setup(context.Background(), 0, fakeSetup)
+	assert.NoError(t, err, "setup client failed")
+
+	// Payload within the limit.
+	res, err := cli.RequestResponse(payload.New(fakeData, fakeMetadata)).
+		DoOnSubscribe(func(ctx context.Context, s rx.Subscription) {
+			readChan <- framing.NewPayloadFrame(1, fakeData, fakeMetadata, core.FlagComplete)
+		}).
+		Block(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, fakeData, res.Data())
+
+	// Fragmented payload exceeds the limit.
+	_, err = cli.RequestResponse(payload.New(fakeData, fakeMetadata)).
+		DoOnSubscribe(func(ctx context.Context, s rx.Subscription) {
+			readChan <- framing.NewPayloadFrame(3, fakeData, fakeMetadata, core.FlagFollow)
+			readChan <- framing.NewPayloadFrame(3, fakeData, nil, core.FlagComplete)
+		}).
+		Block(context.Background())
+	assert.Equal(t, core.ErrResponseTooLarge, err)
+
+	select {
+	case sid := <-cancels:
+		assert.Equal(t, uint32(3), sid)
+	case <-time.After(3 * time.Second):
+		assert.Fail(t, "no CANCEL frame sent")
+	}
+
+	// Stream payload exceeds the limit.
+	errs := make(chan error, 1)
+	cli.RequestStream(payload.New(fakeData, fakeMetadata)).
+		DoOnSubscribe(func(ctx context.Context, s rx.Subscription) {
+			readChan <- framing.NewPayloadFrame(5, fakeData, fakeMetadata, core.FlagNext)
+			readChan <- framing.NewPayloadFrame(5, append(fakeData, fakeData...), fakeMetadata, core.FlagNext)
+		}).
+		Subscribe(context.Background(), rx.OnError(func(e error) {
+			errs <- e
+		}))
+	select {
+	case err = <-errs:
+		assert.Equal(t, core.ErrResponseTooLarge, err)
+	case <-time.After(3 * time.Second):
+		assert.Fail(t, "stream should fail")
+	}
+}
+
+func TestClient_RequestResponseSync(t *testing.T) {
+	ctrl, conn, tp := InitTransport(t)
+	defer ctrl.Finish()
+
+	readChan := make(chan core.BufferedFrame, 64)
+	cancels := make(chan uint32, 64)
+
+	conn.EXPECT().Close().Times(1)
+	conn.EXPECT().SetCounter(gomock.Any()).Times(1)
+	conn.EXPECT().Write(gomock.Any()).DoAndReturn(func(frame core.WriteableFrame) error {
+		h := frame.Header()
+		switch h.Type() {
+		case core.FrameTypeRequestResponse:
+			switch h.StreamID() {
+			case 1:
+				readChan <- framing.NewPayloadFrame(1, fakeData, fakeMetadata, core.FlagNext|core.FlagComplete)
+			case 3:
+				readChan <- framing.NewErrorFrame(3, core.ErrorCodeApplicationError, []byte("boom"))
+			}
+		case core.FrameTypeCancel:
+			cancels <- h.StreamID()
+		}
+		return nil
+	}).AnyTimes()
+	conn.EXPECT().Flush().AnyTimes()
+	conn.EXPECT().Read().DoAndReturn(func() (core.BufferedFrame, error) {
+		next, ok := <-readChan
+		if !ok {
+			return nil, io.EOF
+		}
+		return next, nil
+	}).AnyTimes()
+	conn.EXPECT().SetDeadline(gomock.Any()).AnyTimes()
+
+	cli := socket.NewClient(func(ctx context.Context) (*transport.Transport, error) {
+		return tp, nil
+	}, socket.NewClientDuplexConnection(fragmentation.MaxFragment, 90*time.Second))
+	defer cli.Close()
+
+	err := cli.Setup(context.Background(), 0, fakeSetup)
+	assert.NoError(t, err, "setup client failed")
+
+	res, err := cli.RequestResponseSync(context.Background(), payload.New(fakeData, fakeMetadata))
+	assert.NoError(t, err)
+	assert.Equal(t, fakeData, res.Data())
+	assert.Equal(t, fakeMetadata, extractMetadata(res))
+
+	_, err = cli.RequestResponseSync(context.Background(), payload.New(fakeData, fakeMetadata))
+	assert.Error(t, err)
+	assert.Equal(t, "APPLICATION_ERROR: boom", err.Error())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = cli.RequestResponseSync(ctx, payload.New(fakeData, fakeMetadata))
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	select {
+	case sid := <-cancels:
+		assert.Equal(t, uint32(5), sid)
+	case <-time.After(3 * time.Second):
+		assert.Fail(t, "no CANCEL frame sent")
+	}
+}
+
+type countRequestMetrics struct {
+	rejected  atomic.Int32
+	cancelled atomic.Int32
+}
+
+func (c *countRequestMetrics) OnRequestRejected(requestType core.FrameType, reason socket.RejectReason) {
+	if requestType == core.FrameTypeRequestResponse && reason == socket.RejectedByLease {
+		c.rejected.Inc()
+	}
+}
+
+func (c *countRequestMetrics) OnRequestCancelled(requestType core.FrameType, requester bool) {
+	c.cancelled.Inc()
+}
+
+func TestLease_RequestMetrics(t *testing.T) {
+	ctrl, conn, tp := InitTransport(t)
+	defer ctrl.Finish()
+
+	conn.EXPECT().Close().Times(1)
+	conn.EXPECT().SetCounter(gomock.Any()).Times(1)
+	conn.EXPECT().Write(gomock.Any()).Return(nil).AnyTimes()
+	conn.EXPECT().Flush().AnyTimes()
+	conn.EXPECT().Read().Return(nil, io.EOF).AnyTimes()
+	conn.EXPECT().SetDeadline(gomock.Any()).AnyTimes()
+
+	metrics := &countRequestMetrics{}
+	ds := socket.NewClientDuplexConnection(fragmentation.MaxFragment, 90*time.Second)
+	ds.SetRequestMetrics(metrics)
+	cli := socket.NewClient(func(ctx context.Context) (*transport.Transport, error) {
+		return tp, nil
+	}, ds)
+	defer cli.Close()
+
+	setup := *fakeSetup
+	setup.Lease = true
+	err := cli.Setup(context.Background(), 0, &setup)
+	assert.NoError(t, err, "setup client failed")
+
+	// no lease received yet
+	_, err = cli.RequestResponse(payload.New(fakeData, fakeMetadata)).Block(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), metrics.rejected.Load())
+	assert.Equal(t, int32(0), metrics.cancelled.Load())
+}
+
+func TestClient_WaitReady(t *testing.T) {
+	newClient := func(conn *transporttest.Conn) socket.ClientSocket {
+		ds := socket.NewClientDuplexConnection(fragmentation.MaxFragment, 90*time.Second)
+		return socket.NewClient(func(ctx context.Context) (*transport.Transport, error) {
+			return transport.NewTransport(conn), nil
+		}, ds)
+	}
+
+	cli := newClient(transporttest.NewConn())
+	assert.NoError(t, cli.Setup(context.Background(), 0, fakeSetup))
+	assert.NoError(t, cli.WaitReady(context.Background()))
+	assert.NoError(t, cli.Close())
+	assert.Error(t, cli.WaitReady(context.Background()))
+
+	// lease is enabled, it must wait for the first LEASE.
+	conn := transporttest.NewConn()
+	cli = newClient(conn)
+	defer cli.Close()
+	setup := *fakeSetup
+	setup.Lease = true
+	assert.NoError(t, cli.Setup(context.Background(), 0, &setup))
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, cli.WaitReady(ctx))
+	conn.Push(framing.NewLeaseFrame(10*time.Second, 10, nil))
+	ctx, cancel = context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	assert.NoError(t, cli.WaitReady(ctx))
+	assert.Equal(t, core.FrameTypeSetup, core.ParseFrameHeader(conn.Written()[0]).Type())
+}
+
+func TestClient_KeepaliveRespond(t *testing.T) {
+	conn := transporttest.NewConn()
+	ds := socket.NewClientDuplexConnection(fragmentation.MaxFragment, 90*time.Second)
+	cli := socket.NewClient(func(ctx context.Context) (*transport.Transport, error) {
+		return transport.NewTransport(conn), nil
+	}, ds)
+	defer cli.Close()
+	assert.NoError(t, cli.Setup(context.Background(), 0, fakeSetup))
+
+	conn.Push(framing.NewKeepaliveFrame(0, fakeData, true))
+	conn.Push(framing.NewKeepaliveFrame(0, []byte("no-respond"), false))
+	var echoes [][]byte
+	assert.Eventually(t, func() bool {
+		echoes = echoes[:0]
+		for _, raw := range conn.Written() {
+			if core.ParseFrameHeader(raw).Type() == core.FrameTypeKeepalive {
+				echoes = append(echoes, raw)
+			}
+		}
+		return len(echoes) > 0
+	}, 3*time.Second, 10*time.Millisecond)
+	require.Len(t, echoes, 1)
+	echo, err := framing.FromBytes(echoes[0])
+	require.NoError(t, err)
+	assert.False(t, echo.HasFlag(core.FlagRespond), "RESPOND flag should be cleared")
+	assert.Equal(t, fakeData, echo.(*framing.KeepaliveFrame).Data())
+}
@@ -3,6 +3,7 @@ package socket
 import (
 	"context"
 
+	"github.com/rsocket/rsocket-go/core/framing"
 	"github.com/rsocket/rsocket-go/core/transport"
 )
 
@@ -22,6 +23,10 @@ func (p *simpleServerSocket) SetTransport(tp *transport.Transport) {
 	p.socket.SetTransport(tp)
 }
 
+func (p *simpleServerSocket) Resume(frame *framing.ResumeFrame, tp *transport.Transport) error {
+	return errReplayPosition
+}
+
 func (p *simpleServerSocket) Token() (token []byte, ok bool) {
 	return
 }
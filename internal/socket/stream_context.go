@@ -0,0 +1,60 @@
+package socket
+
+import (
+	"context"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/payload"
+)
+
+type streamContextKey struct{}
+
+type streamContextValue struct {
+	sid         uint32
+	requestType core.FrameType
+	setup       payload.SetupPayload
+	connID      string
+}
+
+// newStreamContext returns the context which responder publishers will be subscribed with.
+func (dc *DuplexConnection) newStreamContext(sid uint32, requestType core.FrameType) context.Context {
+	return context.WithValue(context.Background(), streamContextKey{}, streamContextValue{
+		sid:         sid,
+		requestType: requestType,
+		setup:       dc.setup,
+		connID:      dc.connID,
+	})
+}
+
+// SetSetupPayload sets the SETUP payload of the connection, it will be attached to the context of every responder stream.
+// The payload must stay valid for the lifetime of the connection, so it cannot be a released SETUP frame.
+func (dc *DuplexConnection) SetSetupPayload(setup payload.SetupPayload) {
+	dc.setup = setup
+}
+
+// StreamIDFromContext returns the stream ID of current responder stream.
+func StreamIDFromContext(ctx context.Context) (sid uint32, ok bool) {
+	v, ok := ctx.Value(streamContextKey{}).(streamContextValue)
+	if ok {
+		sid = v.sid
+	}
+	return
+}
+
+// RequestTypeFromContext returns the interaction type of current responder stream.
+func RequestTypeFromContext(ctx context.Context) (requestType core.FrameType, ok bool) {
+	v, ok := ctx.Value(streamContextKey{}).(streamContextValue)
+	if ok {
+		requestType = v.requestType
+	}
+	return
+}
+
+// SetupFromContext returns the SETUP payload of the connection which current responder stream belongs to.
+func SetupFromContext(ctx context.Context) (setup payload.SetupPayload, ok bool) {
+	v, ok := ctx.Value(streamContextKey{}).(streamContextValue)
+	if !ok || v.setup == nil {
+		return nil, false
+	}
+	return v.setup, true
+}
@@ -10,11 +10,21 @@ const (
 )
 
 // StreamID can be used to generate stream ids.
+// It can be replaced to force specific allocation patterns, eg: for interop tests.
 type StreamID interface {
-	// Next returns next stream id.
+	// Next returns next stream id, ids are 31-bit and zero is skipped.
+	// FirstLoop must be false once the ids wrap around, then ids still in use will be skipped.
 	Next() (id uint32, firstLoop bool)
 }
 
+// SetStreamIDs replaces the strategy of allocating stream ids, it must be called before any request is sent.
+// Nil keeps the default strategy.
+func (dc *DuplexConnection) SetStreamIDs(sids StreamID) {
+	if sids != nil {
+		dc.sids = sids
+	}
+}
+
 type serverStreamIDs struct {
 	cur uint64
 }
@@ -22,9 +32,9 @@ type serverStreamIDs struct {
 func (p *serverStreamIDs) Next() (uint32, bool) {
 	// 2,4,6,8...
 	seed := atomic.AddUint64(&p.cur, 1)
-	v := 2 * seed
-	if v != 0 {
-		return uint32(_maskStreamID & v), seed <= _halfSeed
+	// 2^31 is masked to zero when wrapping around, skip it.
+	if v := uint32(_maskStreamID & (2 * seed)); v != 0 {
+		return v, seed <= _halfSeed
 	}
 	return p.Next()
 }
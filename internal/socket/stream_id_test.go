@@ -2,6 +2,7 @@ package socket
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -20,6 +21,59 @@ func TestServerStreamIDs_Next(t *testing.T) {
 	assert.True(t, firstLap)
 }
 
+func TestServerStreamIDs_Wraparound(t *testing.T) {
+	ids := serverStreamIDs{cur: _halfSeed - 2}
+	id, firstLap := ids.Next()
+	assert.Equal(t, uint32(0x7FFFFFFE), id)
+	assert.True(t, firstLap)
+	// zero must be skipped
+	id, firstLap = ids.Next()
+	assert.Equal(t, uint32(2), id)
+	assert.False(t, firstLap)
+	id, firstLap = ids.Next()
+	assert.Equal(t, uint32(4), id)
+	assert.False(t, firstLap)
+}
+
+func TestClientStreamIDs_Wraparound(t *testing.T) {
+	ids := clientStreamIDs{cur: _halfSeed - 1}
+	id, firstLap := ids.Next()
+	assert.Equal(t, uint32(0x7FFFFFFF), id)
+	assert.True(t, firstLap)
+	id, firstLap = ids.Next()
+	assert.Equal(t, uint32(1), id)
+	assert.False(t, firstLap)
+}
+
+type fixedStreamIDs struct {
+	ids []uint32
+}
+
+func (p *fixedStreamIDs) Next() (id uint32, firstLoop bool) {
+	id, p.ids = p.ids[0], p.ids[1:]
+	return
+}
+
+func TestDuplexConnection_NextStreamID(t *testing.T) {
+	// simulate an exhausted id space: the seed is at the end of the first lap.
+	dc := NewServerDuplexConnection(0, nil)
+	dc.sids = &serverStreamIDs{cur: _halfSeed - 2}
+	dc.messages.Store(2, struct{}{})
+	dc.messages.Store(4, struct{}{})
+	assert.Equal(t, uint32(0x7FFFFFFE), dc.nextStreamID())
+	assert.Equal(t, uint32(6), dc.nextStreamID(), "ids still in use should be skipped after wraparound")
+	assert.Equal(t, uint32(8), dc.nextStreamID())
+
+	// custom strategy
+	dc = NewClientDuplexConnection(0, time.Second)
+	dc.SetStreamIDs(nil)
+	assert.IsType(t, &clientStreamIDs{}, dc.sids)
+	dc.SetStreamIDs(&fixedStreamIDs{ids: []uint32{0, 3, 7, 5}})
+	dc.messages.Store(3, struct{}{})
+	dc.messages.Store(7, struct{}{})
+	assert.Equal(t, uint32(5), dc.nextStreamID())
+}
+
 func BenchmarkServerStreamIDs_Next(b *testing.B) {
 	ids := serverStreamIDs{}
 	b.RunParallel(func(pb *testing.PB) {
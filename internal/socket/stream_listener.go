@@ -0,0 +1,82 @@
+package socket
+
+import (
+	"time"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/rx"
+)
+
+// StreamListener listens lifecycle events of streams, it can be used to create a span per stream.
+// Methods are invoked synchronously by the stream machinery, so they should return quickly.
+type StreamListener interface {
+	// OnStreamOpen is invoked when a REQUEST_RESPONSE, REQUEST_STREAM or REQUEST_CHANNEL stream is opened.
+	// Requester is true if the stream was started by current side.
+	OnStreamOpen(sid uint32, requestType core.FrameType, requester bool)
+	// OnStreamPayload is invoked for each payload received (inbound is true) or sent in the stream.
+	// Elapsed is the duration since the stream was opened.
+	OnStreamPayload(sid uint32, inbound bool, elapsed time.Duration)
+	// OnStreamClose is invoked once when the stream is completed, failed or cancelled.
+	// Elapsed is the duration since the stream was opened.
+	OnStreamClose(sid uint32, sig rx.SignalType, elapsed time.Duration)
+}
+
+// SetStreamListener sets a listener of stream lifecycle events.
+func (dc *DuplexConnection) SetStreamListener(listener StreamListener) {
+	dc.listener = listener
+	if listener != nil && dc.streams == nil {
+		dc.streams = newMap32()
+	}
+}
+
+// streamState is the bookkeeping of a stream for the listener.
+type streamState struct {
+	opened time.Time
+	in     *throughputMeter // nil unless the listener implements StreamThroughputListener
+	out    *throughputMeter
+}
+
+func (dc *DuplexConnection) streamOpen(sid uint32, requestType core.FrameType, requester bool) {
+	if dc.listener == nil {
+		return
+	}
+	state := &streamState{
+		opened: dc.clock.Now(),
+	}
+	if _, ok := dc.listener.(StreamThroughputListener); ok {
+		state.in = newThroughputMeter(state.opened)
+		state.out = newThroughputMeter(state.opened)
+	}
+	dc.streams.Store(sid, state)
+	dc.listener.OnStreamOpen(sid, requestType, requester)
+}
+
+// streamPayload is invoked with every payload of the stream, size is the length of data and metadata.
+func (dc *DuplexConnection) streamPayload(sid uint32, inbound bool, size int) {
+	if dc.listener == nil {
+		return
+	}
+	v, ok := dc.streams.Load(sid)
+	if !ok {
+		return
+	}
+	state := v.(*streamState)
+	now := dc.clock.Now()
+	dc.listener.OnStreamPayload(sid, inbound, now.Sub(state.opened))
+	meter := state.out
+	if inbound {
+		meter = state.in
+	}
+	if meter != nil {
+		dc.listener.(StreamThroughputListener).OnStreamThroughput(sid, inbound, meter.add(now, size))
+	}
+}
+
+func (dc *DuplexConnection) streamClose(sid uint32, sig rx.SignalType) {
+	if dc.listener == nil {
+		return
+	}
+	if v, ok := dc.streams.LoadAndDelete(sid); ok {
+		dc.listener.OnStreamClose(sid, sig, dc.clock.Now().Sub(v.(*streamState).opened))
+	}
+}
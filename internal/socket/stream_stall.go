@@ -0,0 +1,122 @@
+package socket
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core/clock"
+	"github.com/rsocket/rsocket-go/rx"
+)
+
+// StreamStallListener can be implemented by a StreamListener to be notified when a responding stream
+// is stalled by backpressure: all payloads requested by the peer have been sent, the stream is still
+// not completed, and no more demand has been signaled by REQUEST_N for the threshold.
+// It helps to diagnose stuck consumers.
+type StreamStallListener interface {
+	// OnStreamStall is invoked once for every stall, stalled is the duration without demand.
+	OnStreamStall(sid uint32, stalled time.Duration)
+}
+
+// SetStreamStallThreshold sets the duration without demand after which a responding REQUEST_STREAM is considered stalled.
+// Zero disables the detector, it only takes effect when the StreamListener implements StreamStallListener.
+func (dc *DuplexConnection) SetStreamStallThreshold(threshold time.Duration) {
+	if threshold < 0 {
+		threshold = 0
+	}
+	dc.stallAfter = threshold
+}
+
+// newStallDetector returns nil if the detector is disabled.
+func (dc *DuplexConnection) newStallDetector(sid uint32, initN uint32) *stallDetector {
+	if dc.stallAfter <= 0 {
+		return nil
+	}
+	l, ok := dc.listener.(StreamStallListener)
+	if !ok {
+		return nil
+	}
+	d := &stallDetector{
+		sid:       sid,
+		threshold: dc.stallAfter,
+		clock:     dc.clock,
+		listener:  l,
+	}
+	d.request(ToIntRequestN(initN))
+	return d
+}
+
+// stallDetector tracks the demand of a responding stream, methods are safe for the nil value.
+type stallDetector struct {
+	mu        sync.Mutex
+	sid       uint32
+	threshold time.Duration
+	listener  StreamStallListener
+	clock     clock.Clock
+	demand    int
+	unbounded bool
+	timer     clock.Timer
+	gen       uint64 // generation of the timer, a stale timer never fires
+	stopped   bool
+}
+
+func (d *stallDetector) request(n int) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if n >= rx.RequestMax || d.demand+n >= rx.RequestMax {
+		d.unbounded = true
+	} else {
+		d.demand += n
+	}
+	d.disarm()
+}
+
+func (d *stallDetector) sent() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.unbounded || d.demand < 1 {
+		return
+	}
+	d.demand--
+	if d.demand == 0 && !d.stopped {
+		gen := d.gen
+		d.timer = d.clock.AfterFunc(d.threshold, func() {
+			d.fire(gen)
+		})
+	}
+}
+
+func (d *stallDetector) fire(gen uint64) {
+	d.mu.Lock()
+	stalled := !d.stopped && d.gen == gen
+	if stalled {
+		d.timer = nil
+	}
+	d.mu.Unlock()
+	if stalled {
+		d.listener.OnStreamStall(d.sid, d.threshold)
+	}
+}
+
+func (d *stallDetector) stop() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.stopped = true
+	d.disarm()
+	d.mu.Unlock()
+}
+
+func (d *stallDetector) disarm() {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+		d.gen++
+	}
+}
@@ -0,0 +1,81 @@
+package socket
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	_throughputWindow  = time.Second
+	_throughputBuckets = 10
+	_throughputBucket  = _throughputWindow / _throughputBuckets
+)
+
+// StreamThroughput is the throughput of one direction of a stream, measured in a rolling window of one second.
+type StreamThroughput struct {
+	BytesPerSecond float64
+	ItemsPerSecond float64
+}
+
+// StreamThroughputListener can be implemented by a StreamListener to be notified of the throughput of active streams.
+// It is invoked after every OnStreamPayload with the throughput of the direction of the payload, bytes are counted
+// by the length of data and metadata. It helps to identify heavy streams.
+type StreamThroughputListener interface {
+	OnStreamThroughput(sid uint32, inbound bool, throughput StreamThroughput)
+}
+
+// throughputMeter counts payloads in buckets of a rolling window, so the throughput is computed in constant time.
+type throughputMeter struct {
+	mu     sync.Mutex
+	opened time.Time
+	latest int64 // index of the latest bucket since opened
+	bytes  [_throughputBuckets]int64
+	items  [_throughputBuckets]int64
+}
+
+func newThroughputMeter(opened time.Time) *throughputMeter {
+	return &throughputMeter{
+		opened: opened,
+	}
+}
+
+// add counts a payload of size bytes at now and returns the throughput in the window which ends at now.
+func (m *throughputMeter) add(now time.Time, size int) (t StreamThroughput) {
+	elapsed := now.Sub(m.opened)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	index := int64(elapsed / _throughputBucket)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if index < m.latest {
+		// the clock is not monotonic across goroutines, count it in the latest bucket.
+		index = m.latest
+	}
+	// reset buckets which have been rolled out of the window.
+	for i := m.latest + 1; i <= index && i-m.latest <= _throughputBuckets; i++ {
+		m.bytes[i%_throughputBuckets] = 0
+		m.items[i%_throughputBuckets] = 0
+	}
+	m.latest = index
+	m.bytes[index%_throughputBuckets] += int64(size)
+	m.items[index%_throughputBuckets]++
+
+	var bytes, items int64
+	for i := 0; i < _throughputBuckets; i++ {
+		bytes += m.bytes[i]
+		items += m.items[i]
+	}
+	// a young stream is measured since it was opened.
+	window := _throughputWindow
+	if elapsed < window {
+		window = elapsed
+		if window < _throughputBucket {
+			window = _throughputBucket
+		}
+	}
+	t.BytesPerSecond = float64(bytes) / window.Seconds()
+	t.ItemsPerSecond = float64(items) / window.Seconds()
+	return
+}
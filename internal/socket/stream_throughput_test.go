@@ -0,0 +1,78 @@
+package socket
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/clock"
+	"github.com/rsocket/rsocket-go/rx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThroughputMeter(t *testing.T) {
+	opened := time.Now()
+	m := newThroughputMeter(opened)
+
+	// a young stream is measured since it was opened.
+	tp := m.add(opened, 100)
+	assert.Equal(t, StreamThroughput{BytesPerSecond: 1000, ItemsPerSecond: 10}, tp)
+	tp = m.add(opened.Add(500*time.Millisecond), 100)
+	assert.Equal(t, StreamThroughput{BytesPerSecond: 400, ItemsPerSecond: 4}, tp)
+
+	// the first payload is rolled out of the window.
+	tp = m.add(opened.Add(1050*time.Millisecond), 300)
+	assert.Equal(t, StreamThroughput{BytesPerSecond: 400, ItemsPerSecond: 2}, tp)
+
+	// all buckets are rolled out after an idle window.
+	tp = m.add(opened.Add(5*time.Second), 50)
+	assert.Equal(t, StreamThroughput{BytesPerSecond: 50, ItemsPerSecond: 1}, tp)
+}
+
+type throughputRecorder struct {
+	sync.Mutex
+	recorded map[bool][]StreamThroughput
+}
+
+func (r *throughputRecorder) OnStreamOpen(sid uint32, requestType core.FrameType, requester bool) {
+}
+
+func (r *throughputRecorder) OnStreamPayload(sid uint32, inbound bool, elapsed time.Duration) {
+}
+
+func (r *throughputRecorder) OnStreamClose(sid uint32, sig rx.SignalType, elapsed time.Duration) {
+}
+
+func (r *throughputRecorder) OnStreamThroughput(sid uint32, inbound bool, throughput StreamThroughput) {
+	r.Lock()
+	r.recorded[inbound] = append(r.recorded[inbound], throughput)
+	r.Unlock()
+}
+
+func TestDuplexConnection_StreamThroughput(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	r := &throughputRecorder{recorded: make(map[bool][]StreamThroughput)}
+	dc := NewServerDuplexConnection(1024, nil)
+	dc.SetClock(fake)
+	dc.SetStreamListener(r)
+
+	dc.streamOpen(1, core.FrameTypeRequestChannel, false)
+	dc.streamPayload(1, true, 10)
+	fake.Advance(500 * time.Millisecond)
+	dc.streamPayload(1, true, 10)
+	dc.streamPayload(1, false, 1000)
+	dc.streamClose(1, rx.SignalComplete)
+	// payloads of closed streams are not measured.
+	dc.streamPayload(1, true, 10)
+
+	assert.Equal(t, map[bool][]StreamThroughput{
+		true: {
+			{BytesPerSecond: 100, ItemsPerSecond: 10},
+			{BytesPerSecond: 40, ItemsPerSecond: 4},
+		},
+		false: {
+			{BytesPerSecond: 2000, ItemsPerSecond: 2},
+		},
+	}, r.recorded)
+}
@@ -18,6 +18,7 @@ type respondChannelSubscriber struct {
 	n          uint32
 	dc         *DuplexConnection
 	rcv        flux.Processor
+	rcvDone    *atomic.Bool
 	subscribed chan<- struct{}
 	calls      *atomic.Int32
 }
@@ -27,13 +28,24 @@ type requestChannelSubscriber struct {
 	n            uint32
 	dc           *DuplexConnection
 	sndRequested *atomic.Bool
+	sndCompleted *atomic.Bool
 	rcv          flux.Processor
+	rcvDone      *atomic.Bool
 	result       chan<- error
+	window       *outboundWindow
 }
 
 func (r requestChannelSubscriber) OnNext(item payload.Payload) {
+	// the outbound side has been cancelled by the peer, items emitted before the source stops are dropped.
+	if r.sndCompleted.Load() {
+		return
+	}
+	var written func()
+	if r.window != nil {
+		written = r.window.written
+	}
 	if !r.sndRequested.CAS(false, true) {
-		r.dc.sendPayload(r.sid, item, core.FlagNext)
+		r.dc.sendPayloadThen(r.sid, item, core.FlagNext, written)
 		return
 	}
 	d := item.Data()
@@ -41,7 +53,11 @@ func (r requestChannelSubscriber) OnNext(item payload.Payload) {
 	size := framing.CalcPayloadFrameSize(d, m) + 4
 	if !r.dc.shouldSplit(size) {
 		metadata, _ := item.Metadata()
-		r.dc.sendFrame(framing.NewWriteableRequestChannelFrame(r.sid, r.n, item.Data(), metadata, core.FlagNext))
+		f := framing.NewWriteableRequestChannelFrame(r.sid, r.n, item.Data(), metadata, core.FlagNext)
+		if written != nil {
+			f.HandleDone(written)
+		}
+		r.dc.sendFrame(f)
 		return
 	}
 	r.dc.doSplitSkip(4, d, m, func(index int, result fragmentation.SplitResult) {
@@ -51,6 +67,9 @@ func (r requestChannelSubscriber) OnNext(item payload.Payload) {
 		} else {
 			f = framing.NewWriteablePayloadFrame(r.sid, result.Data, result.Metadata, result.Flag|core.FlagNext)
 		}
+		if written != nil && !result.Flag.Check(core.FlagFollow) {
+			f.HandleDone(written)
+		}
 		r.dc.sendFrame(f)
 	})
 }
@@ -62,10 +81,15 @@ func (r requestChannelSubscriber) OnError(err error) {
 	r.result <- err
 }
 
+// OnComplete completes the sending side of the channel at once, so the responder is notified even if it keeps
+// emitting, eg: a server pushing notifications until the client completes.
 func (r requestChannelSubscriber) OnComplete() {
 	defer func() {
 		_ = recover()
 	}()
+	if r.sndRequested.Load() && r.sndCompleted.CAS(false, true) {
+		r.dc.sendFrame(framing.NewWriteablePayloadFrame(r.sid, nil, nil, core.FlagComplete))
+	}
 	close(r.result)
 }
 
@@ -75,11 +99,18 @@ func (r requestChannelSubscriber) OnSubscribe(ctx context.Context, s rx.Subscrip
 		r.OnError(reactor.ErrSubscribeCancelled)
 	default:
 		cb := requestChannelCallback{
-			rcv: r.rcv,
-			snd: s,
+			rcv:          r.rcv,
+			rcvDone:      r.rcvDone,
+			snd:          s,
+			sndCompleted: r.sndCompleted,
+			result:       r.result,
+		}
+		if r.window != nil {
+			r.window.bind(s)
+			cb.snd = r.window
 		}
 		r.dc.register(r.sid, cb)
-		s.Request(1)
+		cb.snd.Request(1)
 	}
 }
 
@@ -90,6 +121,7 @@ func (r respondChannelSubscriber) OnNext(next payload.Payload) {
 func (r respondChannelSubscriber) OnError(err error) {
 	if r.calls.Inc() == 2 {
 		r.dc.unregister(r.sid)
+		r.dc.streamClose(r.sid, rx.SignalError)
 	}
 	r.dc.writeError(r.sid, err)
 }
@@ -97,6 +129,7 @@ func (r respondChannelSubscriber) OnError(err error) {
 func (r respondChannelSubscriber) OnComplete() {
 	if r.calls.Inc() == 2 {
 		r.dc.unregister(r.sid)
+		r.dc.streamClose(r.sid, rx.SignalComplete)
 	}
 	complete := framing.NewWriteablePayloadFrame(r.sid, nil, nil, core.FlagComplete)
 	done := make(chan struct{})
@@ -114,8 +147,9 @@ func (r respondChannelSubscriber) OnSubscribe(ctx context.Context, s rx.Subscrip
 		r.OnError(reactor.ErrSubscribeCancelled)
 	default:
 		cb := respondChannelCallback{
-			rcv: r.rcv,
-			snd: s,
+			rcv:     r.rcv,
+			rcvDone: r.rcvDone,
+			snd:     s,
 		}
 		r.dc.register(r.sid, cb)
 		close(r.subscribed)
@@ -6,7 +6,7 @@ import (
 
 	"github.com/jjeffcaii/reactor-go"
 	"github.com/rsocket/rsocket-go/core"
-	"github.com/rsocket/rsocket-go/internal/common"
+	"github.com/rsocket/rsocket-go/core/framing"
 	"github.com/rsocket/rsocket-go/internal/fragmentation"
 	"github.com/rsocket/rsocket-go/payload"
 	"github.com/rsocket/rsocket-go/rx"
@@ -18,17 +18,25 @@ var _requestResponseSubscriberPool = sync.Pool{
 	},
 }
 
+// requestResponseSubscriber responds a single payload, it is used by REQUEST_RESPONSE and by REQUEST_STREAM
+// whose Flux is converted from a Mono, requestType is the type of the request.
 type requestResponseSubscriber struct {
-	dc        *DuplexConnection
-	sid       uint32
-	receiving fragmentation.HeaderAndPayload
+	dc          *DuplexConnection
+	sid         uint32
+	requestType core.FrameType
+	teardown    *responderTeardown
+	stall       *stallDetector
+	sent        bool
 }
 
-func borrowRequestResponseSubscriber(dc *DuplexConnection, sid uint32, receiving fragmentation.HeaderAndPayload) rx.Subscriber {
+func borrowRequestResponseSubscriber(dc *DuplexConnection, requestType core.FrameType, sid uint32, receiving fragmentation.HeaderAndPayload, stall *stallDetector) rx.Subscriber {
 	s := _requestResponseSubscriberPool.Get().(*requestResponseSubscriber)
-	s.receiving = receiving
+	s.teardown = newResponderTeardown(receiving)
 	s.dc = dc
 	s.sid = sid
+	s.requestType = requestType
+	s.stall = stall
+	s.sent = false
 	return s
 }
 
@@ -38,24 +46,33 @@ func returnRequestResponseSubscriber(s rx.Subscriber) {
 		return
 	}
 	actual.dc = nil
-	actual.receiving = nil
+	actual.teardown = nil
+	actual.stall = nil
 	_requestResponseSubscriberPool.Put(actual)
 }
 
 func (r *requestResponseSubscriber) OnNext(next payload.Payload) {
+	r.sent = true
 	r.dc.sendPayload(r.sid, next, core.FlagNext|core.FlagComplete)
+	r.stall.sent()
 }
 
 func (r *requestResponseSubscriber) OnError(err error) {
 	defer func() {
 		r.dc.unregister(r.sid)
+		r.dc.streamClose(r.sid, rx.SignalError)
 		r.finish()
 	}()
 	r.dc.writeError(r.sid, err)
 }
 
 func (r *requestResponseSubscriber) OnComplete() {
+	if !r.sent {
+		// an empty Mono, complete without payload.
+		r.dc.sendFrame(framing.NewWriteablePayloadFrame(r.sid, nil, nil, core.FlagComplete))
+	}
 	r.dc.unregister(r.sid)
+	r.dc.streamClose(r.sid, rx.SignalComplete)
 	r.finish()
 }
 
@@ -64,12 +81,20 @@ func (r *requestResponseSubscriber) OnSubscribe(ctx context.Context, su rx.Subsc
 	case <-ctx.Done():
 		r.OnError(reactor.ErrSubscribeCancelled)
 	default:
-		r.dc.register(r.sid, requestResponseCallbackReverse{su: su})
+		r.dc.register(r.sid, requestResponseCallbackReverse{
+			su:          su,
+			requestType: r.requestType,
+			teardown:    r.teardown,
+			stall:       r.stall,
+		})
 		su.Request(rx.RequestMax)
 	}
 }
 
 func (r *requestResponseSubscriber) finish() {
-	common.TryRelease(r.receiving)
-	returnRequestResponseSubscriber(r)
+	r.teardown.release()
+	r.stall.stop()
+	if !r.teardown.isCancelled() {
+		returnRequestResponseSubscriber(r)
+	}
 }
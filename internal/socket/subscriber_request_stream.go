@@ -7,7 +7,6 @@ import (
 	"github.com/jjeffcaii/reactor-go"
 	"github.com/rsocket/rsocket-go/core"
 	"github.com/rsocket/rsocket-go/core/framing"
-	"github.com/rsocket/rsocket-go/internal/common"
 	"github.com/rsocket/rsocket-go/internal/fragmentation"
 	"github.com/rsocket/rsocket-go/payload"
 	"github.com/rsocket/rsocket-go/rx"
@@ -20,10 +19,11 @@ var _requestStreamSubscriberPool = sync.Pool{
 }
 
 type requestStreamSubscriber struct {
-	n         uint32
-	sid       uint32
-	dc        *DuplexConnection
-	receiving fragmentation.HeaderAndPayload
+	n        uint32
+	sid      uint32
+	dc       *DuplexConnection
+	teardown *responderTeardown
+	stall    *stallDetector
 }
 
 func borrowRequestStreamSubscriber(receiving fragmentation.HeaderAndPayload, dc *DuplexConnection, sid uint32, n uint32) rx.Subscriber {
@@ -31,7 +31,8 @@ func borrowRequestStreamSubscriber(receiving fragmentation.HeaderAndPayload, dc
 	s.sid = sid
 	s.dc = dc
 	s.n = n
-	s.receiving = receiving
+	s.teardown = newResponderTeardown(receiving)
+	s.stall = dc.newStallDetector(sid, n)
 	return s
 }
 
@@ -40,19 +41,26 @@ func returnRequestStreamSubscriber(s rx.Subscriber) {
 	if !ok {
 		return
 	}
-	common.TryRelease(actual.receiving)
+	actual.teardown.release()
+	actual.stall.stop()
+	if actual.teardown.isCancelled() {
+		return
+	}
 	actual.dc = nil
-	actual.receiving = nil
+	actual.teardown = nil
+	actual.stall = nil
 	_requestStreamSubscriberPool.Put(actual)
 }
 
 func (r *requestStreamSubscriber) OnNext(payload payload.Payload) {
 	r.dc.sendPayload(r.sid, payload, core.FlagNext)
+	r.stall.sent()
 }
 
 func (r *requestStreamSubscriber) OnError(err error) {
 	defer func() {
 		r.dc.unregister(r.sid)
+		r.dc.streamClose(r.sid, rx.SignalError)
 		returnRequestStreamSubscriber(r)
 	}()
 	r.dc.writeError(r.sid, err)
@@ -61,6 +69,7 @@ func (r *requestStreamSubscriber) OnError(err error) {
 func (r *requestStreamSubscriber) OnComplete() {
 	defer func() {
 		r.dc.unregister(r.sid)
+		r.dc.streamClose(r.sid, rx.SignalComplete)
 		returnRequestStreamSubscriber(r)
 	}()
 	r.dc.sendFrame(framing.NewWriteablePayloadFrame(r.sid, nil, nil, core.FlagComplete))
@@ -71,7 +80,7 @@ func (r *requestStreamSubscriber) OnSubscribe(ctx context.Context, subscription
 	case <-ctx.Done():
 		r.OnError(reactor.ErrSubscribeCancelled)
 	default:
-		r.dc.register(r.sid, requestStreamCallbackReverse{su: subscription})
+		r.dc.register(r.sid, requestStreamCallbackReverse{su: subscription, teardown: r.teardown, stall: r.stall})
 		subscription.Request(int(r.n))
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"io"
 	"time"
 
+	"github.com/rsocket/rsocket-go/core/framing"
 	"github.com/rsocket/rsocket-go/core/transport"
 	"github.com/rsocket/rsocket-go/payload"
 	"github.com/rsocket/rsocket-go/rx/flux"
@@ -36,6 +37,27 @@ type Responder interface {
 type ClientSocket interface {
 	Closeable
 	Responder
+	HealthReporter
+	// RequestResponseSync sends a RequestResponse request and blocks until the response arrives.
+	RequestResponseSync(ctx context.Context, message payload.Payload) (payload.Payload, error)
+	// KeepaliveSettings returns keepalive settings negotiated by the SETUP frame.
+	KeepaliveSettings() KeepaliveSettings
+	// WaitReady blocks until current socket is ready to send requests.
+	WaitReady(ctx context.Context) error
+	// CancelAll cancels all active requests and returns the amount of them.
+	CancelAll() int
+	// OnMetadataPush registers a handler of METADATA_PUSH frames sent by the peer.
+	OnMetadataPush(handler func(metadata []byte))
+	// ConnectTiming returns the time spent in establishing the current connection.
+	ConnectTiming() transport.ConnectTiming
+	// ActiveStreams returns the amount of streams in progress.
+	ActiveStreams() int
+	// OutboundQueueDepth returns the amount of outbound frames queued but not written yet.
+	OutboundQueueDepth() int
+	// Reassemblies returns the amount of received payloads being reassembled from fragments.
+	Reassemblies() int
+	// ConnectionID returns the ID of the connection.
+	ConnectionID() string
 	// Setup setups current socket.
 	Setup(ctx context.Context, connectTimeout time.Duration, setup *SetupInfo) error
 }
@@ -44,12 +66,36 @@ type ClientSocket interface {
 type ServerSocket interface {
 	Closeable
 	Responder
+	HealthReporter
+	// RequestResponseSync sends a RequestResponse request and blocks until the response arrives.
+	RequestResponseSync(ctx context.Context, message payload.Payload) (payload.Payload, error)
+	// KeepaliveSettings returns keepalive settings negotiated by the SETUP frame.
+	KeepaliveSettings() KeepaliveSettings
+	// WaitReady blocks until current socket is ready to send requests.
+	WaitReady(ctx context.Context) error
+	// CancelAll cancels all active requests and returns the amount of them.
+	CancelAll() int
+	// OnMetadataPush registers a handler of METADATA_PUSH frames sent by the peer.
+	OnMetadataPush(handler func(metadata []byte))
+	// ConnectTiming returns zero, the connection is not established by current side.
+	ConnectTiming() transport.ConnectTiming
+	// ActiveStreams returns the amount of streams in progress.
+	ActiveStreams() int
+	// OutboundQueueDepth returns the amount of outbound frames queued but not written yet.
+	OutboundQueueDepth() int
+	// Reassemblies returns the amount of received payloads being reassembled from fragments.
+	Reassemblies() int
+	// ConnectionID returns the ID of the connection.
+	ConnectionID() string
 	// SetResponder sets a responder for current socket.
 	SetResponder(responder Responder)
 	// SetTransport sets a transport for current socket.
 	SetTransport(tp *transport.Transport)
 	// Pause pause current socket.
 	Pause() bool
+	// Resume sends RESUME_OK and replays frames which have not been received by the peer,
+	// then binds the new transport. An error will be returned if current socket cannot be resumed.
+	Resume(frame *framing.ResumeFrame, tp *transport.Transport) error
 	// Start starts current socket.
 	Start(ctx context.Context) error
 	// Token returns token of socket.
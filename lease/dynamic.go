@@ -0,0 +1,89 @@
+package lease
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DynamicFactory is a lease factory whose grant can be changed at runtime.
+// Every connection receives a new LEASE as soon as the grant changes, and the current grant
+// is renewed once it expires, so at most NumberOfRequests are granted per TTL while nothing changes.
+type DynamicFactory struct {
+	mu      sync.Mutex
+	current Lease
+	changed chan struct{}
+}
+
+// NewDynamicFactory creates a dynamic lease factory with an initial grant.
+func NewDynamicFactory(initial Lease) (*DynamicFactory, error) {
+	if err := validateLease(initial); err != nil {
+		return nil, err
+	}
+	return &DynamicFactory{
+		current: initial,
+		changed: make(chan struct{}),
+	}, nil
+}
+
+// Update replaces current grant, then it will be pushed to all connections.
+// It can be used for adaptive load shedding, eg: reduce NumberOfRequests when CPU is busy.
+func (d *DynamicFactory) Update(next Lease) error {
+	if err := validateLease(next); err != nil {
+		return err
+	}
+	d.mu.Lock()
+	d.current = next
+	close(d.changed)
+	d.changed = make(chan struct{})
+	d.mu.Unlock()
+	return nil
+}
+
+// Current returns current grant.
+func (d *DynamicFactory) Current() (current Lease) {
+	current, _ = d.load()
+	return
+}
+
+// Next generate next lease chan.
+func (d *DynamicFactory) Next(ctx context.Context) (chan Lease, bool) {
+	ch := make(chan Lease)
+	go func(ctx context.Context, ch chan Lease) {
+		defer close(ch)
+		for {
+			current, changed := d.load()
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- current:
+			}
+			renew := time.NewTimer(current.TimeToLive)
+			select {
+			case <-ctx.Done():
+				renew.Stop()
+				return
+			case <-changed:
+				renew.Stop()
+			case <-renew.C:
+			}
+		}
+	}(ctx, ch)
+	return ch, true
+}
+
+func (d *DynamicFactory) load() (current Lease, changed <-chan struct{}) {
+	d.mu.Lock()
+	current, changed = d.current, d.changed
+	d.mu.Unlock()
+	return
+}
+
+func validateLease(l Lease) error {
+	if l.TimeToLive <= 0 {
+		return errors.Errorf("invalid lease TTL: %s", l.TimeToLive)
+	}
+	return nil
+}
@@ -19,3 +19,54 @@ func TestSimpleLease_Next(t *testing.T) {
 	assert.True(t, ok, "get lease failed")
 	fmt.Println(next)
 }
+
+func TestDynamicFactory(t *testing.T) {
+	_, err := lease.NewDynamicFactory(lease.Lease{})
+	assert.Error(t, err, "should fail with invalid TTL")
+
+	f, err := lease.NewDynamicFactory(lease.Lease{
+		TimeToLive:       10 * time.Second,
+		NumberOfRequests: 100,
+	})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, ok := f.Next(ctx)
+	assert.True(t, ok)
+
+	next := <-ch
+	assert.Equal(t, uint32(100), next.NumberOfRequests)
+
+	assert.Error(t, f.Update(lease.Lease{}), "should fail with invalid TTL")
+	err = f.Update(lease.Lease{
+		TimeToLive:       200 * time.Millisecond,
+		NumberOfRequests: 10,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(10), f.Current().NumberOfRequests)
+
+	select {
+	case next = <-ch:
+		assert.Equal(t, uint32(10), next.NumberOfRequests)
+		assert.Equal(t, 200*time.Millisecond, next.TimeToLive)
+	case <-time.After(time.Second):
+		assert.Fail(t, "no lease pushed after update")
+	}
+
+	// renewed once it expires, not before
+	select {
+	case <-ch:
+		assert.Fail(t, "lease was renewed before expiring")
+	case <-time.After(150 * time.Millisecond):
+	}
+	select {
+	case next = <-ch:
+		assert.Equal(t, uint32(10), next.NumberOfRequests)
+	case <-time.After(time.Second):
+		assert.Fail(t, "lease was not renewed")
+	}
+
+	cancel()
+	for range ch {
+	}
+}
@@ -1,12 +1,37 @@
 package logger
 
-import "log"
+import (
+	"fmt"
+	"log"
+	"strings"
 
+	"go.uber.org/atomic"
+)
+
+// The level and the logger can be changed at runtime, eg: enable debug logs of a running server without restart,
+// so every log is checked against the current ones.
 var (
-	_level         = LevelInfo
-	_logger Logger = simpleLogger{}
+	_level  = atomic.NewInt32(int32(LevelInfo))
+	_logger atomic.Value // loggerHolder
 )
 
+// loggerHolder allows to store a nil Logger in atomic.Value.
+type loggerHolder struct {
+	Logger
+}
+
+func init() {
+	_logger.Store(loggerHolder{simpleLogger{}})
+}
+
+func currentLogger() Logger {
+	return _logger.Load().(loggerHolder).Logger
+}
+
+func enabled(level Level) bool {
+	return Level(_level.Load()) <= level
+}
+
 const (
 	// LevelDebug is DEBUG level.
 	LevelDebug Level = 1 << iota
@@ -18,78 +43,175 @@ const (
 	LevelError
 )
 
+// LevelTrace is TRACE level which is lower than DEBUG.
+// It is extremely verbose and should only be used for protocol debugging.
+const LevelTrace Level = 0
+
 // Logger is used to print logs.
 type Logger interface {
 	// Debugf print to the debug level logs.
 	Debugf(format string, args ...interface{})
 	// Infof print to the info level logs.
 	Infof(format string, args ...interface{})
-	// Warnf print to the info level logs.
+	// Warnf print to the warn level logs.
 	Warnf(format string, args ...interface{})
-	// Errorf print to the info level logs.
+	// Errorf print to the error level logs.
 	Errorf(format string, args ...interface{})
 }
 
+// TraceLogger is implemented by a Logger which prints trace level logs, otherwise they are printed by Debugf.
+type TraceLogger interface {
+	// Tracef print to the trace level logs.
+	Tracef(format string, args ...interface{})
+}
+
+// Func is an adapter which routes logs into a structured logger, eg: zerolog or logrus.
+// The message is formatted already and the trailing newline is trimmed.
+// Trace logs are passed with LevelTrace.
+type Func func(level Level, msg string)
+
+// Tracef implements TraceLogger.
+func (f Func) Tracef(format string, args ...interface{}) {
+	f.printf(LevelTrace, format, args)
+}
+
+// Debugf implements Logger.
+func (f Func) Debugf(format string, args ...interface{}) {
+	f.printf(LevelDebug, format, args)
+}
+
+// Infof implements Logger.
+func (f Func) Infof(format string, args ...interface{}) {
+	f.printf(LevelInfo, format, args)
+}
+
+// Warnf implements Logger.
+func (f Func) Warnf(format string, args ...interface{}) {
+	f.printf(LevelWarn, format, args)
+}
+
+// Errorf implements Logger.
+func (f Func) Errorf(format string, args ...interface{}) {
+	f.printf(LevelError, format, args)
+}
+
+func (f Func) printf(level Level, format string, args []interface{}) {
+	f(level, strings.TrimSuffix(fmt.Sprintf(format, args...), "\n"))
+}
+
 // Level is level of logger.
 type Level int8
 
+func (l Level) String() string {
+	switch l {
+	case LevelTrace:
+		return "TRACE"
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return "UNKNOWN"
+	}
+}
+
 // SetLevel set global RSocket log level.
-// Available levels are `LevelDebug`, `LevelInfo`, `LevelWarn` and `LevelError`.
+// Available levels are `LevelTrace`, `LevelDebug`, `LevelInfo`, `LevelWarn` and `LevelError`.
+// It is safe to call at runtime and takes effect immediately.
 func SetLevel(level Level) {
-	_level = level
+	_level.Store(int32(level))
 }
 
-// SetLogger customize the global logger.
+// SetLogger customize the global logger, a nil logger disables all logs.
 // A standard log implementation will be used by default.
+// Loggers with printf-style methods (eg: *zap.SugaredLogger) can be used directly,
+// others can be adapted by Func.
 func SetLogger(logger Logger) {
-	_logger = logger
+	_logger.Store(loggerHolder{logger})
 }
 
 // GetLevel returns current logger level.
 func GetLevel() Level {
-	return _level
+	return Level(_level.Load())
 }
 
 // IsDebugEnabled returns true if debug level is open.
 func IsDebugEnabled() bool {
-	return _level <= LevelDebug
+	return enabled(LevelDebug)
+}
+
+// IsTraceEnabled returns true if trace level is open.
+func IsTraceEnabled() bool {
+	return enabled(LevelTrace)
+}
+
+// Tracef prints trace level log, it will be printed by Debugf of the logger if it doesn't implement TraceLogger.
+func Tracef(format string, args ...interface{}) {
+	if !enabled(LevelTrace) {
+		return
+	}
+	l := currentLogger()
+	if l == nil {
+		return
+	}
+	if tl, ok := l.(TraceLogger); ok {
+		tl.Tracef(format, args...)
+	} else {
+		l.Debugf(format, args...)
+	}
 }
 
 // Debugf prints debug level log.
 func Debugf(format string, args ...interface{}) {
-	if _logger == nil || _level > LevelDebug {
+	if !enabled(LevelDebug) {
 		return
 	}
-	_logger.Debugf(format, args...)
+	if l := currentLogger(); l != nil {
+		l.Debugf(format, args...)
+	}
 }
 
 // Infof prints info level log.
 func Infof(format string, args ...interface{}) {
-	if _logger == nil || _level > LevelInfo {
+	if !enabled(LevelInfo) {
 		return
 	}
-	_logger.Infof(format, args...)
+	if l := currentLogger(); l != nil {
+		l.Infof(format, args...)
+	}
 }
 
 // Warnf prints warn level log.
 func Warnf(format string, args ...interface{}) {
-	if _logger == nil || _level > LevelWarn {
+	if !enabled(LevelWarn) {
 		return
 	}
-	_logger.Warnf(format, args...)
+	if l := currentLogger(); l != nil {
+		l.Warnf(format, args...)
+	}
 }
 
 // Errorf prints error level log.
 func Errorf(format string, args ...interface{}) {
-	if _logger == nil || _level > LevelError {
+	if !enabled(LevelError) {
 		return
 	}
-	_logger.Errorf(format, args...)
+	if l := currentLogger(); l != nil {
+		l.Errorf(format, args...)
+	}
 }
 
 type simpleLogger struct {
 }
 
+func (s simpleLogger) Tracef(format string, args ...interface{}) {
+	log.Printf("[TRACE] "+format, args...)
+}
+
 func (s simpleLogger) Debugf(format string, args ...interface{}) {
 	log.Printf("[DEBUG] "+format, args...)
 }
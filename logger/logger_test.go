@@ -1,6 +1,7 @@
 package logger_test
 
 import (
+	"sync"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -47,3 +48,97 @@ func TestSetLogger(t *testing.T) {
 	logger.SetLogger(nil)
 	call()
 }
+
+func TestTracef(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// the logger doesn't implement TraceLogger, trace logs are printed by Debugf as is.
+	l := NewMockLogger(ctrl)
+	l.EXPECT().Debugf(fakeFormat, gomock.Any()).Times(2)
+	logger.SetLogger(l)
+	defer logger.SetLogger(nil)
+
+	logger.SetLevel(logger.LevelDebug)
+	assert.False(t, logger.IsTraceEnabled())
+	logger.Tracef(fakeFormat, fakeArgs...)
+	logger.Debugf(fakeFormat, fakeArgs...)
+
+	logger.SetLevel(logger.LevelTrace)
+	assert.True(t, logger.IsTraceEnabled())
+	assert.True(t, logger.IsDebugEnabled())
+	logger.Tracef(fakeFormat, fakeArgs...)
+}
+
+func TestFunc(t *testing.T) {
+	defer logger.SetLogger(nil)
+	defer logger.SetLevel(logger.LevelInfo)
+
+	var levels []logger.Level
+	var messages []string
+	logger.SetLogger(logger.Func(func(level logger.Level, msg string) {
+		levels = append(levels, level)
+		messages = append(messages, msg)
+	}))
+	logger.SetLevel(logger.LevelTrace)
+
+	logger.Tracef("trace %d\n", 0)
+	logger.Debugf("debug %d\n", 1)
+	logger.Infof("info %d\n", 2)
+	logger.Warnf("warn %d\n", 3)
+	logger.Errorf("error %d", 4)
+	logger.Debugf("[TRACE] debug %d\n", 5)
+
+	assert.Equal(t, []logger.Level{logger.LevelTrace, logger.LevelDebug, logger.LevelInfo, logger.LevelWarn, logger.LevelError, logger.LevelDebug}, levels)
+	assert.Equal(t, []string{"trace 0", "debug 1", "info 2", "warn 3", "error 4", "[TRACE] debug 5"}, messages)
+	assert.Equal(t, "WARN", logger.LevelWarn.String())
+}
+
+func TestSetLevelAtRuntime(t *testing.T) {
+	defer logger.SetLogger(nil)
+	defer logger.SetLevel(logger.LevelInfo)
+
+	var mu sync.Mutex
+	debugs := 0
+	logger.SetLogger(logger.Func(func(level logger.Level, msg string) {
+		if level == logger.LevelDebug {
+			mu.Lock()
+			debugs++
+			mu.Unlock()
+		}
+	}))
+	logger.SetLevel(logger.LevelInfo)
+
+	// the level is changed while other goroutines are logging.
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					logger.Debugf(fakeFormat, fakeArgs...)
+				}
+			}
+		}()
+	}
+	logger.SetLevel(logger.LevelDebug)
+	assert.True(t, logger.IsDebugEnabled())
+	logger.SetLevel(logger.LevelWarn)
+	close(stop)
+	wg.Wait()
+
+	// the change takes effect immediately.
+	mu.Lock()
+	before := debugs
+	mu.Unlock()
+	logger.Debugf(fakeFormat, fakeArgs...)
+	assert.Equal(t, before, debugs)
+	logger.SetLevel(logger.LevelDebug)
+	logger.Debugf(fakeFormat, fakeArgs...)
+	assert.Equal(t, before+1, debugs)
+}
@@ -39,6 +39,13 @@ type (
 		MaxLifetime() time.Duration
 		// Version return RSocket protocol version.
 		Version() core.Version
+		// LeaseRequested returns true if the client requested to honor LEASE frames sent by the server.
+		LeaseRequested() bool
+		// WillResume returns true if the client intends to resume the connection later with the token.
+		WillResume() bool
+		// Token returns the resume token, it is nil if WillResume is false.
+		// It references the SETUP frame in the ServerAcceptor, copy it if it is retained after the acceptor returns.
+		Token() []byte
 	}
 )
 
@@ -62,8 +69,10 @@ func Clone(payload Payload) Payload {
 		}
 	default:
 		data := common.CloneBytes(v.Data())
-		metadata, _ := v.Metadata()
-		metadata = common.CloneBytes(metadata)
+		var metadata []byte
+		if m, ok := v.Metadata(); ok {
+			metadata = append([]byte{}, m...)
+		}
 		return &rawPayload{
 			data:     data,
 			metadata: metadata,
@@ -72,6 +81,7 @@ func Clone(payload Payload) Payload {
 }
 
 // New create a new payload with bytes.
+// Nil metadata means absent, and a non-nil empty metadata will be sent as present with zero length.
 func New(data []byte, metadata []byte) Payload {
 	return &rawPayload{
 		data:     data,
@@ -106,9 +116,11 @@ func MustNewFile(filename string, metadata []byte) Payload {
 }
 
 // Equal returns true if payloads have same data and metadata.
+// Absent metadata is not equal to empty metadata, nil payloads are only equal to each other.
 func Equal(a Payload, b Payload) bool {
-	if a == b {
-		return true
+	// avoid comparing interfaces directly, it panics if both are of the same uncomparable type.
+	if a == nil || b == nil {
+		return a == nil && b == nil
 	}
 	if !bytes.Equal(a.Data(), b.Data()) {
 		return false
@@ -122,3 +134,45 @@ func Equal(a Payload, b Payload) bool {
 
 	return bytes.Equal(m1, m2)
 }
+
+const (
+	_fnvOffset64 uint64 = 14695981039346656037
+	_fnvPrime64  uint64 = 1099511628211
+)
+
+// Hash returns a 64-bit FNV-1a hash of data and metadata, it is consistent with Equal:
+// equal payloads always have the same hash. Absent metadata and empty metadata have different hashes.
+// It can be used as the key of caches or deduplication, but it is not a cryptographic hash.
+func Hash(p Payload) uint64 {
+	if p == nil {
+		return 0
+	}
+	h := hashLength(_fnvOffset64, len(p.Data()))
+	h = hashBytes(h, p.Data())
+	metadata, ok := p.Metadata()
+	if !ok {
+		return hashByte(h, 0)
+	}
+	h = hashByte(h, 1)
+	h = hashLength(h, len(metadata))
+	return hashBytes(h, metadata)
+}
+
+func hashByte(h uint64, b byte) uint64 {
+	return (h ^ uint64(b)) * _fnvPrime64
+}
+
+func hashBytes(h uint64, b []byte) uint64 {
+	for _, c := range b {
+		h = hashByte(h, c)
+	}
+	return h
+}
+
+// hashLength mixes the length in, so bytes moved between data and metadata change the hash.
+func hashLength(h uint64, n int) uint64 {
+	for i := uint(0); i < 8; i++ {
+		h = hashByte(h, byte(uint64(n)>>(8*i)))
+	}
+	return h
+}
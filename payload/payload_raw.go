@@ -6,7 +6,7 @@ type rawPayload struct {
 }
 
 func (p *rawPayload) Metadata() (metadata []byte, ok bool) {
-	return p.metadata, len(p.metadata) > 0
+	return p.metadata, p.metadata != nil
 }
 
 func (p *rawPayload) MetadataUTF8() (metadata string, ok bool) {
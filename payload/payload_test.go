@@ -45,6 +45,25 @@ func TestRawPayload(t *testing.T) {
 	assert.False(t, utf8.Valid([]byte(s)))
 }
 
+func TestRawPayload_Empty(t *testing.T) {
+	p := payload.New(nil, []byte{})
+	m, ok := p.Metadata()
+	assert.True(t, ok, "empty metadata should be present")
+	assert.Empty(t, m)
+	_, ok = p.MetadataUTF8()
+	assert.True(t, ok)
+	assert.Empty(t, p.Data())
+
+	_, ok = payload.New([]byte{}, nil).Metadata()
+	assert.False(t, ok)
+
+	cloned := payload.Clone(p)
+	m, ok = cloned.Metadata()
+	assert.True(t, ok, "clone should keep the empty metadata")
+	assert.NotNil(t, m)
+	assert.False(t, payload.Equal(p, payload.New(nil, nil)))
+}
+
 func TestStrPayload(t *testing.T) {
 	data, metadata := "hello", "world"
 	p := payload.NewString(data, metadata)
@@ -92,3 +111,49 @@ func TestNewFile(t *testing.T) {
 		payload.MustNewFile("/not/existing", nil)
 	}()
 }
+
+// flagPayload has empty metadata which can be present, like a frame with the METADATA flag.
+type flagPayload struct {
+	data, metadata []byte
+	hasMetadata    bool
+}
+
+func (f flagPayload) Metadata() (metadata []byte, ok bool) {
+	return f.metadata, f.hasMetadata
+}
+
+func (f flagPayload) MetadataUTF8() (metadata string, ok bool) {
+	return string(f.metadata), f.hasMetadata
+}
+
+func (f flagPayload) Data() []byte {
+	return f.data
+}
+
+func (f flagPayload) DataUTF8() string {
+	return string(f.data)
+}
+
+func TestEqualAndHash(t *testing.T) {
+	for _, it := range []struct {
+		name  string
+		a, b  payload.Payload
+		equal bool
+	}{
+		{"same", payload.NewString("foo", "bar"), payload.New([]byte("foo"), []byte("bar")), true},
+		{"custom", payload.NewString("foo", "bar"), customPayload{[]byte("foo"), []byte("bar")}, true},
+		{"no metadata", payload.NewString("foo", ""), payload.New([]byte("foo"), nil), true},
+		{"different data", payload.NewString("foo", "bar"), payload.NewString("fo", "bar"), false},
+		{"different metadata", payload.NewString("foo", "bar"), payload.NewString("foo", "baz"), false},
+		{"absent and empty metadata", flagPayload{data: []byte("foo")}, flagPayload{data: []byte("foo"), hasMetadata: true}, false},
+		{"empty metadata", flagPayload{data: []byte("foo"), hasMetadata: true}, flagPayload{data: []byte("foo"), metadata: []byte{}, hasMetadata: true}, true},
+		{"moved bytes", payload.NewString("foob", "ar"), payload.NewString("foo", "bar"), false},
+		{"nil", payload.NewString("foo", "bar"), nil, false},
+		{"both nil", nil, nil, true},
+	} {
+		assert.Equal(t, it.equal, payload.Equal(it.a, it.b), it.name)
+		assert.Equal(t, it.equal, payload.Equal(it.b, it.a), it.name)
+		assert.Equal(t, it.equal, payload.Hash(it.a) == payload.Hash(it.b), it.name)
+	}
+	assert.Equal(t, payload.Hash(payload.NewString("foo", "bar")), payload.Hash(payload.Clone(payload.NewString("foo", "bar"))))
+}
@@ -0,0 +1,33 @@
+package rsocket
+
+import (
+	"crypto/x509"
+
+	"github.com/rsocket/rsocket-go/payload"
+)
+
+// peerSetupPayload carries the verified certificate chain of the client along with the setup.
+type peerSetupPayload struct {
+	payload.SetupPayload
+	certs []*x509.Certificate
+}
+
+func newPeerSetupPayload(setup payload.SetupPayload, certs []*x509.Certificate) payload.SetupPayload {
+	if len(certs) < 1 {
+		return setup
+	}
+	return peerSetupPayload{
+		SetupPayload: setup,
+		certs:        certs,
+	}
+}
+
+// PeerCertificates returns the verified certificate chain of the client which sent the setup, the first one is the
+// leaf certificate, eg: authorize by PeerCertificates(setup)[0].Subject in the ServerAcceptor.
+// It returns nil unless the server requires client certificates, see TCPServerBuilder.RequireClientCert.
+func PeerCertificates(setup payload.SetupPayload) []*x509.Certificate {
+	if p, ok := setup.(peerSetupPayload); ok {
+		return p.certs
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package rsocket_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	. "github.com/rsocket/rsocket-go"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx/mono"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	return pool
+}
+
+func (ca *testCA) issue(t *testing.T, serial int64, commonName string, usage x509.ExtKeyUsage) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	return tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+	}
+}
+
+func TestRequireClientCert(t *testing.T) {
+	ca := newTestCA(t)
+	serverCert := ca.issue(t, 2, "server", x509.ExtKeyUsageServerAuth)
+	clientCert := ca.issue(t, 3, "alice", x509.ExtKeyUsageClientAuth)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	subjects := make(chan string, 1)
+	started := make(chan struct{})
+	go func() {
+		_ = Receive().
+			OnStart(func() {
+				close(started)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+				certs := PeerCertificates(setup)
+				require.Len(t, certs, 2)
+				subject := certs[0].Subject.CommonName
+				subjects <- subject
+				return NewAbstractSocket(
+					RequestResponse(func(request payload.Payload) mono.Mono {
+						return mono.Just(payload.NewString("hello "+subject, ""))
+					}),
+				), nil
+			}).
+			Transport(TCPServer().
+				SetAddr("127.0.0.1:8103").
+				SetTLSConfig(&tls.Config{Certificates: []tls.Certificate{serverCert}}).
+				RequireClientCert(ca.pool()).
+				Build()).
+			Serve(ctx)
+	}()
+	<-started
+
+	connect := func(certs ...tls.Certificate) (Client, error) {
+		return Connect().
+			Transport(TCPClient().
+				SetAddr("127.0.0.1:8103").
+				SetTLSConfig(&tls.Config{ServerName: "127.0.0.1", RootCAs: ca.pool(), Certificates: certs}).
+				Build()).
+			Start(ctx)
+	}
+
+	cli, err := connect(clientCert)
+	require.NoError(t, err)
+	defer cli.Close()
+	res, err := cli.RequestResponse(payload.NewString("ping", "")).Block(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "hello alice", res.DataUTF8())
+	assert.Equal(t, "alice", <-subjects)
+
+	// a client without certificate is rejected by the handshake.
+	if anonymous, err := connect(); err == nil {
+		_, err = anonymous.RequestResponse(payload.NewString("ping", "")).Block(ctx)
+		assert.Error(t, err)
+		_ = anonymous.Close()
+	}
+	assert.Len(t, subjects, 0, "acceptor should not be invoked without client certificate")
+
+	// a certificate issued by an unknown CA is rejected too.
+	other := newTestCA(t).issue(t, 4, "mallory", x509.ExtKeyUsageClientAuth)
+	if untrusted, err := connect(other); err == nil {
+		_, err = untrusted.RequestResponse(payload.NewString("ping", "")).Block(ctx)
+		assert.Error(t, err)
+		_ = untrusted.Close()
+	}
+	assert.Len(t, subjects, 0)
+}
+
+func TestRequireClientCert_WithoutTLS(t *testing.T) {
+	err := Receive().
+		Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+			return NewAbstractSocket(), nil
+		}).
+		Transport(TCPServer().SetAddr("127.0.0.1:8104").RequireClientCert(nil).Build()).
+		Serve(context.Background())
+	assert.Error(t, err)
+	assert.Nil(t, PeerCertificates(nil))
+}
@@ -0,0 +1,48 @@
+// Package proto converts between payloads and protobuf messages.
+// It is a separate package, so the protobuf dependency is only required by the programs which import it.
+package proto
+
+import (
+	"github.com/rsocket/rsocket-go/extension"
+	"github.com/rsocket/rsocket-go/payload"
+	pb "google.golang.org/protobuf/proto"
+)
+
+// MimeType is the data MIME type of protobuf messages.
+const MimeType = "application/x-protobuf"
+
+// MarshalPayload creates a payload whose data is the protobuf encoding of the message, the metadata is absent.
+func MarshalPayload(m pb.Message) (payload.Payload, error) {
+	data, err := pb.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return payload.New(data, nil), nil
+}
+
+// MarshalPayloadWithMimeType is like MarshalPayload, but the metadata is a CompositeMetadata which declares
+// the data MIME type of the payload as MimeType, see extension.MessageMimeType.
+// It is useful when the data MIME type of the connection is not protobuf.
+func MarshalPayloadWithMimeType(m pb.Message) (payload.Payload, error) {
+	data, err := pb.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	mimeType, err := extension.EncodeDataMimeType(MimeType)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := extension.NewCompositeMetadataBuilder().
+		PushWellKnown(extension.MessageMimeType, mimeType).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+	return payload.New(data, metadata), nil
+}
+
+// UnmarshalPayload parses the data of the payload into the message, the metadata is ignored.
+// The payload can be released after it returns.
+func UnmarshalPayload(p payload.Payload, m pb.Message) error {
+	return pb.Unmarshal(p.Data(), m)
+}
@@ -0,0 +1,44 @@
+package proto_test
+
+import (
+	"testing"
+
+	"github.com/rsocket/rsocket-go/extension"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestMarshalPayload(t *testing.T) {
+	p, err := proto.MarshalPayload(wrapperspb.String("foobar"))
+	require.NoError(t, err)
+	_, ok := p.Metadata()
+	assert.False(t, ok)
+
+	var m wrapperspb.StringValue
+	assert.NoError(t, proto.UnmarshalPayload(p, &m))
+	assert.Equal(t, "foobar", m.GetValue())
+
+	assert.Error(t, proto.UnmarshalPayload(payload.NewString("\xff\xff", ""), &m))
+}
+
+func TestMarshalPayloadWithMimeType(t *testing.T) {
+	p, err := proto.MarshalPayloadWithMimeType(wrapperspb.Int64(42))
+	require.NoError(t, err)
+	metadata, ok := p.Metadata()
+	require.True(t, ok)
+	scanner := extension.NewCompositeMetadataBytes(metadata).Scanner()
+	require.True(t, scanner.Scan())
+	mimeType, entry, err := scanner.Metadata()
+	require.NoError(t, err)
+	assert.Equal(t, extension.MessageMimeType.String(), mimeType)
+	dataMimeType, err := extension.ParseDataMimeType(entry)
+	assert.NoError(t, err)
+	assert.Equal(t, proto.MimeType, dataMimeType)
+
+	var m wrapperspb.Int64Value
+	assert.NoError(t, proto.UnmarshalPayload(p, &m))
+	assert.Equal(t, int64(42), m.GetValue())
+}
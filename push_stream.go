@@ -0,0 +1,83 @@
+package rsocket
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx"
+	"github.com/rsocket/rsocket-go/rx/flux"
+)
+
+// PushRegistry keeps the sending sockets of connected clients by client id, so that the server can push
+// notifications to a client without being requested, eg: a pub/sub server.
+// A push stream is a REQUEST_CHANNEL initiated by the server, since the requester of a REQUEST_STREAM receives the
+// items instead of sending them. Clients receive push streams by PushStreamHandler.
+type PushRegistry struct {
+	mu      sync.RWMutex
+	sockets map[string]CloseableRSocket
+}
+
+// NewPushRegistry creates a new PushRegistry.
+func NewPushRegistry() *PushRegistry {
+	return &PushRegistry{
+		sockets: make(map[string]CloseableRSocket),
+	}
+}
+
+// Register registers the sending socket of a client, it is usually called in the ServerAcceptor with an id
+// from the SETUP payload. It replaces the socket registered with the same id, the socket is unregistered once closed.
+func (r *PushRegistry) Register(clientID string, sendingSocket CloseableRSocket) {
+	r.mu.Lock()
+	r.sockets[clientID] = sendingSocket
+	r.mu.Unlock()
+	sendingSocket.OnClose(func(error) {
+		r.mu.Lock()
+		if r.sockets[clientID] == sendingSocket {
+			delete(r.sockets, clientID)
+		}
+		r.mu.Unlock()
+	})
+}
+
+// Clients returns the ids of registered clients.
+func (r *PushRegistry) Clients() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ids := make([]string, 0, len(r.sockets))
+	for id := range r.sockets {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// PushStream pushes the notifications to the client and blocks until all of them have been sent and the client
+// has completed the stream. The push stream is cancelled if the context is done.
+// It returns an error if the client is not registered or the stream fails.
+func (r *PushRegistry) PushStream(ctx context.Context, clientID string, notifications flux.Flux) error {
+	r.mu.RLock()
+	sendingSocket, ok := r.sockets[clientID]
+	r.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("rsocket: no such client %s", clientID)
+	}
+	_, err := sendingSocket.RequestChannel(notifications).BlockLast(ctx)
+	return err
+}
+
+// PushStreamHandler returns an option of the client acceptor which receives push streams of a PushRegistry.
+// The handler must subscribe to the notifications, the push stream is completed after the notifications terminate.
+// It takes the place of the RequestChannel handler, since push streams are carried by REQUEST_CHANNEL.
+func PushStreamHandler(handler func(notifications flux.Flux)) OptAbstractSocket {
+	return RequestChannel(func(notifications flux.Flux) flux.Flux {
+		done := make(chan payload.Payload)
+		var once sync.Once
+		handler(notifications.DoFinally(func(rx.SignalType) {
+			once.Do(func() {
+				close(done)
+			})
+		}))
+		return flux.CreateFromChannel(done, nil)
+	})
+}
@@ -0,0 +1,85 @@
+package rsocket
+
+import (
+	"context"
+
+	"github.com/rsocket/rsocket-go/logger"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx/flux"
+	"github.com/rsocket/rsocket-go/rx/mono"
+	"go.uber.org/atomic"
+)
+
+// RequestInterceptor is invoked with the request payload of every request just before it is sent, it returns the payload
+// which is sent instead, eg: a copy with an authentication entry in the CompositeMetadata. The request fails with the error.
+type RequestInterceptor = func(request payload.Payload) (payload.Payload, error)
+
+// interceptedClient applies interceptors to requests of the client, METADATA_PUSH is not a request so it is sent as is.
+type interceptedClient struct {
+	CloseableRSocket
+	interceptors []RequestInterceptor
+}
+
+func newInterceptedClient(client CloseableRSocket, interceptors []RequestInterceptor) CloseableRSocket {
+	if len(interceptors) < 1 {
+		return client
+	}
+	return interceptedClient{
+		CloseableRSocket: client,
+		interceptors:     interceptors,
+	}
+}
+
+func (c interceptedClient) intercept(request payload.Payload) (payload.Payload, error) {
+	var err error
+	for _, interceptor := range c.interceptors {
+		if request, err = interceptor(request); err != nil {
+			return nil, err
+		}
+	}
+	return request, nil
+}
+
+func (c interceptedClient) FireAndForget(request payload.Payload) {
+	request, err := c.intercept(request)
+	if err != nil {
+		logger.Warnf("request FireAndForget failed: %v\n", err)
+		return
+	}
+	c.CloseableRSocket.FireAndForget(request)
+}
+
+func (c interceptedClient) RequestResponse(request payload.Payload) mono.Mono {
+	request, err := c.intercept(request)
+	if err != nil {
+		return mono.Error(err)
+	}
+	return c.CloseableRSocket.RequestResponse(request)
+}
+
+func (c interceptedClient) RequestResponseSync(ctx context.Context, request payload.Payload) (payload.Payload, error) {
+	request, err := c.intercept(request)
+	if err != nil {
+		return nil, err
+	}
+	return c.CloseableRSocket.RequestResponseSync(ctx, request)
+}
+
+func (c interceptedClient) RequestStream(request payload.Payload) flux.Flux {
+	request, err := c.intercept(request)
+	if err != nil {
+		return flux.Error(err)
+	}
+	return c.CloseableRSocket.RequestStream(request)
+}
+
+// RequestChannel intercepts the first payload which is sent by the REQUEST_CHANNEL frame.
+func (c interceptedClient) RequestChannel(requests flux.Flux) flux.Flux {
+	first := atomic.NewBool(true)
+	return c.CloseableRSocket.RequestChannel(requests.Map(func(request payload.Payload) (payload.Payload, error) {
+		if first.CAS(true, false) {
+			return c.intercept(request)
+		}
+		return request, nil
+	}))
+}
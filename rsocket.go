@@ -1,7 +1,10 @@
 package rsocket
 
 import (
+	"context"
+
 	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/transport"
 	"github.com/rsocket/rsocket-go/internal/socket"
 	"github.com/rsocket/rsocket-go/payload"
 	"github.com/rsocket/rsocket-go/rx/flux"
@@ -31,6 +34,49 @@ const (
 	ErrorCodeInvalid = core.ErrorCodeInvalid
 )
 
+// All reject reasons
+const (
+	// RejectedByLease means the request is rejected by requester because no lease is available.
+	RejectedByLease = socket.RejectedByLease
+	// RejectedByDraining means the request is rejected by responder because it is draining.
+	RejectedByDraining = socket.RejectedByDraining
+	// RejectedBySaturation means the request is rejected by responder because its dispatch scheduler is saturated.
+	RejectedBySaturation = socket.RejectedBySaturation
+)
+
+// All drop reasons
+const (
+	// DroppedByInvalidStreamID means the stream id is invalid for the frame type, eg: METADATA_PUSH with non-zero stream id.
+	DroppedByInvalidStreamID = transport.DroppedByInvalidStreamID
+	// DroppedByUnknownType means the frame type is not understood, and the frame can be ignored because of the IGNORE flag.
+	DroppedByUnknownType = transport.DroppedByUnknownType
+	// DroppedByUnmatchedStream means the stream of the frame doesn't exist, maybe it has been cancelled or completed.
+	DroppedByUnmatchedStream = transport.DroppedByUnmatchedStream
+	// DroppedByLease means the request frame is not sent because no lease is available.
+	DroppedByLease = transport.DroppedByLease
+	// DroppedByTerminatedStream means the receiving side of a channel has been completed or cancelled, but the stream
+	// is still open for sending, eg: a PAYLOAD after COMPLETE.
+	DroppedByTerminatedStream = transport.DroppedByTerminatedStream
+)
+
+// All frame orderings
+const (
+	// StrictOrdering writes outbound frames exactly in the order they are emitted, it is the default.
+	StrictOrdering = socket.StrictOrdering
+	// PerStreamOrdering keeps the order of frames in every stream, but interleaves frames of different streams.
+	PerStreamOrdering = socket.PerStreamOrdering
+	// FairOrdering keeps the order of frames in every stream, and writes pending frames of different streams in round-robin.
+	FairOrdering = socket.FairOrdering
+)
+
+// All health states
+const (
+	// HealthConnected means the keepalive round-trip completed within the keepalive interval.
+	HealthConnected = socket.HealthConnected
+	// HealthDegraded means the keepalive round-trip took longer than the keepalive interval.
+	HealthDegraded = socket.HealthDegraded
+)
+
 // Aliases for Error defines.
 type (
 	// ErrorCode is code for RSocket error.
@@ -60,11 +106,89 @@ type (
 	// CloseableRSocket is RSocket which can be closed and handle close event.
 	CloseableRSocket interface {
 		socket.Closeable
+		socket.HealthReporter
 		RSocket
+		// RequestResponseSync sends a RequestResponse request and blocks until the response arrives.
+		// It skips the reactive pipeline, so it is cheaper than RequestResponse(...).Block(ctx).
+		// A CANCEL frame will be sent if the context is done before the response.
+		RequestResponseSync(ctx context.Context, message payload.Payload) (payload.Payload, error)
+		// KeepaliveSettings returns keepalive interval and max lifetime negotiated by the SETUP frame.
+		KeepaliveSettings() KeepaliveSettings
+		// WaitReady blocks until the connection is usable: the SETUP handshake has been sent on a connected transport
+		// (it also waits for reconnecting if Resume is enabled), and the first LEASE has been received if Lease is enabled.
+		// It returns an error if the socket has been closed or the context is done.
+		WaitReady(ctx context.Context) error
+		// CancelAll sends CANCEL for every active request sent by current socket and terminates them with core.ErrRequestCancelled.
+		// The connection is kept, new requests can be sent after it. It returns the amount of cancelled requests.
+		CancelAll() int
+		// OnMetadataPush registers a handler of METADATA_PUSH frames sent by the peer, the metadata can be retained safely.
+		// It can be used to exchange small configurations after SETUP, eg: feature flags and protocol sub-version,
+		// data can be sent by MetadataPush. A registered handler takes over METADATA_PUSH from the responder.
+		OnMetadataPush(handler func(metadata []byte))
+		// ConnectTiming returns the time spent in every phase of establishing the current connection of a client:
+		// TCP connect, TLS handshake and SETUP. It is updated when a resumable client reconnects, and it is always
+		// zero for the sending socket of a server.
+		ConnectTiming() ConnectTiming
+		// ActiveStreams returns the amount of streams in progress on the connection, both requested and responded.
+		ActiveStreams() int
+		// OutboundQueueDepth returns the amount of outbound frames queued but not written yet, a growing depth indicates
+		// a slow peer. Once MaxOutboundBufferBytes is exceeded, senders block instead of growing the queue.
+		OutboundQueueDepth() int
+		// Reassemblies returns the amount of received payloads being reassembled from fragments on the connection.
+		// It is capped by MaxReassemblies.
+		Reassemblies() int
+		// ConnectionID returns the ID of the current connection, which is generated when it is set up. It appears in logs
+		// of the connection, see ConnectionIDFromContext. A client gets a new ID when it reconnects without resuming.
+		ConnectionID() string
 	}
 
 	// OptAbstractSocket is option for abstract socket.
 	OptAbstractSocket func(*socket.AbstractRSocket)
+
+	// KeepaliveSettings represents keepalive settings negotiated by the SETUP frame.
+	KeepaliveSettings = socket.KeepaliveSettings
+
+	// StreamListener listens lifecycle events of streams, it can be used for span-per-stream tracing.
+	StreamListener = socket.StreamListener
+
+	// StreamStallListener can be implemented by a StreamListener to be notified when a stream is stalled by backpressure.
+	StreamStallListener = socket.StreamStallListener
+
+	// StreamThroughputListener can be implemented by a StreamListener to be notified of the throughput of active streams.
+	StreamThroughputListener = socket.StreamThroughputListener
+
+	// StreamThroughput is the throughput of one direction of a stream, measured in a rolling window of one second.
+	StreamThroughput = socket.StreamThroughput
+
+	// RequestMetrics receives counter events of rejected or cancelled requests.
+	RequestMetrics = socket.RequestMetrics
+
+	// RejectReason is the reason why a request is rejected.
+	RejectReason = socket.RejectReason
+
+	// DropReason is the reason why a frame is dropped without being handled.
+	DropReason = transport.DropReason
+
+	// FrameDropHandler is invoked when a frame is dropped without being handled.
+	FrameDropHandler = transport.FrameDropHandler
+
+	// ConnectTiming is the time spent in every phase of establishing a client connection.
+	ConnectTiming = transport.ConnectTiming
+
+	// FragmentationMetrics receives events of fragmented payloads, it can be used to tune the MTU.
+	FragmentationMetrics = socket.FragmentationMetrics
+
+	// StreamIDAllocator generates stream ids of requests, it can force specific allocation patterns for interop tests.
+	StreamIDAllocator = socket.StreamID
+
+	// FrameOrdering controls the order in which outbound frames of different streams are written.
+	FrameOrdering = socket.FrameOrdering
+
+	// HealthEvent is emitted by CloseableRSocket.HealthEvents on every keepalive round-trip.
+	HealthEvent = socket.HealthEvent
+
+	// HealthState is the state of a connection observed by keepalive round-trips.
+	HealthState = socket.HealthState
 )
 
 // NewAbstractSocket returns an abstract implementation of RSocket.
@@ -111,3 +235,14 @@ func RequestChannel(fn func(requests flux.Flux) (responses flux.Flux)) OptAbstra
 		opts.RC = fn
 	}
 }
+
+// StreamIDFromContext returns the stream ID of current request.
+// The context is the one which the responding Mono or Flux is subscribed with, eg: mono.Create(func(ctx context.Context, sink mono.Sink){...}).
+func StreamIDFromContext(ctx context.Context) (uint32, bool) {
+	return socket.StreamIDFromContext(ctx)
+}
+
+// RequestTypeFromContext returns the interaction type of current request, eg: core.FrameTypeRequestStream.
+func RequestTypeFromContext(ctx context.Context) (core.FrameType, bool) {
+	return socket.RequestTypeFromContext(ctx)
+}
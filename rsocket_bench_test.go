@@ -0,0 +1,67 @@
+package rsocket_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/rsocket/rsocket-go"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx/mono"
+)
+
+func startEchoBenchServer(ctx context.Context, b *testing.B, port int) Client {
+	started := make(chan struct{})
+	go func() {
+		_ = Receive().
+			OnStart(func() {
+				close(started)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+				return NewAbstractSocket(RequestResponse(func(request payload.Payload) mono.Mono {
+					return mono.Just(request)
+				})), nil
+			}).
+			Transport(TCPServer().SetHostAndPort("127.0.0.1", port).Build()).
+			Serve(ctx)
+	}()
+	<-started
+	cli, err := Connect().Transport(TCPClient().SetHostAndPort("127.0.0.1", port).Build()).Start(ctx)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return cli
+}
+
+func BenchmarkRequestResponse_Block(b *testing.B) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cli := startEchoBenchServer(ctx, b, 8092)
+	defer cli.Close()
+
+	req := payload.NewString("hello", "world")
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := cli.RequestResponse(req).Block(ctx); err != nil {
+				b.Error(err)
+			}
+		}
+	})
+}
+
+func BenchmarkRequestResponse_Sync(b *testing.B) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cli := startEchoBenchServer(ctx, b, 8093)
+	defer cli.Close()
+
+	req := payload.NewString("hello", "world")
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := cli.RequestResponseSync(ctx, req); err != nil {
+				b.Error(err)
+			}
+		}
+	})
+}
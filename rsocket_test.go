@@ -11,11 +11,16 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/jjeffcaii/reactor-go"
 	"github.com/jjeffcaii/reactor-go/scheduler"
 	"github.com/pkg/errors"
 	. "github.com/rsocket/rsocket-go"
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/clock"
+	"github.com/rsocket/rsocket-go/core/framing"
 	"github.com/rsocket/rsocket-go/core/transport"
+	"github.com/rsocket/rsocket-go/core/transport/transporttest"
 	"github.com/rsocket/rsocket-go/extension"
 	"github.com/rsocket/rsocket-go/internal/common"
 	"github.com/rsocket/rsocket-go/lease"
@@ -56,7 +61,7 @@ func TestResume(t *testing.T) {
 	connected := int32(0)
 
 	defer func() {
-		assert.Equal(t, int32(1), atomic.LoadInt32(&connected), "connected should be 1")
+		assert.Equal(t, int32(2), atomic.LoadInt32(&connected), "connected should be 2")
 	}()
 
 	go func(ctx context.Context) {
@@ -125,9 +130,15 @@ func TestResume(t *testing.T) {
 
 	defer (<-ch).Close()
 
-	// client should request failed
-	_, _, err = cli.RequestResponse(payload.NewString("vvv", "vvv")).BlockUnsafe(ctx)
-	assert.Error(t, err, "should return error")
+	// server should reject the expired session, then client should setup a new one.
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&connected) == 2
+	}, 10*time.Second, 50*time.Millisecond, "client should setup a new session")
+	require.NoError(t, cli.WaitReady(ctx))
+	res, release, err = cli.RequestResponse(fakeRequest).BlockUnsafe(ctx)
+	assert.NoError(t, err, "request failed")
+	assert.True(t, payload.Equal(res, fakeRequest))
+	release()
 }
 
 func TestReceiveWithBadArgs(t *testing.T) {
@@ -159,6 +170,41 @@ func TestConnectWithBadArgs(t *testing.T) {
 	assert.Error(t, err, "should connect failed")
 }
 
+func TestClientBuilder_Validate(t *testing.T) {
+	assert.NoError(t, Connect().Validate())
+
+	err := Connect().
+		Fragment(-999).
+		KeepAlive(1*time.Minute, 10*time.Second, 3).
+		ConnectTimeout(-1).
+		QueueDuringReconnect(10, time.Second).
+		MaxOutboundBufferBytes(-1).
+		Ordering(FrameOrdering(9)).
+		Validate()
+	assert.Error(t, err)
+	errs, ok := err.(ConfigErrors)
+	require.True(t, ok)
+	assert.Len(t, errs, 6)
+
+	_, err = Connect().
+		KeepAlive(1*time.Minute, 10*time.Second, 3).
+		Transport(TCPClient().SetHostAndPort("127.0.0.1", DefaultPort).Build()).
+		Start(context.Background())
+	assert.IsType(t, ConfigErrors{}, err)
+}
+
+func TestServerBuilder_Validate(t *testing.T) {
+	assert.NoError(t, Receive().Validate())
+	err := Receive().
+		Fragment(-999).
+		Resume(WithServerResumeSessionDuration(0)).
+		MaxOutboundBufferBytes(-1).
+		Ordering(FrameOrdering(9)).
+		Validate()
+	assert.Error(t, err)
+	assert.Len(t, err.(ConfigErrors), 4)
+}
+
 func TestConnectBroken(t *testing.T) {
 	started := make(chan struct{})
 	ctx, cancel := context.WithCancel(context.Background())
@@ -187,7 +233,12 @@ func TestConnectBroken(t *testing.T) {
 
 	go func() {
 		defer wg.Done()
-		cli, err := Connect().Resume().Transport(TCPClient().SetHostAndPort("127.0.0.1", port).Build()).Start(ctx)
+		_, err := Connect().Resume().Transport(TCPClient().SetHostAndPort("127.0.0.1", port).Build()).Start(ctx)
+		assert.Error(t, err, "should connect failed")
+		cli, err := Connect().Resume().
+			SetupTimeout(0).
+			Transport(TCPClient().SetHostAndPort("127.0.0.1", port).Build()).
+			Start(ctx)
 		require.NoError(t, err, "connect failed")
 		defer cli.Close()
 		_, _, err = cli.RequestResponse(fakeRequest).BlockUnsafe(ctx)
@@ -196,7 +247,12 @@ func TestConnectBroken(t *testing.T) {
 
 	go func() {
 		defer wg.Done()
-		cli, err := Connect().Lease().Transport(TCPClient().SetHostAndPort("127.0.0.1", port).Build()).Start(ctx)
+		_, err := Connect().Lease().Transport(TCPClient().SetHostAndPort("127.0.0.1", port).Build()).Start(ctx)
+		assert.Error(t, err, "should connect failed")
+		cli, err := Connect().Lease().
+			SetupTimeout(0).
+			Transport(TCPClient().SetHostAndPort("127.0.0.1", port).Build()).
+			Start(ctx)
 		require.NoError(t, err, "connect failed")
 		defer cli.Close()
 		_, _, err = cli.RequestResponse(fakeRequest).BlockUnsafe(ctx)
@@ -253,7 +309,7 @@ func TestBiDirection(t *testing.T) {
 		}).
 		Fragment(0).
 		Lease().
-		KeepAlive(1*time.Minute, 10*time.Second, 3).
+		KeepAlive(10*time.Second, 1*time.Minute, 3).
 		DataMimeType(extension.TextPlain.String()).
 		MetadataMimeType(extension.TextPlain.String()).
 		Resume(WithClientResumeToken(func() []byte {
@@ -693,3 +749,2075 @@ func TestEchoParallel(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+// streamError subscribes the Flux and returns the error it terminates with, it is used instead of BlockLast
+// whose error is not synchronized with the subscriber.
+func streamError(ctx context.Context, f flux.Flux) error {
+	errs := make(chan error, 1)
+	f.Subscribe(ctx,
+		rx.OnError(func(e error) {
+			errs <- e
+		}),
+		rx.OnComplete(func() {
+			errs <- nil
+		}),
+	)
+	select {
+	case err := <-errs:
+		return err
+	case <-time.After(3 * time.Second):
+		return errors.New("stream is not terminated")
+	}
+}
+
+func TestServer_Drain(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{})
+	s := Receive().
+		OnStart(func() {
+			close(started)
+		}).
+		Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+			return NewAbstractSocket(
+				RequestResponse(func(request payload.Payload) mono.Mono {
+					return mono.Just(payload.Clone(request))
+				}),
+				RequestStream(func(request payload.Payload) flux.Flux {
+					return flux.Just(payload.Clone(request))
+				}),
+			), nil
+		}).
+		Transport(TCPServer().SetAddr(":8089").Build())
+	go func() {
+		_ = s.Serve(ctx)
+	}()
+	<-started
+
+	cli, err := Connect().Transport(TCPClient().SetAddr("127.0.0.1:8089").Build()).Start(ctx)
+	require.NoError(t, err)
+	defer cli.Close()
+
+	res, err := cli.RequestResponse(fakeRequest).Block(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, fakeData, res.DataUTF8())
+
+	s.Drain()
+	assert.True(t, s.IsDraining())
+
+	_, err = cli.RequestResponse(fakeRequest).Block(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, ErrorCodeRejected, err.(Error).ErrorCode())
+
+	err = streamError(ctx, cli.RequestStream(fakeRequest))
+	assert.Error(t, err)
+	assert.Equal(t, ErrorCodeRejected, err.(Error).ErrorCode())
+
+	s.Undrain()
+	assert.False(t, s.IsDraining())
+
+	res, err = cli.RequestResponse(fakeRequest).Block(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, fakeData, res.DataUTF8())
+}
+
+func TestDynamicLease(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	leases, err := lease.NewDynamicFactory(lease.Lease{
+		TimeToLive:       10 * time.Second,
+		NumberOfRequests: 1,
+	})
+	require.NoError(t, err)
+
+	started := make(chan struct{})
+	go func() {
+		_ = Receive().
+			Lease(leases).
+			OnStart(func() {
+				close(started)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+				return NewAbstractSocket(RequestResponse(func(request payload.Payload) mono.Mono {
+					return mono.Just(payload.Clone(request))
+				})), nil
+			}).
+			Transport(TCPServer().SetAddr(":8090").Build()).
+			Serve(ctx)
+	}()
+	<-started
+
+	cli, err := Connect().Lease().Transport(TCPClient().SetAddr("127.0.0.1:8090").Build()).Start(ctx)
+	require.NoError(t, err)
+	defer cli.Close()
+
+	time.Sleep(100 * time.Millisecond)
+
+	_, err = cli.RequestResponse(fakeRequest).Block(ctx)
+	assert.NoError(t, err)
+	_, err = cli.RequestResponse(fakeRequest).Block(ctx)
+	assert.Equal(t, lease.ErrLeaseNoMoreRequests, err)
+
+	err = leases.Update(lease.Lease{
+		TimeToLive:       10 * time.Second,
+		NumberOfRequests: 2,
+	})
+	assert.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+
+	for i := 0; i < 2; i++ {
+		_, err = cli.RequestResponse(fakeRequest).Block(ctx)
+		assert.NoError(t, err)
+	}
+	_, err = cli.RequestResponse(fakeRequest).Block(ctx)
+	assert.Equal(t, lease.ErrLeaseNoMoreRequests, err)
+}
+
+type streamEvent struct {
+	sid       uint32
+	kind      string
+	requester bool
+	sig       rx.SignalType
+}
+
+type recordStreamListener struct {
+	sync.Mutex
+	events []streamEvent
+}
+
+func (r *recordStreamListener) OnStreamOpen(sid uint32, requestType core.FrameType, requester bool) {
+	r.Lock()
+	r.events = append(r.events, streamEvent{sid: sid, kind: "open:" + requestType.String(), requester: requester})
+	r.Unlock()
+}
+
+func (r *recordStreamListener) OnStreamPayload(sid uint32, inbound bool, elapsed time.Duration) {
+	r.Lock()
+	r.events = append(r.events, streamEvent{sid: sid, kind: fmt.Sprintf("payload:%v", inbound)})
+	r.Unlock()
+}
+
+func (r *recordStreamListener) OnStreamClose(sid uint32, sig rx.SignalType, elapsed time.Duration) {
+	r.Lock()
+	r.events = append(r.events, streamEvent{sid: sid, kind: "close", sig: sig})
+	r.Unlock()
+}
+
+func (r *recordStreamListener) Events() []streamEvent {
+	r.Lock()
+	defer r.Unlock()
+	return append([]streamEvent(nil), r.events...)
+}
+
+func TestStreamListener(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	serverListener := &recordStreamListener{}
+	clientListener := &recordStreamListener{}
+
+	started := make(chan struct{})
+	go func() {
+		_ = Receive().
+			StreamListener(serverListener).
+			OnStart(func() {
+				close(started)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+				return NewAbstractSocket(
+					RequestResponse(func(request payload.Payload) mono.Mono {
+						return mono.Just(payload.Clone(request))
+					}),
+					RequestStream(func(request payload.Payload) flux.Flux {
+						return flux.Just(payload.Clone(request), payload.Clone(request))
+					}),
+				), nil
+			}).
+			Transport(TCPServer().SetAddr(":8091").Build()).
+			Serve(ctx)
+	}()
+	<-started
+
+	cli, err := Connect().
+		StreamListener(clientListener).
+		Transport(TCPClient().SetAddr("127.0.0.1:8091").Build()).
+		Start(ctx)
+	require.NoError(t, err)
+	defer cli.Close()
+
+	_, err = cli.RequestResponse(fakeRequest).Block(ctx)
+	assert.NoError(t, err)
+	_, err = cli.RequestStream(fakeRequest).BlockLast(ctx)
+	assert.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+
+	assert.Equal(t, []streamEvent{
+		{sid: 1, kind: "open:REQUEST_RESPONSE", requester: true},
+		{sid: 1, kind: "payload:true"},
+		{sid: 1, kind: "close", sig: rx.SignalComplete},
+		{sid: 3, kind: "open:REQUEST_STREAM", requester: true},
+		{sid: 3, kind: "payload:true"},
+		{sid: 3, kind: "payload:true"},
+		{sid: 3, kind: "close", sig: rx.SignalComplete},
+	}, clientListener.Events())
+
+	assert.Equal(t, []streamEvent{
+		{sid: 1, kind: "open:REQUEST_RESPONSE"},
+		{sid: 1, kind: "payload:false"},
+		{sid: 1, kind: "close", sig: rx.SignalComplete},
+		{sid: 3, kind: "open:REQUEST_STREAM"},
+		{sid: 3, kind: "payload:false"},
+		{sid: 3, kind: "payload:false"},
+		{sid: 3, kind: "close", sig: rx.SignalComplete},
+	}, serverListener.Events())
+}
+
+func TestStreamIDFromContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	describe := func(ctx context.Context) payload.Payload {
+		sid, ok := StreamIDFromContext(ctx)
+		assert.True(t, ok)
+		requestType, ok := RequestTypeFromContext(ctx)
+		assert.True(t, ok)
+		return payload.NewString(fmt.Sprintf("%d:%s", sid, requestType), "")
+	}
+
+	started := make(chan struct{})
+	go func() {
+		_ = Receive().
+			OnStart(func() {
+				close(started)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+				return NewAbstractSocket(
+					RequestResponse(func(request payload.Payload) mono.Mono {
+						return mono.Create(func(ctx context.Context, sink mono.Sink) {
+							sink.Success(describe(ctx))
+						})
+					}),
+					RequestStream(func(request payload.Payload) flux.Flux {
+						return flux.Create(func(ctx context.Context, sink flux.Sink) {
+							sink.Next(describe(ctx))
+							sink.Complete()
+						})
+					}),
+				), nil
+			}).
+			Transport(TCPServer().SetAddr(":8094").Build()).
+			Serve(ctx)
+	}()
+	<-started
+
+	cli, err := Connect().Transport(TCPClient().SetAddr("127.0.0.1:8094").Build()).Start(ctx)
+	require.NoError(t, err)
+	defer cli.Close()
+
+	res, err := cli.RequestResponseSync(ctx, fakeRequest)
+	assert.NoError(t, err)
+	assert.Equal(t, "1:REQUEST_RESPONSE", res.DataUTF8())
+
+	var last string
+	_, err = cli.RequestStream(fakeRequest).
+		DoOnNext(func(input payload.Payload) error {
+			last = input.DataUTF8()
+			return nil
+		}).
+		BlockLast(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "3:REQUEST_STREAM", last)
+
+	_, ok := StreamIDFromContext(ctx)
+	assert.False(t, ok)
+}
+
+func TestSetupFromContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{})
+	go func() {
+		_ = Receive().
+			OnStart(func() {
+				close(started)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+				return NewAbstractSocket(
+					RequestResponse(func(request payload.Payload) mono.Mono {
+						return mono.Create(func(ctx context.Context, sink mono.Sink) {
+							setup, ok := SetupFromContext(ctx)
+							if !ok {
+								sink.Error(errors.New("no setup in context"))
+								return
+							}
+							metadata, _ := setup.MetadataUTF8()
+							sink.Success(payload.NewString(setup.DataUTF8()+":"+metadata, setup.DataMimeType()))
+						})
+					}),
+				), nil
+			}).
+			Transport(TCPServer().SetAddr(":8111").Build()).
+			Serve(ctx)
+	}()
+	<-started
+
+	cli, err := Connect().
+		SetupPayload(payload.NewString(setupData, setupMetadata)).
+		DataMimeType("text/plain").
+		Transport(TCPClient().SetAddr("127.0.0.1:8111").Build()).
+		Start(ctx)
+	require.NoError(t, err)
+	defer cli.Close()
+
+	// the SETUP frame may be released, a copy is attached to every request.
+	for i := 0; i < 3; i++ {
+		res, err := cli.RequestResponseSync(ctx, fakeRequest)
+		require.NoError(t, err)
+		assert.Equal(t, setupData+":"+setupMetadata, res.DataUTF8())
+		metadata, _ := res.MetadataUTF8()
+		assert.Equal(t, "text/plain", metadata)
+	}
+
+	_, ok := SetupFromContext(ctx)
+	assert.False(t, ok)
+}
+
+func TestEmptySetupPayload(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	type received struct {
+		data        []byte
+		hasMetadata bool
+	}
+	setups := make(chan received, 1)
+	started := make(chan struct{})
+	go func() {
+		_ = Receive().
+			OnStart(func() {
+				close(started)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+				_, hasMetadata := setup.Metadata()
+				setups <- received{data: setup.Data(), hasMetadata: hasMetadata}
+				return NewAbstractSocket(
+					RequestResponse(func(request payload.Payload) mono.Mono {
+						return mono.Create(func(ctx context.Context, sink mono.Sink) {
+							setup, ok := SetupFromContext(ctx)
+							if !ok {
+								sink.Error(errors.New("no setup in context"))
+								return
+							}
+							_, hasMetadata := setup.Metadata()
+							sink.Success(payload.NewString(fmt.Sprintf("%d:%v", len(setup.Data()), hasMetadata), ""))
+						})
+					}),
+				), nil
+			}).
+			Transport(TCPServer().SetAddr(":8123").Build()).
+			Serve(ctx)
+	}()
+	<-started
+
+	for _, builder := range []ClientBuilder{Connect(), Connect().SetupPayload(nil)} {
+		cli, err := builder.
+			Transport(TCPClient().SetAddr("127.0.0.1:8123").Build()).
+			Start(ctx)
+		require.NoError(t, err)
+		select {
+		case setup := <-setups:
+			assert.Nil(t, setup.data)
+			assert.False(t, setup.hasMetadata)
+		case <-time.After(3 * time.Second):
+			require.FailNow(t, "no setup received")
+		}
+		res, err := cli.RequestResponseSync(ctx, fakeRequest)
+		require.NoError(t, err)
+		assert.Equal(t, "0:false", res.DataUTF8())
+		_ = cli.Close()
+	}
+}
+
+func TestConnectionID(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	acceptedIDs := make(chan string, 1)
+	started := make(chan struct{})
+	s := Receive().
+		OnStart(func() {
+			close(started)
+		}).
+		ConnectionIDGenerator(func() string {
+			return "server-conn"
+		}).
+		Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+			acceptedIDs <- sendingSocket.ConnectionID()
+			return NewAbstractSocket(
+				RequestResponse(func(request payload.Payload) mono.Mono {
+					return mono.Create(func(ctx context.Context, sink mono.Sink) {
+						id, ok := ConnectionIDFromContext(ctx)
+						if !ok {
+							sink.Error(errors.New("no connection id in context"))
+							return
+						}
+						sink.Success(payload.NewString(id, ""))
+					})
+				}),
+			), nil
+		}).
+		Transport(TCPServer().SetAddr(":8124").Build())
+	go func() {
+		_ = s.Serve(ctx)
+	}()
+	<-started
+
+	cli, err := Connect().
+		Transport(TCPClient().SetAddr("127.0.0.1:8124").Build()).
+		Start(ctx)
+	require.NoError(t, err)
+	defer cli.Close()
+
+	// a random UUID is generated by default.
+	_, err = uuid.Parse(cli.ConnectionID())
+	assert.NoError(t, err, "bad connection id: %s", cli.ConnectionID())
+	assert.Equal(t, "server-conn", <-acceptedIDs)
+
+	res, err := cli.RequestResponseSync(ctx, fakeRequest)
+	require.NoError(t, err)
+	assert.Equal(t, "server-conn", res.DataUTF8())
+	sessions := s.Sessions()
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "server-conn", sessions[0].ConnectionID)
+
+	_, ok := ConnectionIDFromContext(ctx)
+	assert.False(t, ok)
+}
+
+func TestServer_MaxConcurrentSetups(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var inProgress, maxInProgress int32
+	started := make(chan struct{})
+	go func() {
+		_ = Receive().
+			MaxConcurrentSetups(2, 5*time.Second).
+			OnStart(func() {
+				close(started)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+				n := atomic.AddInt32(&inProgress, 1)
+				defer atomic.AddInt32(&inProgress, -1)
+				for {
+					max := atomic.LoadInt32(&maxInProgress)
+					if n <= max || atomic.CompareAndSwapInt32(&maxInProgress, max, n) {
+						break
+					}
+				}
+				time.Sleep(20 * time.Millisecond)
+				return NewAbstractSocket(
+					RequestResponse(func(request payload.Payload) mono.Mono {
+						return mono.Just(request)
+					}),
+				), nil
+			}).
+			Transport(TCPServer().SetAddr(":8112").Build()).
+			Serve(ctx)
+	}()
+	<-started
+
+	// a burst of connections
+	const clients = 10
+	var wg sync.WaitGroup
+	wg.Add(clients)
+	for i := 0; i < clients; i++ {
+		go func() {
+			defer wg.Done()
+			cli, err := Connect().Transport(TCPClient().SetAddr("127.0.0.1:8112").Build()).Start(ctx)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer cli.Close()
+			_, err = cli.RequestResponseSync(ctx, fakeRequest)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&maxInProgress))
+}
+
+func TestServer_MaxConcurrentSetupsReject(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	accepting := make(chan struct{})
+	blocked := make(chan struct{})
+	started := make(chan struct{})
+	go func() {
+		_ = Receive().
+			MaxConcurrentSetups(1, 50*time.Millisecond).
+			OnStart(func() {
+				close(started)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+				close(accepting)
+				<-blocked
+				return NewAbstractSocket(), nil
+			}).
+			Transport(TCPServer().SetAddr(":8113").Build()).
+			Serve(ctx)
+	}()
+	<-started
+
+	// the SETUP of the first client is not confirmed until the acceptor returns.
+	first, err := Connect().
+		SetupTimeout(0).
+		Transport(TCPClient().SetAddr("127.0.0.1:8113").Build()).
+		Start(ctx)
+	require.NoError(t, err)
+	defer first.Close()
+	<-accepting
+
+	_, err = Connect().
+		SetupTimeout(3 * time.Second).
+		Transport(TCPClient().SetAddr("127.0.0.1:8113").Build()).
+		Start(ctx)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "too many handshakes in progress")
+	close(blocked)
+}
+
+// tieredLeases grants one lease with metadata to every connection.
+type tieredLeases struct{}
+
+func (tieredLeases) Next(ctx context.Context) (chan lease.Lease, bool) {
+	ch := make(chan lease.Lease, 1)
+	ch <- lease.Lease{
+		TimeToLive:       time.Minute,
+		NumberOfRequests: 3,
+		Metadata:         []byte("tier=gold"),
+	}
+	go func() {
+		<-ctx.Done()
+		close(ch)
+	}()
+	return ch, true
+}
+
+func TestClientBuilder_OnLease(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{})
+	go func() {
+		_ = Receive().
+			Lease(tieredLeases{}).
+			OnStart(func() {
+				close(started)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+				return NewAbstractSocket(), nil
+			}).
+			Transport(TCPServer().SetAddr(":8114").Build()).
+			Serve(ctx)
+	}()
+	<-started
+
+	leases := make(chan lease.Lease, 1)
+	cli, err := Connect().
+		OnLease(func(lease lease.Lease) {
+			leases <- lease
+		}).
+		Transport(TCPClient().SetAddr("127.0.0.1:8114").Build()).
+		Start(ctx)
+	require.NoError(t, err)
+	defer cli.Close()
+
+	select {
+	case got := <-leases:
+		assert.Equal(t, time.Minute, got.TimeToLive)
+		assert.Equal(t, uint32(3), got.NumberOfRequests)
+		assert.Equal(t, "tier=gold", string(got.Metadata))
+	case <-time.After(3 * time.Second):
+		require.FailNow(t, "no lease received")
+	}
+	// OnLease enables lease, requests are allowed by the received one.
+	require.NoError(t, cli.WaitReady(ctx))
+}
+
+func TestServerAcceptor_SetupFlags(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	type setupFlags struct {
+		lease, resume bool
+		token         string
+	}
+	setups := make(chan setupFlags, 2)
+	started := make(chan struct{})
+	go func() {
+		l, _ := lease.NewSimpleFactory(time.Second, time.Second, 0, 10)
+		_ = Receive().
+			Lease(l).
+			Resume().
+			OnStart(func() {
+				close(started)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+				setups <- setupFlags{
+					lease:  setup.LeaseRequested(),
+					resume: setup.WillResume(),
+					token:  string(setup.Token()),
+				}
+				return NewAbstractSocket(), nil
+			}).
+			Transport(TCPServer().SetAddr(":8115").Build()).
+			Serve(ctx)
+	}()
+	<-started
+
+	plain, err := Connect().Transport(TCPClient().SetAddr("127.0.0.1:8115").Build()).Start(ctx)
+	require.NoError(t, err)
+	defer plain.Close()
+	assert.Equal(t, setupFlags{}, <-setups)
+
+	resumable, err := Connect().
+		Lease().
+		Resume(WithClientResumeToken(func() []byte {
+			return []byte("my-token")
+		})).
+		Transport(TCPClient().SetAddr("127.0.0.1:8115").Build()).
+		Start(ctx)
+	require.NoError(t, err)
+	defer resumable.Close()
+	assert.Equal(t, setupFlags{lease: true, resume: true, token: "my-token"}, <-setups)
+}
+
+func TestClientBuilder_InterceptRequest(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	auth := extension.NewRequestAuthentication(func(request payload.Payload, auth *extension.Authentication) error {
+		if string(auth.Payload()) != "secret" {
+			return errors.New("bad token")
+		}
+		return nil
+	})
+	authenticated := make(chan bool, 2)
+	started := make(chan struct{})
+	go func() {
+		_ = Receive().
+			OnStart(func() {
+				close(started)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+				return NewAbstractSocket(
+					RequestResponse(auth.RequestResponse(func(request payload.Payload) mono.Mono {
+						return mono.Just(payload.NewString(request.DataUTF8(), ""))
+					})),
+					RequestChannel(func(requests flux.Flux) flux.Flux {
+						requests.
+							DoOnNext(func(request payload.Payload) error {
+								_, ok := request.Metadata()
+								authenticated <- ok
+								return nil
+							}).
+							Subscribe(context.Background())
+						return flux.Just(payload.NewString("ok", ""))
+					}),
+				), nil
+			}).
+			Transport(TCPServer().SetAddr(":8116").Build()).
+			Serve(ctx)
+	}()
+	<-started
+
+	connect := func(token string) Client {
+		cli, err := Connect().
+			InterceptRequest(func(request payload.Payload) (payload.Payload, error) {
+				return payload.NewString(request.DataUTF8()+"!", ""), nil
+			}).
+			InterceptRequest(extension.InjectAuthentication(func(request payload.Payload) (*extension.Authentication, error) {
+				return extension.NewAuthentication("bearer", []byte(token))
+			})).
+			Transport(TCPClient().SetAddr("127.0.0.1:8116").Build()).
+			Start(ctx)
+		require.NoError(t, err)
+		return cli
+	}
+
+	cli := connect("secret")
+	defer cli.Close()
+	res, err := cli.RequestResponse(payload.NewString("hello", "")).Block(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "hello!", res.DataUTF8(), "interceptors should be invoked in order")
+	res, err = cli.RequestResponseSync(ctx, payload.NewString("sync", ""))
+	require.NoError(t, err)
+	assert.Equal(t, "sync!", res.DataUTF8())
+
+	// only the first payload of a channel is intercepted.
+	_, err = cli.RequestChannel(flux.Just(payload.NewString("1", ""), payload.NewString("2", ""))).BlockLast(ctx)
+	require.NoError(t, err)
+	assert.True(t, <-authenticated)
+	assert.False(t, <-authenticated)
+
+	rejected := connect("wrong")
+	defer rejected.Close()
+	_, err = rejected.RequestResponse(payload.NewString("hello", "")).Block(ctx)
+	require.Error(t, err)
+	e, ok := err.(Error)
+	require.True(t, ok, "should be an ERROR frame")
+	assert.Equal(t, ErrorCodeRejected, e.ErrorCode())
+}
+
+func TestClient_Reauthenticate(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{})
+	go func() {
+		_ = Receive().
+			OnStart(func() {
+				close(started)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+				return NewAbstractSocket(
+					RequestResponse(func(request payload.Payload) mono.Mono {
+						return mono.Create(func(ctx context.Context, sink mono.Sink) {
+							setup, _ := SetupFromContext(ctx)
+							sink.Success(payload.NewString(setup.DataUTF8(), ""))
+						})
+					}),
+					RequestStream(func(request payload.Payload) flux.Flux {
+						return flux.Create(func(ctx context.Context, sink flux.Sink) {
+							for i := 0; i < 3; i++ {
+								time.Sleep(50 * time.Millisecond)
+								sink.Next(payload.NewString(fmt.Sprint(i), ""))
+							}
+							sink.Complete()
+						})
+					}),
+				), nil
+			}).
+			Transport(TCPServer().SetAddr(":8117").Build()).
+			Serve(ctx)
+	}()
+	<-started
+
+	var closed int32
+	cli, err := Connect().
+		SetupPayload(payload.NewString("token-1", "")).
+		OnClose(func(error) {
+			atomic.AddInt32(&closed, 1)
+		}).
+		Transport(TCPClient().SetAddr("127.0.0.1:8117").Build()).
+		Start(ctx)
+	require.NoError(t, err)
+	res, err := cli.RequestResponse(payload.NewString("whoami", "")).Block(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", res.DataUTF8())
+
+	// a stream in flight is drained by the previous connection.
+	var received []string
+	streamDone := make(chan error, 1)
+	cli.RequestStream(payload.NewString("stream", "")).Subscribe(ctx,
+		rx.OnNext(func(input payload.Payload) error {
+			received = append(received, string(input.Data()))
+			return nil
+		}),
+		rx.OnComplete(func() {
+			streamDone <- nil
+		}),
+		rx.OnError(func(e error) {
+			streamDone <- e
+		}),
+	)
+	assert.Eventually(t, func() bool {
+		return cli.ActiveStreams() == 1
+	}, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, cli.Reauthenticate(ctx, payload.NewString("token-2", "")))
+	select {
+	case err := <-streamDone:
+		assert.NoError(t, err)
+	default:
+		assert.Fail(t, "the stream should be drained before the previous connection is closed")
+	}
+	assert.Equal(t, []string{"0", "1", "2"}, received)
+
+	res, err = cli.RequestResponse(payload.NewString("whoami", "")).Block(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", res.DataUTF8())
+
+	// a nil payload sets up without data.
+	require.NoError(t, cli.Reauthenticate(ctx, nil))
+	res, err = cli.RequestResponse(payload.NewString("whoami", "")).Block(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "", res.DataUTF8())
+	assert.Equal(t, int32(0), atomic.LoadInt32(&closed), "replaced connections should be closed silently")
+
+	require.NoError(t, cli.Close())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&closed))
+	assert.Error(t, cli.Reauthenticate(ctx, payload.NewString("token-3", "")))
+}
+
+type recordRequestMetrics struct {
+	sync.Mutex
+	events []string
+}
+
+func (r *recordRequestMetrics) OnRequestRejected(requestType core.FrameType, reason RejectReason) {
+	r.Lock()
+	r.events = append(r.events, fmt.Sprintf("rejected:%s:%s", requestType, reason))
+	r.Unlock()
+}
+
+func (r *recordRequestMetrics) OnRequestCancelled(requestType core.FrameType, requester bool) {
+	r.Lock()
+	r.events = append(r.events, fmt.Sprintf("cancelled:%s:%t", requestType, requester))
+	r.Unlock()
+}
+
+func (r *recordRequestMetrics) snapshot() []string {
+	r.Lock()
+	defer r.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+func TestRequestMetrics(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	serverMetrics := &recordRequestMetrics{}
+	clientMetrics := &recordRequestMetrics{}
+
+	started := make(chan struct{})
+	s := Receive().
+		RequestMetrics(serverMetrics).
+		OnStart(func() {
+			close(started)
+		}).
+		Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+			return NewAbstractSocket(
+				RequestResponse(func(request payload.Payload) mono.Mono {
+					// never respond
+					return mono.Create(func(ctx context.Context, sink mono.Sink) {
+					})
+				}),
+			), nil
+		}).
+		Transport(TCPServer().SetAddr(":8095").Build())
+	go func() {
+		_ = s.Serve(ctx)
+	}()
+	<-started
+
+	cli, err := Connect().
+		RequestMetrics(clientMetrics).
+		Transport(TCPClient().SetAddr("127.0.0.1:8095").Build()).
+		Start(ctx)
+	require.NoError(t, err)
+	defer cli.Close()
+
+	timeout, cancelTimeout := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancelTimeout()
+	_, err = cli.RequestResponseSync(timeout, fakeRequest)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	s.Drain()
+	_, err = cli.RequestResponseSync(ctx, fakeRequest)
+	assert.Error(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+
+	assert.Equal(t, []string{"cancelled:REQUEST_RESPONSE:true"}, clientMetrics.snapshot())
+	assert.Equal(t, []string{"cancelled:REQUEST_RESPONSE:false", "rejected:REQUEST_RESPONSE:DRAINING"}, serverMetrics.snapshot())
+}
+
+func TestKeepaliveSettings(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{})
+	negotiated := make(chan KeepaliveSettings, 1)
+	go func() {
+		_ = Receive().
+			OnStart(func() {
+				close(started)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+				negotiated <- sendingSocket.KeepaliveSettings()
+				return NewAbstractSocket(), nil
+			}).
+			Transport(TCPServer().SetAddr(":8096").Build()).
+			Serve(ctx)
+	}()
+	<-started
+
+	cli, err := Connect().
+		KeepAlive(10*time.Second, 20*time.Second, 3).
+		Transport(TCPClient().SetAddr("127.0.0.1:8096").Build()).
+		Start(ctx)
+	require.NoError(t, err)
+	defer cli.Close()
+
+	expect := KeepaliveSettings{
+		Interval:    10 * time.Second,
+		MaxLifetime: 60 * time.Second,
+	}
+	assert.Equal(t, expect, cli.KeepaliveSettings())
+	select {
+	case actual := <-negotiated:
+		assert.Equal(t, expect, actual)
+	case <-time.After(3 * time.Second):
+		assert.FailNow(t, "no setup received")
+	}
+}
+
+func TestClient_CancelAll(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{})
+	hanging := make(chan struct{})
+	var serverCancelled int32
+	go func() {
+		_ = Receive().
+			OnStart(func() {
+				close(started)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+				return NewAbstractSocket(
+					RequestResponse(func(msg payload.Payload) mono.Mono {
+						if msg.DataUTF8() == "ping" {
+							return mono.Just(payload.NewString("pong", ""))
+						}
+						return mono.Create(func(ctx context.Context, sink mono.Sink) {
+							close(hanging)
+						}).DoFinally(func(s rx.SignalType) {
+							if s == rx.SignalCancel {
+								atomic.AddInt32(&serverCancelled, 1)
+							}
+						})
+					}),
+					RequestStream(func(msg payload.Payload) flux.Flux {
+						return flux.Create(func(ctx context.Context, sink flux.Sink) {
+							sink.Next(payload.NewString("first", ""))
+						}).DoFinally(func(s rx.SignalType) {
+							if s == rx.SignalCancel {
+								atomic.AddInt32(&serverCancelled, 1)
+							}
+						})
+					}),
+				), nil
+			}).
+			Transport(TCPServer().SetAddr(":8097").Build()).
+			Serve(ctx)
+	}()
+	<-started
+
+	cli, err := Connect().
+		Transport(TCPClient().SetAddr("127.0.0.1:8097").Build()).
+		Start(ctx)
+	require.NoError(t, err)
+	defer cli.Close()
+
+	const streams = 3
+	errs := make(chan error, streams+1)
+	received := make(chan struct{}, streams)
+	for i := 0; i < streams; i++ {
+		cli.RequestStream(payload.NewString("stream", "")).
+			DoOnNext(func(input payload.Payload) error {
+				received <- struct{}{}
+				return nil
+			}).
+			DoOnError(func(e error) {
+				errs <- e
+			}).
+			Subscribe(ctx)
+	}
+	cli.RequestResponse(payload.NewString("hang", "")).
+		DoOnError(func(e error) {
+			errs <- e
+		}).
+		Subscribe(ctx)
+	for i := 0; i < streams; i++ {
+		select {
+		case <-received:
+		case <-time.After(3 * time.Second):
+			require.FailNow(t, "streams are not active")
+		}
+	}
+	select {
+	case <-hanging:
+	case <-time.After(3 * time.Second):
+		require.FailNow(t, "request is not active")
+	}
+
+	assert.Equal(t, streams+1, cli.CancelAll())
+	for i := 0; i < streams+1; i++ {
+		select {
+		case e := <-errs:
+			assert.Equal(t, core.ErrRequestCancelled, e)
+		case <-time.After(3 * time.Second):
+			require.FailNow(t, "request is not terminated")
+		}
+	}
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&serverCancelled) == streams+1
+	}, 3*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 0, cli.CancelAll())
+
+	// the connection is still usable.
+	res, err := cli.RequestResponse(payload.NewString("ping", "")).Block(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "pong", res.DataUTF8())
+}
+
+func TestServe_Network(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// an IPv4 address can never be bound by a tcp6 listener.
+	var started int32
+	err := Receive().
+		OnStart(func() {
+			atomic.StoreInt32(&started, 1)
+		}).
+		Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+			return NewAbstractSocket(), nil
+		}).
+		Transport(TCPServer().SetNetwork("tcp6").SetAddr("127.0.0.1:8098").Build()).
+		Serve(ctx)
+	assert.Error(t, err)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&started), "OnStart should not be invoked")
+
+	ready := make(chan struct{})
+	go func() {
+		_ = Receive().
+			OnStart(func() {
+				close(ready)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+				return NewAbstractSocket(), nil
+			}).
+			Transport(TCPServer().SetNetwork("tcp4").SetDualStack(false).SetAddr(":8098").Build()).
+			Serve(ctx)
+	}()
+	<-ready
+	cli, err := Connect().
+		Transport(TCPClient().SetAddr("127.0.0.1:8098").Build()).
+		Start(ctx)
+	require.NoError(t, err)
+	_ = cli.Close()
+}
+
+func TestMetadataPushHandshake(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{})
+	clientCapabilities := make(chan string, 1)
+	go func() {
+		_ = Receive().
+			OnStart(func() {
+				close(started)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+				sendingSocket.OnMetadataPush(func(metadata []byte) {
+					clientCapabilities <- string(metadata)
+				})
+				// announce capabilities of server once the connection is accepted.
+				sendingSocket.MetadataPush(payload.New(nil, []byte("version=2;features=lease")))
+				return NewAbstractSocket(), nil
+			}).
+			Transport(TCPServer().SetAddr(":8099").Build()).
+			Serve(ctx)
+	}()
+	<-started
+
+	serverCapabilities := make(chan string, 1)
+	cli, err := Connect().
+		OnMetadataPush(func(metadata []byte) {
+			serverCapabilities <- string(metadata)
+		}).
+		Transport(TCPClient().SetAddr("127.0.0.1:8099").Build()).
+		Start(ctx)
+	require.NoError(t, err)
+	defer cli.Close()
+	cli.MetadataPush(payload.New(nil, []byte("version=1;features=resume")))
+
+	for _, it := range []struct {
+		received <-chan string
+		expect   string
+	}{
+		{serverCapabilities, "version=2;features=lease"},
+		{clientCapabilities, "version=1;features=resume"},
+	} {
+		select {
+		case actual := <-it.received:
+			assert.Equal(t, it.expect, actual)
+		case <-time.After(3 * time.Second):
+			require.FailNow(t, "no capabilities received")
+		}
+	}
+}
+
+func TestClientBuilder_SetupPayloadFactory(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{})
+	tokens := make(chan string, 2)
+	go func() {
+		_ = Receive().
+			OnStart(func() {
+				close(started)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+				token, _ := setup.MetadataUTF8()
+				tokens <- token
+				return NewAbstractSocket(), nil
+			}).
+			Transport(TCPServer().SetAddr(":8100").Build()).
+			Serve(ctx)
+	}()
+	<-started
+
+	var generated int32
+	builder := Connect().
+		SetupPayloadFactory(func() payload.Payload {
+			n := atomic.AddInt32(&generated, 1)
+			return payload.NewString("", fmt.Sprintf("token-%d", n))
+		})
+	for i := 1; i <= 2; i++ {
+		cli, err := builder.
+			Transport(TCPClient().SetAddr("127.0.0.1:8100").Build()).
+			Start(ctx)
+		require.NoError(t, err)
+		select {
+		case token := <-tokens:
+			assert.Equal(t, fmt.Sprintf("token-%d", i), token)
+		case <-time.After(3 * time.Second):
+			require.FailNow(t, "no setup received")
+		}
+		_ = cli.Close()
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&generated))
+}
+
+func TestClientBuilder_InterceptSetup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{})
+	setups := make(chan [2]string, 1)
+	go func() {
+		_ = Receive().
+			OnStart(func() {
+				close(started)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+				metadata, _ := setup.MetadataUTF8()
+				setups <- [2]string{setup.DataUTF8(), metadata}
+				return NewAbstractSocket(), nil
+			}).
+			Transport(TCPServer().SetAddr(":8106").Build()).
+			Serve(ctx)
+	}()
+	<-started
+
+	cli, err := Connect().
+		DataMimeType("text/plain").
+		SetupPayload(payload.NewString("hello", "")).
+		InterceptSetup(func(setup *framing.WriteableSetupFrame) {
+			setup.SetMetadata([]byte(fmt.Sprintf("signed(%s,%s)", setup.DataMimeType(), setup.Data())))
+		}).
+		Transport(TCPClient().SetAddr("127.0.0.1:8106").Build()).
+		Start(ctx)
+	require.NoError(t, err)
+	defer cli.Close()
+	select {
+	case setup := <-setups:
+		assert.Equal(t, [2]string{"hello", "signed(text/plain,hello)"}, setup)
+	case <-time.After(3 * time.Second):
+		require.FailNow(t, "no setup received")
+	}
+}
+
+func TestServer_NilResponder(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{})
+	go func() {
+		_ = Receive().
+			OnStart(func() {
+				close(started)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+				return NewAbstractSocket(
+					RequestResponse(func(payload.Payload) mono.Mono {
+						return nil
+					}),
+					RequestStream(func(payload.Payload) flux.Flux {
+						return nil
+					}),
+					RequestChannel(func(flux.Flux) flux.Flux {
+						return nil
+					}),
+				), nil
+			}).
+			Transport(TCPServer().SetAddr(":8107").Build()).
+			Serve(ctx)
+	}()
+	<-started
+
+	cli, err := Connect().Transport(TCPClient().SetAddr("127.0.0.1:8107").Build()).Start(ctx)
+	require.NoError(t, err)
+	defer cli.Close()
+
+	checkErr := func(err error, expect string) {
+		require.Error(t, err)
+		require.Implements(t, (*Error)(nil), err)
+		assert.Equal(t, ErrorCodeApplicationError, err.(Error).ErrorCode())
+		assert.Equal(t, expect, string(err.(Error).ErrorData()))
+	}
+	borrowed := common.CountBorrowed()
+	_, err = cli.RequestResponse(fakeRequest).Block(ctx)
+	checkErr(err, "Request-Response handler returned a nil Mono.")
+	err = streamError(ctx, cli.RequestStream(fakeRequest))
+	checkErr(err, "Request-Stream handler returned a nil Flux.")
+	err = streamError(ctx, cli.RequestChannel(flux.Just(fakeRequest)))
+	checkErr(err, "Request-Channel handler returned a nil Flux.")
+	// the requests are released although they are never passed to a publisher.
+	assert.Eventually(t, func() bool {
+		return common.CountBorrowed() <= borrowed
+	}, time.Second, 10*time.Millisecond, "requests should be released")
+
+	// the connection is still alive.
+	_, err = cli.RequestResponse(fakeRequest).Block(ctx)
+	checkErr(err, "Request-Response handler returned a nil Mono.")
+}
+
+func TestClientBuilder_RequestNCoalescing(t *testing.T) {
+	const total, prefetch = 100, 32
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{})
+	var requests int32
+	go func() {
+		_ = Receive().
+			OnStart(func() {
+				close(started)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+				return NewAbstractSocket(RequestStream(func(payload.Payload) flux.Flux {
+					return flux.
+						Create(func(ctx context.Context, sink flux.Sink) {
+							for i := 0; i < total; i++ {
+								sink.Next(payload.NewString(fakeData, fmt.Sprintf("%d", i)))
+							}
+							sink.Complete()
+						}).
+						DoOnRequest(func(int) {
+							atomic.AddInt32(&requests, 1)
+						})
+				})), nil
+			}).
+			Transport(TCPServer().SetAddr(":8108").Build()).
+			Serve(ctx)
+	}()
+	<-started
+
+	cli, err := Connect().
+		RequestNCoalescing(time.Minute).
+		Transport(TCPClient().SetAddr("127.0.0.1:8108").Build()).
+		Start(ctx)
+	require.NoError(t, err)
+	defer cli.Close()
+
+	done := make(chan struct{})
+	received := 0
+	var su rx.Subscription
+	cli.RequestStream(fakeRequest).
+		DoFinally(func(rx.SignalType) {
+			close(done)
+		}).
+		Subscribe(ctx,
+			rx.OnSubscribe(func(ctx context.Context, s rx.Subscription) {
+				su = s
+				su.Request(prefetch)
+			}),
+			rx.OnNext(func(payload.Payload) error {
+				received++
+				su.Request(1)
+				return nil
+			}),
+		)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "stream is stalled")
+	}
+	assert.Equal(t, total, received)
+	assert.True(t, atomic.LoadInt32(&requests) < 10, "REQUEST_N should be coalesced: %d", atomic.LoadInt32(&requests))
+}
+
+func TestClient_ConnectTiming(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{})
+	go func() {
+		_ = Receive().
+			OnStart(func() {
+				close(started)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+				return NewAbstractSocket(), nil
+			}).
+			Transport(TCPServer().SetAddr(":8109").Build()).
+			Serve(ctx)
+	}()
+	<-started
+
+	cli, err := Connect().Transport(TCPClient().SetAddr("127.0.0.1:8109").Build()).Start(ctx)
+	require.NoError(t, err)
+	defer cli.Close()
+	timing := cli.ConnectTiming()
+	assert.True(t, timing.Dial > 0)
+	assert.Zero(t, timing.TLSHandshake)
+	assert.True(t, timing.Setup > 0)
+}
+
+func TestClient_HealthEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{})
+	go func() {
+		_ = Receive().
+			OnStart(func() {
+				close(started)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+				return NewAbstractSocket(), nil
+			}).
+			Transport(TCPServer().SetAddr(":8101").Build()).
+			Serve(ctx)
+	}()
+	<-started
+
+	cli, err := Connect().
+		KeepAlive(50*time.Millisecond, 10*time.Second, 1).
+		Transport(TCPClient().SetAddr("127.0.0.1:8101").Build()).
+		Start(ctx)
+	require.NoError(t, err)
+
+	events := make(chan HealthEvent, 16)
+	completed := make(chan struct{})
+	cli.HealthEvents().Subscribe(context.Background(),
+		reactor.OnNext(func(v reactor.Any) error {
+			select {
+			case events <- v.(HealthEvent):
+			default:
+			}
+			return nil
+		}),
+		reactor.OnComplete(func() {
+			close(completed)
+		}),
+	)
+
+	for i := 0; i < 3; i++ {
+		select {
+		case ev := <-events:
+			assert.True(t, ev.RTT > 0)
+			assert.Equal(t, HealthConnected, ev.State)
+		case <-time.After(3 * time.Second):
+			require.FailNow(t, "no health event received")
+		}
+	}
+
+	_ = cli.Close()
+	select {
+	case <-completed:
+	case <-time.After(3 * time.Second):
+		assert.Fail(t, "health events should complete when the connection is closed")
+	}
+}
+
+type stallStreamListener struct {
+	recordStreamListener
+	stalls chan uint32
+}
+
+func (s *stallStreamListener) OnStreamStall(sid uint32, stalled time.Duration) {
+	s.stalls <- sid
+}
+
+func TestStreamStallThreshold(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	listener := &stallStreamListener{stalls: make(chan uint32, 4)}
+	started := make(chan struct{})
+	go func() {
+		_ = Receive().
+			StreamListener(listener).
+			StreamStallThreshold(100 * time.Millisecond).
+			OnStart(func() {
+				close(started)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+				return NewAbstractSocket(
+					RequestStream(func(request payload.Payload) flux.Flux {
+						return flux.Create(func(ctx context.Context, sink flux.Sink) {
+							for i := 0; i < 10; i++ {
+								sink.Next(payload.NewString(fmt.Sprintf("%d", i), ""))
+							}
+							sink.Complete()
+						})
+					}),
+				), nil
+			}).
+			Transport(TCPServer().SetAddr(":8102").Build()).
+			Serve(ctx)
+	}()
+	<-started
+
+	cli, err := Connect().
+		Transport(TCPClient().SetAddr("127.0.0.1:8102").Build()).
+		Start(ctx)
+	require.NoError(t, err)
+	defer cli.Close()
+
+	var received int32
+	subscribed := make(chan rx.Subscription, 1)
+	completed := make(chan struct{})
+	cli.RequestStream(fakeRequest).Subscribe(ctx,
+		rx.OnSubscribe(func(ctx context.Context, s rx.Subscription) {
+			subscribed <- s
+			s.Request(2)
+		}),
+		rx.OnNext(func(input payload.Payload) error {
+			atomic.AddInt32(&received, 1)
+			return nil
+		}),
+		rx.OnComplete(func() {
+			close(completed)
+		}),
+	)
+	su := <-subscribed
+
+	waitStall := func() {
+		select {
+		case sid := <-listener.stalls:
+			assert.Equal(t, uint32(1), sid)
+		case <-time.After(3 * time.Second):
+			require.FailNow(t, "stall should be reported")
+		}
+	}
+	waitStall()
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&received) == 2
+	}, 3*time.Second, 10*time.Millisecond)
+
+	// request again, it stalls again after all requested payloads are sent.
+	su.Request(3)
+	waitStall()
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&received) == 5
+	}, 3*time.Second, 10*time.Millisecond)
+
+	// no stall after the stream is completed.
+	su.Request(10)
+	<-completed
+	select {
+	case <-listener.stalls:
+		assert.Fail(t, "completed stream should not stall")
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func TestEmptyPayload(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	type received struct {
+		data        []byte
+		hasMetadata bool
+	}
+	requests := make(chan received, 4)
+	started := make(chan struct{})
+	go func() {
+		_ = Receive().
+			OnStart(func() {
+				close(started)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+				return NewAbstractSocket(
+					RequestResponse(func(request payload.Payload) mono.Mono {
+						_, ok := request.Metadata()
+						requests <- received{data: request.Data(), hasMetadata: ok}
+						if request.DataUTF8() == "absent" {
+							return mono.Empty()
+						}
+						return mono.Just(payload.New(nil, []byte{}))
+					}),
+					RequestStream(func(request payload.Payload) flux.Flux {
+						return flux.Just(payload.New(nil, nil), payload.New(nil, []byte{}))
+					}),
+				), nil
+			}).
+			Transport(TCPServer().SetAddr(":8105").Build()).
+			Serve(ctx)
+	}()
+	<-started
+
+	cli, err := Connect().
+		Transport(TCPClient().SetAddr("127.0.0.1:8105").Build()).
+		Start(ctx)
+	require.NoError(t, err)
+	defer cli.Close()
+
+	// empty data with empty metadata which is present.
+	res, err := cli.RequestResponse(payload.New(nil, []byte{})).Block(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, res, "empty payload should be emitted")
+	m, ok := res.Metadata()
+	assert.True(t, ok)
+	assert.Empty(t, m)
+	assert.Empty(t, res.Data())
+	req := <-requests
+	assert.Empty(t, req.data)
+	assert.True(t, req.hasMetadata)
+
+	// absent metadata.
+	_, err = cli.RequestResponse(payload.New(nil, nil)).Block(ctx)
+	require.NoError(t, err)
+	assert.False(t, (<-requests).hasMetadata)
+
+	// absent payload: the responder completes without any payload.
+	res, err = cli.RequestResponse(payload.NewString("absent", "")).Block(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+	<-requests
+	res, err = cli.RequestResponseSync(ctx, payload.NewString("absent", ""))
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+	<-requests
+
+	var hasMetadata []bool
+	_, err = cli.RequestStream(payload.New(nil, nil)).
+		DoOnNext(func(input payload.Payload) error {
+			_, ok := input.Metadata()
+			hasMetadata = append(hasMetadata, ok)
+			return nil
+		}).
+		BlockLast(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false, true}, hasMetadata, "empty payloads should be emitted")
+}
+
+type brokenListener struct {
+	net.Listener
+	errs chan error
+}
+
+func (b brokenListener) Accept() (net.Conn, error) {
+	select {
+	case err := <-b.errs:
+		return nil, err
+	default:
+		return b.Listener.Accept()
+	}
+}
+
+func TestServer_OnAcceptError(t *testing.T) {
+	fatal := errors.New("fatal accept error")
+	errs := make(chan error, 2)
+	errs <- errors.New("ignored accept error")
+	errs <- fatal
+
+	var handled int32
+	err := Receive().
+		OnAcceptError(func(err error) bool {
+			atomic.AddInt32(&handled, 1)
+			return err != fatal
+		}).
+		Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+			return NewAbstractSocket(), nil
+		}).
+		Transport(func(ctx context.Context) (transport.ServerTransport, error) {
+			return transport.NewTCPServerTransport(func(ctx context.Context) (net.Listener, error) {
+				l, err := net.Listen("tcp", "127.0.0.1:0")
+				if err != nil {
+					return nil, err
+				}
+				return brokenListener{Listener: l, errs: errs}, nil
+			}), nil
+		}).
+		Serve(context.Background())
+	assert.Equal(t, fatal, errors.Cause(err))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&handled))
+}
+
+func TestPushRegistry(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	registry := NewPushRegistry()
+	registered := make(chan struct{})
+	started := make(chan struct{})
+	go func() {
+		_ = Receive().
+			OnStart(func() {
+				close(started)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+				registry.Register(setup.DataUTF8(), sendingSocket)
+				close(registered)
+				return NewAbstractSocket(), nil
+			}).
+			Transport(TCPServer().SetAddr(":8118").Build()).
+			Serve(ctx)
+	}()
+	<-started
+
+	received := make(chan string, 3)
+	completed := make(chan struct{})
+	cli, err := Connect().
+		SetupPayload(payload.NewString("alice", "")).
+		Acceptor(func(socket RSocket) RSocket {
+			return NewAbstractSocket(PushStreamHandler(func(notifications flux.Flux) {
+				notifications.
+					DoOnNext(func(input payload.Payload) error {
+						received <- input.DataUTF8()
+						return nil
+					}).
+					DoOnComplete(func() {
+						close(completed)
+					}).
+					Subscribe(context.Background())
+			}))
+		}).
+		Transport(TCPClient().SetAddr("127.0.0.1:8118").Build()).
+		Start(ctx)
+	require.NoError(t, err)
+	<-registered
+	assert.Equal(t, []string{"alice"}, registry.Clients())
+
+	err = registry.PushStream(ctx, "alice", flux.Just(
+		payload.NewString("foo", ""),
+		payload.NewString("bar", ""),
+		payload.NewString("qux", ""),
+	))
+	assert.NoError(t, err)
+	<-completed
+	assert.Equal(t, "foo", <-received)
+	assert.Equal(t, "bar", <-received)
+	assert.Equal(t, "qux", <-received)
+
+	assert.Error(t, registry.PushStream(ctx, "bob", flux.Just(payload.NewString("foo", ""))), "bob is not registered")
+
+	_ = cli.Close()
+	assert.Eventually(t, func() bool {
+		return len(registry.Clients()) == 0
+	}, 3*time.Second, 10*time.Millisecond, "closed client should be unregistered")
+}
+
+type rejectedError string
+
+func (e rejectedError) Error() string {
+	return string(e)
+}
+
+func (e rejectedError) ErrorCode() ErrorCode {
+	return ErrorCodeRejected
+}
+
+func (e rejectedError) ErrorData() []byte {
+	return []byte(e)
+}
+
+func TestCircuitBreakerClient(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{})
+	go func() {
+		_ = Receive().
+			OnStart(func() {
+				close(started)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+				return NewAbstractSocket(
+					RequestResponse(func(request payload.Payload) mono.Mono {
+						switch request.DataUTF8() {
+						case "reject":
+							return mono.Error(rejectedError("busy"))
+						case "fail":
+							return mono.Error(errors.New("business failure"))
+						default:
+							return mono.Just(payload.NewString("ok", ""))
+						}
+					}),
+				), nil
+			}).
+			Transport(TCPServer().SetAddr(":8119").Build()).
+			Serve(ctx)
+	}()
+	<-started
+
+	cli, err := Connect().
+		Transport(TCPClient().SetAddr("127.0.0.1:8119").Build()).
+		Start(ctx)
+	require.NoError(t, err)
+	defer cli.Close()
+
+	fake := clock.NewFake(time.Now())
+	breaker := NewCircuitBreakerClient(cli, CircuitBreakerPolicy{
+		ErrorRate:    0.5,
+		MinRequests:  4,
+		Window:       time.Minute,
+		OpenDuration: time.Second,
+		Clock:        fake,
+	})
+	request := func(data string) error {
+		_, err := breaker.RequestResponse(payload.NewString(data, "")).Block(ctx)
+		return err
+	}
+
+	// an APPLICATION_ERROR doesn't trip the circuit.
+	for _, data := range []string{"ok", "fail", "fail", "fail", "ok"} {
+		assert.NotEqual(t, ErrCircuitOpen, request(data))
+	}
+	// 5 of 10 requests are rejected.
+	for i := 0; i < 5; i++ {
+		assert.Error(t, request("reject"))
+	}
+	assert.Equal(t, ErrCircuitOpen, request("ok"))
+	_, err = breaker.RequestResponseSync(ctx, payload.NewString("ok", ""))
+	assert.Equal(t, ErrCircuitOpen, err)
+	_, err = breaker.RequestStream(payload.NewString("ok", "")).BlockLast(ctx)
+	assert.Equal(t, ErrCircuitOpen, err)
+
+	// the failed probe keeps the circuit open.
+	fake.Advance(time.Second)
+	assert.Error(t, request("reject"))
+	assert.Equal(t, ErrCircuitOpen, request("ok"))
+
+	// the circuit is closed after a successful probe.
+	fake.Advance(time.Second)
+	assert.NoError(t, request("ok"))
+	assert.NoError(t, request("ok"))
+	res, err := breaker.RequestResponseSync(ctx, payload.NewString("ok", ""))
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", res.DataUTF8())
+}
+
+func TestClientBuilder_SetupTimeout(t *testing.T) {
+	// a server which accepts connections but never completes SETUP.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	start := time.Now()
+	_, err = Connect().
+		SetupTimeout(200 * time.Millisecond).
+		Transport(TCPClient().SetAddr(l.Addr().String()).Build()).
+		Start(context.Background())
+	assert.Equal(t, core.ErrSetupTimeout, err)
+	assert.True(t, time.Since(start) < 3*time.Second, "should fail fast")
+
+	_, err = Connect().
+		SetupTimeout(-time.Second).
+		Transport(TCPClient().SetAddr(l.Addr().String()).Build()).
+		Start(context.Background())
+	assert.Error(t, err, "negative setup timeout should be rejected")
+}
+
+func TestServer_Sessions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{})
+	s := Receive().
+		OnStart(func() {
+			close(started)
+		}).
+		Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+			return NewAbstractSocket(
+				RequestStream(func(request payload.Payload) flux.Flux {
+					// never completes.
+					return flux.Create(func(ctx context.Context, sink flux.Sink) {})
+				}),
+			), nil
+		}).
+		Transport(TCPServer().SetAddr(":8120").Build())
+	go func() {
+		_ = s.Serve(ctx)
+	}()
+	<-started
+	assert.Empty(t, s.Sessions())
+
+	before := time.Now()
+	cli, err := Connect().
+		KeepAlive(10*time.Second, 20*time.Second, 3).
+		Transport(TCPClient().SetAddr("127.0.0.1:8120").Build()).
+		Start(ctx)
+	require.NoError(t, err)
+	defer cli.Close()
+
+	streamCtx, cancelStream := context.WithCancel(ctx)
+	defer cancelStream()
+	cli.RequestStream(payload.NewString("foo", "")).Subscribe(streamCtx)
+
+	assert.Eventually(t, func() bool {
+		sessions := s.Sessions()
+		return len(sessions) == 1 && sessions[0].ActiveStreams == 1
+	}, 3*time.Second, 10*time.Millisecond)
+	info := s.Sessions()[0]
+	require.NotNil(t, info.RemoteAddr)
+	assert.Contains(t, info.RemoteAddr.String(), "127.0.0.1:")
+	assert.False(t, info.ConnectedAt.Before(before.Add(-time.Second)))
+	assert.True(t, info.Uptime >= 0)
+	assert.Equal(t, KeepaliveSettings{Interval: 10 * time.Second, MaxLifetime: 60 * time.Second}, info.Keepalive)
+	assert.False(t, info.Resumable)
+	assert.True(t, info.BytesRead > 0, "SETUP and the request have been read")
+	assert.True(t, info.BytesWritten > 0, "the keepalive response has been written")
+	assert.Zero(t, info.OutboundQueueDepth, "nothing is queued to an idle client")
+	assert.Zero(t, cli.OutboundQueueDepth())
+	assert.Zero(t, info.Reassemblies, "the request is not fragmented")
+	assert.Zero(t, cli.Reassemblies())
+
+	_ = cli.Close()
+	assert.Eventually(t, func() bool {
+		return len(s.Sessions()) == 0
+	}, 3*time.Second, 10*time.Millisecond)
+}
+
+// serveConnectionErrors accepts connections and sends ERROR[CONNECTION_ERROR] after the SETUP of the first one,
+// other connections are kept open.
+func serveConnectionErrors(t *testing.T, l net.Listener, accepted *int32) {
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+		n := atomic.AddInt32(accepted, 1)
+		go func(conn *transport.TCPConn) {
+			defer conn.Close()
+			setup, err := conn.Read()
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, core.FrameTypeSetup, setup.Header().Type())
+			if n == 1 {
+				_ = conn.Write(framing.NewWriteableErrorFrame(0, core.ErrorCodeConnectionError, []byte("boom")))
+				_ = conn.Flush()
+			}
+			for {
+				if _, err := conn.Read(); err != nil {
+					return
+				}
+			}
+		}(transport.NewTCPConn(c))
+	}
+}
+
+func TestClientBuilder_OnConnectionError(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	var accepted int32
+	go serveConnectionErrors(t, l, &accepted)
+
+	connErrs := make(chan error, 1)
+	closed := make(chan error, 1)
+	cli, err := Connect().
+		SetupTimeout(0).
+		OnConnectionError(func(err error) bool {
+			connErrs <- err
+			return true
+		}).
+		OnClose(func(err error) {
+			closed <- err
+		}).
+		Transport(TCPClient().SetAddr(l.Addr().String()).Build()).
+		Start(context.Background())
+	require.NoError(t, err)
+	defer cli.Close()
+
+	err = <-connErrs
+	require.Error(t, err)
+	assert.Equal(t, core.ErrorCodeConnectionError, err.(core.CustomError).ErrorCode())
+	assert.Equal(t, "boom", string(err.(core.CustomError).ErrorData()))
+	// the client reconnects instead of being closed.
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&accepted) == 2
+	}, 3*time.Second, 10*time.Millisecond)
+	select {
+	case <-closed:
+		assert.Fail(t, "the client should not be closed")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	_ = cli.Close()
+	select {
+	case <-closed:
+	case <-time.After(3 * time.Second):
+		assert.Fail(t, "the client should be closed")
+	}
+}
+
+func TestClientBuilder_OnConnectionErrorAfterReauthenticate(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	setups := make(chan string, 3)
+	boom := make(chan struct{})
+	go func() {
+		for n := 1; ; n++ {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func(n int, conn *transport.TCPConn) {
+				defer conn.Close()
+				setup, err := conn.Read()
+				if !assert.NoError(t, err) {
+					return
+				}
+				setups <- string(setup.(*framing.SetupFrame).Data())
+				// the reauthenticated connection fails with a connection error.
+				if n == 2 {
+					<-boom
+					_ = conn.Write(framing.NewWriteableErrorFrame(0, core.ErrorCodeConnectionError, []byte("boom")))
+					_ = conn.Flush()
+				}
+				for {
+					if _, err := conn.Read(); err != nil {
+						return
+					}
+				}
+			}(n, transport.NewTCPConn(c))
+		}
+	}()
+
+	cli, err := Connect().
+		SetupTimeout(0).
+		SetupPayload(payload.NewString("token-1", "")).
+		OnConnectionError(func(err error) bool {
+			return true
+		}).
+		Transport(TCPClient().SetAddr(l.Addr().String()).Build()).
+		Start(context.Background())
+	require.NoError(t, err)
+	defer cli.Close()
+	assert.Equal(t, "token-1", <-setups)
+
+	require.NoError(t, cli.Reauthenticate(context.Background(), payload.NewString("token-2", "")))
+	assert.Equal(t, "token-2", <-setups)
+	close(boom)
+
+	select {
+	case token := <-setups:
+		assert.Equal(t, "token-2", token, "the client should reconnect with the current SETUP")
+	case <-time.After(3 * time.Second):
+		assert.Fail(t, "the client should reconnect")
+	}
+}
+
+func TestClientBuilder_OnConnectionErrorDefault(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	var accepted int32
+	go serveConnectionErrors(t, l, &accepted)
+
+	closed := make(chan error, 1)
+	_, err = Connect().
+		SetupTimeout(0).
+		OnClose(func(err error) {
+			closed <- err
+		}).
+		Transport(TCPClient().SetAddr(l.Addr().String()).Build()).
+		Start(context.Background())
+	require.NoError(t, err)
+
+	select {
+	case err := <-closed:
+		require.Error(t, err)
+		assert.Equal(t, core.ErrorCodeConnectionError, err.(core.CustomError).ErrorCode())
+	case <-time.After(3 * time.Second):
+		assert.Fail(t, "the client should be closed")
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&accepted))
+}
+
+func TestFaultyClientTransporter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{})
+	go func() {
+		_ = Receive().
+			OnStart(func() {
+				close(started)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket CloseableRSocket) (RSocket, error) {
+				return NewAbstractSocket(
+					RequestResponse(func(request payload.Payload) mono.Mono {
+						return mono.Just(request)
+					}),
+				), nil
+			}).
+			Transport(TCPServer().SetAddr(":8122").Build()).
+			Serve(ctx)
+	}()
+	<-started
+
+	const latency = 100 * time.Millisecond
+	cli, err := Connect().
+		Transport(transporttest.FaultyClientTransporter(TCPClient().SetAddr("127.0.0.1:8122").Build(), transporttest.Faults{
+			Latency: latency,
+			Jitter:  20 * time.Millisecond,
+		})).
+		Start(ctx)
+	require.NoError(t, err)
+	defer cli.Close()
+	start := time.Now()
+	res, err := cli.RequestResponse(payload.NewString("foo", "")).Block(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "foo", res.DataUTF8())
+	assert.True(t, time.Since(start) >= latency, "response should be delayed")
+
+	// the KEEPALIVE which acknowledges the SETUP is lost.
+	_, err = Connect().
+		SetupTimeout(200 * time.Millisecond).
+		Transport(transporttest.FaultyClientTransporter(TCPClient().SetAddr("127.0.0.1:8122").Build(), transporttest.Faults{
+			DropRate: 1,
+		})).
+		Start(ctx)
+	assert.Equal(t, core.ErrSetupTimeout, err)
+}
@@ -2,6 +2,7 @@ package flux
 
 import (
 	"context"
+	"time"
 
 	"github.com/jjeffcaii/reactor-go/flux"
 	"github.com/jjeffcaii/reactor-go/scheduler"
@@ -12,6 +13,9 @@ import (
 // FnSwitchOnFirst is an alias of Func for DoSwitchOnFirst.
 type FnSwitchOnFirst = func(s Signal, f Flux) Flux
 
+// FnAccumulate is an alias of Func for Scan and Reduce, it returns the new accumulator.
+type FnAccumulate = func(acc, next payload.Payload) payload.Payload
+
 // Sink represent a wrapper API around an actual downstream Subscriber for emitting nothing, a single value or an error (mutually exclusive).
 type Sink interface {
 	// Next emits next single value
@@ -45,6 +49,23 @@ type Flux interface {
 	DoOnSubscribe(rx.FnOnSubscribe) Flux
 	// Map transform the items emitted by this Flux by applying a synchronous function to each item.
 	Map(rx.FnTransform) Flux
+	// Scan aggregates the items by applying the function to the accumulator and each item, the first accumulator is the seed.
+	// It emits the new accumulator after each item, so it requests exactly the same amount as requested by the subscriber.
+	// Items are released after fn unless fn returns them, clone an item if it should be kept in the accumulator.
+	// The returned Flux keeps the accumulator, it can be subscribed again after the previous subscription terminated.
+	Scan(seed payload.Payload, fn FnAccumulate) Flux
+	// Reduce aggregates the items like Scan, but it only emits the final accumulator when the Flux completes,
+	// or the seed if the Flux is empty. Intermediate accumulators are released once they are replaced.
+	Reduce(seed payload.Payload, fn FnAccumulate) Flux
+	// Sample emits the latest item every period, intermediate items are dropped and released.
+	// The source is requested unbounded, the latest item which is not sampled yet is emitted before completion.
+	Sample(period time.Duration) Flux
+	// ThrottleFirst emits the first item of every window since the last emitted one, other items in the window
+	// are dropped and released. A dropped item is replaced by a request of 1 upstream, like Filter.
+	ThrottleFirst(window time.Duration) Flux
+	// SwitchIfEmpty switches to the alternative Publisher if this Flux completes without any item,
+	// the alternative is never subscribed once an item has been emitted. Errors are not switched.
+	SwitchIfEmpty(alternative rx.Publisher) Flux
 	// SwitchOnFirst transform the current Flux once it emits its first element, making a conditional transformation possible.
 	SwitchOnFirst(FnSwitchOnFirst) Flux
 	// SubscribeOn run subscribe, onSubscribe and request on a specified scheduler.
@@ -1,9 +1,11 @@
 package flux_test
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -464,3 +466,439 @@ func genRandomFlux(n int) flux.Flux {
 			s.Complete()
 		})
 }
+
+type endlessReader struct {
+	reads *atomic.Int32
+}
+
+func (e endlessReader) Read(p []byte) (int, error) {
+	e.reads.Inc()
+	time.Sleep(time.Millisecond)
+	return copy(p, "x"), nil
+}
+
+type errorReader struct{}
+
+func (errorReader) Read([]byte) (int, error) {
+	return 0, errors.New("fake read error")
+}
+
+func TestFromReader(t *testing.T) {
+	results, err := flux.FromReader(bytes.NewBufferString("abcdefghij"), 4).BlockSlice(context.Background())
+	assert.NoError(t, err)
+	var chunks []string
+	for _, it := range results {
+		chunks = append(chunks, it.DataUTF8())
+	}
+	assert.Equal(t, "abcd,efgh,ij", strings.Join(chunks, ","))
+
+	results, err = flux.FromReader(bytes.NewReader(nil), 4).BlockSlice(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+
+	_, err = flux.FromReader(bytes.NewReader(nil), 0).BlockSlice(context.Background())
+	assert.Error(t, err)
+
+	_, err = flux.FromReader(errorReader{}, 4).BlockSlice(context.Background())
+	assert.EqualError(t, err, "fake read error")
+}
+
+func TestFromReader_Cancel(t *testing.T) {
+	reads := atomic.NewInt32(0)
+	results, err := flux.FromReader(endlessReader{reads: reads}, 2).Take(3).BlockSlice(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+	time.Sleep(50 * time.Millisecond)
+	stopped := reads.Load()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, stopped, reads.Load(), "reading should stop after cancelled")
+}
+
+func TestFromReader_Demand(t *testing.T) {
+	reads := atomic.NewInt32(0)
+	f := flux.FromReader(endlessReader{reads: reads}, 2)
+	received := atomic.NewInt32(0)
+	subscribed := make(chan rx.Subscription, 1)
+	f.Subscribe(context.Background(),
+		rx.OnSubscribe(func(ctx context.Context, su rx.Subscription) {
+			subscribed <- su
+			su.Request(1)
+		}),
+		rx.OnNext(func(input payload.Payload) error {
+			received.Inc()
+			return nil
+		}),
+	)
+	su := <-subscribed
+	assert.Eventually(t, func() bool {
+		return received.Load() == 1
+	}, time.Second, 5*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(2), reads.Load(), "should not read ahead of demand")
+
+	su.Request(2)
+	assert.Eventually(t, func() bool {
+		return received.Load() == 3
+	}, time.Second, 5*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(6), reads.Load(), "should not read ahead of demand")
+	su.Cancel()
+
+	_, err := f.BlockSlice(context.Background())
+	assert.Error(t, err, "should be subscribed only once")
+}
+
+func TestMerge(t *testing.T) {
+	results, err := flux.Merge(genRandomFlux(3), genRandomFlux(4), flux.Empty(), genRandomFlux(5)).BlockSlice(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, results, 12)
+
+	results, err = flux.Merge().BlockSlice(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+
+	var requested []int
+	_, err = flux.Merge(flux.Create(func(ctx context.Context, s flux.Sink) {
+		for i := 0; i < 100; i++ {
+			s.Next(payload.NewString(strconv.Itoa(i), ""))
+		}
+		s.Complete()
+	}).DoOnRequest(func(n int) {
+		requested = append(requested, n)
+	})).BlockSlice(context.Background())
+	assert.NoError(t, err)
+	assert.NotEmpty(t, requested)
+	for _, n := range requested {
+		assert.True(t, n > 0 && n < rx.RequestMax, "source should be requested in batches")
+	}
+}
+
+func TestMerge_Error(t *testing.T) {
+	fakeErr := errors.New("fake merge error")
+	_, err := flux.Merge(genRandomFlux(3), flux.Error(fakeErr), genRandomFlux(3)).BlockSlice(context.Background())
+	assert.Equal(t, fakeErr, err)
+
+	received := atomic.NewInt32(0)
+	_, err = flux.MergeDelayError(flux.Error(fakeErr), genRandomFlux(3)).
+		DoOnNext(func(input payload.Payload) error {
+			received.Inc()
+			return nil
+		}).
+		BlockLast(context.Background())
+	assert.Equal(t, fakeErr, err)
+	assert.Equal(t, int32(3), received.Load(), "other sources should not be cancelled")
+}
+
+func TestMerge_Cancel(t *testing.T) {
+	cancelled := atomic.NewInt32(0)
+	endless := func() flux.Flux {
+		return flux.Create(func(ctx context.Context, s flux.Sink) {
+			go func() {
+				for i := 0; i < 1000; i++ {
+					s.Next(payload.NewString(strconv.Itoa(i), ""))
+					time.Sleep(time.Millisecond)
+				}
+				s.Complete()
+			}()
+		}).DoFinally(func(s rx.SignalType) {
+			if s == rx.SignalCancel {
+				cancelled.Inc()
+			}
+		})
+	}
+	results, err := flux.Merge(endless(), endless()).Take(5).BlockSlice(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, results, 5)
+	assert.Eventually(t, func() bool {
+		return cancelled.Load() == 2
+	}, 3*time.Second, 10*time.Millisecond)
+}
+
+type releasablePayload struct {
+	payload.Payload
+	released *atomic.Int32
+}
+
+func (r *releasablePayload) IncRef() int32 { return 1 }
+func (r *releasablePayload) RefCnt() int32 { return 1 }
+func (r *releasablePayload) Release()      { r.released.Inc() }
+
+func sumAccumulate(acc, next payload.Payload) payload.Payload {
+	a, _ := strconv.Atoi(acc.DataUTF8())
+	b, _ := strconv.Atoi(next.DataUTF8())
+	return payload.NewString(strconv.Itoa(a+b), "")
+}
+
+func TestScan(t *testing.T) {
+	released := atomic.NewInt32(0)
+	var inputs []payload.Payload
+	for i := 1; i <= 5; i++ {
+		inputs = append(inputs, &releasablePayload{Payload: payload.NewString(strconv.Itoa(i), ""), released: released})
+	}
+	f := flux.Just(inputs...).Scan(payload.NewString("0", ""), sumAccumulate)
+
+	for i := 0; i < 2; i++ {
+		results, err := f.BlockSlice(context.Background())
+		assert.NoError(t, err)
+		var sums []string
+		for _, it := range results {
+			sums = append(sums, it.DataUTF8())
+		}
+		assert.Equal(t, []string{"1", "3", "6", "10", "15"}, sums, "accumulator should be reset on resubscription")
+	}
+	assert.Equal(t, int32(10), released.Load(), "items should be released after accumulated")
+
+	// the accumulator is kept if fn returns the item.
+	released.Store(0)
+	results, err := flux.Just(inputs...).
+		Scan(nil, func(acc, next payload.Payload) payload.Payload {
+			return next
+		}).
+		BlockSlice(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, results, 5)
+	assert.Equal(t, int32(0), released.Load())
+}
+
+func TestScan_Backpressure(t *testing.T) {
+	var (
+		su       rx.Subscription
+		received []string
+		requests []int
+	)
+	var inputs []payload.Payload
+	for i := 1; i <= 5; i++ {
+		inputs = append(inputs, payload.NewString(strconv.Itoa(i), ""))
+	}
+	flux.Just(inputs...).
+		DoOnRequest(func(n int) {
+			requests = append(requests, n)
+		}).
+		Scan(payload.NewString("0", ""), sumAccumulate).
+		Subscribe(context.Background(),
+			rx.OnSubscribe(func(ctx context.Context, s rx.Subscription) {
+				su = s
+				s.Request(2)
+			}),
+			rx.OnNext(func(input payload.Payload) error {
+				received = append(received, input.DataUTF8())
+				return nil
+			}),
+		)
+	assert.Equal(t, []string{"1", "3"}, received)
+	su.Request(1)
+	assert.Equal(t, []string{"1", "3", "6"}, received)
+	assert.Equal(t, []int{2, 1}, requests)
+	su.Cancel()
+}
+
+func TestReduce(t *testing.T) {
+	released := atomic.NewInt32(0)
+	var inputs []payload.Payload
+	for i := 1; i <= 4; i++ {
+		inputs = append(inputs, &releasablePayload{Payload: payload.NewString(strconv.Itoa(i), ""), released: released})
+	}
+	results, err := flux.Just(inputs...).
+		Reduce(payload.NewString("0", ""), func(acc, next payload.Payload) payload.Payload {
+			return &releasablePayload{Payload: sumAccumulate(acc, next), released: released}
+		}).
+		BlockSlice(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "10", results[0].DataUTF8())
+	// 4 items and 3 intermediate accumulators.
+	assert.Equal(t, int32(7), released.Load())
+
+	last, err := flux.Empty().Reduce(payload.NewString("seed", ""), sumAccumulate).BlockLast(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "seed", last.DataUTF8())
+
+	last, err = flux.Empty().Reduce(nil, sumAccumulate).BlockLast(context.Background())
+	assert.NoError(t, err)
+	assert.Nil(t, last)
+
+	fakeErr := errors.New("fake error")
+	_, err = flux.Create(func(ctx context.Context, s flux.Sink) {
+		s.Next(payload.NewString("1", ""))
+		s.Error(fakeErr)
+	}).
+		Reduce(payload.NewString("0", ""), sumAccumulate).
+		BlockLast(context.Background())
+	assert.Equal(t, fakeErr, err)
+}
+
+func TestReduce_Cancel(t *testing.T) {
+	cancelled := atomic.NewBool(false)
+	done := make(chan struct{})
+	source := flux.Raw(reactorFlux.Interval(10 * time.Millisecond).
+		DoOnCancel(func() {
+			cancelled.Store(true)
+		}).
+		Map(func(any reactorFlux.Any) (reactorFlux.Any, error) {
+			return payload.NewString("1", ""), nil
+		}))
+	source.
+		Reduce(payload.NewString("0", ""), sumAccumulate).
+		DoFinally(func(s rx.SignalType) {
+			close(done)
+		}).
+		Subscribe(context.Background(),
+			rx.OnSubscribe(func(ctx context.Context, s rx.Subscription) {
+				s.Request(1)
+				time.AfterFunc(50*time.Millisecond, s.Cancel)
+			}),
+		)
+	<-done
+	assert.Eventually(t, cancelled.Load, time.Second, 10*time.Millisecond, "source should be cancelled")
+}
+
+func TestSwitchIfEmpty(t *testing.T) {
+	subscribed := atomic.NewInt32(0)
+	alternative := flux.Just(payload.NewString("alt1", ""), payload.NewString("alt2", "")).
+		DoOnSubscribe(func(ctx context.Context, s rx.Subscription) {
+			subscribed.Inc()
+		})
+
+	results, err := flux.Empty().SwitchIfEmpty(alternative).BlockSlice(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, "alt1", results[0].DataUTF8())
+	assert.Equal(t, int32(1), subscribed.Load())
+
+	results, err = flux.Just(payload.NewString("primary", "")).SwitchIfEmpty(alternative).BlockSlice(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "primary", results[0].DataUTF8())
+	assert.Equal(t, int32(1), subscribed.Load(), "should not switch after an item emitted")
+
+	fakeErr := errors.New("fake switch error")
+	_, err = flux.Error(fakeErr).SwitchIfEmpty(alternative).BlockSlice(context.Background())
+	assert.Equal(t, fakeErr, err)
+	assert.Equal(t, int32(1), subscribed.Load(), "should not switch on error")
+
+	var requested []int
+	results, err = flux.Empty().
+		SwitchIfEmpty(genRandomFlux(100).DoOnRequest(func(n int) {
+			requested = append(requested, n)
+		})).
+		BlockSlice(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, results, 100)
+	assert.NotEmpty(t, requested)
+	for _, n := range requested {
+		assert.True(t, n > 0 && n < rx.RequestMax, "alternative should be requested in batches")
+	}
+}
+
+func TestSwitchIfEmpty_Cancel(t *testing.T) {
+	cancelled := atomic.NewInt32(0)
+	endless := flux.Create(func(ctx context.Context, s flux.Sink) {
+		go func() {
+			for i := 0; i < 1000; i++ {
+				s.Next(payload.NewString(strconv.Itoa(i), ""))
+				time.Sleep(time.Millisecond)
+			}
+			s.Complete()
+		}()
+	}).DoFinally(func(s rx.SignalType) {
+		if s == rx.SignalCancel {
+			cancelled.Inc()
+		}
+	})
+	results, err := flux.Empty().SwitchIfEmpty(endless).Take(3).BlockSlice(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+	assert.Eventually(t, func() bool {
+		return cancelled.Load() == 1
+	}, 3*time.Second, 10*time.Millisecond)
+}
+
+// burstFlux emits bursts of releasable items, and waits for the pause between bursts.
+func burstFlux(released *atomic.Int32, pause time.Duration, bursts ...[]string) flux.Flux {
+	return flux.Create(func(ctx context.Context, s flux.Sink) {
+		for i, burst := range bursts {
+			if i > 0 {
+				select {
+				case <-time.After(pause):
+				case <-ctx.Done():
+					return
+				}
+			}
+			for _, it := range burst {
+				s.Next(&releasablePayload{Payload: payload.NewString(it, ""), released: released})
+			}
+		}
+		s.Complete()
+	})
+}
+
+func TestSample(t *testing.T) {
+	released := atomic.NewInt32(0)
+	results, err := burstFlux(released, 150*time.Millisecond, []string{"1", "2", "3"}, []string{"4", "5"}).
+		Sample(50 * time.Millisecond).
+		BlockSlice(context.Background())
+	assert.NoError(t, err)
+	var values []string
+	for _, it := range results {
+		values = append(values, it.DataUTF8())
+	}
+	assert.Equal(t, []string{"3", "5"}, values, "the latest item should be emitted before completion")
+	assert.Equal(t, int32(3), released.Load(), "dropped items should be released")
+
+	fakeErr := errors.New("fake sample error")
+	released.Store(0)
+	_, err = flux.Create(func(ctx context.Context, s flux.Sink) {
+		s.Next(&releasablePayload{Payload: payload.NewString("1", ""), released: released})
+		s.Error(fakeErr)
+	}).
+		Sample(time.Second).
+		BlockSlice(context.Background())
+	assert.Equal(t, fakeErr, err)
+	assert.Equal(t, int32(1), released.Load(), "the pending item should be released on error")
+}
+
+func TestSample_Cancel(t *testing.T) {
+	cancelled := atomic.NewBool(false)
+	released := atomic.NewInt32(0)
+	done := make(chan struct{})
+	flux.Raw(reactorFlux.Interval(5*time.Millisecond).
+		DoOnCancel(func() {
+			cancelled.Store(true)
+		}).
+		Map(func(any reactorFlux.Any) (reactorFlux.Any, error) {
+			return &releasablePayload{Payload: payload.NewString("1", ""), released: released}, nil
+		})).
+		Sample(time.Hour).
+		DoFinally(func(s rx.SignalType) {
+			close(done)
+		}).
+		Subscribe(context.Background(),
+			rx.OnSubscribe(func(ctx context.Context, s rx.Subscription) {
+				s.Request(1)
+				time.AfterFunc(50*time.Millisecond, s.Cancel)
+			}),
+			rx.OnNext(func(input payload.Payload) error {
+				assert.FailNow(t, "unreachable")
+				return nil
+			}),
+		)
+	<-done
+	assert.Eventually(t, cancelled.Load, time.Second, 10*time.Millisecond, "source should be cancelled")
+	assert.True(t, released.Load() > 0, "pending items should be released")
+}
+
+func TestThrottleFirst(t *testing.T) {
+	released := atomic.NewInt32(0)
+	f := burstFlux(released, 150*time.Millisecond, []string{"1", "2", "3"}, []string{"4", "5"}).
+		ThrottleFirst(50 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		results, err := f.BlockSlice(context.Background())
+		assert.NoError(t, err)
+		var values []string
+		for _, it := range results {
+			values = append(values, it.DataUTF8())
+		}
+		assert.Equal(t, []string{"1", "4"}, values, "the window should be reset on resubscription")
+	}
+	assert.Equal(t, int32(6), released.Load(), "dropped items should be released")
+}
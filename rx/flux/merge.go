@@ -0,0 +1,182 @@
+package flux
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rsocket/rsocket-go/internal/common"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx"
+)
+
+// mergePrefetch is the amount requested from every source of a merged Flux at first,
+// half of it will be requested again after half of it has been emitted.
+const mergePrefetch = 32
+
+// Merge creates a Flux which subscribes to all sources and interleaves their emissions.
+// It completes when all sources complete, and it fails on the first error then other sources will be cancelled.
+// Every source is requested in small batches, so a slow subscriber throttles all sources.
+func Merge(sources ...rx.Publisher) Flux {
+	return merge(sources, false)
+}
+
+// MergeDelayError is like Merge, but the first error is delayed until all sources terminate.
+func MergeDelayError(sources ...rx.Publisher) Flux {
+	return merge(sources, true)
+}
+
+func merge(sources []rx.Publisher, delayError bool) Flux {
+	if len(sources) < 1 {
+		return Empty()
+	}
+	var (
+		mu      sync.Mutex
+		mergers = make(map[*merger]struct{})
+	)
+	return Create(func(ctx context.Context, s Sink) {
+		m := &merger{
+			sink:       s,
+			delayError: delayError,
+			remaining:  len(sources),
+		}
+		mu.Lock()
+		mergers[m] = struct{}{}
+		mu.Unlock()
+		m.onTerminate = func() {
+			mu.Lock()
+			delete(mergers, m)
+			mu.Unlock()
+		}
+		for _, source := range sources {
+			m.subscribe(ctx, source)
+		}
+	}).DoFinally(func(s rx.SignalType) {
+		if s != rx.SignalCancel {
+			return
+		}
+		mu.Lock()
+		cancelled := mergers
+		mergers = make(map[*merger]struct{})
+		mu.Unlock()
+		for m := range cancelled {
+			m.cancel()
+		}
+	})
+}
+
+type merger struct {
+	sync.Mutex
+	emitting      sync.Mutex // serializes signals to sink, it is not held with the state lock
+	sink          Sink
+	delayError    bool
+	remaining     int
+	err           error
+	done          bool
+	subscriptions []rx.Subscription
+	onTerminate   func()
+}
+
+func (m *merger) subscribe(ctx context.Context, source rx.Publisher) {
+	var (
+		su       rx.Subscription
+		received int
+	)
+	source.Subscribe(ctx,
+		rx.OnSubscribe(func(ctx context.Context, s rx.Subscription) {
+			su = s
+			if !m.add(s) {
+				s.Cancel()
+				return
+			}
+			s.Request(mergePrefetch)
+		}),
+		rx.OnNext(func(input payload.Payload) error {
+			if !m.next(input) {
+				return nil
+			}
+			if received++; received == mergePrefetch/2 {
+				received = 0
+				su.Request(mergePrefetch / 2)
+			}
+			return nil
+		}),
+		rx.OnComplete(func() {
+			m.terminate(nil)
+		}),
+		rx.OnError(func(e error) {
+			m.terminate(e)
+		}),
+	)
+}
+
+func (m *merger) add(su rx.Subscription) bool {
+	m.Lock()
+	defer m.Unlock()
+	if m.done {
+		return false
+	}
+	m.subscriptions = append(m.subscriptions, su)
+	return true
+}
+
+func (m *merger) next(input payload.Payload) bool {
+	m.emitting.Lock()
+	defer m.emitting.Unlock()
+	m.Lock()
+	done := m.done
+	m.Unlock()
+	if done {
+		common.TryRelease(input)
+		return false
+	}
+	m.sink.Next(input)
+	return true
+}
+
+func (m *merger) terminate(err error) {
+	m.Lock()
+	if m.done {
+		m.Unlock()
+		return
+	}
+	m.remaining--
+	if err != nil && m.err == nil {
+		m.err = err
+	}
+	if m.remaining > 0 && (err == nil || m.delayError) {
+		m.Unlock()
+		return
+	}
+	m.done = true
+	others, remaining, err := m.subscriptions, m.remaining, m.err
+	m.subscriptions = nil
+	m.Unlock()
+	m.onTerminate()
+	if remaining > 0 {
+		for _, su := range others {
+			su.Cancel()
+		}
+	}
+	m.emitting.Lock()
+	defer m.emitting.Unlock()
+	if err != nil {
+		m.sink.Error(err)
+	} else {
+		m.sink.Complete()
+	}
+}
+
+func (m *merger) cancel() {
+	m.Lock()
+	if m.done {
+		m.Unlock()
+		return
+	}
+	m.done = true
+	others := m.subscriptions
+	m.subscriptions = nil
+	m.Unlock()
+	for _, su := range others {
+		su.Cancel()
+	}
+}
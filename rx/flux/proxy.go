@@ -2,10 +2,12 @@ package flux
 
 import (
 	"context"
+	"time"
 
 	"github.com/jjeffcaii/reactor-go"
 	"github.com/jjeffcaii/reactor-go/flux"
 	"github.com/jjeffcaii/reactor-go/scheduler"
+	"github.com/rsocket/rsocket-go/core/clock"
 	"github.com/rsocket/rsocket-go/payload"
 	"github.com/rsocket/rsocket-go/rx"
 )
@@ -146,12 +148,32 @@ func (p proxy) DoFinally(fn rx.FnFinally) Flux {
 	}))
 }
 
+func (p proxy) Scan(seed payload.Payload, fn FnAccumulate) Flux {
+	return scan(p, seed, fn)
+}
+
+func (p proxy) Reduce(seed payload.Payload, fn FnAccumulate) Flux {
+	return reduce(p, seed, fn)
+}
+
+func (p proxy) Sample(period time.Duration) Flux {
+	return sample(p, period, clock.Real())
+}
+
+func (p proxy) ThrottleFirst(window time.Duration) Flux {
+	return throttleFirst(p, window, clock.Real())
+}
+
 func (p proxy) SwitchOnFirst(fn FnSwitchOnFirst) Flux {
 	return newProxy(p.Flux.SwitchOnFirst(func(s flux.Signal, f flux.Flux) flux.Flux {
 		return fn(newSignal(s), newProxy(f)).Raw()
 	}))
 }
 
+func (p proxy) SwitchIfEmpty(alternative rx.Publisher) Flux {
+	return switchIfEmpty(p, alternative)
+}
+
 func (p proxy) SubscribeOn(sc scheduler.Scheduler) Flux {
 	return newProxy(p.Flux.SubscribeOn(sc))
 }
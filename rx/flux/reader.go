@@ -0,0 +1,133 @@
+package flux
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx"
+	"go.uber.org/atomic"
+)
+
+var (
+	errReaderSubscribed = errors.New("rsocket: flux from reader can be subscribed only once")
+	errReaderCancelled  = errors.New("rsocket: flux from reader has been cancelled")
+)
+
+// FromReader creates a Flux which emits chunks read from a reader as payload data.
+// Every payload carries chunkSize bytes except the last one. It completes at io.EOF and emits the error if reading fails.
+// A chunk is read only when it has been requested, and reading stops once the subscription is cancelled.
+// Since the reader is consumed, the Flux can be subscribed only once, later subscriptions receive an error.
+//
+// It can be used as the outbound side of RequestChannel to upload large data, payloads larger than MTU will be fragmented.
+func FromReader(r io.Reader, chunkSize int) Flux {
+	if chunkSize < 1 {
+		return Error(errors.Errorf("invalid chunk size: %d", chunkSize))
+	}
+	cr := &chunkReader{
+		r:          r,
+		size:       chunkSize,
+		subscribed: atomic.NewBool(false),
+		wake:       make(chan struct{}, 1),
+		cancelled:  make(chan struct{}),
+	}
+	return Create(func(ctx context.Context, s Sink) {
+		if !cr.subscribed.CAS(false, true) {
+			s.Error(errReaderSubscribed)
+			return
+		}
+		go cr.run(ctx, s)
+	}).
+		DoOnRequest(cr.request).
+		DoFinally(func(s rx.SignalType) {
+			if s == rx.SignalCancel {
+				cr.cancel()
+			}
+		})
+}
+
+// chunkReader reads chunks of the reader as they are requested by the only subscription.
+type chunkReader struct {
+	sync.Mutex
+	r          io.Reader
+	size       int
+	subscribed *atomic.Bool
+	demand     int
+	wake       chan struct{}
+	cancelled  chan struct{}
+	cancelOnce sync.Once
+}
+
+func (c *chunkReader) run(ctx context.Context, s Sink) {
+	for {
+		if err := c.acquire(ctx); err == errReaderCancelled {
+			return
+		} else if err != nil {
+			s.Error(err)
+			return
+		}
+		chunk := make([]byte, c.size)
+		n, err := io.ReadFull(c.r, chunk)
+		if n > 0 {
+			s.Next(payload.New(chunk[:n], nil))
+		}
+		switch err {
+		case nil:
+		case io.EOF, io.ErrUnexpectedEOF:
+			s.Complete()
+			return
+		default:
+			s.Error(err)
+			return
+		}
+	}
+}
+
+// acquire waits until the next chunk is requested.
+func (c *chunkReader) acquire(ctx context.Context) error {
+	for {
+		select {
+		case <-c.cancelled:
+			return errReaderCancelled
+		default:
+		}
+		c.Lock()
+		if c.demand > 0 {
+			if c.demand < rx.RequestMax {
+				c.demand--
+			}
+			c.Unlock()
+			return nil
+		}
+		c.Unlock()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.cancelled:
+			return errReaderCancelled
+		case <-c.wake:
+		}
+	}
+}
+
+func (c *chunkReader) request(n int) {
+	c.Lock()
+	if n >= rx.RequestMax || c.demand >= rx.RequestMax-n {
+		c.demand = rx.RequestMax
+	} else {
+		c.demand += n
+	}
+	c.Unlock()
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (c *chunkReader) cancel() {
+	c.cancelOnce.Do(func() {
+		close(c.cancelled)
+	})
+}
@@ -0,0 +1,217 @@
+package flux
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core/clock"
+	"github.com/rsocket/rsocket-go/internal/common"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx"
+)
+
+func sample(source proxy, period time.Duration, c clock.Clock) Flux {
+	var (
+		mu       sync.Mutex
+		samplers = make(map[*sampler]struct{})
+	)
+	return Create(func(ctx context.Context, s Sink) {
+		sp := &sampler{
+			sink:   s,
+			ticker: c.NewTicker(period),
+			stop:   make(chan struct{}),
+		}
+		mu.Lock()
+		samplers[sp] = struct{}{}
+		mu.Unlock()
+		sp.onTerminate = func() {
+			mu.Lock()
+			delete(samplers, sp)
+			mu.Unlock()
+		}
+		go sp.loop()
+		source.Subscribe(ctx,
+			rx.OnSubscribe(func(ctx context.Context, su rx.Subscription) {
+				if sp.subscribe(su) {
+					su.Request(rx.RequestMax)
+				}
+			}),
+			rx.OnNext(func(input payload.Payload) error {
+				sp.next(input)
+				return nil
+			}),
+			rx.OnComplete(func() {
+				sp.terminate(nil)
+			}),
+			rx.OnError(func(e error) {
+				sp.terminate(e)
+			}),
+		)
+	}).DoFinally(func(s rx.SignalType) {
+		if s != rx.SignalCancel {
+			return
+		}
+		mu.Lock()
+		cancelled := samplers
+		samplers = make(map[*sampler]struct{})
+		mu.Unlock()
+		for sp := range cancelled {
+			sp.cancel()
+		}
+	})
+}
+
+// sampler keeps the latest item of one subscription and emits it every period, replaced items are released.
+// emitting serializes signals to the sink, it is never held while the state is locked by cancel.
+type sampler struct {
+	sync.Mutex
+	emitting    sync.Mutex
+	sink        Sink
+	ticker      clock.Ticker
+	stop        chan struct{}
+	latest      payload.Payload
+	su          rx.Subscription
+	done        bool
+	onTerminate func()
+}
+
+func (sp *sampler) loop() {
+	for {
+		select {
+		case <-sp.ticker.C():
+			sp.emit()
+		case <-sp.stop:
+			return
+		}
+	}
+}
+
+func (sp *sampler) subscribe(su rx.Subscription) bool {
+	sp.Lock()
+	done := sp.done
+	sp.su = su
+	sp.Unlock()
+	if done {
+		su.Cancel()
+	}
+	return !done
+}
+
+func (sp *sampler) next(input payload.Payload) {
+	sp.Lock()
+	if sp.done {
+		sp.Unlock()
+		common.TryRelease(input)
+		return
+	}
+	prev := sp.latest
+	sp.latest = input
+	sp.Unlock()
+	if prev != nil {
+		common.TryRelease(prev)
+	}
+}
+
+func (sp *sampler) emit() {
+	sp.emitting.Lock()
+	defer sp.emitting.Unlock()
+	sp.Lock()
+	if sp.done {
+		sp.Unlock()
+		return
+	}
+	latest := sp.latest
+	sp.latest = nil
+	sp.Unlock()
+	if latest == nil {
+		return
+	}
+	// the sink may have been disposed by a cancel which is not propagated to the sampler yet.
+	defer func() {
+		if recover() != nil {
+			common.TryRelease(latest)
+		}
+	}()
+	sp.sink.Next(latest)
+}
+
+// finish marks the subscription done and stops the ticker, it returns the pending item and false if it has been done.
+func (sp *sampler) finish() (latest payload.Payload, ok bool) {
+	sp.Lock()
+	defer sp.Unlock()
+	if sp.done {
+		return
+	}
+	sp.done = true
+	latest, sp.latest = sp.latest, nil
+	sp.ticker.Stop()
+	close(sp.stop)
+	ok = true
+	return
+}
+
+func (sp *sampler) terminate(err error) {
+	latest, ok := sp.finish()
+	if !ok {
+		return
+	}
+	sp.onTerminate()
+	sp.emitting.Lock()
+	defer sp.emitting.Unlock()
+	if err != nil {
+		if latest != nil {
+			common.TryRelease(latest)
+		}
+		sp.sink.Error(err)
+		return
+	}
+	// the latest item is emitted before completion, like the next sample.
+	if latest != nil {
+		sp.sink.Next(latest)
+	}
+	sp.sink.Complete()
+}
+
+func (sp *sampler) cancel() {
+	latest, ok := sp.finish()
+	if !ok {
+		return
+	}
+	sp.Lock()
+	su := sp.su
+	sp.Unlock()
+	if su != nil {
+		su.Cancel()
+	}
+	if latest != nil {
+		common.TryRelease(latest)
+	}
+}
+
+func throttleFirst(source proxy, window time.Duration, c clock.Clock) Flux {
+	var (
+		mu     sync.Mutex
+		opened bool
+		last   time.Time
+	)
+	// Filter requests one more item for each dropped item, the window is reset on every subscription.
+	return source.
+		DoOnSubscribe(func(ctx context.Context, s rx.Subscription) {
+			mu.Lock()
+			opened = false
+			mu.Unlock()
+		}).
+		Filter(func(input payload.Payload) bool {
+			now := c.Now()
+			mu.Lock()
+			defer mu.Unlock()
+			if opened && now.Sub(last) < window {
+				common.TryRelease(input)
+				return false
+			}
+			opened = true
+			last = now
+			return true
+		})
+}
@@ -0,0 +1,171 @@
+package flux
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rsocket/rsocket-go/internal/common"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx"
+)
+
+func scan(source proxy, seed payload.Payload, fn FnAccumulate) Flux {
+	var (
+		mu  sync.Mutex
+		acc payload.Payload
+	)
+	// Map keeps the demand of subscriber, the accumulator is reset on every subscription.
+	return source.
+		DoOnSubscribe(func(ctx context.Context, s rx.Subscription) {
+			mu.Lock()
+			acc = seed
+			mu.Unlock()
+		}).
+		Map(func(input payload.Payload) (payload.Payload, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			acc = fn(acc, input)
+			releaseUnless(input, acc)
+			return acc, nil
+		})
+}
+
+func reduce(source proxy, seed payload.Payload, fn FnAccumulate) Flux {
+	var (
+		mu       sync.Mutex
+		reducers = make(map[*reducer]struct{})
+	)
+	return Create(func(ctx context.Context, s Sink) {
+		r := &reducer{
+			sink: s,
+			seed: seed,
+			acc:  seed,
+			fn:   fn,
+		}
+		mu.Lock()
+		reducers[r] = struct{}{}
+		mu.Unlock()
+		r.onTerminate = func() {
+			mu.Lock()
+			delete(reducers, r)
+			mu.Unlock()
+		}
+		source.Subscribe(ctx,
+			rx.OnSubscribe(func(ctx context.Context, su rx.Subscription) {
+				if r.subscribe(su) {
+					su.Request(rx.RequestMax)
+				}
+			}),
+			rx.OnNext(func(input payload.Payload) error {
+				r.next(input)
+				return nil
+			}),
+			rx.OnComplete(func() {
+				r.terminate(nil)
+			}),
+			rx.OnError(func(e error) {
+				r.terminate(e)
+			}),
+		)
+	}).DoFinally(func(s rx.SignalType) {
+		if s != rx.SignalCancel {
+			return
+		}
+		mu.Lock()
+		cancelled := reducers
+		reducers = make(map[*reducer]struct{})
+		mu.Unlock()
+		for r := range cancelled {
+			r.cancel()
+		}
+	})
+}
+
+// reducer aggregates the items of one subscription, intermediate accumulators are released once replaced.
+type reducer struct {
+	sync.Mutex
+	sink        Sink
+	seed        payload.Payload
+	acc         payload.Payload
+	fn          FnAccumulate
+	su          rx.Subscription
+	done        bool
+	onTerminate func()
+}
+
+func (r *reducer) subscribe(su rx.Subscription) bool {
+	r.Lock()
+	done := r.done
+	r.su = su
+	r.Unlock()
+	if done {
+		su.Cancel()
+	}
+	return !done
+}
+
+func (r *reducer) next(input payload.Payload) {
+	r.Lock()
+	defer r.Unlock()
+	if r.done {
+		common.TryRelease(input)
+		return
+	}
+	prev := r.acc
+	r.acc = r.fn(prev, input)
+	releaseUnless(input, r.acc)
+	releaseUnless(prev, r.acc, r.seed)
+}
+
+func (r *reducer) terminate(err error) {
+	r.Lock()
+	if r.done {
+		r.Unlock()
+		return
+	}
+	r.done = true
+	acc := r.acc
+	r.acc = nil
+	r.Unlock()
+	r.onTerminate()
+	if err != nil {
+		releaseUnless(acc, r.seed)
+		r.sink.Error(err)
+		return
+	}
+	if acc != nil {
+		r.sink.Next(acc)
+	}
+	r.sink.Complete()
+}
+
+func (r *reducer) cancel() {
+	r.Lock()
+	if r.done {
+		r.Unlock()
+		return
+	}
+	r.done = true
+	su, acc := r.su, r.acc
+	r.acc = nil
+	r.Unlock()
+	if su != nil {
+		su.Cancel()
+	}
+	releaseUnless(acc, r.seed)
+}
+
+// releaseUnless releases the payload unless it is one of the kept payloads.
+func releaseUnless(p payload.Payload, kept ...payload.Payload) {
+	r, ok := p.(common.Releasable)
+	if !ok {
+		return
+	}
+	// only releasable payloads are compared, they are always comparable pointers.
+	for _, it := range kept {
+		if p == it {
+			return
+		}
+	}
+	r.Release()
+}
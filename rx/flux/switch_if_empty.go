@@ -0,0 +1,156 @@
+package flux
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rsocket/rsocket-go/internal/common"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx"
+)
+
+func switchIfEmpty(source proxy, alternative rx.Publisher) Flux {
+	var (
+		mu        sync.Mutex
+		switchers = make(map[*switcher]struct{})
+	)
+	return Create(func(ctx context.Context, s Sink) {
+		sw := &switcher{
+			sink:        s,
+			alternative: alternative,
+		}
+		mu.Lock()
+		switchers[sw] = struct{}{}
+		mu.Unlock()
+		sw.onTerminate = func() {
+			mu.Lock()
+			delete(switchers, sw)
+			mu.Unlock()
+		}
+		sw.subscribe(ctx, source, true)
+	}).DoFinally(func(s rx.SignalType) {
+		if s != rx.SignalCancel {
+			return
+		}
+		mu.Lock()
+		cancelled := switchers
+		switchers = make(map[*switcher]struct{})
+		mu.Unlock()
+		for sw := range cancelled {
+			sw.cancel()
+		}
+	})
+}
+
+// switcher forwards the items of the primary source, or the alternative one if the primary completes without any item.
+// Like Merge, every source is requested in small batches.
+type switcher struct {
+	sync.Mutex
+	sink        Sink
+	alternative rx.Publisher
+	su          rx.Subscription
+	emitted     bool
+	done        bool
+	onTerminate func()
+}
+
+func (sw *switcher) subscribe(ctx context.Context, source rx.Publisher, primary bool) {
+	var received int
+	source.Subscribe(ctx,
+		rx.OnSubscribe(func(ctx context.Context, s rx.Subscription) {
+			if !sw.setSubscription(s) {
+				s.Cancel()
+				return
+			}
+			s.Request(mergePrefetch)
+		}),
+		rx.OnNext(func(input payload.Payload) error {
+			if !sw.next(input) {
+				return nil
+			}
+			if received++; received == mergePrefetch/2 {
+				received = 0
+				sw.request(mergePrefetch / 2)
+			}
+			return nil
+		}),
+		rx.OnComplete(func() {
+			if primary && !sw.hasEmitted() {
+				sw.subscribe(ctx, sw.alternative, false)
+				return
+			}
+			sw.terminate(nil)
+		}),
+		rx.OnError(func(e error) {
+			sw.terminate(e)
+		}),
+	)
+}
+
+func (sw *switcher) setSubscription(su rx.Subscription) bool {
+	sw.Lock()
+	defer sw.Unlock()
+	if sw.done {
+		return false
+	}
+	sw.su = su
+	return true
+}
+
+func (sw *switcher) request(n int) {
+	sw.Lock()
+	su := sw.su
+	sw.Unlock()
+	su.Request(n)
+}
+
+func (sw *switcher) hasEmitted() bool {
+	sw.Lock()
+	defer sw.Unlock()
+	return sw.emitted
+}
+
+func (sw *switcher) next(input payload.Payload) bool {
+	sw.Lock()
+	done := sw.done
+	sw.emitted = true
+	sw.Unlock()
+	if done {
+		common.TryRelease(input)
+		return false
+	}
+	sw.sink.Next(input)
+	return true
+}
+
+func (sw *switcher) terminate(err error) {
+	sw.Lock()
+	if sw.done {
+		sw.Unlock()
+		return
+	}
+	sw.done = true
+	sw.su = nil
+	sw.Unlock()
+	sw.onTerminate()
+	if err != nil {
+		sw.sink.Error(err)
+	} else {
+		sw.sink.Complete()
+	}
+}
+
+func (sw *switcher) cancel() {
+	sw.Lock()
+	if sw.done {
+		sw.Unlock()
+		return
+	}
+	sw.done = true
+	su := sw.su
+	sw.su = nil
+	sw.Unlock()
+	if su != nil {
+		su.Cancel()
+	}
+}
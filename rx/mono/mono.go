@@ -6,8 +6,10 @@ import (
 
 	"github.com/jjeffcaii/reactor-go/mono"
 	"github.com/jjeffcaii/reactor-go/scheduler"
+	"github.com/rsocket/rsocket-go/core/clock"
 	"github.com/rsocket/rsocket-go/payload"
 	"github.com/rsocket/rsocket-go/rx"
+	"github.com/rsocket/rsocket-go/rx/flux"
 )
 
 // ReleaseFunc can be used to release resources.
@@ -52,6 +54,12 @@ type Mono interface {
 	ToChan(ctx context.Context) (c <-chan payload.Payload, e <-chan error)
 	// Timeout sets the timeout value.
 	Timeout(timeout time.Duration) Mono
+	// TimeoutWithClock is like Timeout, but the timeout is measured by the Clock, eg: a clock.Fake in tests.
+	// The source will be cancelled once it timed out.
+	TimeoutWithClock(timeout time.Duration, c clock.Clock) Mono
+	// ToFlux converts this Mono to a Flux which emits at most one item then completes.
+	// If the Flux is returned by a RequestStream handler as is, the item and the completion are sent in one PAYLOAD frame.
+	ToFlux() flux.Flux
 }
 
 // Sink is a wrapper API around an actual downstream Subscriber for emitting nothing, a single value or an error (mutually exclusive).
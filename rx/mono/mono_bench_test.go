@@ -2,9 +2,12 @@ package mono_test
 
 import (
 	"context"
+	"sync"
 	"testing"
 
+	"github.com/jjeffcaii/reactor-go/scheduler"
 	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx"
 	"github.com/rsocket/rsocket-go/rx/mono"
 )
 
@@ -25,3 +28,23 @@ func BenchmarkOneshotProxy(b *testing.B) {
 		}
 	})
 }
+
+func BenchmarkSubscribeOn(b *testing.B) {
+	wg := &sync.WaitGroup{}
+	wg.Add(b.N)
+	sub := rx.NewSubscriber(rx.OnComplete(wg.Done))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mono.JustOneshot(_fakePayload).SubscribeOn(scheduler.Parallel()).SubscribeWith(context.Background(), sub)
+	}
+	wg.Wait()
+}
+
+func BenchmarkSubscribeAll(b *testing.B) {
+	monos := make([]mono.Mono, b.N)
+	for i := 0; i < b.N; i++ {
+		monos[i] = mono.JustOneshot(_fakePayload)
+	}
+	b.ResetTimer()
+	_ = mono.SubscribeAll(context.Background(), scheduler.Parallel(), monos)
+}
@@ -2,6 +2,7 @@ package mono_test
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"strings"
 	"testing"
@@ -10,6 +11,7 @@ import (
 	"github.com/jjeffcaii/reactor-go"
 	"github.com/jjeffcaii/reactor-go/scheduler"
 	"github.com/pkg/errors"
+	"github.com/rsocket/rsocket-go/core/clock"
 	"github.com/rsocket/rsocket-go/payload"
 	"github.com/rsocket/rsocket-go/rx"
 	. "github.com/rsocket/rsocket-go/rx/mono"
@@ -191,6 +193,49 @@ func TestTimeout(t *testing.T) {
 	assert.True(t, reactor.IsCancelledError(err), "should be cancelled error")
 }
 
+func TestTimeoutWithClock(t *testing.T) {
+	c := clock.NewFake(time.Now())
+	cancelled := atomic.NewBool(false)
+	done := make(chan error, 1)
+	go func() {
+		_, err := Create(func(ctx context.Context, sink Sink) {
+			go func() {
+				<-ctx.Done()
+				cancelled.Store(true)
+			}()
+		}).TimeoutWithClock(time.Hour, c).Block(context.Background())
+		done <- err
+	}()
+	assert.Eventually(t, func() bool {
+		return c.Waiters() == 1
+	}, time.Second, time.Millisecond)
+	c.Advance(time.Hour - time.Millisecond)
+	select {
+	case <-done:
+		assert.Fail(t, "should not time out yet")
+	default:
+	}
+	c.Advance(time.Millisecond)
+	err := <-done
+	assert.True(t, reactor.IsCancelledError(err), "should be cancelled error")
+	assert.Eventually(t, cancelled.Load, time.Second, time.Millisecond, "source should be cancelled")
+
+	res, err := Just(payload.NewString("foobar", "")).TimeoutWithClock(time.Hour, c).Block(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "foobar", res.DataUTF8())
+	assert.Eventually(t, func() bool {
+		return c.Waiters() == 0
+	}, time.Second, time.Millisecond, "timer should be stopped")
+
+	res, err = Empty().TimeoutWithClock(time.Hour, c).Block(context.Background())
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+
+	res, err = JustOneshot(payload.NewString("foobar", "")).TimeoutWithClock(time.Hour, c).Block(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "foobar", res.DataUTF8())
+}
+
 func TestBlockRelease(t *testing.T) {
 	input := (*mockPayload)(atomic.NewInt32(0))
 	_, release, err := Just(input).BlockUnsafe(context.Background())
@@ -237,3 +282,66 @@ func (m *mockPayload) Data() []byte {
 func (m *mockPayload) DataUTF8() string {
 	return string(m.Data())
 }
+
+func TestToFlux(t *testing.T) {
+	f := Just(payload.NewString("foo", "")).ToFlux()
+	values, err := f.BlockSlice(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, values, 1)
+	assert.Equal(t, "foo", values[0].DataUTF8())
+	_, ok := FromFlux(f)
+	assert.True(t, ok)
+	_, ok = FromFlux(f.Take(1))
+	assert.False(t, ok, "should not be converted back after applying an operator")
+
+	values, err = Empty().ToFlux().BlockSlice(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, values)
+
+	fakeErr := errors.New("fake error")
+	_, err = Error(fakeErr).ToFlux().BlockSlice(context.Background())
+	assert.Equal(t, fakeErr, err)
+}
+
+func TestSubscribeAll(t *testing.T) {
+	const n = 1000
+	fakeErr := errors.New("fake error")
+	monos := make([]Mono, 0, n)
+	for i := 0; i < n; i++ {
+		switch i % 3 {
+		case 0:
+			monos = append(monos, Just(payload.NewString(fmt.Sprint(i), "")))
+		case 1:
+			monos = append(monos, Empty())
+		default:
+			monos = append(monos, Error(fakeErr))
+		}
+	}
+	nexts := atomic.NewInt32(0)
+	completes := atomic.NewInt32(0)
+	errs := atomic.NewInt32(0)
+	err := SubscribeAll(context.Background(), scheduler.Parallel(), monos,
+		rx.OnNext(func(input payload.Payload) error {
+			nexts.Inc()
+			return nil
+		}),
+		rx.OnComplete(func() {
+			completes.Inc()
+		}),
+		rx.OnError(func(e error) {
+			assert.Equal(t, fakeErr, e)
+			errs.Inc()
+		}),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(334), nexts.Load())
+	assert.Equal(t, int32(667), completes.Load())
+	assert.Equal(t, int32(333), errs.Load())
+
+	assert.NoError(t, SubscribeAll(context.Background(), scheduler.Parallel(), nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	never := Create(func(ctx context.Context, sink Sink) {})
+	assert.Equal(t, context.DeadlineExceeded, SubscribeAll(ctx, scheduler.Parallel(), []Mono{Just(payload.NewString("foo", "")), never}))
+}
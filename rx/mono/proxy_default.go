@@ -7,17 +7,20 @@ import (
 	"github.com/jjeffcaii/reactor-go"
 	"github.com/jjeffcaii/reactor-go/mono"
 	"github.com/jjeffcaii/reactor-go/scheduler"
+	"github.com/rsocket/rsocket-go/core/clock"
 	"github.com/rsocket/rsocket-go/internal/common"
 	"github.com/rsocket/rsocket-go/payload"
 	"github.com/rsocket/rsocket-go/rx"
+	"github.com/rsocket/rsocket-go/rx/flux"
 )
 
 type proxy struct {
 	mono.Mono
+	async bool // subscribed on an rx.AsyncScheduler by the outermost operator
 }
 
 func newProxy(source mono.Mono) proxy {
-	return proxy{source}
+	return proxy{Mono: source}
 }
 
 func noopRelease() {
@@ -40,7 +43,10 @@ func (p proxy) ToChan(ctx context.Context) (<-chan payload.Payload, <-chan error
 }
 
 func (p proxy) SubscribeOn(sc scheduler.Scheduler) Mono {
-	return newProxy(p.Mono.SubscribeOn(sc))
+	return proxy{
+		Mono:  p.Mono.SubscribeOn(sc),
+		async: rx.IsAsyncScheduler(sc),
+	}
 }
 
 func (p proxy) SubscribeWithChan(ctx context.Context, valueChan chan<- payload.Payload, errChan chan<- error) {
@@ -127,6 +133,14 @@ func (p proxy) Timeout(timeout time.Duration) Mono {
 	return newProxy(p.Mono.Timeout(timeout))
 }
 
+func (p proxy) TimeoutWithClock(timeout time.Duration, c clock.Clock) Mono {
+	return newProxy(timeoutWithClock(p.Mono, timeout, c))
+}
+
+func (p proxy) ToFlux() flux.Flux {
+	return toFlux(p)
+}
+
 func (p proxy) Subscribe(ctx context.Context, options ...rx.SubscriberOption) {
 	p.SubscribeWith(ctx, rx.NewSubscriber(options...))
 }
@@ -8,9 +8,11 @@ import (
 	"github.com/jjeffcaii/reactor-go"
 	"github.com/jjeffcaii/reactor-go/mono"
 	"github.com/jjeffcaii/reactor-go/scheduler"
+	"github.com/rsocket/rsocket-go/core/clock"
 	"github.com/rsocket/rsocket-go/internal/common"
 	"github.com/rsocket/rsocket-go/payload"
 	"github.com/rsocket/rsocket-go/rx"
+	"github.com/rsocket/rsocket-go/rx/flux"
 )
 
 var _oneshotProxyPool = sync.Pool{
@@ -21,6 +23,7 @@ var _oneshotProxyPool = sync.Pool{
 
 type oneshotProxy struct {
 	mono.Mono
+	async bool // subscribed on an rx.AsyncScheduler by the outermost operator
 }
 
 func borrowOneshotProxy(origin mono.Mono) *oneshotProxy {
@@ -30,7 +33,7 @@ func borrowOneshotProxy(origin mono.Mono) *oneshotProxy {
 }
 
 func returnOneshotProxy(o *oneshotProxy) (raw mono.Mono) {
-	raw, o.Mono = o.Mono, nil
+	raw, o.Mono, o.async = o.Mono, nil, false
 	_oneshotProxyPool.Put(o)
 	return
 }
@@ -58,6 +61,7 @@ func (o *oneshotProxy) Subscribe(ctx context.Context, options ...rx.SubscriberOp
 }
 
 func (o *oneshotProxy) Filter(predicate rx.FnPredicate) Mono {
+	o.async = false
 	o.Mono = o.Mono.Filter(func(any reactor.Any) bool {
 		return predicate(any.(payload.Payload))
 	})
@@ -65,6 +69,7 @@ func (o *oneshotProxy) Filter(predicate rx.FnPredicate) Mono {
 }
 
 func (o *oneshotProxy) Map(transform rx.FnTransform) Mono {
+	o.async = false
 	o.Mono = o.Mono.Map(func(any reactor.Any) (reactor.Any, error) {
 		return transform(any.(payload.Payload))
 	})
@@ -72,6 +77,7 @@ func (o *oneshotProxy) Map(transform rx.FnTransform) Mono {
 }
 
 func (o *oneshotProxy) FlatMap(f func(payload.Payload) Mono) Mono {
+	o.async = false
 	o.Mono = o.Mono.FlatMap(func(any reactor.Any) mono.Mono {
 		return f(any.(payload.Payload)).Raw()
 	})
@@ -79,6 +85,7 @@ func (o *oneshotProxy) FlatMap(f func(payload.Payload) Mono) Mono {
 }
 
 func (o *oneshotProxy) DoFinally(finally rx.FnFinally) Mono {
+	o.async = false
 	o.Mono = o.Mono.DoFinally(func(s reactor.SignalType) {
 		finally(rx.SignalType(s))
 	})
@@ -86,6 +93,7 @@ func (o *oneshotProxy) DoFinally(finally rx.FnFinally) Mono {
 }
 
 func (o *oneshotProxy) DoOnError(onError rx.FnOnError) Mono {
+	o.async = false
 	o.Mono = o.Mono.DoOnError(func(e error) {
 		onError(e)
 	})
@@ -93,6 +101,7 @@ func (o *oneshotProxy) DoOnError(onError rx.FnOnError) Mono {
 }
 
 func (o *oneshotProxy) DoOnSuccess(next rx.FnOnNext) Mono {
+	o.async = false
 	o.Mono = o.Mono.DoOnNext(func(v reactor.Any) error {
 		return next(v.(payload.Payload))
 	})
@@ -100,17 +109,20 @@ func (o *oneshotProxy) DoOnSuccess(next rx.FnOnNext) Mono {
 }
 
 func (o *oneshotProxy) DoOnCancel(cancel rx.FnOnCancel) Mono {
+	o.async = false
 	o.Mono = o.Mono.DoOnCancel(cancel)
 	return o
 }
 
 func (o *oneshotProxy) DoOnSubscribe(subscribe rx.FnOnSubscribe) Mono {
+	o.async = false
 	o.Mono = o.Mono.DoOnSubscribe(subscribe)
 	return o
 }
 
 func (o *oneshotProxy) SubscribeOn(scheduler scheduler.Scheduler) Mono {
 	o.Mono = o.Mono.SubscribeOn(scheduler)
+	o.async = rx.IsAsyncScheduler(scheduler)
 	return o
 }
 
@@ -143,6 +155,7 @@ func (o *oneshotProxy) Block(ctx context.Context) (payload.Payload, error) {
 }
 
 func (o *oneshotProxy) SwitchIfEmpty(alternative Mono) Mono {
+	o.async = false
 	o.Mono = o.Mono.SwitchIfEmpty(alternative.Raw())
 	return o
 }
@@ -156,6 +169,17 @@ func (o *oneshotProxy) ToChan(ctx context.Context) (c <-chan payload.Payload, e
 }
 
 func (o *oneshotProxy) Timeout(timeout time.Duration) Mono {
+	o.async = false
 	o.Mono = o.Mono.Timeout(timeout)
 	return o
 }
+
+func (o *oneshotProxy) ToFlux() flux.Flux {
+	return toFlux(o)
+}
+
+func (o *oneshotProxy) TimeoutWithClock(timeout time.Duration, c clock.Clock) Mono {
+	o.async = false
+	o.Mono = timeoutWithClock(o.Mono, timeout, c)
+	return o
+}
@@ -0,0 +1,74 @@
+package mono
+
+import (
+	"context"
+	"runtime"
+
+	"github.com/jjeffcaii/reactor-go/scheduler"
+	"github.com/rsocket/rsocket-go/rx"
+	"go.uber.org/atomic"
+)
+
+// SubscribeAll subscribes all the Monos with one Subscriber which is created by the options,
+// then blocks until all of them have terminated or the context is done.
+// Instead of scheduling every Subscribe like SubscribeOn, the Monos are split into one batch per CPU
+// and each batch is subscribed by one task of the Scheduler, eg: issuing a large amount of requests in benchmarks.
+// The Subscriber is shared by all the Monos, so the options must be safe for concurrent use.
+func SubscribeAll(ctx context.Context, sc scheduler.Scheduler, monos []Mono, options ...rx.SubscriberOption) error {
+	if len(monos) < 1 {
+		return nil
+	}
+	b := &barrier{
+		Subscriber: rx.NewSubscriber(options...),
+		remaining:  atomic.NewInt64(int64(len(monos))),
+		done:       make(chan struct{}),
+	}
+
+	size := (len(monos) + runtime.NumCPU() - 1) / runtime.NumCPU()
+	for start := 0; start < len(monos); start += size {
+		end := start + size
+		if end > len(monos) {
+			end = len(monos)
+		}
+		batch := monos[start:end]
+		subscribe := func() {
+			for _, m := range batch {
+				m.SubscribeWith(ctx, b)
+			}
+		}
+		// subscribe in place if the scheduler rejects the task, eg: it has been closed.
+		if err := sc.Worker().Do(subscribe); err != nil {
+			subscribe()
+		}
+	}
+
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// barrier forwards signals to the actual Subscriber and closes done once all the Monos have terminated.
+type barrier struct {
+	rx.Subscriber
+	remaining *atomic.Int64
+	done      chan struct{}
+}
+
+func (b *barrier) OnComplete() {
+	b.Subscriber.OnComplete()
+	b.terminate()
+}
+
+func (b *barrier) OnError(err error) {
+	b.Subscriber.OnError(err)
+	b.terminate()
+}
+
+func (b *barrier) terminate() {
+	if b.remaining.Dec() == 0 {
+		close(b.done)
+	}
+}
@@ -0,0 +1,63 @@
+package mono
+
+import (
+	"context"
+	"time"
+
+	"github.com/jjeffcaii/reactor-go"
+	"github.com/jjeffcaii/reactor-go/mono"
+	"github.com/rsocket/rsocket-go/core/clock"
+	"github.com/rsocket/rsocket-go/internal/common"
+	"go.uber.org/atomic"
+)
+
+// timeoutWithClock fails with reactor.ErrSubscribeCancelled like Timeout if the source doesn't terminate
+// before the timer of the Clock fires, then the source will be cancelled.
+func timeoutWithClock(source mono.Mono, timeout time.Duration, c clock.Clock) mono.Mono {
+	return mono.Create(func(ctx context.Context, sink mono.Sink) {
+		ctx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		finished := atomic.NewBool(false)
+		// finish returns true only for the first terminal signal.
+		finish := func() bool {
+			if !finished.CAS(false, true) {
+				return false
+			}
+			close(done)
+			return true
+		}
+		timer := clock.OrReal(c).NewTimer(timeout)
+		go func() {
+			defer cancel()
+			defer timer.Stop()
+			select {
+			case <-timer.C():
+				if finish() {
+					sink.Error(reactor.ErrSubscribeCancelled)
+				}
+			case <-done:
+			case <-ctx.Done():
+			}
+		}()
+		source.Subscribe(ctx,
+			reactor.OnNext(func(v reactor.Any) error {
+				if finish() {
+					sink.Success(v)
+				} else {
+					common.TryRelease(v)
+				}
+				return nil
+			}),
+			reactor.OnComplete(func() {
+				if finish() {
+					sink.Success(nil)
+				}
+			}),
+			reactor.OnError(func(e error) {
+				if finish() {
+					sink.Error(e)
+				}
+			}),
+		)
+	})
+}
@@ -0,0 +1,27 @@
+package mono
+
+import (
+	"github.com/rsocket/rsocket-go/rx/flux"
+)
+
+// monoFlux is a Flux converted from a Mono, it keeps the Mono so it can be converted back.
+type monoFlux struct {
+	flux.Flux
+	source Mono
+}
+
+func toFlux(source Mono) flux.Flux {
+	return monoFlux{
+		Flux:   flux.Clone(source),
+		source: source,
+	}
+}
+
+// FromFlux returns the Mono which the Flux is converted from by ToFlux.
+// It returns false if any operator has been applied on the converted Flux.
+func FromFlux(f flux.Flux) (Mono, bool) {
+	if it, ok := f.(monoFlux); ok {
+		return it.source, true
+	}
+	return nil, false
+}
@@ -12,7 +12,18 @@ import (
 var empty = newProxy(mono.Empty())
 
 // IsSubscribeAsync returns true if target Mono will be subscribed async.
+// It is true if the outermost operator is SubscribeOn with an asynchronous scheduler, see rx.IsAsyncScheduler.
 func IsSubscribeAsync(m Mono) bool {
+	switch it := m.(type) {
+	case proxy:
+		if it.async {
+			return true
+		}
+	case *oneshotProxy:
+		if it.async {
+			return true
+		}
+	}
 	return mono.IsSubscribeAsync(m.Raw())
 }
 
@@ -9,6 +9,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx"
 	"github.com/rsocket/rsocket-go/rx/mono"
 	"github.com/stretchr/testify/assert"
 )
@@ -154,3 +155,17 @@ func TestSubscribeWithChan(t *testing.T) {
 		assert.NoError(t, err, "should not return error")
 	}
 }
+
+func TestIsSubscribeAsync(t *testing.T) {
+	sc, err := rx.NewBoundedScheduler(1, rx.BlockOnSaturation)
+	assert.NoError(t, err)
+	defer sc.Close()
+
+	assert.False(t, mono.IsSubscribeAsync(mono.Just(payload.NewString("foo", ""))))
+	assert.True(t, mono.IsSubscribeAsync(mono.Just(payload.NewString("foo", "")).SubscribeOn(scheduler.Parallel())))
+	assert.True(t, mono.IsSubscribeAsync(mono.Just(payload.NewString("foo", "")).SubscribeOn(sc)))
+	assert.True(t, mono.IsSubscribeAsync(mono.JustOneshot(payload.NewString("foo", "")).SubscribeOn(sc)))
+	// the Mono is not known to be asynchronous once another operator is applied.
+	assert.False(t, mono.IsSubscribeAsync(mono.Just(payload.NewString("foo", "")).SubscribeOn(sc).DoOnCancel(func() {})))
+	assert.False(t, mono.IsSubscribeAsync(mono.JustOneshot(payload.NewString("foo", "")).SubscribeOn(sc).DoOnCancel(func() {})))
+}
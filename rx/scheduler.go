@@ -0,0 +1,173 @@
+package rx
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/jjeffcaii/reactor-go/scheduler"
+	"github.com/panjf2000/ants/v2"
+	"github.com/pkg/errors"
+	"github.com/rsocket/rsocket-go/core"
+	"go.uber.org/atomic"
+)
+
+const _boundedName = "bounded"
+
+// DefaultWorkersPerProc is the default multiplier of worker pool size per GOMAXPROCS.
+const DefaultWorkersPerProc = 256
+
+var (
+	_workersPerProc = atomic.NewInt32(DefaultWorkersPerProc)
+	_elastic        scheduler.Scheduler
+	_elasticOnce    sync.Once
+)
+
+// SetWorkersPerProc sets the multiplier k, then the default worker pool size will be GOMAXPROCS * k.
+// It only affects schedulers created after it's called.
+func SetWorkersPerProc(k int) {
+	if k > 0 {
+		_workersPerProc.Store(int32(k))
+	}
+}
+
+// DefaultWorkers returns the default worker pool size which is GOMAXPROCS * k.
+// GOMAXPROCS is read on every call.
+func DefaultWorkers() int {
+	return runtime.GOMAXPROCS(0) * int(_workersPerProc.Load())
+}
+
+// NewElasticScheduler creates a scheduler backed by a goroutine pool with at most size workers.
+// A non-positive size means DefaultWorkers(), which is read once at creation.
+func NewElasticScheduler(size int) scheduler.Scheduler {
+	if size < 1 {
+		size = DefaultWorkers()
+	}
+	return scheduler.NewElastic(size)
+}
+
+// ElasticScheduler returns a shared elastic scheduler.
+// Its size is DefaultWorkers() at the first call and will not follow later changes of GOMAXPROCS.
+func ElasticScheduler() scheduler.Scheduler {
+	_elasticOnce.Do(func() {
+		_elastic = NewElasticScheduler(0)
+	})
+	return _elastic
+}
+
+// AsyncScheduler is implemented by schedulers which never run tasks on the goroutine of the caller,
+// so Monos subscribed on them are known to be asynchronous, see mono.IsSubscribeAsync.
+type AsyncScheduler interface {
+	scheduler.Scheduler
+	// IsAsync returns true if tasks never run on the goroutine of the caller.
+	IsAsync() bool
+}
+
+// IsAsyncScheduler returns true if tasks of the scheduler never run on the goroutine of the caller,
+// it includes the elastic, parallel and single schedulers of reactor-go and any AsyncScheduler.
+func IsAsyncScheduler(sc scheduler.Scheduler) bool {
+	if it, ok := sc.(AsyncScheduler); ok {
+		return it.IsAsync()
+	}
+	return scheduler.IsElastic(sc) || scheduler.IsParallel(sc) || scheduler.IsSingle(sc)
+}
+
+// SaturationPolicy decides what a BoundedScheduler does with a task once all of its workers are busy.
+type SaturationPolicy int8
+
+const (
+	// BlockOnSaturation blocks the caller until a worker is free, so the load is pushed back to the caller.
+	BlockOnSaturation SaturationPolicy = iota
+	// RejectOnSaturation rejects the task with core.ErrSchedulerSaturated at once, so the caller can fail fast.
+	RejectOnSaturation
+)
+
+func (p SaturationPolicy) String() string {
+	switch p {
+	case BlockOnSaturation:
+		return "BLOCK"
+	case RejectOnSaturation:
+		return "REJECT"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// BoundedScheduler is an asynchronous scheduler backed by a goroutine pool with at most size workers,
+// the SaturationPolicy decides what to do with tasks once all workers are busy.
+//
+// Be careful that SubscribeOn panics if a task is rejected, so a BoundedScheduler with RejectOnSaturation
+// should be used by callers which handle the error of Worker().Do, eg: ClientBuilder.DispatchScheduler.
+type BoundedScheduler struct {
+	pool     *ants.Pool
+	policy   SaturationPolicy
+	rejected *atomic.Uint64
+}
+
+// NewBoundedScheduler creates a BoundedScheduler with at most size workers.
+// A non-positive size means DefaultWorkers(), which is read once at creation.
+func NewBoundedScheduler(size int, policy SaturationPolicy) (*BoundedScheduler, error) {
+	if policy != BlockOnSaturation && policy != RejectOnSaturation {
+		return nil, errors.Errorf("rsocket: invalid saturation policy %d", policy)
+	}
+	if size < 1 {
+		size = DefaultWorkers()
+	}
+	pool, err := ants.NewPool(size, ants.WithNonblocking(policy == RejectOnSaturation))
+	if err != nil {
+		return nil, err
+	}
+	return &BoundedScheduler{
+		pool:     pool,
+		policy:   policy,
+		rejected: atomic.NewUint64(0),
+	}, nil
+}
+
+// Name returns the name of the scheduler.
+func (s *BoundedScheduler) Name() string {
+	return _boundedName
+}
+
+// IsAsync returns true since tasks always run on workers of the pool, so Monos subscribed on the scheduler
+// are known to be asynchronous.
+func (s *BoundedScheduler) IsAsync() bool {
+	return true
+}
+
+// Close releases all workers of the scheduler.
+func (s *BoundedScheduler) Close() error {
+	s.pool.Release()
+	return nil
+}
+
+// Worker returns the scheduler itself.
+func (s *BoundedScheduler) Worker() scheduler.Worker {
+	return s
+}
+
+// Do runs the task on a free worker, it returns core.ErrSchedulerSaturated if the task is rejected
+// because all workers are busy, or core.ErrSchedulerClosed if the scheduler has been closed.
+func (s *BoundedScheduler) Do(task scheduler.Task) error {
+	switch err := s.pool.Submit(task); err {
+	case nil:
+		return nil
+	case ants.ErrPoolOverload:
+		s.rejected.Inc()
+		return core.ErrSchedulerSaturated
+	case ants.ErrPoolClosed:
+		return core.ErrSchedulerClosed
+	default:
+		return err
+	}
+}
+
+// Policy returns the SaturationPolicy of the scheduler.
+func (s *BoundedScheduler) Policy() SaturationPolicy {
+	return s.policy
+}
+
+// Rejected returns the amount of tasks rejected because all workers were busy, it can be used as a counter.
+// It is always zero with BlockOnSaturation.
+func (s *BoundedScheduler) Rejected() uint64 {
+	return s.rejected.Load()
+}
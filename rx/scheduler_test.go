@@ -0,0 +1,91 @@
+package rx_test
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/jjeffcaii/reactor-go/scheduler"
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/rx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultWorkers(t *testing.T) {
+	assert.Equal(t, runtime.GOMAXPROCS(0)*rx.DefaultWorkersPerProc, rx.DefaultWorkers())
+
+	rx.SetWorkersPerProc(2)
+	defer rx.SetWorkersPerProc(rx.DefaultWorkersPerProc)
+	assert.Equal(t, runtime.GOMAXPROCS(0)*2, rx.DefaultWorkers())
+
+	// ignore invalid multiplier
+	rx.SetWorkersPerProc(0)
+	assert.Equal(t, runtime.GOMAXPROCS(0)*2, rx.DefaultWorkers())
+
+	procs := runtime.GOMAXPROCS(1)
+	defer runtime.GOMAXPROCS(procs)
+	assert.Equal(t, 2, rx.DefaultWorkers())
+}
+
+func TestElasticScheduler(t *testing.T) {
+	sc := rx.ElasticScheduler()
+	assert.True(t, scheduler.IsElastic(sc))
+	assert.Equal(t, sc, rx.ElasticScheduler(), "should be shared")
+
+	sc = rx.NewElasticScheduler(0)
+	defer sc.Close()
+	done := make(chan struct{})
+	err := sc.Worker().Do(func() {
+		close(done)
+	})
+	assert.NoError(t, err)
+	<-done
+}
+
+func TestBoundedScheduler(t *testing.T) {
+	_, err := rx.NewBoundedScheduler(1, rx.SaturationPolicy(-1))
+	assert.Error(t, err)
+
+	sc, err := rx.NewBoundedScheduler(1, rx.RejectOnSaturation)
+	assert.NoError(t, err)
+	defer sc.Close()
+	assert.True(t, rx.IsAsyncScheduler(sc))
+	assert.False(t, scheduler.IsElastic(sc))
+	assert.Equal(t, rx.RejectOnSaturation, sc.Policy())
+	assert.Equal(t, "REJECT", sc.Policy().String())
+
+	release := make(chan struct{})
+	assert.NoError(t, sc.Worker().Do(func() {
+		<-release
+	}))
+	assert.Equal(t, core.ErrSchedulerSaturated, sc.Worker().Do(func() {}))
+	assert.Equal(t, uint64(1), sc.Rejected())
+	close(release)
+
+	sc, err = rx.NewBoundedScheduler(1, rx.BlockOnSaturation)
+	assert.NoError(t, err)
+	defer sc.Close()
+	blocked := make(chan struct{})
+	assert.NoError(t, sc.Worker().Do(func() {
+		<-blocked
+	}))
+	done := make(chan struct{})
+	go func() {
+		assert.NoError(t, sc.Worker().Do(func() {
+			close(done)
+		}))
+	}()
+	select {
+	case <-done:
+		assert.Fail(t, "the task should wait for a free worker")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(blocked)
+	<-done
+	assert.Zero(t, sc.Rejected())
+
+	// a closed scheduler is not saturated.
+	_ = sc.Close()
+	assert.Equal(t, core.ErrSchedulerClosed, sc.Worker().Do(func() {}))
+	assert.Zero(t, sc.Rejected())
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"time"
 
+	"github.com/jjeffcaii/reactor-go/scheduler"
 	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/core/clock"
 	"github.com/rsocket/rsocket-go/core/framing"
 	"github.com/rsocket/rsocket-go/core/transport"
 	"github.com/rsocket/rsocket-go/internal/bytesconv"
@@ -14,6 +16,7 @@ import (
 	"github.com/rsocket/rsocket-go/internal/socket"
 	"github.com/rsocket/rsocket-go/lease"
 	"github.com/rsocket/rsocket-go/logger"
+	"go.uber.org/atomic"
 )
 
 const (
@@ -26,6 +29,7 @@ var (
 	_errUnavailableLease     = "lease not supported"
 	_errDuplicatedSetupToken = "duplicated setup token"
 	_errInvalidFirstFrame    = "first frame must be setup or resume"
+	_errTooManySetups        = "too many handshakes in progress"
 )
 
 type (
@@ -43,6 +47,67 @@ type (
 		Acceptor(acceptor ServerAcceptor) ToServerStarter
 		// OnStart register a handler when serve success.
 		OnStart(onStart func()) ServerBuilder
+		// OnAcceptError register a handler which is invoked when the listener fails to accept a connection,
+		// the server keeps serving if it returns true, otherwise Serve returns the error.
+		// By default the server only keeps serving after temporary errors, see transport.IsTemporaryError.
+		// It is supported by TCP and UDS transports, which implement transport.AcceptErrorNotifier.
+		OnAcceptError(handler func(err error) (continued bool)) ServerBuilder
+		// StreamListener set a listener of stream lifecycle events for every connection.
+		StreamListener(listener StreamListener) ServerBuilder
+		// StreamStallThreshold set the duration without REQUEST_N after which a responding REQUEST_STREAM is considered stalled.
+		// Default is zero which means disabled, see ClientBuilder.StreamStallThreshold for details.
+		StreamStallThreshold(threshold time.Duration) ServerBuilder
+		// RequestNCoalescing set the window in which REQUEST_N signals of a receiving stream are accumulated for every connection.
+		// Default is zero which means disabled, see ClientBuilder.RequestNCoalescing for details.
+		RequestNCoalescing(window time.Duration) ServerBuilder
+		// RequestMetrics set a receiver of rejected/cancelled request counters for every connection.
+		RequestMetrics(metrics RequestMetrics) ServerBuilder
+		// DispatchScheduler set the scheduler which dispatches responses of every connection, default is a new goroutine
+		// for every response. See ClientBuilder.DispatchScheduler for details, it is shared by all connections.
+		DispatchScheduler(sc scheduler.Scheduler) ServerBuilder
+		// ConnectionIDGenerator set the generator of IDs of connections, it is invoked when a SETUP is received.
+		// Default is a random UUID, see ConnectionIDFromContext.
+		ConnectionIDGenerator(gen func() string) ServerBuilder
+		// OnFrameDrop register handler of frames dropped by every connection, see ClientBuilder.OnFrameDrop for details.
+		OnFrameDrop(handler FrameDropHandler) ServerBuilder
+		// FragmentationMetrics set a receiver of fragmentation events for every connection.
+		FragmentationMetrics(metrics FragmentationMetrics) ServerBuilder
+		// MaxOutboundBufferBytes set the max bytes of outbound frames queued but not written yet for every connection.
+		// Once the limit is exceeded, responses and requests will block until queued frames are written.
+		// Default is zero which means unlimited.
+		MaxOutboundBufferBytes(n int) ServerBuilder
+		// MaxConnectionMemory set the max bytes held by every connection across all its streams: payloads being reassembled
+		// plus outbound frames queued but not written yet. Once a received fragment exceeds the budget, the request is rejected
+		// with ERROR[REJECTED], or the connection is closed if closeConn is true. It stops a single client from exhausting
+		// the memory of the server with many large fragmented payloads in flight. Default is zero which means unlimited.
+		MaxConnectionMemory(n int, closeConn bool) ServerBuilder
+		// MaxReassemblies set the max amount of payloads being reassembled from fragments at the same time for every
+		// connection. Once it is reached, a request starting another fragmented payload is rejected with ERROR[REJECTED].
+		// It stops a single client from exhausting the server with many concurrent fragmented streams, which are cheap
+		// to open but each one holds a buffer. Default is zero which means unlimited.
+		MaxReassemblies(n int) ServerBuilder
+		// MaxConcurrentSetups set the max amount of SETUP and RESUME handshakes processed at the same time, including the
+		// ServerAcceptor. Excess connections wait at most the duration for a free slot, then they are rejected with
+		// ERROR[REJECTED_SETUP] (or ERROR[REJECTED_RESUME]) and closed. It smooths the CPU load of connection storms,
+		// eg: all clients reconnect after a restart. Default is zero which means unlimited.
+		MaxConcurrentSetups(n int, wait time.Duration) ServerBuilder
+		// ChannelOutboundWindow set the max amount of outbound payloads in flight for every RequestChannel sent by the server.
+		// Default is zero which means unlimited, see ClientBuilder.ChannelOutboundWindow for details.
+		ChannelOutboundWindow(size int) ServerBuilder
+		// ControlFrameRetry set the max times a KEEPALIVE, CANCEL or REQUEST_N frame is written again after a temporary
+		// write error for every connection. Default is zero which means disabled, see ClientBuilder.ControlFrameRetry for details.
+		ControlFrameRetry(maxRetries int, backoff time.Duration) ServerBuilder
+		// Ordering set the order in which outbound frames of different streams are written for every connection.
+		// Default is StrictOrdering, see ClientBuilder.Ordering for details.
+		Ordering(ordering FrameOrdering) ServerBuilder
+		// StreamIDAllocator replace the default allocator of stream ids: 2,4,6...
+		// The generator is called once for every connection, it is mainly used by interop tests.
+		StreamIDAllocator(gen func() StreamIDAllocator) ServerBuilder
+		// Clock set the clock of time-based features for every connection, see ClientBuilder.Clock for details.
+		Clock(c clock.Clock) ServerBuilder
+		// Validate returns all configuration errors at once as ConfigErrors, it returns nil if the configuration is valid.
+		// Serve will validate the configuration before listening.
+		Validate() error
 	}
 
 	// ToServerStarter is used to build a RSocket server with custom Transport string.
@@ -57,6 +122,18 @@ type (
 	Start interface {
 		// Serve serve RSocket server.
 		Serve(ctx context.Context) error
+		// Drain switches the server into draining mode.
+		// New requests will be rejected with ErrorCodeRejected so clients can retry elsewhere,
+		// existing streams will finish as usual. Listener and connections are kept open.
+		Drain()
+		// Undrain switches the server back to accept new requests.
+		Undrain()
+		// IsDraining returns true if the server is in draining mode.
+		IsDraining() bool
+		// Sessions returns a snapshot of active connections ordered by the time they were connected,
+		// with the remote address, uptime, negotiated keepalive, stream count and bytes transferred.
+		// A resumable session is not active while its client is disconnected.
+		Sessions() []SessionInfo
 	}
 )
 
@@ -66,6 +143,7 @@ func Receive() ServerBuilder {
 		fragment: fragmentation.MaxFragment,
 		sm:       session.NewManager(),
 		done:     make(chan struct{}),
+		draining: atomic.NewBool(false),
 		resumeOpts: &serverResumeOptions{
 			sessionDuration: serverSessionDuration,
 		},
@@ -78,14 +156,37 @@ type serverResumeOptions struct {
 }
 
 type server struct {
-	tp         transport.ServerTransporter
-	resumeOpts *serverResumeOptions
-	fragment   int
-	acc        ServerAcceptor
-	sm         *session.Manager
-	done       chan struct{}
-	onServe    []func()
-	leases     lease.Factory
+	tp          transport.ServerTransporter
+	resumeOpts  *serverResumeOptions
+	fragment    int
+	acc         ServerAcceptor
+	sm          *session.Manager
+	done        chan struct{}
+	onServe     []func()
+	onAcceptErr transport.AcceptErrorHandler
+	leases      lease.Factory
+	draining    *atomic.Bool
+	listener    StreamListener
+	stallAfter  time.Duration
+	coalesceN   time.Duration
+	metrics     RequestMetrics
+	dispatcher  scheduler.Scheduler
+	connIDGen   func() string
+	fragMetrics FragmentationMetrics
+	maxOutbound int
+	maxMemory   int
+	memoryClose bool
+	maxJoiners  int
+	maxSetups   int
+	setupWait   time.Duration
+	ordering    FrameOrdering
+	channelWnd  int
+	ctrlRetries int
+	ctrlBackoff time.Duration
+	streamIDs   func() StreamIDAllocator
+	clock       clock.Clock
+	onDrop      FrameDropHandler
+	sessions    sessionRegistry
 }
 
 func (p *server) Lease(leases lease.Factory) ServerBuilder {
@@ -100,6 +201,99 @@ func (p *server) OnStart(onStart func()) ServerBuilder {
 	return p
 }
 
+func (p *server) OnAcceptError(handler func(err error) (continued bool)) ServerBuilder {
+	p.onAcceptErr = handler
+	return p
+}
+
+func (p *server) StreamStallThreshold(threshold time.Duration) ServerBuilder {
+	p.stallAfter = threshold
+	return p
+}
+
+func (p *server) RequestNCoalescing(window time.Duration) ServerBuilder {
+	p.coalesceN = window
+	return p
+}
+
+func (p *server) StreamListener(listener StreamListener) ServerBuilder {
+	p.listener = listener
+	return p
+}
+
+func (p *server) DispatchScheduler(sc scheduler.Scheduler) ServerBuilder {
+	p.dispatcher = sc
+	return p
+}
+
+func (p *server) ConnectionIDGenerator(gen func() string) ServerBuilder {
+	p.connIDGen = gen
+	return p
+}
+
+func (p *server) RequestMetrics(metrics RequestMetrics) ServerBuilder {
+	p.metrics = metrics
+	return p
+}
+
+func (p *server) OnFrameDrop(handler FrameDropHandler) ServerBuilder {
+	p.onDrop = handler
+	return p
+}
+
+func (p *server) FragmentationMetrics(metrics FragmentationMetrics) ServerBuilder {
+	p.fragMetrics = metrics
+	return p
+}
+
+func (p *server) MaxOutboundBufferBytes(n int) ServerBuilder {
+	p.maxOutbound = n
+	return p
+}
+
+func (p *server) MaxConnectionMemory(n int, closeConn bool) ServerBuilder {
+	p.maxMemory = n
+	p.memoryClose = closeConn
+	return p
+}
+
+func (p *server) MaxReassemblies(n int) ServerBuilder {
+	p.maxJoiners = n
+	return p
+}
+
+func (p *server) MaxConcurrentSetups(n int, wait time.Duration) ServerBuilder {
+	p.maxSetups = n
+	p.setupWait = wait
+	return p
+}
+
+func (p *server) ChannelOutboundWindow(size int) ServerBuilder {
+	p.channelWnd = size
+	return p
+}
+
+func (p *server) ControlFrameRetry(maxRetries int, backoff time.Duration) ServerBuilder {
+	p.ctrlRetries = maxRetries
+	p.ctrlBackoff = backoff
+	return p
+}
+
+func (p *server) StreamIDAllocator(gen func() StreamIDAllocator) ServerBuilder {
+	p.streamIDs = gen
+	return p
+}
+
+func (p *server) Clock(c clock.Clock) ServerBuilder {
+	p.clock = c
+	return p
+}
+
+func (p *server) Ordering(ordering FrameOrdering) ServerBuilder {
+	p.ordering = ordering
+	return p
+}
+
 func (p *server) Resume(opts ...OpServerResume) ServerBuilder {
 	p.resumeOpts.enable = true
 	for _, it := range opts {
@@ -127,8 +321,40 @@ func (p *server) Transport(t transport.ServerTransporter) Start {
 	return p
 }
 
+func (p *server) Drain() {
+	p.draining.Store(true)
+}
+
+func (p *server) Undrain() {
+	p.draining.Store(false)
+}
+
+func (p *server) IsDraining() bool {
+	return p.draining.Load()
+}
+
+func (p *server) Validate() error {
+	v := &configValidator{}
+	v.add(fragmentation.IsValidFragment(p.fragment))
+	v.check(p.maxOutbound >= 0, "max outbound buffer bytes cannot be negative: %d", p.maxOutbound)
+	v.check(p.maxMemory >= 0, "max connection memory cannot be negative: %d", p.maxMemory)
+	v.check(p.maxJoiners >= 0, "max reassemblies cannot be negative: %d", p.maxJoiners)
+	v.check(p.maxSetups >= 0, "max concurrent setups cannot be negative: %d", p.maxSetups)
+	v.check(p.setupWait >= 0, "setup wait cannot be negative: %s", p.setupWait)
+	v.check(p.channelWnd >= 0, "channel outbound window cannot be negative: %d", p.channelWnd)
+	v.check(p.ctrlRetries >= 0, "control frame retries cannot be negative: %d", p.ctrlRetries)
+	v.check(p.ctrlBackoff >= 0, "control frame retry backoff cannot be negative: %s", p.ctrlBackoff)
+	v.check(p.stallAfter >= 0, "stream stall threshold cannot be negative: %s", p.stallAfter)
+	v.check(p.coalesceN >= 0, "request n coalescing window cannot be negative: %s", p.coalesceN)
+	v.check(p.ordering >= StrictOrdering && p.ordering <= FairOrdering, "invalid frame ordering: %d", p.ordering)
+	if p.resumeOpts.enable {
+		v.check(p.resumeOpts.sessionDuration > 0, "resume session duration must be positive: %s", p.resumeOpts.sessionDuration)
+	}
+	return v.result()
+}
+
 func (p *server) Serve(ctx context.Context) error {
-	err := fragmentation.IsValidFragment(p.fragment)
+	err := p.Validate()
 	if err != nil {
 		return err
 	}
@@ -142,11 +368,17 @@ func (p *server) Serve(ctx context.Context) error {
 		_ = t.Close()
 	}()
 
+	if n, ok := t.(transport.AcceptErrorNotifier); ok && p.onAcceptErr != nil {
+		n.OnAcceptError(p.onAcceptErr)
+	}
+
 	go func(ctx context.Context) {
 		_ = p.loopCleanSession(ctx)
 	}(ctx)
+	setups := newSetupLimiter(p.maxSetups, p.setupWait, p.clock)
 	t.Accept(func(ctx context.Context, tp *transport.Transport, onClose func(*transport.Transport)) {
 		defer onClose(tp)
+		defer p.sessions.remove(tp)
 		socketChan := make(chan socket.ServerSocket, 1)
 		defer func() {
 			select {
@@ -180,25 +412,13 @@ func (p *server) Serve(ctx context.Context) error {
 
 		defer first.Release()
 
-		switch frame := first.(type) {
-		case *framing.ResumeFrame:
-			p.doResume(frame, tp, socketChan)
-		case *framing.SetupFrame:
-			sendingSocket, err := p.doSetup(frame, tp, socketChan)
-			if err != nil {
-				_ = tp.Send(err, true)
-				_ = tp.Close()
-				return
-			}
-			go func(ctx context.Context, sendingSocket socket.ServerSocket) {
-				if err := sendingSocket.Start(ctx); err != nil && logger.IsDebugEnabled() {
-					logger.Debugf("sending socket exit: %w\n", err)
-				}
-			}(ctx, sendingSocket)
-		default:
-			err := framing.NewWriteableErrorFrame(0, core.ErrorCodeConnectionError, bytesconv.StringToBytes(_errInvalidFirstFrame))
-			_ = tp.Send(err, true)
-			_ = tp.Close()
+		if !setups.acquire(ctx) {
+			p.rejectBusy(first, tp)
+			return
+		}
+		ok := p.handshake(ctx, first, tp, socketChan)
+		setups.release()
+		if !ok {
 			return
 		}
 		if err := tp.Start(ctx); err != nil {
@@ -208,7 +428,9 @@ func (p *server) Serve(ctx context.Context) error {
 
 	notifier := make(chan bool)
 	go func(c <-chan bool, fn []func()) {
-		<-c
+		if ok := <-c; !ok {
+			return
+		}
 		for i := range fn {
 			fn[i]()
 		}
@@ -216,6 +438,43 @@ func (p *server) Serve(ctx context.Context) error {
 	return t.Listen(ctx, notifier)
 }
 
+// handshake handles the first frame of a connection, it returns false if the transport has been closed.
+func (p *server) handshake(ctx context.Context, first core.BufferedFrame, tp *transport.Transport, socketChan chan<- socket.ServerSocket) bool {
+	switch frame := first.(type) {
+	case *framing.ResumeFrame:
+		return p.doResume(frame, tp, socketChan)
+	case *framing.SetupFrame:
+		sendingSocket, err := p.doSetup(frame, tp, socketChan)
+		if err != nil {
+			_ = tp.Send(err, true)
+			_ = tp.Close()
+			return false
+		}
+		go func(ctx context.Context, sendingSocket socket.ServerSocket) {
+			if err := sendingSocket.Start(ctx); err != nil && logger.IsDebugEnabled() {
+				logger.Debugf("sending socket exit: %w\n", err)
+			}
+		}(ctx, sendingSocket)
+		return true
+	default:
+		err := framing.NewWriteableErrorFrame(0, core.ErrorCodeConnectionError, bytesconv.StringToBytes(_errInvalidFirstFrame))
+		_ = tp.Send(err, true)
+		_ = tp.Close()
+		return false
+	}
+}
+
+// rejectBusy rejects the connection whose handshake cannot be processed because too many handshakes are in progress.
+func (p *server) rejectBusy(first core.BufferedFrame, tp *transport.Transport) {
+	logger.Warnf("reject connection: %s\n", _errTooManySetups)
+	code := core.ErrorCodeRejectedSetup
+	if first.Header().Type() == core.FrameTypeResume {
+		code = core.ErrorCodeRejectedResume
+	}
+	_ = tp.Send(framing.NewWriteableErrorFrame(0, code, bytesconv.StringToBytes(_errTooManySetups)), true)
+	_ = tp.Close()
+}
+
 func (p *server) doSetup(frame *framing.SetupFrame, tp *transport.Transport, socketChan chan<- socket.ServerSocket) (sendingSocket socket.ServerSocket, err *framing.WriteableErrorFrame) {
 	if frame.HasFlag(core.FlagLease) && p.leases == nil {
 		err = framing.NewWriteableErrorFrame(0, core.ErrorCodeUnsupportedSetup, bytesconv.StringToBytes(_errUnavailableLease))
@@ -231,16 +490,38 @@ func (p *server) doSetup(frame *framing.SetupFrame, tp *transport.Transport, soc
 	}
 
 	rawSocket := socket.NewServerDuplexConnection(p.fragment, p.leases)
+	rawSocket.SetConnectionID(newConnectionID(p.connIDGen))
+	rawSocket.SetClock(p.clock)
+	rawSocket.SetDraining(p.draining.Load)
+	rawSocket.SetStreamListener(p.listener)
+	rawSocket.SetStreamStallThreshold(p.stallAfter)
+	rawSocket.SetRequestNCoalescing(p.coalesceN)
+	rawSocket.SetRequestMetrics(p.metrics)
+	rawSocket.SetDispatchScheduler(p.dispatcher)
+	rawSocket.SetFrameDropHandler(p.onDrop)
+	rawSocket.SetFragmentationMetrics(p.fragMetrics)
+	rawSocket.SetMaxOutboundBufferBytes(p.maxOutbound)
+	rawSocket.SetMaxConnectionMemory(p.maxMemory, p.memoryClose)
+	rawSocket.SetMaxReassemblies(p.maxJoiners)
+	rawSocket.SetFrameOrdering(p.ordering)
+	rawSocket.SetChannelOutboundWindow(p.channelWnd)
+	rawSocket.SetControlFrameRetry(p.ctrlRetries, p.ctrlBackoff)
+	if p.streamIDs != nil {
+		rawSocket.SetStreamIDs(p.streamIDs())
+	}
+	rawSocket.SetKeepaliveSettings(frame.TimeBetweenKeepalive(), frame.MaxLifetime())
+	setup := newPeerSetupPayload(frame, tp.PeerCertificates())
+	rawSocket.SetSetupPayload(newPeerSetupPayload(detachSetupPayload(frame), tp.PeerCertificates()))
 
 	// 2. no resume
 	if !isResume {
 		sendingSocket = socket.NewSimpleServerSocket(rawSocket)
-		if responder, e := p.acc(frame, sendingSocket); e != nil {
+		if responder, e := p.acc(setup, sendingSocket); e != nil {
 			err = framing.NewWriteableErrorFrame(0, core.ErrorCodeRejectedSetup, []byte(e.Error()))
 		} else {
 			sendingSocket.SetResponder(responder)
 			sendingSocket.SetTransport(tp)
-			socketChan <- sendingSocket
+			p.accepted(tp, sendingSocket, socketChan)
 		}
 		return
 	}
@@ -258,7 +539,7 @@ func (p *server) doSetup(frame *framing.SetupFrame, tp *transport.Transport, soc
 
 	// 4. resume success
 	sendingSocket = socket.NewResumableServerSocket(rawSocket, token)
-	if responder, e := p.acc(frame, sendingSocket); e != nil {
+	if responder, e := p.acc(setup, sendingSocket); e != nil {
 		switch vv := e.(type) {
 		case *framing.ErrorFrame:
 			err = framing.NewWriteableErrorFrame(0, vv.ErrorCode(), vv.ErrorData())
@@ -268,21 +549,27 @@ func (p *server) doSetup(frame *framing.SetupFrame, tp *transport.Transport, soc
 	} else {
 		sendingSocket.SetResponder(responder)
 		sendingSocket.SetTransport(tp)
-		socketChan <- sendingSocket
+		p.accepted(tp, sendingSocket, socketChan)
 	}
 	return
 }
 
-func (p *server) doResume(frame *framing.ResumeFrame, tp *transport.Transport, socketChan chan<- socket.ServerSocket) {
+// doResume resumes the session of the token, otherwise it responds REJECTED_RESUME and closes the transport,
+// then the client may set up a new session.
+func (p *server) doResume(frame *framing.ResumeFrame, tp *transport.Transport, socketChan chan<- socket.ServerSocket) (ok bool) {
 	var sending core.WriteableFrame
 	if !p.resumeOpts.enable {
 		sending = framing.NewWriteableErrorFrame(0, core.ErrorCodeRejectedResume, bytesconv.StringToBytes(_errUnavailableResume))
-	} else if s, ok := p.sm.Load(frame.Token()); ok {
-		sending = framing.NewWriteableResumeOKFrame(0)
-		s.Socket().SetTransport(tp)
-		socketChan <- s.Socket()
-		if logger.IsDebugEnabled() {
-			logger.Debugf("recover session: %s\n", s)
+	} else if s, found := p.sm.Load(frame.Token()); found {
+		if err := s.Socket().Resume(frame, tp); err != nil {
+			sending = framing.NewWriteableErrorFrame(0, core.ErrorCodeRejectedResume, []byte(err.Error()))
+		} else {
+			p.accepted(tp, s.Socket(), socketChan)
+			if logger.IsDebugEnabled() {
+				logger.Debugf("recover session: %s\n", s)
+			}
+			ok = true
+			return
 		}
 	} else {
 		sending = framing.NewWriteableErrorFrame(
@@ -293,8 +580,9 @@ func (p *server) doResume(frame *framing.ResumeFrame, tp *transport.Transport, s
 	}
 	if err := tp.Send(sending, true); err != nil {
 		logger.Errorf("send resume response failed: %s\n", err)
-		_ = tp.Close()
 	}
+	_ = tp.Close()
+	return
 }
 
 func (p *server) loopCleanSession(ctx context.Context) (err error) {
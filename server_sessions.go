@@ -0,0 +1,103 @@
+package rsocket
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core/clock"
+	"github.com/rsocket/rsocket-go/core/transport"
+	"github.com/rsocket/rsocket-go/internal/socket"
+)
+
+// SessionInfo is a snapshot of an active connection of a server, eg: for a debug dashboard.
+type SessionInfo struct {
+	// RemoteAddr is the address of the client, it is nil if the transport doesn't know it.
+	RemoteAddr net.Addr
+	// ConnectedAt is the time when the SETUP (or RESUME) frame was accepted.
+	ConnectedAt time.Time
+	// Uptime is the time since ConnectedAt.
+	Uptime time.Duration
+	// Keepalive is the keepalive settings negotiated by the SETUP frame.
+	Keepalive KeepaliveSettings
+	// ActiveStreams is the amount of streams in progress, both requested and responded by the server.
+	ActiveStreams int
+	// OutboundQueueDepth is the amount of frames queued to the client but not written yet, eg: the client reads slowly.
+	OutboundQueueDepth int
+	// Reassemblies is the amount of payloads sent by the client which are being reassembled from fragments.
+	Reassemblies int
+	// ConnectionID is the ID generated when the session is set up, it is kept if the session is resumed.
+	ConnectionID string
+	// Resumable is true if the session can be resumed by the client.
+	Resumable bool
+	// BytesRead is the bytes of frames sent by the client over the current connection.
+	BytesRead uint64
+	// BytesWritten is the bytes of frames sent by the server over the current connection.
+	BytesWritten uint64
+}
+
+type activeSession struct {
+	socket      socket.ServerSocket
+	connectedAt time.Time
+}
+
+// sessionRegistry keeps the connections whose handshake succeeded, until their transports are closed.
+type sessionRegistry struct {
+	mu       sync.Mutex
+	sessions map[*transport.Transport]activeSession
+}
+
+func (r *sessionRegistry) add(tp *transport.Transport, sk socket.ServerSocket, now time.Time) {
+	r.mu.Lock()
+	if r.sessions == nil {
+		r.sessions = make(map[*transport.Transport]activeSession)
+	}
+	r.sessions[tp] = activeSession{
+		socket:      sk,
+		connectedAt: now,
+	}
+	r.mu.Unlock()
+}
+
+func (r *sessionRegistry) remove(tp *transport.Transport) {
+	r.mu.Lock()
+	delete(r.sessions, tp)
+	r.mu.Unlock()
+}
+
+func (r *sessionRegistry) snapshot(now time.Time) []SessionInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	infos := make([]SessionInfo, 0, len(r.sessions))
+	for tp, s := range r.sessions {
+		_, resumable := s.socket.Token()
+		infos = append(infos, SessionInfo{
+			RemoteAddr:         tp.RemoteAddr(),
+			ConnectedAt:        s.connectedAt,
+			Uptime:             now.Sub(s.connectedAt),
+			Keepalive:          s.socket.KeepaliveSettings(),
+			ActiveStreams:      s.socket.ActiveStreams(),
+			OutboundQueueDepth: s.socket.OutboundQueueDepth(),
+			Reassemblies:       s.socket.Reassemblies(),
+			ConnectionID:       s.socket.ConnectionID(),
+			Resumable:          resumable,
+			BytesRead:          tp.BytesRead(),
+			BytesWritten:       tp.BytesWritten(),
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ConnectedAt.Before(infos[j].ConnectedAt)
+	})
+	return infos
+}
+
+func (p *server) Sessions() []SessionInfo {
+	return p.sessions.snapshot(clock.OrReal(p.clock).Now())
+}
+
+// accepted hands over the socket of a connection whose handshake succeeded, and registers it as an active session.
+func (p *server) accepted(tp *transport.Transport, sk socket.ServerSocket, socketChan chan<- socket.ServerSocket) {
+	p.sessions.add(tp, sk, clock.OrReal(p.clock).Now())
+	socketChan <- sk
+}
@@ -0,0 +1,79 @@
+package rsocket
+
+import (
+	"context"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core"
+	"github.com/rsocket/rsocket-go/internal/common"
+	"github.com/rsocket/rsocket-go/internal/socket"
+	"github.com/rsocket/rsocket-go/payload"
+)
+
+// detachedSetupPayload is a copy of the SETUP payload, it stays valid after the SETUP frame is released.
+type detachedSetupPayload struct {
+	payload.Payload
+	dataMimeType     string
+	metadataMimeType string
+	keepalive        time.Duration
+	lifetime         time.Duration
+	version          core.Version
+	lease            bool
+	resume           bool
+	token            []byte
+}
+
+func detachSetupPayload(setup payload.SetupPayload) payload.SetupPayload {
+	return detachedSetupPayload{
+		Payload:          payload.Clone(setup),
+		dataMimeType:     setup.DataMimeType(),
+		metadataMimeType: setup.MetadataMimeType(),
+		keepalive:        setup.TimeBetweenKeepalive(),
+		lifetime:         setup.MaxLifetime(),
+		version:          setup.Version(),
+		lease:            setup.LeaseRequested(),
+		resume:           setup.WillResume(),
+		token:            common.CloneBytes(setup.Token()),
+	}
+}
+
+func (d detachedSetupPayload) DataMimeType() string {
+	return d.dataMimeType
+}
+
+func (d detachedSetupPayload) MetadataMimeType() string {
+	return d.metadataMimeType
+}
+
+func (d detachedSetupPayload) TimeBetweenKeepalive() time.Duration {
+	return d.keepalive
+}
+
+func (d detachedSetupPayload) MaxLifetime() time.Duration {
+	return d.lifetime
+}
+
+func (d detachedSetupPayload) Version() core.Version {
+	return d.version
+}
+
+func (d detachedSetupPayload) LeaseRequested() bool {
+	return d.lease
+}
+
+func (d detachedSetupPayload) WillResume() bool {
+	return d.resume
+}
+
+func (d detachedSetupPayload) Token() []byte {
+	return d.token
+}
+
+// SetupFromContext returns the SETUP payload of the connection which current request is received from, so the
+// identity sent in SETUP is accessible in any handler, even if it is registered after the ServerAcceptor has run.
+// The context is the one which the responding Mono or Flux is subscribed with, see StreamIDFromContext.
+// The payload is a copy which can be retained for the lifetime of the connection, PeerCertificates works on it too.
+// It is only available on the server side.
+func SetupFromContext(ctx context.Context) (payload.SetupPayload, bool) {
+	return socket.SetupFromContext(ctx)
+}
@@ -0,0 +1,57 @@
+package rsocket
+
+import (
+	"context"
+	"time"
+
+	"github.com/rsocket/rsocket-go/core/clock"
+)
+
+// setupLimiter bounds the amount of SETUP and RESUME handshakes processed at the same time, methods are safe for the nil value.
+type setupLimiter struct {
+	slots chan struct{}
+	wait  time.Duration
+	clock clock.Clock
+}
+
+func newSetupLimiter(max int, wait time.Duration, c clock.Clock) *setupLimiter {
+	if max < 1 {
+		return nil
+	}
+	return &setupLimiter{
+		slots: make(chan struct{}, max),
+		wait:  wait,
+		clock: clock.OrReal(c),
+	}
+}
+
+// acquire waits for a free slot at most the wait duration, it returns false if there is no slot.
+func (l *setupLimiter) acquire(ctx context.Context) bool {
+	if l == nil {
+		return true
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+	}
+	if l.wait <= 0 {
+		return false
+	}
+	timer := l.clock.NewTimer(l.wait)
+	defer timer.Stop()
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	case <-timer.C():
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (l *setupLimiter) release() {
+	if l != nil {
+		<-l.slots
+	}
+}
@@ -3,14 +3,19 @@ package rsocket
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
 	"github.com/rsocket/rsocket-go/core/transport"
 )
 
+var errClientCertWithoutTLS = errors.New("client certificates require a tls config")
+
 const (
 	// DefaultUnixSockPath is the default UDS sock file path.
 	DefaultUnixSockPath = "/var/run/rsocket.sock"
@@ -20,29 +25,45 @@ const (
 
 // TCPClientBuilder provides builder which can be used to create a client-side TCP transport easily.
 type TCPClientBuilder struct {
-	addr   string
-	tlsCfg *tls.Config
+	addr    string
+	tlsCfg  *tls.Config
+	opts    []transport.TCPConnOption
+	codec   transport.FrameCodec
+	hexdump bool
 }
 
 // TCPServerBuilder provides builder which can be used to create a server-side TCP transport easily.
 type TCPServerBuilder struct {
-	addr   string
-	tlsCfg *tls.Config
+	network     string
+	addr        string
+	tlsCfg      *tls.Config
+	clientAuth  bool
+	clientCAs   *x509.CertPool
+	opts        []transport.TCPConnOption
+	codec       transport.FrameCodec
+	singleStack bool
+	backlog     int
+	maxConns    int
+	hexdump     bool
 }
 
 // WebsocketClientBuilder provides builder which can be used to create a client-side Websocket transport easily.
 type WebsocketClientBuilder struct {
-	url    string
-	tlsCfg *tls.Config
-	header http.Header
+	url     string
+	tlsCfg  *tls.Config
+	header  http.Header
+	hexdump bool
 }
 
 // WebsocketServerBuilder provides builder which can be used to create a server-side Websocket transport easily.
 type WebsocketServerBuilder struct {
-	addr      string
-	path      string
-	tlsConfig *tls.Config
-	upgrader  *websocket.Upgrader
+	addr       string
+	path       string
+	tlsConfig  *tls.Config
+	clientAuth bool
+	clientCAs  *x509.CertPool
+	upgrader   *websocket.Upgrader
+	hexdump    bool
 }
 
 // UnixClientBuilder provides builder which can be used to create a client-side UDS transport easily.
@@ -125,26 +146,51 @@ func (ws *WebsocketServerBuilder) SetTLSConfig(c *tls.Config) *WebsocketServerBu
 	return ws
 }
 
+// RequireClientCert enables mutual TLS, clients must present a certificate signed by one of the CAs.
+// The system root CAs will be used if clientCAs is nil. It requires a tls config set by SetTLSConfig.
+// The verified certificate chain is available to the acceptor by PeerCertificates.
+func (ws *WebsocketServerBuilder) RequireClientCert(clientCAs *x509.CertPool) *WebsocketServerBuilder {
+	ws.clientAuth = true
+	ws.clientCAs = clientCAs
+	return ws
+}
+
 // SetUpgrader sets websocket upgrader.
 // You can customize your own websocket upgrader instead of the default upgrader.
 //
 // Example(also the default value):
-// upgrader := &websocket.Upgrader{
-//		ReadBufferSize:  1024,
-//		WriteBufferSize: 1024,
-//		CheckOrigin: func(r *http.Request) bool {
-//			return true
-//		},
-// }
+//
+//	upgrader := &websocket.Upgrader{
+//			ReadBufferSize:  1024,
+//			WriteBufferSize: 1024,
+//			CheckOrigin: func(r *http.Request) bool {
+//				return true
+//			},
+//	}
 func (ws *WebsocketServerBuilder) SetUpgrader(upgrader *websocket.Upgrader) *WebsocketServerBuilder {
 	ws.upgrader = upgrader
 	return ws
 }
 
+// SetHexdump enables or disables dumping raw bytes of every frame of accepted connections in hex, like `xxd`.
+// Dumps are printed by logger.Tracef only when the trace level of logger is enabled, see transport.NewHexdumpConn.
+func (ws *WebsocketServerBuilder) SetHexdump(enabled bool) *WebsocketServerBuilder {
+	ws.hexdump = enabled
+	return ws
+}
+
 // Build builds and returns a new websocket ServerTransporter.
 func (ws *WebsocketServerBuilder) Build() transport.ServerTransporter {
 	return func(ctx context.Context) (transport.ServerTransport, error) {
-		return transport.NewWebsocketServerTransportWithAddr(ws.addr, ws.path, ws.upgrader, ws.tlsConfig), nil
+		tlsConfig, err := serverTLSConfig(ws.tlsConfig, ws.clientAuth, ws.clientCAs)
+		if err != nil {
+			return nil, err
+		}
+		t := transport.NewWebsocketServerTransportWithAddr(ws.addr, ws.path, ws.upgrader, tlsConfig)
+		if h, ok := t.(transport.Hexdumper); ok && ws.hexdump {
+			h.EnableHexdump()
+		}
+		return t, nil
 	}
 }
 
@@ -152,9 +198,9 @@ func (ws *WebsocketServerBuilder) Build() transport.ServerTransporter {
 //
 // Here's an example:
 //
-// tc := &tls.Config{
-//	InsecureSkipVerify: true,
-// }
+//	tc := &tls.Config{
+//		InsecureSkipVerify: true,
+//	}
 func (wc *WebsocketClientBuilder) SetTLSConfig(c *tls.Config) *WebsocketClientBuilder {
 	wc.tlsCfg = c
 	return wc
@@ -173,10 +219,24 @@ func (wc *WebsocketClientBuilder) SetHeader(header http.Header) *WebsocketClient
 	return wc
 }
 
+// SetHexdump enables or disables dumping raw bytes of every frame of the connection in hex, like `xxd`.
+// Dumps are printed by logger.Tracef only when the trace level of logger is enabled, see transport.NewHexdumpConn.
+func (wc *WebsocketClientBuilder) SetHexdump(enabled bool) *WebsocketClientBuilder {
+	wc.hexdump = enabled
+	return wc
+}
+
 // Build builds and returns a new websocket ClientTransporter
 func (wc *WebsocketClientBuilder) Build() transport.ClientTransporter {
 	return func(ctx context.Context) (*transport.Transport, error) {
-		return transport.NewWebsocketClientTransport(ctx, wc.url, wc.tlsCfg, wc.header)
+		tp, err := transport.NewWebsocketClientTransport(ctx, wc.url, wc.tlsCfg, wc.header)
+		if err != nil {
+			return nil, err
+		}
+		if wc.hexdump {
+			tp.EnableHexdump()
+		}
+		return tp, nil
 	}
 }
 
@@ -192,6 +252,21 @@ func (ts *TCPServerBuilder) SetAddr(addr string) *TCPServerBuilder {
 	return ts
 }
 
+// SetNetwork forces the IP family of the listener, it must be "tcp", "tcp4" or "tcp6". Default is "tcp".
+// Bind to a specific interface by setting its IP in the addr, eg: "10.0.0.1:7878".
+// An invalid network or addr will fail the Serve, and OnStart will not be invoked.
+func (ts *TCPServerBuilder) SetNetwork(network string) *TCPServerBuilder {
+	ts.network = network
+	return ts
+}
+
+// SetDualStack controls whether a "tcp" listener on a wildcard address accepts both IPv4 and IPv6, default is true.
+// If disabled, ":7878" and "0.0.0.0:7878" only listen on IPv4, and "[::]:7878" only listens on IPv6.
+func (ts *TCPServerBuilder) SetDualStack(enabled bool) *TCPServerBuilder {
+	ts.singleStack = !enabled
+	return ts
+}
+
 // SetTLSConfig sets the tls config.
 //
 // You can generate cert.pem and key.pem for local testing:
@@ -221,10 +296,81 @@ func (ts *TCPServerBuilder) SetTLSConfig(c *tls.Config) *TCPServerBuilder {
 	return ts
 }
 
+// RequireClientCert enables mutual TLS, clients must present a certificate signed by one of the CAs.
+// The system root CAs will be used if clientCAs is nil. It requires a tls config set by SetTLSConfig.
+// The verified certificate chain is available to the acceptor by PeerCertificates.
+func (ts *TCPServerBuilder) RequireClientCert(clientCAs *x509.CertPool) *TCPServerBuilder {
+	ts.clientAuth = true
+	ts.clientCAs = clientCAs
+	return ts
+}
+
+// SetKeepAlive enables or disables SO_KEEPALIVE on accepted sockets, a positive period sets the probe interval.
+//
+// Note that this is the OS-level TCP keepalive, which is independent of the RSocket KEEPALIVE frames
+// configured by the client setup. It only detects broken TCP connections and never reaches the RSocket layer.
+func (ts *TCPServerBuilder) SetKeepAlive(enabled bool, period time.Duration) *TCPServerBuilder {
+	ts.opts = append(ts.opts, transport.WithTCPKeepAlive(enabled, period))
+	return ts
+}
+
+// SetNoDelay controls TCP_NODELAY on accepted sockets, default is true.
+// Set false to enable the Nagle algorithm, which may suit bulk streaming better than latency-sensitive requests.
+func (ts *TCPServerBuilder) SetNoDelay(noDelay bool) *TCPServerBuilder {
+	ts.opts = append(ts.opts, transport.WithTCPNoDelay(noDelay))
+	return ts
+}
+
+// SetFrameCodec replaces the codec which delimits frames on accepted connections, default is transport.DefaultFrameCodec.
+// It is designed for protocol experiments, peers must use the same codec.
+func (ts *TCPServerBuilder) SetFrameCodec(codec transport.FrameCodec) *TCPServerBuilder {
+	ts.codec = codec
+	return ts
+}
+
+// SetListenBacklog sets the max number of connections completed by the kernel but not accepted yet, default is the
+// system max. It helps servers which accept bursts of connections, eg: thousands of clients starting at once.
+// The kernel caps the backlog, eg: by net.core.somaxconn on Linux. It is ignored on non-unix platforms.
+func (ts *TCPServerBuilder) SetListenBacklog(backlog int) *TCPServerBuilder {
+	ts.backlog = backlog
+	return ts
+}
+
+// SetMaxConcurrentConns sets the max number of connections served at the same time, default is unlimited.
+// A connection holds a slot from being accepted to being closed, new connections wait in the listen backlog
+// once all slots are taken, so it bounds the goroutines and memory of the server.
+func (ts *TCPServerBuilder) SetMaxConcurrentConns(n int) *TCPServerBuilder {
+	ts.maxConns = n
+	return ts
+}
+
+// SetHexdump enables or disables dumping raw bytes of every frame of accepted connections in hex, like `xxd`.
+// Dumps are printed by logger.Tracef only when the trace level of logger is enabled, see transport.NewHexdumpConn.
+func (ts *TCPServerBuilder) SetHexdump(enabled bool) *TCPServerBuilder {
+	ts.hexdump = enabled
+	return ts
+}
+
 // Build builds and returns a new TCP ServerTransporter.
 func (ts *TCPServerBuilder) Build() transport.ServerTransporter {
 	return func(ctx context.Context) (transport.ServerTransport, error) {
-		return transport.NewTCPServerTransportWithAddr("tcp", ts.addr, ts.tlsCfg), nil
+		network := ts.network
+		if ts.singleStack {
+			network = transport.SingleStackNetwork(network, ts.addr)
+		}
+		tlsCfg, err := serverTLSConfig(ts.tlsCfg, ts.clientAuth, ts.clientCAs)
+		if err != nil {
+			return nil, err
+		}
+		f := transport.NewTCPListenerFactoryWithBacklog(network, ts.addr, tlsCfg, ts.backlog, ts.opts...)
+		t := transport.NewTCPServerTransportWithCodec(f, ts.codec)
+		if l, ok := t.(transport.ConnLimiter); ok {
+			l.SetMaxConcurrentConns(ts.maxConns)
+		}
+		if h, ok := t.(transport.Hexdumper); ok && ts.hexdump {
+			h.EnableHexdump()
+		}
+		return t, nil
 	}
 }
 
@@ -244,18 +390,57 @@ func (tc *TCPClientBuilder) SetAddr(addr string) *TCPClientBuilder {
 //
 // Here's an example:
 //
-// tc := &tls.Config{
-//	InsecureSkipVerify: true,
-// }
+//	tc := &tls.Config{
+//		InsecureSkipVerify: true,
+//	}
 func (tc *TCPClientBuilder) SetTLSConfig(c *tls.Config) *TCPClientBuilder {
 	tc.tlsCfg = c
 	return tc
 }
 
+// SetKeepAlive enables or disables SO_KEEPALIVE on the dialed socket, a positive period sets the probe interval.
+//
+// Note that this is the OS-level TCP keepalive, which is independent of the RSocket KEEPALIVE frames
+// configured by ClientBuilder.KeepAlive. It only detects broken TCP connections and never reaches the RSocket layer.
+func (tc *TCPClientBuilder) SetKeepAlive(enabled bool, period time.Duration) *TCPClientBuilder {
+	tc.opts = append(tc.opts, transport.WithTCPKeepAlive(enabled, period))
+	return tc
+}
+
+// SetNoDelay controls TCP_NODELAY on the dialed socket, default is true.
+// Set false to enable the Nagle algorithm, which may suit bulk streaming better than latency-sensitive requests.
+func (tc *TCPClientBuilder) SetNoDelay(noDelay bool) *TCPClientBuilder {
+	tc.opts = append(tc.opts, transport.WithTCPNoDelay(noDelay))
+	return tc
+}
+
+// SetFrameCodec replaces the codec which delimits frames on the dialed connection, default is transport.DefaultFrameCodec.
+// It is designed for protocol experiments, peers must use the same codec.
+func (tc *TCPClientBuilder) SetFrameCodec(codec transport.FrameCodec) *TCPClientBuilder {
+	tc.codec = codec
+	return tc
+}
+
+// SetHexdump enables or disables dumping raw bytes of every frame of the dialed connection in hex, like `xxd`.
+// Dumps are printed by logger.Tracef only when the trace level of logger is enabled, see transport.NewHexdumpConn.
+func (tc *TCPClientBuilder) SetHexdump(enabled bool) *TCPClientBuilder {
+	tc.hexdump = enabled
+	return tc
+}
+
 // Build builds and returns a new TCP ClientTransporter.
 func (tc *TCPClientBuilder) Build() transport.ClientTransporter {
 	return func(ctx context.Context) (*transport.Transport, error) {
-		return transport.NewTCPClientTransportWithAddr(ctx, "tcp", tc.addr, tc.tlsCfg)
+		conn, timing, err := transport.DialTCPWithTiming(ctx, "tcp", tc.addr, tc.tlsCfg, tc.opts...)
+		if err != nil {
+			return nil, err
+		}
+		tp := transport.NewTCPClientTransportWithCodec(conn, tc.codec)
+		tp.SetConnectTiming(timing)
+		if tc.hexdump {
+			tp.EnableHexdump()
+		}
+		return tp, nil
 	}
 }
 
@@ -269,7 +454,8 @@ func TCPClient() *TCPClientBuilder {
 // TCPServer creates a new TCPServerBuilder
 func TCPServer() *TCPServerBuilder {
 	return &TCPServerBuilder{
-		addr: fmt.Sprintf(":%d", DefaultPort),
+		network: "tcp",
+		addr:    fmt.Sprintf(":%d", DefaultPort),
 	}
 }
 
@@ -301,3 +487,13 @@ func UnixServer() *UnixServerBuilder {
 		path: DefaultUnixSockPath,
 	}
 }
+
+func serverTLSConfig(config *tls.Config, clientAuth bool, clientCAs *x509.CertPool) (*tls.Config, error) {
+	if !clientAuth {
+		return config, nil
+	}
+	if config == nil {
+		return nil, errClientCertWithoutTLS
+	}
+	return transport.RequireClientCert(config, clientCAs), nil
+}
@@ -7,11 +7,17 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/rsocket/rsocket-go"
+	"github.com/rsocket/rsocket-go/logger"
+	"github.com/rsocket/rsocket-go/payload"
+	"github.com/rsocket/rsocket-go/rx/mono"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var fakeSockFile string
@@ -43,6 +49,8 @@ func TestTcpClient(t *testing.T) {
 			SetAddr(":7878").
 			SetHostAndPort("127.0.0.1", 7878).
 			SetTLSConfig(fakeTlsConfig).
+			SetKeepAlive(true, 30*time.Second).
+			SetNoDelay(false).
 			Build()
 	})
 }
@@ -51,6 +59,10 @@ func TestTcpServerBuilder(t *testing.T) {
 	assert.NotPanics(t, func() {
 		rsocket.TCPServer().SetAddr(":7878").Build()
 		rsocket.TCPServer().SetHostAndPort("127.0.0.1", 7878).SetTLSConfig(fakeTlsConfig).Build()
+		rsocket.TCPServer().SetAddr(":7878").SetKeepAlive(false, 0).Build()
+		rsocket.TCPServer().SetAddr(":7878").SetNoDelay(false).Build()
+		rsocket.TCPServer().SetAddr(":7878").SetNetwork("tcp6").SetDualStack(false).Build()
+		rsocket.TCPServer().SetAddr(":7878").SetHexdump(true).Build()
 	})
 }
 
@@ -76,3 +88,78 @@ func TestWebsocketServer(t *testing.T) {
 		assert.NotNil(t, tp)
 	})
 }
+
+// traceRecorder records trace logs only.
+type traceRecorder struct {
+	sync.Mutex
+	traces []string
+}
+
+func (r *traceRecorder) Tracef(format string, args ...interface{}) {
+	r.Lock()
+	r.traces = append(r.traces, fmt.Sprintf(format, args...))
+	r.Unlock()
+}
+
+func (r *traceRecorder) Debugf(format string, args ...interface{}) {
+}
+
+func (r *traceRecorder) Infof(format string, args ...interface{}) {
+}
+
+func (r *traceRecorder) Warnf(format string, args ...interface{}) {
+}
+
+func (r *traceRecorder) Errorf(format string, args ...interface{}) {
+}
+
+func (r *traceRecorder) count(substr string) (n int) {
+	r.Lock()
+	defer r.Unlock()
+	for _, it := range r.traces {
+		if strings.Contains(it, substr) {
+			n++
+		}
+	}
+	return
+}
+
+func TestTCPBuilder_SetHexdump(t *testing.T) {
+	l := &traceRecorder{}
+	logger.SetLogger(l)
+	logger.SetLevel(logger.LevelTrace)
+	defer func() {
+		logger.SetLevel(logger.LevelInfo)
+		logger.SetLogger(nil)
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	started := make(chan struct{})
+	go func() {
+		_ = rsocket.Receive().
+			OnStart(func() {
+				close(started)
+			}).
+			Acceptor(func(setup payload.SetupPayload, sendingSocket rsocket.CloseableRSocket) (rsocket.RSocket, error) {
+				return rsocket.NewAbstractSocket(rsocket.RequestResponse(func(request payload.Payload) mono.Mono {
+					return mono.Just(payload.Clone(request))
+				})), nil
+			}).
+			Transport(rsocket.TCPServer().SetAddr(":8125").SetHexdump(true).Build()).
+			Serve(ctx)
+	}()
+	<-started
+
+	cli, err := rsocket.Connect().
+		Transport(rsocket.TCPClient().SetAddr("127.0.0.1:8125").SetHexdump(true).Build()).
+		Start(ctx)
+	require.NoError(t, err)
+	defer cli.Close()
+
+	res, err := cli.RequestResponse(payload.NewString("hexdump", "")).Block(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "hexdump", res.DataUTF8())
+	// the request and the response are dumped by both sides.
+	assert.Equal(t, 4, l.count("hexdump"))
+}
@@ -0,0 +1,45 @@
+package rsocket
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConfigErrors contains all configuration errors found when validating a builder.
+type ConfigErrors []error
+
+func (c ConfigErrors) Error() string {
+	bu := strings.Builder{}
+	bu.WriteString("rsocket: invalid configuration: ")
+	for i, err := range c {
+		if i > 0 {
+			bu.WriteString("; ")
+		}
+		bu.WriteString(err.Error())
+	}
+	return bu.String()
+}
+
+// configValidator collects configuration errors.
+type configValidator struct {
+	errs ConfigErrors
+}
+
+func (v *configValidator) check(ok bool, format string, args ...interface{}) {
+	if !ok {
+		v.errs = append(v.errs, fmt.Errorf(format, args...))
+	}
+}
+
+func (v *configValidator) add(err error) {
+	if err != nil {
+		v.errs = append(v.errs, err)
+	}
+}
+
+func (v *configValidator) result() error {
+	if len(v.errs) < 1 {
+		return nil
+	}
+	return v.errs
+}